@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cloud-provider-vsphere/pkg/cli/cloudconfig"
+)
+
+var (
+	// live runs the checks against vCenter and NSX-T
+	live bool
+	// vCenter credentials, for the vCenters without credentials in the config
+	vcUser     string
+	vcPassword string
+	// NSX-T credentials, if the nsxt section has none
+	nsxtUser     string
+	nsxtPassword string
+
+	// output is the file the YAML cloud config is written to, stdout if empty
+	output string
+	// manifest is the file the ConfigMap and secrets are written to, if set
+	manifest      string
+	configMapName string
+	namespace     string
+	secretName    string
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with vSphere cloud provider config files",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate FILE",
+	Short: "Validate a vSphere cloud provider config file",
+	Long: `Reads all sections of a YAML or INI cloud config (global, vcenter, nodes,
+load balancer, NSX-T and route) and reports every problem found, with the line
+it was found at. Unknown and deprecated keys are reported as warnings.
+
+With --live the datacenters are looked up on the vCenters, and the IP pools and
+application profiles of the load balancer classes are resolved on NSX-T.
+`,
+	Example: `# Validate a cloud config
+	vcpctl config validate vsphere.conf
+
+	# Validate a cloud config against vCenter and NSX-T
+	vcpctl config validate vsphere.conf --live --vc-user administrator@vsphere.local --vc-password secret
+`,
+	Args: cobra.ExactArgs(1),
+	Run:  RunValidate,
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert FILE",
+	Short: "Convert an INI vSphere cloud provider config file to YAML",
+	Long: `Converts an INI cloud config, including the load balancer, NSX-T and route
+sections, to the equivalent YAML cloud config. Along with it, a manifest of the
+ConfigMap holding the YAML cloud config can be written. With --secret-name the
+user names and passwords are moved from the cloud config to secrets, which are
+added to the manifest.
+`,
+	Example: `# Print the YAML cloud config
+	vcpctl config convert vsphere.conf
+
+	# Write the YAML cloud config and a manifest with the credentials in a secret
+	vcpctl config convert vsphere.conf -o vsphere.yaml --manifest cloud-config.yaml --secret-name vsphere-creds
+`,
+	Args: cobra.ExactArgs(1),
+	Run:  RunConvert,
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the YAML vSphere cloud provider config",
+	Long: `Prints the JSON Schema of all sections of the YAML cloud config, for editors to
+validate the config with. Unknown keys are rejected, as in strict mode.
+`,
+	Example: `# Write the JSON Schema to a file
+	vcpctl config schema > cloud_config.schema.json
+`,
+	Args: cobra.NoArgs,
+	Run:  RunSchema,
+}
+
+// AddConfig initializes the "config" command.
+func AddConfig(cmd *cobra.Command) {
+	validateCmd.Flags().BoolVar(&live, "live", false, "Check the config against vCenter and NSX-T")
+	validateCmd.Flags().StringVar(&vcUser, "vc-user", "", "vCenter user, for vCenters without credentials in the config")
+	validateCmd.Flags().StringVar(&vcPassword, "vc-password", "", "vCenter password, for vCenters without credentials in the config")
+	validateCmd.Flags().StringVar(&nsxtUser, "nsxt-user", "", "NSX-T user, if the config has no NSX-T credentials")
+	validateCmd.Flags().StringVar(&nsxtPassword, "nsxt-password", "", "NSX-T password, if the config has no NSX-T credentials")
+
+	convertCmd.Flags().StringVarP(&output, "output", "o", "", "File to write the YAML cloud config to, stdout by default")
+	convertCmd.Flags().StringVar(&manifest, "manifest", "", "File to write the manifest of the ConfigMap and secrets to")
+	convertCmd.Flags().StringVar(&configMapName, "name", cloudconfig.DefaultConfigMapName, "Name of the ConfigMap")
+	convertCmd.Flags().StringVar(&namespace, "namespace", cloudconfig.DefaultNamespace, "Namespace of the ConfigMap and secrets")
+	convertCmd.Flags().StringVar(&secretName, "secret-name", "", "Name of the secret to move the credentials to, they are kept in the cloud config if empty")
+
+	configCmd.AddCommand(validateCmd)
+	configCmd.AddCommand(convertCmd)
+	configCmd.AddCommand(schemaCmd)
+	cmd.AddCommand(configCmd)
+}
+
+// RunValidate executes the "config validate" command.
+func RunValidate(cmd *cobra.Command, args []string) {
+	file := args[0]
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var report *cloudconfig.Report
+	if live {
+		report = cloudconfig.ValidateLive(context.Background(), data, &cloudconfig.LiveOptions{
+			VCenterUser:     vcUser,
+			VCenterPassword: vcPassword,
+			NSXTUser:        nsxtUser,
+			NSXTPassword:    nsxtPassword,
+		})
+	} else {
+		report = cloudconfig.Validate(data)
+	}
+	for _, p := range report.Problems {
+		if p.Line > 0 {
+			// file:line: severity: key: message
+			fmt.Printf("%s:%s\n", file, p)
+		} else {
+			fmt.Printf("%s: %s\n", file, p)
+		}
+	}
+	if report.HasErrors() {
+		os.Exit(1)
+	}
+}
+
+// RunConvert executes the "config convert" command.
+func RunConvert(cmd *cobra.Command, args []string) {
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	conversion, err := cloudconfig.Convert(data, &cloudconfig.ConvertOptions{
+		Name:       configMapName,
+		Namespace:  namespace,
+		SecretName: secretName,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		fmt.Print(string(conversion.Config))
+	} else if err := ioutil.WriteFile(output, conversion.Config, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if manifest != "" {
+		if err := ioutil.WriteFile(manifest, conversion.Manifest, 0600); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// RunSchema executes the "config schema" command.
+func RunSchema(cmd *cobra.Command, args []string) {
+	schema, err := cloudconfig.Schema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(string(schema))
+}
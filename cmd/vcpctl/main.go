@@ -23,17 +23,18 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"k8s.io/cloud-provider-vsphere/cmd/vcpctl/config"
 	"k8s.io/cloud-provider-vsphere/cmd/vcpctl/provision"
 )
 
 func main() {
 
 	provision.AddProvision(cmd)
+	config.AddConfig(cmd)
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("\nCompleted!\n")
 }
 
 var cmd = &cobra.Command{
@@ -44,6 +45,7 @@ var cmd = &cobra.Command{
 * Create vSphere role with a minimal set of permissioins.
 * Create vSphere solution user, to be used with CCM
 * Convert old in-tree vsphere.conf configuration files to new configMap
+* Validate vsphere.conf configuration files
 
 `,
 
@@ -98,6 +98,12 @@ func RunProvision(cmd *cobra.Command, args []string) {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+	solutionUserConfig, err := cli.SolutionUserConfig(&o)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(solutionUserConfig)
 	r := cli.Role{RoleName: "k8s-vcp-default"}
 	fmt.Println("Create default role with minimal permissions...")
 	err = cli.CreateRole(ctx, &o, &r)
@@ -111,4 +117,5 @@ func RunProvision(cmd *cobra.Command, args []string) {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Printf("\nCompleted!\n")
 }
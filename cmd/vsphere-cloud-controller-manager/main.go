@@ -75,6 +75,7 @@ func main() {
 	}
 
 	var controllerInitializers map[string]app.InitFunc
+	var cloudConfigDir string
 	command := &cobra.Command{
 		Use:  "vsphere-cloud-controller-manager",
 		Long: `vsphere-cloud-controller-manager manages vSphere cloud resources for a Kubernetes cluster.`,
@@ -102,6 +103,9 @@ func main() {
 			}
 
 			// initialize cloud provider with the cloud provider name and config file provided
+			vsphere.CloudConfigFile = c.ComponentConfig.KubeCloudShared.CloudProvider.CloudConfigFile
+			vsphere.CloudConfigDir = cloudConfigDir
+			vsphereparavirtual.CloudConfigDir = cloudConfigDir
 			cloud, err := cloudprovider.InitCloudProvider(cloudProvider, c.ComponentConfig.KubeCloudShared.CloudProvider.CloudConfigFile)
 			if err != nil {
 				klog.Fatalf("Cloud provider could not be initialized: %v", err)
@@ -146,6 +150,8 @@ func main() {
 	namedFlagSets := s.Flags(KnownControllers(), app.ControllersDisabledByDefault.List())
 	verflag.AddFlags(namedFlagSets.FlagSet("global"))
 	globalflag.AddGlobalFlags(namedFlagSets.FlagSet("global"), command.Name())
+	namedFlagSets.FlagSet("generic").StringVar(&cloudConfigDir, "cloud-config-dir", "",
+		"Directory of YAML fragments merged into the cloud config file in the order of their file names. vCenters and load balancer classes may only be defined once, other settings are overridden by later fragments.")
 
 	for _, f := range namedFlagSets.FlagSets {
 		fs.AddFlagSet(f)
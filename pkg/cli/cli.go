@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	klog "k8s.io/klog/v2"
 
@@ -139,8 +140,9 @@ func CreateSolutionUser(ctx context.Context, o *ClientOption) error {
 			block, _ := pem.Decode([]byte(cert))
 			if block != nil {
 				u.solution.Certificate = base64.StdEncoding.EncodeToString(block.Bytes)
+			} else {
+				u.solution.Certificate = cert
 			}
-			u.solution.Certificate = cert
 		}
 		if u.solution.Certificate == "" {
 			return fmt.Errorf("Need solution user certificate (--cert) to create solution user")
@@ -160,3 +162,27 @@ func CreateSolutionUser(ctx context.Context, o *ClientOption) error {
 		return nil
 	}))
 }
+
+// SolutionUserConfig returns the cloud config and the secret keys with which the
+// vSphere cloud provider logs in as the solution user created by CreateSolutionUser.
+func SolutionUserConfig(o *ClientOption) (string, error) {
+	certFile, err := filepath.Abs(o.getCredential().cert)
+	if err != nil {
+		return "", err
+	}
+	keyFile := keyFileFor(certFile)
+	server := o.url.Hostname()
+	return fmt.Sprintf(solutionUserConfig, server, server, certFile, keyFile, server, certFile, server, keyFile), nil
+}
+
+const solutionUserConfig = `# Cloud config (YAML) of the vSphere cloud provider:
+vcenter:
+  %s:
+    server: %s
+    certFile: %s
+    keyFile: %s
+
+# Alternatively, add the following keys to the credentials secret:
+#   %s.cert: contents of %s
+#   %s.key: contents of %s
+`
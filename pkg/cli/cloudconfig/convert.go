@@ -0,0 +1,435 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	gcfg "gopkg.in/gcfg.v1"
+	yaml "gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "sigs.k8s.io/yaml"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	lcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap the cloud config is
+	// mounted from by the manifests of the cloud provider
+	DefaultConfigMapName = "cloud-config"
+	// DefaultNamespace is the namespace the cloud provider runs in
+	DefaultNamespace = "kube-system"
+	// ConfigMapKey is the key of the ConfigMap holding the cloud config
+	ConfigMapKey = "vsphere.conf"
+	// nsxtSecretSuffix is appended to the name of the secret of the vCenter
+	// credentials to name the secret of the NSX-T credentials
+	nsxtSecretSuffix = "-nsxt"
+)
+
+// ConvertOptions configure the manifest of a converted cloud config
+type ConvertOptions struct {
+	// Name and Namespace of the ConfigMap
+	Name      string
+	Namespace string
+	// SecretName is the name of the secret the user names and passwords of the
+	// cloud config are moved to, if set. The NSX-T credentials are moved to the
+	// secret of the same name with the -nsxt suffix.
+	SecretName string
+}
+
+// Conversion is the result of converting an INI cloud config
+type Conversion struct {
+	// Config is the YAML cloud config
+	Config []byte
+	// Manifest is the ConfigMap holding Config, followed by the secrets of the
+	// credentials if any
+	Manifest []byte
+}
+
+// iniConfig holds all sections of an INI cloud config as written, before the
+// defaults are applied by the readers of the sections
+type iniConfig struct {
+	ccfg.CPIConfigINI
+	lcfg.LBConfigINI
+	ncfg.NsxtConfigINI
+	rcfg.RouteConfigINI
+}
+
+// Convert converts an INI cloud config to the equivalent YAML cloud config,
+// and returns it along with a manifest to deploy it. The INI cloud config is
+// validated first and not converted if it has errors.
+func Convert(data []byte, opts *ConvertOptions) (*Conversion, error) {
+	if f := DetectFormat(data); f != FormatINI {
+		return nil, fmt.Errorf("the cloud config is not INI based")
+	}
+	r, s := validate(data)
+	if r.HasErrors() {
+		var messages []string
+		for _, p := range r.Problems {
+			if p.Severity == SeverityError {
+				messages = append(messages, p.String())
+			}
+		}
+		return nil, fmt.Errorf("the cloud config has errors:\n%s", strings.Join(messages, "\n"))
+	}
+
+	ini := &iniConfig{}
+	ini.VirtualCenter = make(map[string]*vcfg.VirtualCenterConfigINI)
+	if err := gcfg.FatalOnly(gcfg.ReadStringInto(ini, string(data))); err != nil {
+		return nil, err
+	}
+	doc, err := convertSections(ini, s)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = withDefaults(opts)
+	var secrets []*corev1.Secret
+	if opts.SecretName != "" {
+		secrets = moveCredentials(doc, opts)
+	}
+
+	config, err := marshalPruned(doc.sections())
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := marshalManifest(config, secrets, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Conversion{Config: config, Manifest: manifest}, nil
+}
+
+func withDefaults(opts *ConvertOptions) *ConvertOptions {
+	o := ConvertOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Name == "" {
+		o.Name = DefaultConfigMapName
+	}
+	if o.Namespace == "" {
+		o.Namespace = DefaultNamespace
+	}
+	return &o
+}
+
+// yamlDocument holds the sections of the YAML cloud config, nil if absent
+type yamlDocument struct {
+	common vcfg.CommonConfigYAML
+	cpi    ccfg.CPIConfigYAML
+	lb     *lcfg.LBConfigYAML
+	nsxt   *ncfg.NsxtConfigYAML
+	route  *rcfg.RouteConfigYAML
+}
+
+// sections returns the sections in the order of the documentation
+func (doc *yamlDocument) sections() yaml.MapSlice {
+	sections := yaml.MapSlice{
+		{Key: "global", Value: doc.common.Global},
+		{Key: "vcenter", Value: doc.common.Vcenter},
+		{Key: "labels", Value: doc.common.Labels},
+		{Key: "nodes", Value: doc.cpi.Nodes},
+		{Key: "tracing", Value: doc.cpi.Tracing},
+	}
+	if doc.lb != nil {
+		sections = append(sections,
+			yaml.MapItem{Key: "loadBalancer", Value: doc.lb.LoadBalancer},
+			yaml.MapItem{Key: "loadBalancerClass", Value: doc.lb.LoadBalancerClass})
+	}
+	if doc.nsxt != nil {
+		sections = append(sections, yaml.MapItem{Key: "nsxt", Value: doc.nsxt.NSXT})
+	}
+	if doc.route != nil {
+		sections = append(sections, yaml.MapItem{Key: "route", Value: doc.route.Route})
+	}
+	return sections
+}
+
+func convertSections(ini *iniConfig, s *sections) (*yamlDocument, error) {
+	doc := &yamlDocument{}
+	var err error
+
+	copyFields(&doc.common.Global, &ini.Global)
+	if doc.common.Global.VCenterPort, err = parsePort(ini.Global.VCenterPort); err != nil {
+		return nil, fmt.Errorf("global: %v", err)
+	}
+	doc.common.Global.Datacenters = splitList(ini.Global.Datacenters)
+	doc.common.Global.IPFamilyPriority = splitList(ini.Global.IPFamily)
+
+	doc.common.Vcenter = make(map[string]*vcfg.VirtualCenterConfigYAML)
+	for name, vc := range ini.VirtualCenter {
+		vcYAML := &vcfg.VirtualCenterConfigYAML{}
+		copyFields(vcYAML, vc)
+		// the INI section is named after the server unless the server is set,
+		// the YAML section always needs it
+		if vcYAML.VCenterIP == "" {
+			vcYAML.VCenterIP = name
+		}
+		vcYAML.TenantRef = ""
+		vcYAML.SecretRef = ""
+		if vcYAML.VCenterPort, err = parsePort(vc.VCenterPort); err != nil {
+			return nil, fmt.Errorf("virtualcenter %s: %v", name, err)
+		}
+		vcYAML.Datacenters = splitList(vc.Datacenters)
+		vcYAML.IPFamilyPriority = splitList(vc.IPFamily)
+		vcYAML.Labels = vcfg.LabelsYAML{Zone: vc.Zone, Region: vc.Region, Categories: vcfg.SplitLabelCategories(vc.LabelCategories)}
+		doc.common.Vcenter[name] = vcYAML
+	}
+	copyFields(&doc.common.Labels, &ini.Labels)
+	doc.common.Labels.Categories = vcfg.SplitLabelCategories(ini.Labels.Categories)
+	copyFields(&doc.cpi.Nodes, &ini.Nodes)
+	copyFields(&doc.cpi.Tracing, &ini.Tracing)
+
+	if s.lb != nil {
+		doc.lb = &lcfg.LBConfigYAML{}
+		copyFields(&doc.lb.LoadBalancer, &ini.LoadBalancer)
+		doc.lb.LoadBalancer.Monitor = lcfg.MonitorConfigYAML(ini.LoadBalancer.MonitorConfig())
+		if ini.LoadBalancer.RawTags != "" {
+			if err := json.Unmarshal([]byte(ini.LoadBalancer.RawTags), &doc.lb.LoadBalancer.AdditionalTags); err != nil {
+				return nil, fmt.Errorf("unmarshalling load balancer tags failed: %s", err)
+			}
+		}
+		if len(ini.LoadBalancerClass) > 0 {
+			doc.lb.LoadBalancerClass = make(map[string]*lcfg.LoadBalancerClassConfigYAML)
+			for name, class := range ini.LoadBalancerClass {
+				classYAML := &lcfg.LoadBalancerClassConfigYAML{}
+				copyFields(classYAML, class)
+				classYAML.Monitor = lcfg.MonitorConfigYAML(class.MonitorConfig())
+				doc.lb.LoadBalancerClass[name] = classYAML
+			}
+		}
+	}
+	if s.nsxt != nil {
+		doc.nsxt = &ncfg.NsxtConfigYAML{}
+		copyFields(&doc.nsxt.NSXT, &ini.NSXT)
+	}
+	if s.route != nil {
+		doc.route = &rcfg.RouteConfigYAML{}
+		copyFields(&doc.route.Route, &ini.Route)
+	}
+	return doc, nil
+}
+
+// copyFields copies the fields of src to the fields of dst of the same name
+// and type. The INI and YAML structs of a section name their fields alike,
+// the fields whose types differ are converted by the callers.
+func copyFields(dst interface{}, src interface{}) {
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src).Elem()
+	for i := 0; i < d.NumField(); i++ {
+		field := d.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		v := s.FieldByName(field.Name)
+		if v.IsValid() && v.Type() == field.Type {
+			d.Field(i).Set(v)
+		}
+	}
+}
+
+func parsePort(port string) (uint, error) {
+	if port == "" {
+		return 0, nil
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port %q", port)
+	}
+	return uint(p), nil
+}
+
+// splitList splits a comma separated INI value the way the INI readers do
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// moveCredentials moves the user names and passwords of the vCenters and
+// NSX-T to secrets, and returns the secrets.
+func moveCredentials(doc *yamlDocument, opts *ConvertOptions) []*corev1.Secret {
+	global := &doc.common.Global
+	vcSecret := newSecret(opts.SecretName, opts.Namespace)
+
+	// the vCenters the global credentials apply to, by server
+	servers := map[string]*vcfg.VirtualCenterConfigYAML{}
+	if global.VCenterIP != "" {
+		if _, ok := doc.common.Vcenter[global.VCenterIP]; !ok {
+			servers[global.VCenterIP] = nil
+		}
+	}
+	for _, vc := range doc.common.Vcenter {
+		servers[vc.VCenterIP] = vc
+	}
+	for server, vc := range servers {
+		user, password := global.User, global.Password
+		if vc != nil {
+			if vc.SecretName != "" || vc.CertFile != "" || vc.CredentialProvider.Type != "" {
+				continue
+			}
+			if vc.User != "" {
+				user, password = vc.User, vc.Password
+			}
+			if vc.Password != "" {
+				password = vc.Password
+			}
+			vc.User, vc.Password = "", ""
+		}
+		if user == "" {
+			continue
+		}
+		vcSecret.StringData[server+".username"] = user
+		vcSecret.StringData[server+".password"] = password
+	}
+
+	var secrets []*corev1.Secret
+	if len(vcSecret.StringData) > 0 {
+		global.User, global.Password = "", ""
+		global.SecretName, global.SecretNamespace = opts.SecretName, opts.Namespace
+		secrets = append(secrets, vcSecret)
+	}
+	if doc.nsxt != nil && doc.nsxt.NSXT.User != "" && doc.nsxt.NSXT.SecretName == "" {
+		nsxt := &doc.nsxt.NSXT
+		nsxtSecret := newSecret(opts.SecretName+nsxtSecretSuffix, opts.Namespace)
+		nsxtSecret.StringData[ncfg.UsernameKeyInSecret] = nsxt.User
+		nsxtSecret.StringData[ncfg.PasswordKeyInSecret] = nsxt.Password
+		nsxt.User, nsxt.Password = "", ""
+		nsxt.SecretName, nsxt.SecretNamespace = nsxtSecret.Name, nsxtSecret.Namespace
+		secrets = append(secrets, nsxtSecret)
+	}
+	return secrets
+}
+
+func newSecret(name string, namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		StringData: map[string]string{},
+	}
+}
+
+func marshalManifest(config []byte, secrets []*corev1.Secret, opts *ConvertOptions) ([]byte, error) {
+	objects := []interface{}{&corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: opts.Namespace},
+		Data:       map[string]string{ConfigMapKey: string(config)},
+	}}
+	for _, secret := range secrets {
+		objects = append(objects, secret)
+	}
+
+	var b bytes.Buffer
+	for i, object := range objects {
+		data, err := k8syaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		// the objects are not read back from the API server
+		b.Write(bytes.Replace(data, []byte("  creationTimestamp: null\n"), nil, 1))
+	}
+	return b.Bytes(), nil
+}
+
+// marshalPruned marshals the sections of a cloud config without the keys that
+// are not set, as the readers treat a zero value like an absent key.
+func marshalPruned(sections yaml.MapSlice) ([]byte, error) {
+	var doc yaml.MapSlice
+	for _, section := range sections {
+		if value := prune(reflect.ValueOf(section.Value)); value != nil {
+			doc = append(doc, yaml.MapItem{Key: section.Key, Value: value})
+		}
+	}
+	return yaml.Marshal(doc)
+}
+
+// prune returns the value to marshal for a field of a section, without the
+// zero values, and the structs, maps and slices left empty. It returns nil if
+// nothing is left. Pointers are kept if set, as they tell a zero value from an
+// absent key.
+func prune(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() != reflect.Struct {
+			return v.Elem().Interface()
+		}
+		return prune(v.Elem())
+	case reflect.Struct:
+		var fields yaml.MapSlice
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if value := prune(v.Field(i)); value != nil {
+				fields = append(fields, yaml.MapItem{Key: yamlKeyName(f), Value: value})
+			}
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		return fields
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		entries := make(yaml.MapSlice, 0, len(keys))
+		for _, key := range keys {
+			entry := v.MapIndex(reflect.ValueOf(key))
+			value := prune(entry)
+			if entry.Kind() == reflect.String {
+				// values of a map are kept, e.g. tags without a value
+				value = entry.Interface()
+			}
+			entries = append(entries, yaml.MapItem{Key: key, Value: value})
+		}
+		return entries
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		return v.Interface()
+	}
+	if v.IsZero() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8syaml "sigs.k8s.io/yaml"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	lcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+)
+
+const convertINIConfig = `
+[Global]
+user = "user"
+password = "password"
+port = "443"
+insecure-flag = "1"
+datacenters = "us-west"
+soap-roundtrip-count = 5
+session-max-age = 3600
+rate-limit-qps = 2.5
+ip-family = "ipv4"
+
+[VirtualCenter "tenant1"]
+server = "10.0.0.1"
+datacenters = "dc1,dc2"
+proxy-url = "http://proxy:3128"
+label-categories = "team, cost-center"
+
+[VirtualCenter "10.0.0.2"]
+user = "vc2-user"
+password = "vc2-password"
+port = "8443"
+ip-family = "ipv4,ipv6"
+
+[Labels]
+zone = "k8s-zone"
+region = "k8s-region"
+categories = "team"
+
+[Nodes]
+internal-network-subnet-cidr = "192.0.2.0/24"
+external-vm-network-name = "External"
+
+[Tracing]
+endpoint = "collector:4318"
+sampling-ratio = 0
+
+[LoadBalancer]
+ip-pool-name = "pool1"
+size = "MEDIUM"
+lb-service-id = "lbs"
+tcp-app-profile-name = "tcp"
+udp-app-profile-name = "udp"
+tags = {\"tag1\": \"value1\", \"tag2\": \"\"}
+monitor-interval = 10
+
+[LoadBalancerClass "public"]
+ip-pool-name = "public"
+monitor-type = "HTTP"
+monitor-request-path = "/healthz"
+monitor-response-status-code = 200
+monitor-response-status-code = 204
+
+[NSXT]
+user = "admin"
+password = "secret"
+host = "nsxt-server"
+
+[Route]
+router-path = "/infra/tier-1s/t1"
+`
+
+func TestConvertRoundTrip(t *testing.T) {
+	conversion, err := Convert([]byte(convertINIConfig), nil)
+	if err != nil {
+		t.Fatalf("Convert err=%v", err)
+	}
+	yamlConfig := conversion.Config
+	if r := Validate(yamlConfig); r.HasErrors() {
+		t.Errorf("expected no errors in the converted config, got %v\n%s", r.Problems, yamlConfig)
+	}
+
+	ini := []byte(convertINIConfig)
+	cpiINI, err := ccfg.ReadCPIConfigINI(ini)
+	if err != nil {
+		t.Fatalf("ReadCPIConfigINI err=%v", err)
+	}
+	cpiYAML, err := ccfg.ReadCPIConfigYAML(yamlConfig)
+	if err != nil {
+		t.Fatalf("ReadCPIConfigYAML err=%v", err)
+	}
+	if !reflect.DeepEqual(cpiINI, cpiYAML) {
+		t.Errorf("CPI config differs\nINI:  %+v\nYAML: %+v", cpiINI, cpiYAML)
+	}
+	for name, vc := range cpiINI.VirtualCenter {
+		if !reflect.DeepEqual(vc, cpiYAML.VirtualCenter[name]) {
+			t.Errorf("vCenter %s differs\nINI:  %+v\nYAML: %+v", name, vc, cpiYAML.VirtualCenter[name])
+		}
+	}
+
+	lbINI, err := lcfg.ReadConfigINI(ini)
+	if err != nil {
+		t.Fatalf("lb ReadConfigINI err=%v", err)
+	}
+	lbYAML, err := lcfg.ReadConfigYAML(yamlConfig)
+	if err != nil {
+		t.Fatalf("lb ReadConfigYAML err=%v", err)
+	}
+	if !reflect.DeepEqual(lbINI, lbYAML) {
+		t.Errorf("load balancer config differs\nINI:  %+v\nYAML: %+v", lbINI, lbYAML)
+	}
+
+	nsxtINI, err := ncfg.ReadConfigINI(ini)
+	if err != nil {
+		t.Fatalf("nsxt ReadConfigINI err=%v", err)
+	}
+	nsxtYAML, err := ncfg.ReadConfigYAML(yamlConfig)
+	if err != nil {
+		t.Fatalf("nsxt ReadConfigYAML err=%v", err)
+	}
+	if !reflect.DeepEqual(nsxtINI, nsxtYAML) {
+		t.Errorf("NSX-T config differs\nINI:  %+v\nYAML: %+v", nsxtINI, nsxtYAML)
+	}
+
+	routeINI, err := rcfg.ReadConfigINI(ini)
+	if err != nil {
+		t.Fatalf("route ReadConfigINI err=%v", err)
+	}
+	routeYAML, err := rcfg.ReadConfigYAML(yamlConfig)
+	if err != nil {
+		t.Fatalf("route ReadConfigYAML err=%v", err)
+	}
+	if !reflect.DeepEqual(routeINI, routeYAML) {
+		t.Errorf("route config differs\nINI:  %+v\nYAML: %+v", routeINI, routeYAML)
+	}
+}
+
+func TestConvertManifest(t *testing.T) {
+	conversion, err := Convert([]byte(convertINIConfig), &ConvertOptions{SecretName: "vsphere-creds"})
+	if err != nil {
+		t.Fatalf("Convert err=%v", err)
+	}
+	documents := strings.Split(string(conversion.Manifest), "---\n")
+	if len(documents) != 3 {
+		t.Fatalf("expected a ConfigMap and two secrets, got %s", conversion.Manifest)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := k8syaml.Unmarshal([]byte(documents[0]), cm); err != nil {
+		t.Fatalf("Unmarshal ConfigMap err=%v", err)
+	}
+	if cm.Name != DefaultConfigMapName || cm.Namespace != DefaultNamespace {
+		t.Errorf("unexpected ConfigMap %s/%s", cm.Namespace, cm.Name)
+	}
+	config := cm.Data[ConfigMapKey]
+	if config != string(conversion.Config) {
+		t.Errorf("expected the ConfigMap to hold the converted config, got %s", config)
+	}
+	if strings.Contains(config, "password") {
+		t.Errorf("expected the passwords to be moved to the secrets, got %s", config)
+	}
+
+	vcSecret := &corev1.Secret{}
+	if err := k8syaml.Unmarshal([]byte(documents[1]), vcSecret); err != nil {
+		t.Fatalf("Unmarshal Secret err=%v", err)
+	}
+	expected := map[string]string{
+		"10.0.0.1.username": "user",
+		"10.0.0.1.password": "password",
+		"10.0.0.2.username": "vc2-user",
+		"10.0.0.2.password": "vc2-password",
+	}
+	if vcSecret.Name != "vsphere-creds" || !reflect.DeepEqual(vcSecret.StringData, expected) {
+		t.Errorf("unexpected vCenter secret %s: %v", vcSecret.Name, vcSecret.StringData)
+	}
+
+	nsxtSecret := &corev1.Secret{}
+	if err := k8syaml.Unmarshal([]byte(documents[2]), nsxtSecret); err != nil {
+		t.Fatalf("Unmarshal Secret err=%v", err)
+	}
+	expected = map[string]string{ncfg.UsernameKeyInSecret: "admin", ncfg.PasswordKeyInSecret: "secret"}
+	if nsxtSecret.Name != "vsphere-creds-nsxt" || !reflect.DeepEqual(nsxtSecret.StringData, expected) {
+		t.Errorf("unexpected NSX-T secret %s: %v", nsxtSecret.Name, nsxtSecret.StringData)
+	}
+
+	cpi, err := ccfg.ReadCPIConfigYAML([]byte(config))
+	if err != nil {
+		t.Fatalf("ReadCPIConfigYAML err=%v", err)
+	}
+	if cpi.Global.SecretName != "vsphere-creds" || cpi.Global.SecretNamespace != DefaultNamespace {
+		t.Errorf("expected the config to refer to the secret, got %s/%s", cpi.Global.SecretNamespace, cpi.Global.SecretName)
+	}
+	nsxt, err := ncfg.ReadConfigYAML([]byte(config))
+	if err != nil {
+		t.Fatalf("nsxt ReadConfigYAML err=%v", err)
+	}
+	if nsxt.SecretName != "vsphere-creds-nsxt" {
+		t.Errorf("expected the NSX-T config to refer to the secret, got %s", nsxt.SecretName)
+	}
+}
+
+func TestConvertYAML(t *testing.T) {
+	if _, err := Convert([]byte("global:\n  user: user\n"), nil); err == nil {
+		t.Error("expected a YAML config not to be converted")
+	}
+}
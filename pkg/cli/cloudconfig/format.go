@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	lcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+)
+
+// Format of a cloud config file
+type Format string
+
+const (
+	// FormatYAML is the YAML based cloud config
+	FormatYAML Format = "yaml"
+	// FormatINI is the deprecated INI based cloud config
+	FormatINI Format = "ini"
+)
+
+// DetectFormat returns the format of a cloud config. A cloud config is INI
+// based if its first line that is neither blank nor a comment is a section
+// header.
+func DetectFormat(data []byte) Format {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			return FormatINI
+		}
+		return FormatYAML
+	}
+	return FormatYAML
+}
+
+// formatSpec describes how the sections of a cloud config are named and read
+// in one of the formats.
+type formatSpec struct {
+	global            string
+	vcenter           string
+	nodes             string
+	tracing           string
+	loadBalancer      string
+	loadBalancerClass string
+	nsxt              string
+	route             string
+
+	readCPI   func([]byte) (*ccfg.CPIConfig, error)
+	readLB    func([]byte) (*lcfg.LBConfig, error)
+	readNSXT  func([]byte) (*ncfg.Config, error)
+	readRoute func([]byte) (*rcfg.Config, error)
+}
+
+var formats = map[Format]*formatSpec{
+	FormatYAML: {
+		global:            "global",
+		vcenter:           "vcenter",
+		nodes:             "nodes",
+		tracing:           "tracing",
+		loadBalancer:      "loadBalancer",
+		loadBalancerClass: "loadBalancerClass",
+		nsxt:              "nsxt",
+		route:             "route",
+		readCPI:           ccfg.ReadCPIConfigYAML,
+		readLB:            lcfg.ReadConfigYAML,
+		readNSXT:          ncfg.ReadConfigYAML,
+		readRoute:         rcfg.ReadConfigYAML,
+	},
+	FormatINI: {
+		global:            "global",
+		vcenter:           "virtualcenter",
+		nodes:             "nodes",
+		tracing:           "tracing",
+		loadBalancer:      "loadbalancer",
+		loadBalancerClass: "loadbalancerclass",
+		nsxt:              "nsxt",
+		route:             "route",
+		readCPI:           ccfg.ReadCPIConfigINI,
+		readLB:            lcfg.ReadConfigINI,
+		readNSXT:          ncfg.ReadConfigINI,
+		readRoute:         rcfg.ReadConfigINI,
+	},
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	gcfg "gopkg.in/gcfg.v1"
+	yaml "gopkg.in/yaml.v3"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	lcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+)
+
+// keyTree describes the keys a section of the cloud config accepts.
+type keyTree struct {
+	// fields are the keys of a section, nil if the key holds a value or the
+	// keys of the section are names
+	fields map[string]*keyTree
+	// elem describes the entries of a section whose keys are names, such as
+	// the vCenters
+	elem *keyTree
+	// deprecated describes the replacement of a deprecated key
+	deprecated string
+}
+
+func (t *keyTree) isSection() bool {
+	return t.fields != nil || t.elem != nil
+}
+
+// yamlKeys and iniKeys are the keys of all sections of the cloud config, as
+// every section is read from the same file.
+var (
+	yamlKeys = deprecate(mergeKeys(
+		newKeyTree(reflect.TypeOf(ccfg.CPIConfigYAML{}), yamlKeyName),
+		newKeyTree(reflect.TypeOf(lcfg.LBConfigYAML{}), yamlKeyName),
+		newKeyTree(reflect.TypeOf(ncfg.NsxtConfigYAML{}), yamlKeyName),
+		newKeyTree(reflect.TypeOf(rcfg.RouteConfigYAML{}), yamlKeyName),
+	), "use the vcenter section to specify the vCenter servers", "global", "server")
+	iniKeys = deprecate(mergeKeys(
+		newKeyTree(reflect.TypeOf(ccfg.CPIConfigINI{}), iniKeyName),
+		newKeyTree(reflect.TypeOf(lcfg.LBConfigINI{}), iniKeyName),
+		newKeyTree(reflect.TypeOf(ncfg.NsxtConfigINI{}), iniKeyName),
+		newKeyTree(reflect.TypeOf(rcfg.RouteConfigINI{}), iniKeyName),
+	), "use VirtualCenter sections to specify the vCenter servers", "global", "server")
+)
+
+// newKeyTree returns the keys of a type, named by the given function.
+// Embedded structs are inlined, as the readers of the cloud config decode
+// them separately.
+func newKeyTree(t reflect.Type, name func(reflect.StructField) string) *keyTree {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		tree := &keyTree{fields: map[string]*keyTree{}}
+		addFields(tree, t, name)
+		return tree
+	case reflect.Map:
+		return &keyTree{elem: newKeyTree(t.Elem(), name)}
+	}
+	return &keyTree{}
+}
+
+func addFields(tree *keyTree, t reflect.Type, name func(reflect.StructField) string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			addFields(tree, f.Type, name)
+			continue
+		}
+		if key := name(f); key != "-" {
+			tree.fields[key] = newKeyTree(f.Type, name)
+		}
+	}
+}
+
+// mergeKeys returns the union of the keys of sections
+func mergeKeys(trees ...*keyTree) *keyTree {
+	merged := &keyTree{fields: map[string]*keyTree{}}
+	for _, tree := range trees {
+		for key, child := range tree.fields {
+			merged.fields[key] = child
+		}
+	}
+	return merged
+}
+
+// deprecate marks the key at the given path as deprecated
+func deprecate(tree *keyTree, replacement string, path ...string) *keyTree {
+	key := tree
+	for _, name := range path {
+		key = key.fields[name]
+	}
+	key.deprecated = replacement
+	return tree
+}
+
+// yamlKeyName returns the key of a field as yaml.v2 decodes it
+func yamlKeyName(f reflect.StructField) string {
+	if tag := strings.Split(f.Tag.Get("yaml"), ",")[0]; tag != "" {
+		return tag
+	}
+	return strings.ToLower(f.Name)
+}
+
+// iniKeyName returns the key of a field as gcfg matches it, normalized by iniKey
+func iniKeyName(f reflect.StructField) string {
+	if tag := strings.Split(f.Tag.Get("gcfg"), ",")[0]; tag != "" {
+		return iniKey(tag)
+	}
+	return iniKey(f.Name)
+}
+
+// iniKey normalizes the name of an INI section or variable, as gcfg matches
+// them case insensitively and with dashes in place of underscores.
+func iniKey(name string) string {
+	return strings.ToLower(strings.Replace(name, "-", "_", -1))
+}
+
+// joinKey appends a key to the path of its section
+func joinKey(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// checkYAMLKeys reports the unknown and deprecated keys of a YAML cloud config
+// and returns the lines of its keys by path.
+func (r *Report) checkYAMLKeys(data []byte) map[string]int {
+	lines := map[string]int{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		r.addError(0, "", err)
+		return lines
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		r.add(SeverityError, 0, "", "the cloud config is not a YAML mapping")
+		return lines
+	}
+	r.walkYAML(doc.Content[0], yamlKeys, "", lines)
+	return lines
+}
+
+func (r *Report) walkYAML(node *yaml.Node, tree *keyTree, path string, lines map[string]int) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		// values of the wrong type are reported by the readers of the sections
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keyPath := joinKey(path, key.Value)
+		lines[keyPath] = key.Line
+
+		child := tree.elem
+		if tree.fields != nil {
+			child = tree.fields[key.Value]
+			if child == nil {
+				r.add(SeverityWarning, key.Line, keyPath, "unknown key")
+				continue
+			}
+			if child.deprecated != "" {
+				r.add(SeverityWarning, key.Line, keyPath, "deprecated, %s", child.deprecated)
+			}
+		}
+		if child.isSection() {
+			r.walkYAML(value, child, keyPath, lines)
+		}
+	}
+}
+
+var (
+	iniSection  = regexp.MustCompile(`^\[\s*([^\s"\]]+)\s*("((?:[^"\\]|\\.)*)")?\s*\]`)
+	iniVariable = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*)\s*(=|;|#|$)`)
+)
+
+// checkINIKeys reports the unknown and deprecated sections and variables of
+// an INI cloud config and returns the lines of its sections and variables by
+// path.
+func (r *Report) checkINIKeys(data []byte) map[string]int {
+	lines := map[string]int{}
+	// gcfg reports syntax errors as fatal and data it cannot store as warnings
+	if err := gcfg.FatalOnly(gcfg.ReadStringInto(&struct{}{}, string(data))); err != nil {
+		r.addError(0, "", err)
+		return lines
+	}
+
+	var section *keyTree
+	var sectionName, sectionPath string
+	continued := false
+	for i, text := range strings.Split(string(data), "\n") {
+		line := strings.TrimSpace(text)
+		number := i + 1
+		if continued {
+			continued = strings.HasSuffix(line, `\`)
+			continue
+		}
+		continued = strings.HasSuffix(line, `\`)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if m := iniSection.FindStringSubmatch(line); m != nil {
+			named := m[2] != ""
+			sectionName, sectionPath = m[1], iniKey(m[1])
+			if named {
+				sectionName += " " + m[2]
+				sectionPath = joinKey(sectionPath, m[3])
+			}
+			if _, ok := lines[sectionPath]; !ok {
+				lines[sectionPath] = number
+			}
+
+			tree := iniKeys.fields[iniKey(m[1])]
+			section = nil
+			switch {
+			case tree == nil:
+				r.add(SeverityWarning, number, sectionName, "unknown section")
+			case tree.elem != nil && !named:
+				r.add(SeverityWarning, number, sectionName, "unknown section, the section requires a name, e.g. [%s \"name\"]", m[1])
+			case tree.elem != nil:
+				section = tree.elem
+			case named:
+				r.add(SeverityWarning, number, sectionName, "unknown section, the section does not take a name")
+			default:
+				section = tree
+			}
+			continue
+		}
+
+		m := iniVariable.FindStringSubmatch(line)
+		if m == nil || section == nil {
+			continue
+		}
+		keyPath := joinKey(sectionPath, iniKey(m[1]))
+		if _, ok := lines[keyPath]; !ok {
+			lines[keyPath] = number
+		}
+		child := section.fields[iniKey(m[1])]
+		if child == nil {
+			r.add(SeverityWarning, number, joinKey(sectionName, m[1]), "unknown key")
+		} else if child.deprecated != "" {
+			r.add(SeverityWarning, number, joinKey(sectionName, m[1]), "deprecated, %s", child.deprecated)
+		}
+	}
+	return lines
+}
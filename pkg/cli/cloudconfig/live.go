@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+	"k8s.io/cloud-provider-vsphere/pkg/nsxt"
+)
+
+// LiveOptions are the credentials used for the live checks, for the vCenters
+// and NSX-T managers whose credentials are not part of the cloud config, e.g.
+// as they are read from a secret.
+type LiveOptions struct {
+	VCenterUser     string
+	VCenterPassword string
+	NSXTUser        string
+	NSXTPassword    string
+}
+
+// ValidateLive validates a cloud config like Validate, and if it can be read,
+// checks that the datacenters exist on the vCenters and that the IP pools and
+// application profiles of the load balancer classes resolve on NSX-T.
+func ValidateLive(ctx context.Context, data []byte, opts *LiveOptions) *Report {
+	r, s := validate(data)
+	if r.HasErrors() {
+		return r
+	}
+	spec := formats[r.Format]
+	if s.cpi != nil {
+		for _, name := range sortedKeys(s.cpi.VirtualCenter) {
+			key := joinKey(spec.vcenter, name)
+			r.checkVCenter(ctx, s.cpi.VirtualCenter[name], opts, s.lines[key], key)
+		}
+	}
+	if s.lb != nil && s.nsxt != nil {
+		r.checkLoadBalancer(s, opts)
+	}
+	r.sort()
+	return r
+}
+
+func (r *Report) checkVCenter(ctx context.Context, vc *vcfg.VirtualCenterConfig, opts *LiveOptions, line int, key string) {
+	user, password := vc.User, vc.Password
+	if user == "" && vc.CertFile == "" {
+		user, password = opts.VCenterUser, opts.VCenterPassword
+	}
+	if user == "" && vc.CertFile == "" {
+		r.add(SeverityWarning, line, key, "no credentials, the vCenter is not checked")
+		return
+	}
+
+	// validated with the config
+	tlsMinVersion, _ := vcfg.TLSVersion(vc.TLSMinVersion)
+	conn := &vclib.VSphereConnection{
+		Username:          user,
+		Password:          password,
+		CertFile:          vc.CertFile,
+		KeyFile:           vc.KeyFile,
+		Hostname:          vc.VCenterIP,
+		Insecure:          vc.InsecureFlag,
+		RoundTripperCount: vc.RoundTripperCount,
+		Port:              vc.VCenterPort,
+		CACert:            vc.CAFile,
+		Thumbprint:        vc.Thumbprint,
+		ProxyURL:          vc.ProxyURL,
+		ConnectTimeout:    time.Duration(vc.ConnectTimeout) * time.Second,
+		RequestTimeout:    time.Duration(vc.RequestTimeout) * time.Second,
+		TLSMinVersion:     tlsMinVersion,
+	}
+	if err := conn.Connect(ctx); err != nil {
+		r.add(SeverityError, line, key, "connecting to the vCenter failed: %v", err)
+		return
+	}
+	defer conn.Logout(ctx)
+
+	if vc.Datacenters == "" {
+		dcs, err := vclib.GetAllDatacenter(ctx, conn)
+		if err != nil {
+			r.add(SeverityError, line, key, "listing the datacenters failed: %v", err)
+		} else if len(dcs) == 0 {
+			r.add(SeverityWarning, line, key, "the vCenter has no datacenters")
+		}
+		return
+	}
+	for _, dc := range strings.Split(vc.Datacenters, ",") {
+		dc = strings.TrimSpace(dc)
+		if dc == "" {
+			continue
+		}
+		if _, err := vclib.GetDatacenter(ctx, conn, dc); err != nil {
+			r.add(SeverityError, line, key, "datacenter %s: %v", dc, err)
+		}
+	}
+}
+
+func (r *Report) checkLoadBalancer(s *sections, opts *LiveOptions) {
+	spec := formats[r.Format]
+	nsxtLine := s.lines[spec.nsxt]
+	cfg := *s.nsxt
+	if cfg.User == "" && cfg.VMCAccessToken == "" && cfg.ClientAuthCertFile == "" {
+		cfg.User, cfg.Password = opts.NSXTUser, opts.NSXTPassword
+	}
+	if cfg.User == "" && cfg.VMCAccessToken == "" && cfg.ClientAuthCertFile == "" {
+		r.add(SeverityWarning, nsxtLine, spec.nsxt, "no credentials, NSX-T is not checked")
+		return
+	}
+
+	cm, err := nsxt.NewConnectorManager(&cfg)
+	if err != nil {
+		r.add(SeverityError, nsxtLine, spec.nsxt, "connecting to NSX-T failed: %v", err)
+		return
+	}
+	problems, err := loadbalancer.ValidateClasses(s.lb, cm.GetConnector())
+	if err != nil {
+		r.add(SeverityError, nsxtLine, spec.nsxt, "%v", err)
+		return
+	}
+	names := make([]string, 0, len(problems))
+	for name := range problems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lbLine, _ := s.sectionLine(spec.loadBalancer, spec.loadBalancerClass)
+	for _, name := range names {
+		key := joinKey(spec.loadBalancerClass, name)
+		line, ok := s.lines[key]
+		if !ok {
+			key, line = spec.loadBalancer, lbLine
+		}
+		r.add(SeverityError, line, key, "load balancer class %s: %v", name, problems[name])
+	}
+}
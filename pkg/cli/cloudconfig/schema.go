@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"encoding/json"
+	"reflect"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	lcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+)
+
+// SchemaFile is the path of the published JSON Schema of the YAML cloud
+// config, relative to the root of the repository.
+const SchemaFile = "docs/book/cloud_config.schema.json"
+
+// jsonSchema is the subset of JSON Schema draft-07 used to describe the YAML
+// cloud config.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+}
+
+// Schema returns the JSON Schema of the YAML cloud config, generated from the
+// structs all sections of the config are read into. Unknown keys are not
+// allowed, as in strict mode.
+func Schema() ([]byte, error) {
+	schema := &jsonSchema{
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		Title:       "vSphere cloud provider configuration",
+		Description: "YAML cloud config of the vSphere cloud provider",
+		Type:        "object",
+		Properties:  map[string]*jsonSchema{},
+	}
+	for _, section := range []interface{}{
+		ccfg.CPIConfigYAML{},
+		lcfg.LBConfigYAML{},
+		ncfg.NsxtConfigYAML{},
+		rcfg.RouteConfigYAML{},
+	} {
+		addSchemaFields(schema, reflect.TypeOf(section))
+	}
+	addDeprecation(yamlKeys, schema)
+	schema.AdditionalProperties = false
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// newSchema returns the schema of a type as yaml.v2 decodes it
+func newSchema(t reflect.Type) *jsonSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: false}
+		addSchemaFields(schema, t)
+		return schema
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: newSchema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: newSchema(t.Elem())}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	}
+	return &jsonSchema{}
+}
+
+// addSchemaFields adds the fields of a struct to the properties of schema.
+// Embedded structs are inlined, like newKeyTree does.
+func addSchemaFields(schema *jsonSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			addSchemaFields(schema, f.Type)
+			continue
+		}
+		if key := yamlKeyName(f); key != "-" {
+			schema.Properties[key] = newSchema(f.Type)
+		}
+	}
+}
+
+// addDeprecation copies the deprecations of the keys to the descriptions of
+// their schemas
+func addDeprecation(tree *keyTree, schema *jsonSchema) {
+	if tree.deprecated != "" {
+		schema.Description = "Deprecated: " + tree.deprecated
+	}
+	for key, child := range tree.fields {
+		if property, ok := schema.Properties[key]; ok {
+			addDeprecation(child, property)
+		}
+	}
+	if elem, ok := schema.AdditionalProperties.(*jsonSchema); ok && tree.elem != nil {
+		addDeprecation(tree.elem, elem)
+	}
+}
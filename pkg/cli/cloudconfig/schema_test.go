@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSchemaPublished(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema err=%v", err)
+	}
+	published, err := ioutil.ReadFile(filepath.Join("..", "..", "..", SchemaFile))
+	if err != nil {
+		t.Fatalf("ReadFile err=%v", err)
+	}
+	if string(schema) != string(published) {
+		t.Errorf("%s is out of date, run: make schema", SchemaFile)
+	}
+}
+
+func TestSchemaKeys(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema err=%v", err)
+	}
+	schema := &jsonSchema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		t.Fatalf("Unmarshal err=%v", err)
+	}
+	compareSchemaKeys(t, "", yamlKeys, schema)
+}
+
+// compareSchemaKeys checks the schema accepts exactly the keys the validator
+// knows of
+func compareSchemaKeys(t *testing.T, path string, tree *keyTree, schema *jsonSchema) {
+	switch {
+	case tree.fields != nil:
+		if schema.Type != "object" || schema.AdditionalProperties != false {
+			t.Errorf("%s: expected a closed object, got %+v", path, schema)
+			return
+		}
+		if keys, properties := sortedTreeKeys(tree), sortedProperties(schema); !reflect.DeepEqual(keys, properties) {
+			t.Errorf("%s: expected properties %v, got %v", path, keys, properties)
+			return
+		}
+		for key, child := range tree.fields {
+			compareSchemaKeys(t, joinKey(path, key), child, schema.Properties[key])
+		}
+	case tree.elem != nil:
+		elem, ok := schema.AdditionalProperties.(map[string]interface{})
+		if schema.Type != "object" || !ok {
+			t.Errorf("%s: expected a map, got %+v", path, schema)
+			return
+		}
+		data, _ := json.Marshal(elem)
+		elemSchema := &jsonSchema{}
+		if err := json.Unmarshal(data, elemSchema); err != nil {
+			t.Fatalf("Unmarshal err=%v", err)
+		}
+		compareSchemaKeys(t, joinKey(path, "*"), tree.elem, elemSchema)
+	case schema.Type == "object" && schema.AdditionalProperties == false:
+		t.Errorf("%s: expected a value, got %+v", path, schema)
+	}
+}
+
+func sortedTreeKeys(tree *keyTree) []string {
+	var keys []string
+	for key := range tree.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedProperties(schema *jsonSchema) []string {
+	var keys []string
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
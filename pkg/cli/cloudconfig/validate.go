@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml2 "gopkg.in/yaml.v2"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	lcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+)
+
+// Severity of a Problem
+type Severity string
+
+const (
+	// SeverityError is a problem that keeps the cloud provider from starting,
+	// or disables the feature configured by the section
+	SeverityError Severity = "error"
+	// SeverityWarning is a problem the cloud provider ignores
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is an issue found in a cloud config
+type Problem struct {
+	Severity Severity
+	// Line of the cloud config the problem was found at, 0 if unknown
+	Line int
+	// Key is the section or key the problem was found at
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "%d: ", p.Line)
+	}
+	fmt.Fprintf(&b, "%s: ", p.Severity)
+	if p.Key != "" {
+		fmt.Fprintf(&b, "%s: ", p.Key)
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// Report lists the problems found in a cloud config, ordered by line
+type Report struct {
+	Format   Format
+	Problems []Problem
+}
+
+// HasErrors returns true if any of the problems is an error
+func (r *Report) HasErrors() bool {
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Report) add(severity Severity, line int, key string, format string, args ...interface{}) {
+	p := Problem{Severity: severity, Line: line, Key: key, Message: fmt.Sprintf(format, args...)}
+	for _, existing := range r.Problems {
+		if existing == p {
+			return
+		}
+	}
+	r.Problems = append(r.Problems, p)
+}
+
+var (
+	// errors of yaml.v2 and yaml.v3
+	yamlLineError = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	// syntax errors of gcfg
+	iniLineError = regexp.MustCompile(`^(\d+):\d+: (.*)$`)
+)
+
+// addError adds an error, at the line the error refers to if it does so, or
+// else at the given line.
+func (r *Report) addError(line int, key string, err error) {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml2.TypeError); ok {
+		messages = typeErr.Errors
+	}
+	for _, message := range messages {
+		message = strings.TrimSpace(message)
+		if m := yamlLineError.FindStringSubmatch(message); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			r.add(SeverityError, n, "", "%s", m[2])
+		} else if m := iniLineError.FindStringSubmatch(message); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			r.add(SeverityError, n, "", "%s", m[2])
+		} else {
+			r.add(SeverityError, line, key, "%s", message)
+		}
+	}
+}
+
+// sections are the sections of a cloud config that could be read
+type sections struct {
+	cpi   *ccfg.CPIConfig
+	lb    *lcfg.LBConfig
+	nsxt  *ncfg.Config
+	route *rcfg.Config
+	lines map[string]int
+}
+
+// Validate reads all sections of a cloud config and reports the problems found
+// in it. Unlike the cloud provider, it does not apply the environment
+// variables to the cloud config.
+func Validate(data []byte) *Report {
+	r, _ := validate(data)
+	return r
+}
+
+func validate(data []byte) (*Report, *sections) {
+	r := &Report{Format: DetectFormat(data)}
+	spec := formats[r.Format]
+	s := &sections{}
+	if r.Format == FormatINI {
+		r.add(SeverityWarning, 0, "", "the INI based cloud config is deprecated, use the YAML based cloud config")
+		s.lines = r.checkINIKeys(data)
+	} else {
+		s.lines = r.checkYAMLKeys(data)
+	}
+	if r.HasErrors() {
+		// syntax errors, the sections cannot be read
+		return r, s
+	}
+
+	if cpi, err := spec.readCPI(data); err != nil {
+		r.addError(s.lines[spec.global], spec.global, err)
+	} else {
+		s.cpi = cpi
+		if err := cpi.Nodes.Validate(); err != nil {
+			r.addError(s.lines[spec.nodes], spec.nodes, err)
+		}
+		if err := cpi.Tracing.Validate(); err != nil {
+			r.addError(s.lines[spec.tracing], spec.tracing, err)
+		}
+		for _, name := range sortedKeys(cpi.VirtualCenter) {
+			if len(cpi.VirtualCenter[name].IPFamilyPriority) > 1 {
+				key := joinKey(spec.vcenter, name)
+				r.add(SeverityWarning, s.lines[key], key,
+					"multiple IP families require the ENABLE_ALPHA_DUAL_STACK environment variable of the cloud provider")
+			}
+		}
+	}
+
+	_, hasNSXT := s.lines[spec.nsxt]
+	if line, ok := s.sectionLine(spec.loadBalancer, spec.loadBalancerClass); ok {
+		if lb, err := spec.readLB(data); err != nil {
+			r.addError(line, spec.loadBalancer, err)
+		} else if lb.IsEnabled() {
+			s.lb = lb
+			if !hasNSXT {
+				r.add(SeverityError, line, spec.loadBalancer, "the load balancer requires the %s section", spec.nsxt)
+			}
+		}
+	}
+	if hasNSXT {
+		if nsxt, err := spec.readNSXT(data); err != nil {
+			r.addError(s.lines[spec.nsxt], spec.nsxt, err)
+		} else {
+			s.nsxt = nsxt
+		}
+	}
+	if line, ok := s.lines[spec.route]; ok {
+		if route, err := spec.readRoute(data); err != nil {
+			r.addError(line, spec.route, err)
+		} else {
+			s.route = route
+			if !hasNSXT {
+				r.add(SeverityError, line, spec.route, "routes require the %s section", spec.nsxt)
+			}
+		}
+	}
+
+	r.sort()
+	return r, s
+}
+
+// sectionLine returns the line of the first of the sections present
+func (s *sections) sectionLine(names ...string) (int, bool) {
+	found := false
+	first := 0
+	for _, name := range names {
+		if line, ok := s.lines[name]; ok && (!found || line < first) {
+			found = true
+			first = line
+		}
+	}
+	return first, found
+}
+
+func (r *Report) sort() {
+	sort.SliceStable(r.Problems, func(i, j int) bool {
+		return r.Problems[i].Line < r.Problems[j].Line
+	})
+}
+
+func sortedKeys(m map[string]*vcfg.VirtualCenterConfig) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+)
+
+func findProblem(r *Report, severity Severity, line int, message string) bool {
+	for _, p := range r.Problems {
+		if p.Severity == severity && p.Line == line && strings.Contains(p.Message, message) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDetectFormat(t *testing.T) {
+	if f := DetectFormat([]byte("# comment\n\n[Global]\nuser = \"user\"\n")); f != FormatINI {
+		t.Errorf("expected INI, got %s", f)
+	}
+	if f := DetectFormat([]byte("# comment\nglobal:\n  user: user\n")); f != FormatYAML {
+		t.Errorf("expected YAML, got %s", f)
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	data := []byte(`global:
+  user: user
+  server: 10.0.0.1
+  password: pass
+  unknownField: true
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+    datacenters:
+      - dc1
+    ipFamily:
+      - ipv4
+      - ipv6
+nodes:
+  internalNetworkSubnetCidr: 10.0.0.0/33
+`)
+	r := Validate(data)
+	if r.Format != FormatYAML {
+		t.Errorf("expected YAML, got %s", r.Format)
+	}
+	if !findProblem(r, SeverityWarning, 3, "deprecated") {
+		t.Errorf("expected the global server to be deprecated, got %v", r.Problems)
+	}
+	if !findProblem(r, SeverityWarning, 5, "unknown key") {
+		t.Errorf("expected an unknown key at line 5, got %v", r.Problems)
+	}
+	if !findProblem(r, SeverityWarning, 7, "ENABLE_ALPHA_DUAL_STACK") {
+		t.Errorf("expected a dual stack warning at line 7, got %v", r.Problems)
+	}
+	if !findProblem(r, SeverityError, 14, "10.0.0.0/33") {
+		t.Errorf("expected an invalid CIDR at line 14, got %v", r.Problems)
+	}
+	if !r.HasErrors() {
+		t.Error("expected errors")
+	}
+}
+
+func TestValidateYAMLSyntaxError(t *testing.T) {
+	data := []byte(`global:
+  port: 443
+ insecureFlag: true
+`)
+	r := Validate(data)
+	if len(r.Problems) != 1 || r.Problems[0].Severity != SeverityError || r.Problems[0].Line != 2 {
+		t.Errorf("expected a syntax error at line 2, got %v", r.Problems)
+	}
+}
+
+func TestValidateYAMLLoadBalancerWithoutNSXT(t *testing.T) {
+	data := []byte(`global:
+  secretName: vsphere-creds
+  secretNamespace: kube-system
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+loadBalancer:
+  ipPoolName: pool
+  lbServiceId: lbs
+  tcpAppProfileName: tcp
+  udpAppProfileName: udp
+  size: SMALL
+`)
+	r := Validate(data)
+	if !findProblem(r, SeverityError, 7, "requires the nsxt section") {
+		t.Errorf("expected the load balancer to require NSX-T, got %v", r.Problems)
+	}
+}
+
+func TestValidateINI(t *testing.T) {
+	data := []byte(`[Global]
+user = user
+password = pass
+unknown-field = true
+
+[VirtualCenter "10.0.0.1"]
+datacenters = dc1
+
+[VirtualCenter]
+user = user
+
+[Unknown]
+key = value
+`)
+	r := Validate(data)
+	if r.Format != FormatINI {
+		t.Errorf("expected INI, got %s", r.Format)
+	}
+	if !findProblem(r, SeverityWarning, 0, "deprecated") {
+		t.Errorf("expected the INI format to be deprecated, got %v", r.Problems)
+	}
+	if !findProblem(r, SeverityWarning, 4, "unknown key") {
+		t.Errorf("expected an unknown key at line 4, got %v", r.Problems)
+	}
+	if !findProblem(r, SeverityWarning, 9, "requires a name") {
+		t.Errorf("expected an unnamed section at line 9, got %v", r.Problems)
+	}
+	if !findProblem(r, SeverityWarning, 12, "unknown section") {
+		t.Errorf("expected an unknown section at line 12, got %v", r.Problems)
+	}
+	// gcfg reads the unnamed section as a vCenter without a name
+	if !findProblem(r, SeverityError, 1, "VirtualCenter IP address") {
+		t.Errorf("expected a vCenter without address, got %v", r.Problems)
+	}
+}
+
+func TestValidateINISyntaxError(t *testing.T) {
+	data := []byte(`[Global]
+port = 443
+insecure-flag
+[VirtualCenter "10.0.0.1"
+`)
+	r := Validate(data)
+	if !r.HasErrors() || r.Problems[len(r.Problems)-1].Line != 4 {
+		t.Errorf("expected a syntax error at line 4, got %v", r.Problems)
+	}
+}
+
+func TestValidateLive(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatal(err)
+	}
+	model.Service.TLS = new(tls.Config)
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	data := []byte(fmt.Sprintf(`global:
+  insecureFlag: true
+  secretName: vsphere-creds
+  secretNamespace: kube-system
+vcenter:
+  tenant1:
+    server: %s
+    port: %s
+    datacenters:
+      - DC0
+      - DC1
+`, s.URL.Hostname(), s.URL.Port()))
+
+	r := ValidateLive(context.Background(), data, &LiveOptions{})
+	if !findProblem(r, SeverityWarning, 6, "no credentials") {
+		t.Errorf("expected the vCenter not to be checked, got %v", r.Problems)
+	}
+
+	r = ValidateLive(context.Background(), data, &LiveOptions{VCenterUser: "user", VCenterPassword: "pass"})
+	if !findProblem(r, SeverityError, 6, "datacenter DC1") {
+		t.Errorf("expected datacenter DC1 not to exist, got %v", r.Problems)
+	}
+	if findProblem(r, SeverityError, 6, "datacenter DC0") {
+		t.Errorf("expected datacenter DC0 to exist, got %v", r.Problems)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"encoding/pem"
 	"math/big"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -123,15 +124,14 @@ type Role struct {
 
 // createCert creates a key pair for login purpose
 func (u *User) createCert() error {
-	id := strings.TrimSuffix(u.cert, ".crt")
 	var encodedCert bytes.Buffer
-	certFile, err := os.Create(id + ".crt")
+	certFile, err := os.Create(u.cert)
 	if err != nil {
 		return err
 	}
 	defer certFile.Close()
 
-	keyFile, err := os.Create(id + ".key")
+	keyFile, err := os.Create(keyFileFor(u.cert))
 	if err != nil {
 		return err
 	}
@@ -184,3 +184,8 @@ func (u *User) createCert() error {
 
 	return nil
 }
+
+// keyFileFor returns the path of the private key created along with the certificate
+func keyFileFor(certFile string) string {
+	return strings.TrimSuffix(certFile, filepath.Ext(certFile)) + ".key"
+}
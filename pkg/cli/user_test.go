@@ -18,7 +18,13 @@ package cli
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/vmware/govmomi/ssoadmin"
@@ -94,3 +100,37 @@ func buildClient(ctx context.Context) (func(), error) {
 	o.insecure = true
 	return fn, err
 }
+
+func TestSolutionUserConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "solution-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	solutionUser := User{cert: filepath.Join(dir, "k8s-vcp.crt")}
+	if err := solutionUser.createCert(); err != nil {
+		t.Fatalf("createCert error : %s", err)
+	}
+	keyFile := filepath.Join(dir, "k8s-vcp.key")
+	if _, err := tls.LoadX509KeyPair(solutionUser.cert, keyFile); err != nil {
+		t.Fatalf("created key pair is invalid : %s", err)
+	}
+
+	option := ClientOption{url: &url.URL{Host: "10.0.0.1:443"}}
+	option.LoadCredential("", "", solutionUser.cert, "", false)
+	cfg, err := SolutionUserConfig(&option)
+	if err != nil {
+		t.Fatalf("SolutionUserConfig error : %s", err)
+	}
+	for _, expected := range []string{
+		"certFile: " + solutionUser.cert,
+		"keyFile: " + keyFile,
+		"10.0.0.1.cert: contents of " + solutionUser.cert,
+		"10.0.0.1.key: contents of " + keyFile,
+	} {
+		if !strings.Contains(cfg, expected) {
+			t.Errorf("SolutionUserConfig should contain %q, got:\n%s", expected, cfg)
+		}
+	}
+}
@@ -17,11 +17,13 @@ limitations under the License.
 package vsphere
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"runtime"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	klog "k8s.io/klog/v2"
@@ -36,8 +38,10 @@ import (
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route"
 	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/server"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
 	k8s "k8s.io/cloud-provider-vsphere/pkg/common/kubernetes"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 	"k8s.io/cloud-provider-vsphere/pkg/nsxt"
 	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
 )
@@ -55,6 +59,9 @@ const (
 
 	// dualStackFeatureGateEnv is a required environment variable when enabling dual-stack nodes
 	dualStackFeatureGateEnv string = "ENABLE_ALPHA_DUAL_STACK"
+
+	// tracingShutdownTimeout is how long the spans ended before stopping are exported for
+	tracingShutdownTimeout = 5 * time.Second
 )
 
 func init() {
@@ -64,11 +71,20 @@ func init() {
 			klog.Errorf("ReadAll failed: %s", err)
 			return nil, err
 		}
+		byConfig, err = vcfg.MergeDropInDir(CloudConfigFile, byConfig, CloudConfigDir)
+		if err != nil {
+			klog.Errorf("Merging the cloud config drop-in directory failed: %s", err)
+			return nil, err
+		}
 
 		cfg, err := ccfg.ReadCPIConfig(byConfig)
 		if err != nil {
 			return nil, err
 		}
+		if err := checkConfigSections(byConfig); err != nil {
+			klog.Errorf("Strict cloud config check failed: %s", err)
+			return nil, err
+		}
 		nsxtcfg, err := ncfg.ReadNsxtConfig(byConfig)
 		if err != nil {
 			klog.Errorf("ReadNsxtConfig failed: %s", err)
@@ -90,6 +106,15 @@ func init() {
 
 var _ cloudprovider.Interface = &VSphere{}
 
+// checkConfigSections rejects the keys of a strict YAML cloud config that
+// none of the sections read by the cloud provider is read from. The readers
+// of the optional sections fail on them as well, but disable the section
+// rather than failing the cloud provider.
+func checkConfigSections(byConfig []byte) error {
+	return vcfg.CheckSectionsYAML(byConfig, vcfg.CommonConfigYAML{}, ccfg.CPIConfigYAML{},
+		lcfg.LBConfigYAML{}, ncfg.NsxtConfigYAML{}, rcfg.RouteConfigYAML{})
+}
+
 // Creates new Controller node interface and returns
 func newVSphere(cfg *ccfg.CPIConfig, nsxtcfg *ncfg.Config, lbcfg *lcfg.LBConfig, routecfg *rcfg.Config, finalize ...bool) (*VSphere, error) {
 	vs, err := buildVSphereFromConfig(cfg, nsxtcfg, lbcfg, routecfg)
@@ -105,6 +130,16 @@ func newVSphere(cfg *ccfg.CPIConfig, nsxtcfg *ncfg.Config, lbcfg *lcfg.LBConfig,
 
 // Initialize initializes the cloud provider.
 func (vs *VSphere) Initialize(clientBuilder cloudprovider.ControllerClientBuilder, stop <-chan struct{}) {
+	if err := configureTracing(&vs.cfg.Tracing); err != nil {
+		klog.Errorf("Tracing disabled: %v", err)
+	}
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		tracing.Shutdown(ctx)
+	}()
+
 	client, err := clientBuilder.Client(ClientName)
 	if err == nil {
 		klog.V(1).Info("Kubernetes Client Init Succeeded")
@@ -114,6 +149,7 @@ func (vs *VSphere) Initialize(clientBuilder cloudprovider.ControllerClientBuilde
 		connMgr := cm.NewConnectionManager(&vs.cfg.Config, vs.informMgr, client)
 		vs.connectionManager = connMgr
 		vs.nodeManager.connectionManager = connMgr
+		vs.nodeManager.client = client
 
 		vs.informMgr.AddNodeListener(vs.nodeAdded, vs.nodeDeleted, nil)
 
@@ -122,12 +158,28 @@ func (vs *VSphere) Initialize(clientBuilder cloudprovider.ControllerClientBuilde
 		// if running secrets, init them
 		connMgr.InitializeSecretLister()
 
+		// keep the vCenter sessions alive and track their health
+		connMgr.StartSessionMonitor(stop)
+
+		// log in again as soon as mounted credentials are rotated
+		connMgr.WatchSecretsDirectory(stop)
+
+		// log in again as soon as the credentials in a secret change
+		if err := connMgr.AddSecretListener(vs.informMgr.GetSecretInformer()); err != nil {
+			klog.Warningf("Adding vCenter secret listener failed: %v", err)
+		}
+
 		if !vs.cfg.Global.APIDisable {
 			klog.V(1).Info("Starting the API Server")
 			vs.server.Start()
 		} else {
 			klog.V(1).Info("API Server is disabled")
 		}
+
+		// apply changes of the cloud config without restart
+		if CloudConfigFile != "" {
+			vs.watchConfig(CloudConfigFile, stop)
+		}
 	} else {
 		klog.Errorf("Kubernetes Client Init Failed: %v", err)
 	}
@@ -144,6 +196,20 @@ func (vs *VSphere) Initialize(clientBuilder cloudprovider.ControllerClientBuilde
 	}
 }
 
+// configureTracing starts exporting traces to the configured collector, or
+// stops exporting them if no endpoint is configured.
+func configureTracing(cfg *ccfg.Tracing) error {
+	if cfg.Endpoint != "" {
+		klog.Infof("Exporting traces to %s", cfg.Endpoint)
+	}
+	return tracing.Configure(tracing.Config{
+		Endpoint:      cfg.Endpoint,
+		Headers:       cfg.Headers,
+		ServiceName:   cfg.ServiceName,
+		SamplingRatio: cfg.SamplingRatio,
+	})
+}
+
 func (vs *VSphere) isLoadBalancerSupportEnabled() bool {
 	return vs.loadbalancer != nil
 }
@@ -238,16 +304,20 @@ func buildVSphereFromConfig(cfg *ccfg.CPIConfig, nsxtcfg *ncfg.Config, lbcfg *lc
 		return nil, err
 	}
 
+	lbMgr, routeMgr := newInventories(lb, routes)
+
 	vs := VSphere{
 		cfg:              cfg,
 		cfgLB:            lbcfg,
+		cfgNSXT:          nsxtcfg,
+		cfgRoute:         routecfg,
 		nodeManager:      nm,
 		nsxtConnectorMgr: ncm,
 		loadbalancer:     lb,
 		routes:           routes,
 		instances:        newInstances(nm),
 		zones:            newZones(nm, cfg.Labels.Zone, cfg.Labels.Region),
-		server:           server.NewServer(cfg.Global.APIBinding, nm),
+		server:           server.NewServer(cfg.Global.APIBinding, nm, lbMgr, routeMgr),
 	}
 	return &vs, nil
 }
@@ -18,7 +18,10 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	klog "k8s.io/klog/v2"
 )
@@ -31,7 +34,12 @@ func (cfg *CPIConfig) FromCPIEnv() error {
 	if err := cfg.FromEnv(); err != nil {
 		return err
 	}
+	return cfg.fromCPIEnv()
+}
 
+// fromCPIEnv overrides the nodes and tracing settings by the environment
+// variables that are set. The common settings are overridden when reading them.
+func (cfg *CPIConfig) fromCPIEnv() error {
 	if v := os.Getenv("VSPHERE_NODES_INTERNAL_NETWORK_SUBNET_CIDR"); v != "" {
 		cfg.Nodes.InternalNetworkSubnetCIDR = v
 	}
@@ -46,6 +54,27 @@ func (cfg *CPIConfig) FromCPIEnv() error {
 		cfg.Nodes.ExternalVMNetworkName = v
 	}
 
+	if v := os.Getenv("VSPHERE_TRACING_ENDPOINT"); v != "" {
+		cfg.Tracing.Endpoint = v
+	}
+	if v := os.Getenv("VSPHERE_TRACING_HEADERS"); v != "" {
+		headers, err := parseHeaders(v)
+		if err != nil {
+			return fmt.Errorf("failed to parse VSPHERE_TRACING_HEADERS: %s", err)
+		}
+		cfg.Tracing.Headers = headers
+	}
+	if v := os.Getenv("VSPHERE_TRACING_SERVICE_NAME"); v != "" {
+		cfg.Tracing.ServiceName = v
+	}
+	if v := os.Getenv("VSPHERE_TRACING_SAMPLING_RATIO"); v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse VSPHERE_TRACING_SAMPLING_RATIO: %s", err)
+		}
+		cfg.Tracing.SamplingRatio = ratio
+	}
+
 	return nil
 }
 
@@ -80,11 +109,55 @@ func ReadCPIConfig(byConfig []byte) (*CPIConfig, error) {
 	}
 
 	// Env Vars should override config file entries if present
-	if err := cfg.FromCPIEnv(); err != nil {
+	if err := cfg.fromCPIEnv(); err != nil {
 		klog.Errorf("FromEnv failed: %s", err)
 		return nil, err
 	}
 
+	if err := cfg.Nodes.Validate(); err != nil {
+		klog.Errorf("Invalid nodes subnet: %s", err)
+		return nil, err
+	}
+
+	if err := cfg.Tracing.Validate(); err != nil {
+		klog.Errorf("Invalid tracing sampling ratio %v", cfg.Tracing.SamplingRatio)
+		return nil, err
+	}
+
 	klog.Info("Config initialized")
 	return cfg, nil
 }
+
+// Validate returns an error if the subnets of the node address settings are invalid
+func (nodes *Nodes) Validate() error {
+	for _, cidr := range []string{nodes.InternalNetworkSubnetCIDR, nodes.ExternalNetworkSubnetCIDR} {
+		if cidr == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the sampling ratio is not between 0 and 1
+func (tracing *Tracing) Validate() error {
+	if tracing.SamplingRatio < 0 || tracing.SamplingRatio > 1 {
+		return ErrInvalidTracingSamplingRatio
+	}
+	return nil
+}
+
+// parseHeaders parses a comma separated list of name=value pairs.
+func parseHeaders(v string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected name=value", pair)
+		}
+		headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return headers, nil
+}
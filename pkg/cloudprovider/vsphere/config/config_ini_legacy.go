@@ -40,6 +40,14 @@ func (cci *CPIConfigINI) CreateConfig() *CPIConfig {
 			InternalVMNetworkName:     cci.Nodes.InternalVMNetworkName,
 			ExternalVMNetworkName:     cci.Nodes.ExternalVMNetworkName,
 		},
+		Tracing{
+			Endpoint:      cci.Tracing.Endpoint,
+			ServiceName:   cci.Tracing.ServiceName,
+			SamplingRatio: DefaultTracingSamplingRatio,
+		},
+	}
+	if cci.Tracing.SamplingRatio != nil {
+		cfg.Tracing.SamplingRatio = *cci.Tracing.SamplingRatio
 	}
 
 	return cfg
@@ -66,7 +74,7 @@ func ReadCPIConfigINI(byConfig []byte) (*CPIConfig, error) {
 		return nil, err
 	}
 
-	cfg := &CPIConfigINI{*vCFG, cfgOLD.Nodes}
+	cfg := &CPIConfigINI{*vCFG, cfgOLD.Nodes, cfgOLD.Tracing}
 
 	return cfg.CreateConfig(), nil
 }
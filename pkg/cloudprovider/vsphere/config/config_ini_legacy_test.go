@@ -52,6 +52,19 @@ internal-vm-network-name = "Internal K8s Traffic"
 external-vm-network-name = "External/Outbound Traffic"
 `
 
+const tracingINIConfig = `
+[Global]
+server = 0.0.0.0
+user = user
+password = password
+datacenters = us-west
+
+[Tracing]
+endpoint = http://otel-collector:4318
+service-name = vsphere-ccm
+sampling-ratio = 0
+`
+
 func TestReadINIConfigSubnetCidr(t *testing.T) {
 	_, err := ReadCPIConfigINI(nil)
 	if err == nil {
@@ -94,3 +107,20 @@ func TestReadINIConfigNetworkName(t *testing.T) {
 		t.Errorf("incorrect internal vm network name: %s", cfg.Nodes.ExternalVMNetworkName)
 	}
 }
+
+func TestReadINIConfigTracing(t *testing.T) {
+	cfg, err := ReadCPIConfigINI([]byte(tracingINIConfig))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	if cfg.Tracing.Endpoint != "http://otel-collector:4318" {
+		t.Errorf("incorrect tracing endpoint: %s", cfg.Tracing.Endpoint)
+	}
+	if cfg.Tracing.ServiceName != "vsphere-ccm" {
+		t.Errorf("incorrect tracing service name: %s", cfg.Tracing.ServiceName)
+	}
+	if cfg.Tracing.SamplingRatio != 0 {
+		t.Errorf("incorrect tracing sampling ratio: %v", cfg.Tracing.SamplingRatio)
+	}
+}
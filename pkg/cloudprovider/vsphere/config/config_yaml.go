@@ -19,8 +19,6 @@ package config
 import (
 	"fmt"
 
-	yaml "gopkg.in/yaml.v2"
-
 	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 )
 
@@ -41,6 +39,20 @@ func (ccy *CPIConfigYAML) CreateConfig() *CPIConfig {
 			InternalVMNetworkName:     ccy.Nodes.InternalVMNetworkName,
 			ExternalVMNetworkName:     ccy.Nodes.ExternalVMNetworkName,
 		},
+		Tracing{
+			Endpoint:      ccy.Tracing.Endpoint,
+			ServiceName:   ccy.Tracing.ServiceName,
+			SamplingRatio: DefaultTracingSamplingRatio,
+		},
+	}
+	if ccy.Tracing.SamplingRatio != nil {
+		cfg.Tracing.SamplingRatio = *ccy.Tracing.SamplingRatio
+	}
+	if len(ccy.Tracing.Headers) > 0 {
+		cfg.Tracing.Headers = make(map[string]string, len(ccy.Tracing.Headers))
+		for k, v := range ccy.Tracing.Headers {
+			cfg.Tracing.Headers[k] = v
+		}
 	}
 
 	return cfg
@@ -55,7 +67,7 @@ func ReadCPIConfigYAML(byConfig []byte) (*CPIConfig, error) {
 	// Must grab the entire config then overwrite it...
 	cfgOLD := &CPIConfigYAML{}
 
-	if err := yaml.Unmarshal(byConfig, cfgOLD); err != nil {
+	if err := vcfg.UnmarshalYAML(byConfig, cfgOLD); err != nil {
 		return nil, err
 	}
 
@@ -65,7 +77,7 @@ func ReadCPIConfigYAML(byConfig []byte) (*CPIConfig, error) {
 		return nil, err
 	}
 
-	cfg := &CPIConfigYAML{*vCFG, cfgOLD.Nodes}
+	cfg := &CPIConfigYAML{*vCFG, cfgOLD.Nodes, cfgOLD.Tracing}
 
 	return cfg.CreateConfig(), nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"os"
 	"testing"
 )
 
@@ -57,6 +58,22 @@ nodes:
   externalVmNetworkName: External/Outbound Traffic
 `
 
+const tracingYAMLConfig = `
+global:
+  server: 0.0.0.0
+  user: user
+  password: password
+  datacenters:
+    - us-west
+
+tracing:
+  endpoint: http://otel-collector:4318
+  headers:
+    Authorization: Bearer token
+  serviceName: vsphere-ccm
+  samplingRatio: 0.25
+`
+
 func TestReadYAMLConfigSubnetCidr(t *testing.T) {
 	_, err := ReadCPIConfigYAML(nil)
 	if err == nil {
@@ -99,3 +116,98 @@ func TestReadYAMLConfigNetworkName(t *testing.T) {
 		t.Errorf("incorrect internal vm network name: %s", cfg.Nodes.ExternalVMNetworkName)
 	}
 }
+
+func TestReadYAMLConfigTracing(t *testing.T) {
+	cfg, err := ReadCPIConfigYAML([]byte(tracingYAMLConfig))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	if cfg.Tracing.Endpoint != "http://otel-collector:4318" {
+		t.Errorf("incorrect tracing endpoint: %s", cfg.Tracing.Endpoint)
+	}
+	if cfg.Tracing.Headers["Authorization"] != "Bearer token" {
+		t.Errorf("incorrect tracing headers: %v", cfg.Tracing.Headers)
+	}
+	if cfg.Tracing.ServiceName != "vsphere-ccm" {
+		t.Errorf("incorrect tracing service name: %s", cfg.Tracing.ServiceName)
+	}
+	if cfg.Tracing.SamplingRatio != 0.25 {
+		t.Errorf("incorrect tracing sampling ratio: %v", cfg.Tracing.SamplingRatio)
+	}
+
+	cfg, err = ReadCPIConfigYAML([]byte(networkNameYAMLConfig))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+	if cfg.Tracing.Endpoint != "" || cfg.Tracing.SamplingRatio != DefaultTracingSamplingRatio {
+		t.Errorf("incorrect tracing defaults: %+v", cfg.Tracing)
+	}
+}
+
+func TestTracingEnv(t *testing.T) {
+	os.Setenv("VSPHERE_TRACING_ENDPOINT", "https://collector:4318")
+	os.Setenv("VSPHERE_TRACING_HEADERS", "Authorization=Bearer token, X-Scope=cpi")
+	os.Setenv("VSPHERE_TRACING_SAMPLING_RATIO", "0.5")
+	defer func() {
+		os.Unsetenv("VSPHERE_TRACING_ENDPOINT")
+		os.Unsetenv("VSPHERE_TRACING_HEADERS")
+		os.Unsetenv("VSPHERE_TRACING_SAMPLING_RATIO")
+	}()
+
+	cfg, err := ReadCPIConfig([]byte(tracingYAMLConfig))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+	if cfg.Tracing.Endpoint != "https://collector:4318" {
+		t.Errorf("incorrect tracing endpoint: %s", cfg.Tracing.Endpoint)
+	}
+	if len(cfg.Tracing.Headers) != 2 || cfg.Tracing.Headers["Authorization"] != "Bearer token" || cfg.Tracing.Headers["X-Scope"] != "cpi" {
+		t.Errorf("incorrect tracing headers: %v", cfg.Tracing.Headers)
+	}
+	if cfg.Tracing.ServiceName != "vsphere-ccm" {
+		t.Errorf("incorrect tracing service name: %s", cfg.Tracing.ServiceName)
+	}
+	if cfg.Tracing.SamplingRatio != 0.5 {
+		t.Errorf("incorrect tracing sampling ratio: %v", cfg.Tracing.SamplingRatio)
+	}
+
+	os.Setenv("VSPHERE_TRACING_SAMPLING_RATIO", "2")
+	if _, err := ReadCPIConfig([]byte(tracingYAMLConfig)); err != ErrInvalidTracingSamplingRatio {
+		t.Errorf("expected %v, got %v", ErrInvalidTracingSamplingRatio, err)
+	}
+
+	os.Setenv("VSPHERE_TRACING_HEADERS", "Authorization")
+	if _, err := ReadCPIConfig([]byte(tracingYAMLConfig)); err == nil {
+		t.Errorf("expected error for invalid headers")
+	}
+}
+
+func TestNodesEnv(t *testing.T) {
+	os.Setenv("VSPHERE_NODES_INTERNAL_NETWORK_SUBNET_CIDR", "203.0.113.0/24")
+	os.Setenv("VSPHERE_NODES_EXTERNAL_VM_NETWORK_NAME", "Outbound")
+	defer func() {
+		os.Unsetenv("VSPHERE_NODES_INTERNAL_NETWORK_SUBNET_CIDR")
+		os.Unsetenv("VSPHERE_NODES_EXTERNAL_VM_NETWORK_NAME")
+	}()
+
+	cfg, err := ReadCPIConfig([]byte(subnetCidrYAMLConfig))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+	// the environment takes precedence over the file
+	if cfg.Nodes.InternalNetworkSubnetCIDR != "203.0.113.0/24" {
+		t.Errorf("incorrect internal subnet: %s", cfg.Nodes.InternalNetworkSubnetCIDR)
+	}
+	if cfg.Nodes.ExternalNetworkSubnetCIDR != "198.51.100.0/24" {
+		t.Errorf("incorrect external subnet: %s", cfg.Nodes.ExternalNetworkSubnetCIDR)
+	}
+	if cfg.Nodes.ExternalVMNetworkName != "Outbound" {
+		t.Errorf("incorrect external network name: %s", cfg.Nodes.ExternalVMNetworkName)
+	}
+
+	os.Setenv("VSPHERE_NODES_INTERNAL_NETWORK_SUBNET_CIDR", "203.0.113.0")
+	if _, err := ReadCPIConfig([]byte(subnetCidrYAMLConfig)); err == nil {
+		t.Errorf("expected error for an invalid subnet")
+	}
+}
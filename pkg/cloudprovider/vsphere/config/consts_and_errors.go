@@ -0,0 +1,33 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+)
+
+const (
+	// DefaultTracingSamplingRatio is the fraction of operations traced when
+	// tracing is enabled and no ratio is configured.
+	DefaultTracingSamplingRatio = 1.0
+)
+
+var (
+	// ErrInvalidTracingSamplingRatio is returned when the tracing sampling
+	// ratio is not between 0 and 1.
+	ErrInvalidTracingSamplingRatio = errors.New("Invalid tracing sampling ratio")
+)
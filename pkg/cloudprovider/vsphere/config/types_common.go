@@ -41,7 +41,21 @@ type Nodes struct {
 }
 
 // CPIConfig is used to read and store information (related only to the CPI) from the cloud configuration file
+// Tracing configures the export of traces to an OpenTelemetry collector.
+type Tracing struct {
+	// Endpoint is the URL of the OTLP/HTTP collector, e.g. http://otel-collector:4318.
+	// Tracing is disabled when empty.
+	Endpoint string
+	// Headers are added to the export requests, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is reported as the service.name of the traces.
+	ServiceName string
+	// SamplingRatio is the fraction of operations traced, between 0 and 1.
+	SamplingRatio float64
+}
+
 type CPIConfig struct {
 	vcfg.Config
-	Nodes Nodes
+	Nodes   Nodes
+	Tracing Tracing
 }
@@ -40,7 +40,16 @@ type NodesINI struct {
 }
 
 // CPIConfigINI is the INI representation
+// TracingINI has no headers, as the INI format has no maps. Use the YAML
+// format or the VSPHERE_TRACING_HEADERS environment variable to set them.
+type TracingINI struct {
+	Endpoint      string   `gcfg:"endpoint"`
+	ServiceName   string   `gcfg:"service-name"`
+	SamplingRatio *float64 `gcfg:"sampling-ratio"`
+}
+
 type CPIConfigINI struct {
 	vcfg.CommonConfigINI
-	Nodes NodesINI
+	Nodes   NodesINI
+	Tracing TracingINI
 }
@@ -44,7 +44,15 @@ type NodesYAML struct {
 }
 
 // CPIConfigYAML is the YAML representation
+type TracingYAML struct {
+	Endpoint      string            `yaml:"endpoint"`
+	Headers       map[string]string `yaml:"headers"`
+	ServiceName   string            `yaml:"serviceName"`
+	SamplingRatio *float64          `yaml:"samplingRatio"`
+}
+
 type CPIConfigYAML struct {
 	vcfg.CommonConfigYAML
-	Nodes NodesYAML
+	Nodes   NodesYAML
+	Tracing TracingYAML
 }
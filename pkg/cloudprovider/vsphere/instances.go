@@ -27,6 +27,7 @@ import (
 	klog "k8s.io/klog/v2"
 
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 )
 
@@ -48,16 +49,18 @@ var _ cloudprovider.Instances = &instances{}
 //
 // When nodeName identifies more than one instance, only the first will be
 // considered.
-func (i *instances) NodeAddresses(ctx context.Context, nodeName types.NodeName) ([]v1.NodeAddress, error) {
+func (i *instances) NodeAddresses(ctx context.Context, nodeName types.NodeName) (_ []v1.NodeAddress, err error) {
 	klog.V(4).Info("instances.NodeAddresses() called with ", string(nodeName))
+	ctx, span := tracing.Start(ctx, "instances.NodeAddresses", tracing.String("node", string(nodeName)))
+	defer func() { span.End(err) }()
 
 	// Check if node has been discovered already
-	if node, ok := i.nodeManager.nodeNameMap[string(nodeName)]; ok {
+	if node, ok := i.nodeManager.cachedNodeByName(string(nodeName)); ok {
 		klog.V(2).Info("instances.NodeAddresses() CACHED with ", string(nodeName))
 		return node.NodeAddresses, nil
 	}
 
-	if err := i.nodeManager.DiscoverNode(string(nodeName), cm.FindVMByName); err == nil {
+	if err := i.nodeManager.DiscoverNode(ctx, string(nodeName), cm.FindVMByName); err == nil {
 		if i.nodeManager.nodeNameMap[string(nodeName)] == nil {
 			klog.Errorf("DiscoverNode succeeded, but CACHE missed for node=%s. If this is a Linux VM, hostnames are case sensitive. Make sure they match.", string(nodeName))
 			return []v1.NodeAddress{}, ErrNodeNotFound
@@ -73,17 +76,19 @@ func (i *instances) NodeAddresses(ctx context.Context, nodeName types.NodeName)
 // NodeAddressesByProviderID returns all the valid addresses of the instance
 // identified by providerID. Only the public/private IPv4 addresses will be
 // considered for now.
-func (i *instances) NodeAddressesByProviderID(ctx context.Context, providerID string) ([]v1.NodeAddress, error) {
+func (i *instances) NodeAddressesByProviderID(ctx context.Context, providerID string) (_ []v1.NodeAddress, err error) {
 	klog.V(4).Info("instances.NodeAddressesByProviderID() called with ", providerID)
+	ctx, span := tracing.Start(ctx, "instances.NodeAddressesByProviderID", tracing.String("provider_id", providerID))
+	defer func() { span.End(err) }()
 
 	// Check if node has been discovered already
 	uid := GetUUIDFromProviderID(providerID)
-	if node, ok := i.nodeManager.nodeUUIDMap[uid]; ok {
+	if node, ok := i.nodeManager.cachedNodeByUUID(uid); ok {
 		klog.V(2).Info("instances.NodeAddressesByProviderID() CACHED with ", uid)
 		return node.NodeAddresses, nil
 	}
 
-	if err := i.nodeManager.DiscoverNode(uid, cm.FindVMByUUID); err == nil {
+	if err := i.nodeManager.DiscoverNode(ctx, uid, cm.FindVMByUUID); err == nil {
 		klog.V(2).Info("instances.NodeAddressesByProviderID() FOUND with ", uid)
 		return i.nodeManager.nodeUUIDMap[uid].NodeAddresses, nil
 	}
@@ -104,16 +109,18 @@ func (i *instances) ExternalID(ctx context.Context, nodeName types.NodeName) (st
 }
 
 // InstanceID returns the cloud provider ID of the instance identified by nodeName.
-func (i *instances) InstanceID(ctx context.Context, nodeName types.NodeName) (string, error) {
+func (i *instances) InstanceID(ctx context.Context, nodeName types.NodeName) (_ string, err error) {
 	klog.V(4).Info("instances.InstanceID() called with ", nodeName)
+	ctx, span := tracing.Start(ctx, "instances.InstanceID", tracing.String("node", string(nodeName)))
+	defer func() { span.End(err) }()
 
 	// Check if node has been discovered already
-	if node, ok := i.nodeManager.nodeNameMap[string(nodeName)]; ok {
+	if node, ok := i.nodeManager.cachedNodeByName(string(nodeName)); ok {
 		klog.V(2).Info("instances.InstanceID() CACHED with ", string(nodeName))
 		return node.UUID, nil
 	}
 
-	err := i.nodeManager.DiscoverNode(string(nodeName), cm.FindVMByName)
+	err = i.nodeManager.DiscoverNode(ctx, string(nodeName), cm.FindVMByName)
 	if err == nil {
 		if i.nodeManager.nodeNameMap[string(nodeName)] == nil {
 			klog.Errorf("DiscoverNode succeeded, but CACHE missed for node=%s. If this is a Linux VM, hostnames are case sensitive. Make sure they match.", string(nodeName))
@@ -154,12 +161,14 @@ func (i *instances) CurrentNodeName(ctx context.Context, hostname string) (types
 
 // InstanceExistsByProviderID returns true if the instance identified by
 // providerID is running.
-func (i *instances) InstanceExistsByProviderID(ctx context.Context, providerID string) (bool, error) {
+func (i *instances) InstanceExistsByProviderID(ctx context.Context, providerID string) (_ bool, err error) {
 	klog.V(4).Info("instances.InstanceExistsByProviderID() called with ", providerID)
+	ctx, span := tracing.Start(ctx, "instances.InstanceExistsByProviderID", tracing.String("provider_id", providerID))
+	defer func() { span.End(err) }()
 
 	// Check if node has been discovered already
 	uid := GetUUIDFromProviderID(providerID)
-	err := i.nodeManager.DiscoverNode(uid, cm.FindVMByUUID)
+	err = i.nodeManager.DiscoverNode(ctx, uid, cm.FindVMByUUID)
 	if err == nil {
 		klog.V(2).Info("instances.InstanceExistsByProviderID() EXISTS with ", uid)
 		return true, nil
@@ -181,15 +190,17 @@ func (i *instances) InstanceExistsByProviderID(ctx context.Context, providerID s
 }
 
 // InstanceShutdownByProviderID returns true if the instance is in safe state to detach volumes
-func (i *instances) InstanceShutdownByProviderID(ctx context.Context, providerID string) (bool, error) {
+func (i *instances) InstanceShutdownByProviderID(ctx context.Context, providerID string) (_ bool, err error) {
 	klog.V(4).Info("instances.InstanceShutdownByProviderID() called")
+	ctx, span := tracing.Start(ctx, "instances.InstanceShutdownByProviderID", tracing.String("provider_id", providerID))
+	defer func() { span.End(err) }()
 
 	// Check if node has been discovered already
 	uid := GetUUIDFromProviderID(providerID)
-	if _, ok := i.nodeManager.nodeUUIDMap[uid]; !ok {
+	if _, ok := i.nodeManager.cachedNodeByUUID(uid); !ok {
 		// IF the uuid is not cached, we end up here
 		klog.V(2).Info("instances.InstanceShutdownByProviderID() NOT CACHED")
-		if err := i.nodeManager.DiscoverNode(uid, cm.FindVMByUUID); err != nil {
+		if err := i.nodeManager.DiscoverNode(ctx, uid, cm.FindVMByUUID); err != nil {
 			klog.V(4).Info("instances.InstanceShutdownByProviderID() NOT FOUND with ", uid)
 			// if we can't discover, return false with an error in tow
 			return false, err
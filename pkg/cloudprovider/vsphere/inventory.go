@@ -0,0 +1,69 @@
+/*
+ Copyright 2020 The Kubernetes Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer"
+	pb "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/proto"
+	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route"
+	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/server"
+)
+
+// lbInventory exports the load balancers of the cluster to the API server
+type lbInventory struct {
+	provider loadbalancer.LBProvider
+}
+
+// routeInventory exports the routes of the cluster to the API server
+type routeInventory struct {
+	provider route.RoutesProvider
+}
+
+var _ server.LoadBalancerManagerInterface = &lbInventory{}
+var _ server.RouteManagerInterface = &routeInventory{}
+
+// newInventories returns the inventories for the API server. An inventory is
+// nil if the corresponding provider is not enabled.
+func newInventories(lb loadbalancer.LBProvider, routes route.RoutesProvider) (server.LoadBalancerManagerInterface, server.RouteManagerInterface) {
+	var lbMgr server.LoadBalancerManagerInterface
+	var routeMgr server.RouteManagerInterface
+	if lb != nil {
+		lbMgr = &lbInventory{provider: lb}
+	}
+	if routes != nil {
+		routeMgr = &routeInventory{provider: routes}
+	}
+	return lbMgr, routeMgr
+}
+
+// GetLoadBalancer fills lb with the NSX-T objects of the given service
+func (i *lbInventory) GetLoadBalancer(namespace string, name string, lb *pb.LoadBalancer) error {
+	objectName := types.NamespacedName{Namespace: namespace, Name: name}
+	return i.provider.DescribeLoadBalancer(loadbalancer.ClusterName, objectName, lb)
+}
+
+// ExportLoadBalancers appends the load balancers of the cluster to lbList
+func (i *lbInventory) ExportLoadBalancers(namespace string, lbList *[]*pb.LoadBalancer) error {
+	return i.provider.ExportLoadBalancers(loadbalancer.ClusterName, namespace, lbList)
+}
+
+// ExportRoutes appends the static routes of the cluster to routeList
+func (i *routeInventory) ExportRoutes(routeList *[]*pb.Route) error {
+	return i.provider.ExportRoutes(loadbalancer.ClusterName, routeList)
+}
@@ -18,9 +18,12 @@ package loadbalancer
 
 import (
 	"fmt"
+	"net"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
 
 	corev1 "k8s.io/api/core/v1"
@@ -208,7 +211,8 @@ func (a *access) GetAppProfilePath(class LBClass, protocol corev1.Protocol) (str
 }
 
 func (a *access) CreateVirtualServer(clusterName string, objectName types.NamespacedName, class LBClass, ipAddress string,
-	mapping Mapping, lbServicePath, applicationProfilePath string, poolPath *string) (*model.LBVirtualServer, error) {
+	mapping Mapping, lbServicePath, applicationProfilePath string, poolPath *string,
+	accessList *model.LBAccessListControl) (*model.LBVirtualServer, error) {
 	allTags := append(class.Tags(), clusterTag(clusterName), serviceTag(objectName), portTag(mapping))
 	virtualServer := model.LBVirtualServer{
 		Description: strptr(fmt.Sprintf("virtual server for cluster %s, service %s created by %s",
@@ -222,6 +226,7 @@ func (a *access) CreateVirtualServer(clusterName string, objectName types.Namesp
 		PoolPath:               poolPath,
 		Ports:                  []string{fmt.Sprintf("%d", mapping.SourcePort)},
 		LbServicePath:          strptr(lbServicePath),
+		AccessListControl:      accessList,
 	}
 	result, err := a.broker.CreateLoadBalancerVirtualServer(virtualServer)
 	if err != nil {
@@ -356,50 +361,50 @@ func (a *access) DeletePool(id string) error {
 	return nil
 }
 
-func (a *access) CreateTCPMonitorProfile(clusterName string, objectName types.NamespacedName, mapping Mapping) (*model.LBTcpMonitorProfile, error) {
-	profile := model.LBTcpMonitorProfile{
-		Description: strptr(fmt.Sprintf("tcp monitor for cluster %s, service %s, port %d created by %s",
-			clusterName, objectName, mapping.NodePort, AppName)),
-		DisplayName: displayNameMapping(clusterName, objectName, mapping),
-		Tags:        a.standardTags.Append(clusterTag(clusterName), serviceTag(objectName), portTag(mapping)).Normalize(),
-		MonitorPort: int64ptr(int64(mapping.NodePort)),
+func (a *access) CreateMonitorProfile(clusterName string, objectName types.NamespacedName, mapping Mapping, settings MonitorSettings) (*MonitorProfile, error) {
+	profile := MonitorProfile{
+		Description: strptr(fmt.Sprintf("%s for cluster %s, service %s, port %d created by %s",
+			settings.ResourceType, clusterName, objectName, mapping.NodePort, AppName)),
+		DisplayName:     displayNameMapping(clusterName, objectName, mapping),
+		Tags:            a.standardTags.Append(clusterTag(clusterName), serviceTag(objectName), portTag(mapping)).Normalize(),
+		MonitorSettings: settings,
 	}
-	monitor, err := a.broker.CreateLoadBalancerTCPMonitorProfile(profile)
+	monitor, err := a.broker.CreateLoadBalancerMonitorProfile(profile)
 	if err != nil {
-		return nil, errors.Wrapf(err, "creating tcp monitor failed for %s:%s:%d", clusterName, objectName, mapping.NodePort)
+		return nil, errors.Wrapf(err, "creating monitor failed for %s:%s:%d", clusterName, objectName, mapping.NodePort)
 	}
 	return &monitor, nil
 }
 
-func (a *access) GetTCPMonitorProfile(id string) (*model.LBTcpMonitorProfile, error) {
-	monitor, err := a.broker.ReadLoadBalancerTCPMonitorProfile(id)
+func (a *access) GetMonitorProfile(id string) (*MonitorProfile, error) {
+	monitor, err := a.broker.ReadLoadBalancerMonitorProfile(id)
 	if err != nil {
-		return nil, errors.Wrapf(err, "reading tcp monitor %s failed", id)
+		return nil, errors.Wrapf(err, "reading monitor %s failed", id)
 	}
 	return &monitor, nil
 }
 
-func (a *access) FindTCPMonitorProfiles(clusterName string, objectName types.NamespacedName) ([]*model.LBTcpMonitorProfile, error) {
-	return a.listTCPMonitorProfiles(a.ownerTag, clusterTag(clusterName), serviceTag(objectName))
+func (a *access) FindMonitorProfiles(clusterName string, objectName types.NamespacedName) ([]*MonitorProfile, error) {
+	return a.listMonitorProfiles(a.ownerTag, clusterTag(clusterName), serviceTag(objectName))
 }
 
-func (a *access) ListTCPMonitorProfiles(clusterName string) ([]*model.LBTcpMonitorProfile, error) {
-	return a.listTCPMonitorProfiles(a.ownerTag, clusterTag(clusterName))
+func (a *access) ListMonitorProfiles(clusterName string) ([]*MonitorProfile, error) {
+	return a.listMonitorProfiles(a.ownerTag, clusterTag(clusterName))
 }
 
-func (a *access) listTCPMonitorProfiles(tags ...model.Tag) ([]*model.LBTcpMonitorProfile, error) {
+func (a *access) listMonitorProfiles(tags ...model.Tag) ([]*MonitorProfile, error) {
 	list, err := a.broker.ListLoadBalancerMonitorProfiles()
 	if err != nil {
 		return nil, errors.Wrapf(err, "listing load balancer monitors failed")
 	}
-	result := []*model.LBTcpMonitorProfile{}
+	result := []*MonitorProfile{}
 	converter := newNsxtTypeConverter()
 	for _, item := range list {
 		resourceType, err := item.String("resource_type")
-		if err != nil || resourceType != model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE {
+		if err != nil || !isMonitorResourceType(resourceType) {
 			continue
 		}
-		profile, err := converter.convertStructValueToLBTCPMonitorProfile(item)
+		profile, err := converter.convertStructValueToMonitorProfile(item)
 		if err != nil {
 			return nil, err
 		}
@@ -410,15 +415,15 @@ func (a *access) listTCPMonitorProfiles(tags ...model.Tag) ([]*model.LBTcpMonito
 	return result, nil
 }
 
-func (a *access) UpdateTCPMonitorProfile(monitor *model.LBTcpMonitorProfile) error {
-	_, err := a.broker.UpdateLoadBalancerTCPMonitorProfile(*monitor)
+func (a *access) UpdateMonitorProfile(monitor *MonitorProfile) error {
+	_, err := a.broker.UpdateLoadBalancerMonitorProfile(*monitor)
 	if err != nil {
-		return errors.Wrapf(err, "updating load balancer TCP monitor %s (%s) failed", *monitor.DisplayName, *monitor.Id)
+		return errors.Wrapf(err, "updating load balancer monitor %s (%s) failed", *monitor.DisplayName, *monitor.ID)
 	}
 	return nil
 }
 
-func (a *access) DeleteTCPMonitorProfile(id string) error {
+func (a *access) DeleteMonitorProfile(id string) error {
 	err := a.broker.DeleteLoadBalancerMonitorProfile(id)
 	if isNotFoundError(err) {
 		return nil
@@ -429,10 +434,116 @@ func (a *access) DeleteTCPMonitorProfile(id string) error {
 	return nil
 }
 
-func (a *access) AllocateExternalIPAddress(ipPoolID string, clusterName string, objectName types.NamespacedName) (*model.IpAddressAllocation, *string, error) {
+func (a *access) CreateSourceRangesGroup(clusterName string, objectName types.NamespacedName, sourceRanges []string) (*model.Group, error) {
+	expression, err := newIPAddressExpression(sourceRanges)
+	if err != nil {
+		return nil, err
+	}
+	group := model.Group{
+		Description: strptr(fmt.Sprintf("source ranges for cluster %s, service %s created by %s",
+			clusterName, objectName, AppName)),
+		DisplayName: displayNameObject(clusterName, objectName),
+		Tags:        a.standardTags.Append(clusterTag(clusterName), serviceTag(objectName)).Normalize(),
+		Expression:  []*data.StructValue{expression},
+	}
+	result, err := a.broker.CreateGroup(group)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating source ranges group failed for %s:%s", clusterName, objectName)
+	}
+	return &result, nil
+}
+
+func (a *access) FindSourceRangesGroups(clusterName string, objectName types.NamespacedName) ([]*model.Group, error) {
+	return a.listGroups(a.ownerTag, clusterTag(clusterName), serviceTag(objectName))
+}
+
+func (a *access) ListSourceRangesGroups(clusterName string) ([]*model.Group, error) {
+	return a.listGroups(a.ownerTag, clusterTag(clusterName))
+}
+
+func (a *access) listGroups(tags ...model.Tag) ([]*model.Group, error) {
+	list, err := a.broker.ListGroups()
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing groups failed")
+	}
+	var result []*model.Group
+	for _, item := range list {
+		if checkTags(item.Tags, tags...) {
+			itemCopy := item
+			result = append(result, &itemCopy)
+		}
+	}
+	return result, nil
+}
+
+func (a *access) UpdateSourceRangesGroup(group *model.Group, sourceRanges []string) error {
+	expression, err := newIPAddressExpression(sourceRanges)
+	if err != nil {
+		return err
+	}
+	group.Expression = []*data.StructValue{expression}
+	_, err = a.broker.UpdateGroup(*group)
+	if err != nil {
+		return errors.Wrapf(err, "updating source ranges group %s (%s) failed", strval(group.DisplayName), *group.Id)
+	}
+	return nil
+}
+
+func (a *access) DeleteSourceRangesGroup(id string) error {
+	err := a.broker.DeleteGroup(id)
+	if isNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "deleting source ranges group %s failed", id)
+	}
+	return nil
+}
+
+// newIPAddressExpression returns the expression of a group of IP addresses
+func newIPAddressExpression(ipAddresses []string) (*data.StructValue, error) {
+	expression := model.IPAddressExpression{
+		ResourceType: model.IPAddressExpression__TYPE_IDENTIFIER,
+		IpAddresses:  ipAddresses,
+	}
+	value, err := newNsxtTypeConverter().convertIPAddressExpressionToStructValue(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "converting IPAddressExpression failed")
+	}
+	return value, nil
+}
+
+// groupIPAddresses returns the IP addresses of the IP address expressions of
+// a group
+func groupIPAddresses(group *model.Group) ([]string, error) {
+	var ipAddresses []string
+	converter := newNsxtTypeConverter()
+	for _, item := range group.Expression {
+		resourceType, err := item.String("resource_type")
+		if err != nil || resourceType != model.IPAddressExpression__TYPE_IDENTIFIER {
+			continue
+		}
+		expression, err := converter.convertStructValueToIPAddressExpression(item)
+		if err != nil {
+			return nil, err
+		}
+		ipAddresses = append(ipAddresses, expression.IpAddresses...)
+	}
+	return ipAddresses, nil
+}
+
+func (a *access) AllocateExternalIPAddress(ipPoolID string, clusterName string, objectName types.NamespacedName,
+	ipAddress string) (*model.IpAddressAllocation, *string, error) {
 	allocation := model.IpAddressAllocation{
 		Tags: a.standardTags.Append(clusterTag(clusterName), serviceTag(objectName)).Normalize(),
 	}
+	if ipAddress != "" {
+		err := a.checkRequestedIPAddress(ipPoolID, clusterName, objectName, ipAddress)
+		if err != nil {
+			return nil, nil, err
+		}
+		allocation.AllocationIp = strptr(ipAddress)
+	}
 	allocated, ipAdress, err := a.broker.AllocateFromIPPool(ipPoolID, allocation)
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, "allocating external IP address failed")
@@ -440,28 +551,82 @@ func (a *access) AllocateExternalIPAddress(ipPoolID string, clusterName string,
 	return &allocated, &ipAdress, nil
 }
 
-func (a *access) FindExternalIPAddressForObject(ipPoolID string, clusterName string, objectName types.NamespacedName) (*model.IpAddressAllocation, *string, error) {
+// checkRequestedIPAddress checks that a requested IP address belongs to a
+// static subnet of the IP pool and is not allocated to another object yet
+func (a *access) checkRequestedIPAddress(ipPoolID string, clusterName string, objectName types.NamespacedName, ipAddress string) error {
+	ip, err := parseLoadBalancerIP(ipAddress)
+	if err != nil {
+		return err
+	}
+
+	list, err := a.broker.ListIPPoolSubnets(ipPoolID)
+	if err != nil {
+		return errors.Wrapf(err, "listing subnets of IP pool %s failed", ipPoolID)
+	}
+	inPool := false
+	converter := newNsxtTypeConverter()
+	for _, item := range list {
+		resourceType, err := item.String("resource_type")
+		if err != nil || resourceType != model.IpAddressPoolSubnet_RESOURCE_TYPE_IPADDRESSPOOLSTATICSUBNET {
+			continue
+		}
+		subnet, err := converter.convertStructValueToIPPoolStaticSubnet(item)
+		if err != nil {
+			return err
+		}
+		if staticSubnetContains(subnet, ip) {
+			inPool = true
+			break
+		}
+	}
+	if !inPool {
+		return fmt.Errorf("load balancer IP %s is not in the allocation ranges of IP pool %s", ipAddress, ipPoolID)
+	}
+
+	allocations, err := a.broker.ListIPPoolAllocations(ipPoolID)
+	if err != nil {
+		return errors.Wrapf(err, "listing IP address allocations from IP pool %s failed", ipPoolID)
+	}
+	for _, allocation := range allocations {
+		if allocation.AllocationIp == nil || !ip.Equal(net.ParseIP(*allocation.AllocationIp)) {
+			continue
+		}
+		if checkTags(allocation.Tags, a.ownerTag, clusterTag(clusterName), serviceTag(objectName)) {
+			// a replaced allocation of the object itself is no conflict,
+			// the object takes it back
+			continue
+		}
+		if owner := getTag(allocation.Tags, ScopeService); owner != "" {
+			return fmt.Errorf("load balancer IP %s of IP pool %s is already in use by service %s", ipAddress, ipPoolID, owner)
+		}
+		return fmt.Errorf("load balancer IP %s of IP pool %s is already in use by allocation %s", ipAddress, ipPoolID, strval(allocation.Id))
+	}
+	return nil
+}
+
+func (a *access) FindExternalIPAddressForObject(ipPoolID string, clusterName string, objectName types.NamespacedName) (*model.IpAddressAllocation, *string, []*model.IpAddressAllocation, error) {
 	results, err := a.findExternalIPAddresses(ipPoolID, a.ownerTag, clusterTag(clusterName), serviceTag(objectName))
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if len(results) == 0 {
-		return nil, nil, nil
-	}
-	if len(results) > 1 {
-		return nil, nil, fmt.Errorf("Multiple IP address allocations")
+		return nil, nil, nil, nil
 	}
+	// the allocation replacing the others is the most recent one
+	sort.SliceStable(results, func(i, j int) bool {
+		return int64val(results[i].CreateTime) > int64val(results[j].CreateTime)
+	})
 
 	item := results[0]
 	ipAddress := item.AllocationIp
 	if ipAddress == nil {
 		ipAddress, err = a.broker.GetRealizedExternalIPAddress(*item.Path, 5*time.Second)
 		if err != nil {
-			return nil, nil, errors.Wrapf(err, "GetReleaziedExternalIPAddress failed for allocation %s IP pool %s failed", *item.Path, ipPoolID)
+			return nil, nil, nil, errors.Wrapf(err, "GetReleaziedExternalIPAddress failed for allocation %s IP pool %s failed", *item.Path, ipPoolID)
 		}
 	}
 
-	return item, ipAddress, nil
+	return item, ipAddress, results[1:], nil
 }
 
 func (a *access) ListExternalIPAddresses(ipPoolID string, clusterName string) ([]*model.IpAddressAllocation, error) {
@@ -494,6 +659,14 @@ func (a *access) ReleaseExternalIPAddress(ipPoolID string, id string) error {
 	return nil
 }
 
+func (a *access) GetRealizedState(path string) (string, error) {
+	state, err := a.broker.GetRealizedState(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading realized state of %s failed", path)
+	}
+	return state, nil
+}
+
 func displayName(clusterName string) *string {
 	return strptr(fmt.Sprintf("cluster:%s", clusterName))
 }
@@ -18,10 +18,13 @@ package loadbalancer
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/protocol/client"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
 
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
@@ -37,6 +40,7 @@ type loadBalancerClass struct {
 	ipPool        Reference
 	tcpAppProfile Reference
 	udpAppProfile Reference
+	monitor       config.MonitorConfig
 
 	tags []model.Tag
 }
@@ -79,6 +83,54 @@ func setupClasses(access NSXTAccess, cfg *config.LBConfig) (*loadBalancerClasses
 	return lbClasses, nil
 }
 
+// ValidateClasses resolves the IP pools and application profiles of the load
+// balancer classes of the configuration against NSX-T. It returns the error of
+// every class that does not resolve, keyed by the name of the class.
+func ValidateClasses(cfg *config.LBConfig, connector client.Connector) (map[string]error, error) {
+	broker, err := NewNsxtBroker(connector)
+	if err != nil {
+		return nil, err
+	}
+	access, err := NewNSXTAccess(broker, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating access handler failed")
+	}
+
+	problems := map[string]error{}
+	resolver := &ipPoolResolver{access: access, knownIPPools: map[string]string{}}
+	defaultClass, err := resolveClass(access, resolver, config.DefaultLoadBalancerClass, &cfg.LoadBalancer.LoadBalancerClassConfig, nil)
+	if defCfg, ok := cfg.LoadBalancerClass[config.DefaultLoadBalancerClass]; ok {
+		defaultClass, err = resolveClass(access, resolver, config.DefaultLoadBalancerClass, defCfg, defaultClass)
+	}
+	if err != nil {
+		problems[config.DefaultLoadBalancerClass] = err
+	}
+	for name, classConfig := range cfg.LoadBalancerClass {
+		if name == config.DefaultLoadBalancerClass {
+			continue
+		}
+		if _, err := resolveClass(access, resolver, name, classConfig, defaultClass); err != nil {
+			problems[name] = err
+		}
+	}
+	return problems, nil
+}
+
+// resolveClass creates a load balancer class and resolves its application profiles
+func resolveClass(access NSXTAccess, resolver *ipPoolResolver, name string, classConfig *config.LoadBalancerClassConfig,
+	defaults *loadBalancerClass) (*loadBalancerClass, error) {
+	class, err := newLBClass(name, classConfig, defaults, resolver)
+	if err != nil {
+		return nil, err
+	}
+	for _, protocol := range []corev1.Protocol{corev1.ProtocolTCP, corev1.ProtocolUDP} {
+		if _, err := access.GetAppProfilePath(class, protocol); err != nil {
+			return nil, err
+		}
+	}
+	return class, nil
+}
+
 func (c *loadBalancerClasses) GetClassNames() []string {
 	names := make([]string, 0, len(c.classes))
 	for name := range c.classes {
@@ -95,6 +147,41 @@ func (c *loadBalancerClasses) add(class *loadBalancerClass) {
 	c.classes[class.className] = class
 }
 
+// diff returns a description of the classes added, removed or changed in other
+func (c *loadBalancerClasses) diff(other *loadBalancerClasses) []string {
+	var changes []string
+	for name, class := range other.classes {
+		old, ok := c.classes[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("load balancer class %s added", name))
+			continue
+		}
+		if old.ipPool.Identifier != class.ipPool.Identifier {
+			changes = append(changes, fmt.Sprintf("load balancer class %s: IP pool changed from %s to %s",
+				name, old.ipPool.Identifier, class.ipPool.Identifier))
+		}
+		if old.tcpAppProfile != class.tcpAppProfile {
+			changes = append(changes, fmt.Sprintf("load balancer class %s: TCP application profile changed from %+v to %+v",
+				name, old.tcpAppProfile, class.tcpAppProfile))
+		}
+		if old.udpAppProfile != class.udpAppProfile {
+			changes = append(changes, fmt.Sprintf("load balancer class %s: UDP application profile changed from %+v to %+v",
+				name, old.udpAppProfile, class.udpAppProfile))
+		}
+		if !reflect.DeepEqual(old.monitor, class.monitor) {
+			changes = append(changes, fmt.Sprintf("load balancer class %s: monitor changed from %+v to %+v",
+				name, old.monitor, class.monitor))
+		}
+	}
+	for name := range c.classes {
+		if _, ok := other.classes[name]; !ok {
+			changes = append(changes, fmt.Sprintf("load balancer class %s removed", name))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
 type ipPoolResolver struct {
 	access       NSXTAccess
 	knownIPPools map[string]string
@@ -133,6 +220,7 @@ func newLBClass(name string, classConfig *config.LoadBalancerClassConfig, defaul
 			Identifier: classConfig.UDPAppProfilePath,
 			Name:       classConfig.UDPAppProfileName,
 		},
+		monitor: classConfig.Monitor,
 	}
 	if defaults != nil {
 		if class.ipPool.IsEmpty() {
@@ -144,6 +232,7 @@ func newLBClass(name string, classConfig *config.LoadBalancerClassConfig, defaul
 		if class.udpAppProfile.IsEmpty() {
 			class.udpAppProfile = defaults.udpAppProfile
 		}
+		class.monitor = mergeMonitorConfig(class.monitor, defaults.monitor)
 	}
 	if resolver != nil {
 		err := resolver.resolve(&class.ipPool)
@@ -26,9 +26,15 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	klog "k8s.io/klog/v2"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 )
 
-const maxPeriod = 30 * time.Minute
+const (
+	maxPeriod = 30 * time.Minute
+	// cleanupStepTimeout bounds a single cleanup step
+	cleanupStepTimeout = 10 * time.Minute
+)
 
 // cleanup is used to cleanup obsolete and potentially forgotten objects
 // created by the loadbalancer controller in NSX-T. This should not
@@ -45,6 +51,17 @@ const maxPeriod = 30 * time.Minute
 // comparing this set with the actually required objects it is possible
 // to identify those that are orphaned and safely delete them.
 func (p *lbProvider) cleanup(clusterName string, client clientcorev1.ServiceInterface, stop <-chan struct{}) {
+	// cancels a cleanup step in flight once the controller is stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
 	timer := time.NewTimer(1 * time.Second)
 	lastErrNext := 0 * time.Second
 	for {
@@ -53,7 +70,9 @@ func (p *lbProvider) cleanup(clusterName string, client clientcorev1.ServiceInte
 			return
 		case <-timer.C:
 			var next time.Duration
-			err := p.doCleanupStep(clusterName, client)
+			stepCtx, cancelStep := context.WithTimeout(ctx, cleanupStepTimeout)
+			err := p.doCleanupStep(stepCtx, clusterName, client)
+			cancelStep()
 			if err == nil {
 				next = maxPeriod
 				lastErrNext = 0
@@ -74,9 +93,12 @@ func (p *lbProvider) cleanup(clusterName string, client clientcorev1.ServiceInte
 	}
 }
 
-func (p *lbProvider) doCleanupStep(clusterName string, client clientcorev1.ServiceInterface) error {
+func (p *lbProvider) doCleanupStep(ctx context.Context, clusterName string, client clientcorev1.ServiceInterface) (err error) {
+	ctx, span := tracing.Start(ctx, "loadbalancer.Cleanup")
+	defer func() { span.End(err) }()
+
 	klog.Infof("starting cleanup...")
-	list, err := client.List(context.TODO(), metav1.ListOptions{})
+	list, err := client.List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return err
 	}
@@ -88,18 +110,20 @@ func (p *lbProvider) doCleanupStep(clusterName string, client clientcorev1.Servi
 		}
 	}
 
-	return p.CleanupServices(clusterName, services)
+	return p.CleanupServices(ctx, clusterName, services)
 }
 
-func (p *lbProvider) CleanupServices(clusterName string, validServices map[types.NamespacedName]corev1.Service) error {
+func (p *lbProvider) CleanupServices(ctx context.Context, clusterName string, validServices map[types.NamespacedName]corev1.Service) error {
+	access := withTracing(ctx, p.access)
 	ipPoolIds := sets.NewString()
-	for _, name := range p.classes.GetClassNames() {
-		class := p.classes.GetClass(name)
+	classes := p.getClasses()
+	for _, name := range classes.GetClassNames() {
+		class := classes.GetClass(name)
 		ipPoolIds.Insert(class.ipPool.Identifier)
 	}
 
 	lbs := map[types.NamespacedName]struct{}{}
-	servers, err := p.access.ListVirtualServers(ClusterName)
+	servers, err := access.ListVirtualServers(ClusterName)
 	if err != nil {
 		return err
 	}
@@ -113,7 +137,7 @@ func (p *lbProvider) CleanupServices(clusterName string, validServices map[types
 	}
 	ipPoolIds.Delete("")
 
-	pools, err := p.access.ListPools(clusterName)
+	pools, err := access.ListPools(clusterName)
 	if err != nil {
 		return err
 	}
@@ -124,7 +148,7 @@ func (p *lbProvider) CleanupServices(clusterName string, validServices map[types
 		}
 	}
 
-	monitors, err := p.access.ListTCPMonitorProfiles(clusterName)
+	monitors, err := access.ListMonitorProfiles(clusterName)
 	if err != nil {
 		return err
 	}
@@ -135,8 +159,19 @@ func (p *lbProvider) CleanupServices(clusterName string, validServices map[types
 		}
 	}
 
+	groups, err := access.ListSourceRangesGroups(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		tag := getTag(group.Tags, ScopeService)
+		if tag != "" {
+			lbs[parseNamespacedName(tag)] = struct{}{}
+		}
+	}
+
 	for ipPoolID := range ipPoolIds {
-		ipAddressAllocs, err := p.access.ListExternalIPAddresses(ipPoolID, clusterName)
+		ipAddressAllocs, err := access.ListExternalIPAddresses(ipPoolID, clusterName)
 		if err != nil {
 			return err
 		}
@@ -157,8 +192,11 @@ func (p *lbProvider) CleanupServices(clusterName string, validServices map[types
 					Name:      lb.Name,
 				},
 			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			klog.Infof("deleting artefacts for non-existing service %s/%s", lb.Namespace, lb.Name)
-			err = p.EnsureLoadBalancerDeleted(context.TODO(), clusterName, service)
+			err = p.EnsureLoadBalancerDeleted(ctx, clusterName, service)
 			if err != nil {
 				return err
 			}
@@ -18,6 +18,8 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	klog "k8s.io/klog/v2"
 )
@@ -40,6 +42,38 @@ func (cfg *LoadBalancerConfig) IsEmpty() bool {
 		cfg.Tier1GatewayPath == ""
 }
 
+// Validate checks the health monitor settings for invalid values
+func (mc MonitorConfig) Validate() error {
+	if mc.Type != "" && !MonitorTypes.Has(mc.Type) {
+		return fmt.Errorf("monitor type %q is invalid. Valid values are: %s", mc.Type, strings.Join(MonitorTypes.List(), ","))
+	}
+	if mc.Port < 0 || mc.Port > 65535 {
+		return fmt.Errorf("monitor port %d is out of range", mc.Port)
+	}
+	for _, code := range mc.ResponseStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("monitor response status code %d is invalid", code)
+		}
+	}
+	if mc.Interval < 0 || mc.Timeout < 0 || mc.FallCount < 0 || mc.RiseCount < 0 {
+		return fmt.Errorf("monitor interval, timeout, fall and rise count must not be negative")
+	}
+	if mc.Type == MonitorTypeUDP && (mc.Send == "" || mc.Receive == "") {
+		return fmt.Errorf("monitor type %s requires send and receive", MonitorTypeUDP)
+	}
+	return nil
+}
+
+// stringsFromEnv sets the settings to the values of the environment variables
+// they are keyed by, for the environment variables that are set
+func stringsFromEnv(settings map[string]*string) {
+	for env, setting := range settings {
+		if v := os.Getenv(env); v != "" {
+			*setting = v
+		}
+	}
+}
+
 /*
 	TODO:
 	When the INI based cloud-config is deprecated, the references to the
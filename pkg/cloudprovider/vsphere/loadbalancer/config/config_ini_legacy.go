@@ -45,6 +45,7 @@ func (lbc *LBConfigINI) CreateConfig() *LBConfig {
 	cfg.LoadBalancer.TCPAppProfilePath = lbc.LoadBalancer.TCPAppProfilePath
 	cfg.LoadBalancer.UDPAppProfileName = lbc.LoadBalancer.UDPAppProfileName
 	cfg.LoadBalancer.UDPAppProfilePath = lbc.LoadBalancer.UDPAppProfilePath
+	cfg.LoadBalancer.Monitor = lbc.LoadBalancer.MonitorConfig()
 	//LoadBalancerClassConfig -> LoadBalancerConfig
 	cfg.LoadBalancer.Size = lbc.LoadBalancer.Size
 	cfg.LoadBalancer.LBServiceID = lbc.LoadBalancer.LBServiceID
@@ -60,12 +61,29 @@ func (lbc *LBConfigINI) CreateConfig() *LBConfig {
 			TCPAppProfilePath: value.TCPAppProfilePath,
 			UDPAppProfileName: value.UDPAppProfileName,
 			UDPAppProfilePath: value.UDPAppProfilePath,
+			Monitor:           value.MonitorConfig(),
 		}
 	}
 
 	return cfg
 }
 
+// MonitorConfig returns the health monitor settings of the flat monitor-* keys
+func (lbc *LoadBalancerClassConfigINI) MonitorConfig() MonitorConfig {
+	return MonitorConfig{
+		Type:                lbc.MonitorType,
+		Port:                lbc.MonitorPort,
+		RequestPath:         lbc.MonitorRequestPath,
+		ResponseStatusCodes: lbc.MonitorResponseStatusCodes,
+		Interval:            lbc.MonitorInterval,
+		Timeout:             lbc.MonitorTimeout,
+		FallCount:           lbc.MonitorFallCount,
+		RiseCount:           lbc.MonitorRiseCount,
+		Send:                lbc.MonitorSend,
+		Receive:             lbc.MonitorReceive,
+	}
+}
+
 func (lbc *LBConfigINI) isEnabled() bool {
 	return len(lbc.LoadBalancerClass) > 0 || !lbc.LoadBalancer.isEmpty()
 }
@@ -109,9 +127,35 @@ func (lbc *LBConfigINI) validateConfig() error {
 			return fmt.Errorf(msg)
 		}
 	}
+	if err := lbc.LoadBalancer.MonitorConfig().Validate(); err != nil {
+		klog.Errorf("load balancer: %s", err)
+		return fmt.Errorf("load balancer: %s", err)
+	}
+	for name, class := range lbc.LoadBalancerClass {
+		if err := class.MonitorConfig().Validate(); err != nil {
+			klog.Errorf("load balancer class %s: %s", name, err)
+			return fmt.Errorf("load balancer class %s: %s", name, err)
+		}
+	}
 	return nil
 }
 
+// fromEnv overrides the settings by the environment variables that are set
+func (lbc *LoadBalancerConfigINI) fromEnv() {
+	stringsFromEnv(map[string]*string{
+		EnvSize:              &lbc.Size,
+		EnvLBServiceID:       &lbc.LBServiceID,
+		EnvTier1GatewayPath:  &lbc.Tier1GatewayPath,
+		EnvIPPoolName:        &lbc.IPPoolName,
+		EnvIPPoolID:          &lbc.IPPoolID,
+		EnvTCPAppProfileName: &lbc.TCPAppProfileName,
+		EnvTCPAppProfilePath: &lbc.TCPAppProfilePath,
+		EnvUDPAppProfileName: &lbc.UDPAppProfileName,
+		EnvUDPAppProfilePath: &lbc.UDPAppProfilePath,
+		EnvTags:              &lbc.RawTags,
+	})
+}
+
 func (lbc *LoadBalancerConfigINI) isEmpty() bool {
 	return lbc.Size == "" && lbc.LBServiceID == "" &&
 		lbc.IPPoolID == "" && lbc.IPPoolName == "" &&
@@ -120,6 +164,9 @@ func (lbc *LoadBalancerConfigINI) isEmpty() bool {
 
 // CompleteAndValidate sets default values, overrides by env and validates the resulting config
 func (lbc *LBConfigINI) CompleteAndValidate() error {
+	// Env Vars should override config file entries if present
+	lbc.LoadBalancer.fromEnv()
+
 	if !lbc.isEnabled() {
 		return nil
 	}
@@ -97,3 +97,33 @@ udp-app-profile-path = infra/xxx/udp1234
 	assertEquals("LoadBalancer.tcp-app-profile-path", config.LoadBalancer.TCPAppProfilePath, "infra/xxx/tcp1234")
 	assertEquals("LoadBalancer.udp-app-profile-path", config.LoadBalancer.UDPAppProfilePath, "infra/xxx/udp1234")
 }
+
+func TestReadINIConfigMonitor(t *testing.T) {
+	contents := `
+[LoadBalancer]
+ip-pool-name = pool1
+size = MEDIUM
+lb-service-id = 4711
+tcp-app-profile-name = tcp
+udp-app-profile-name = udp
+monitor-interval = 10
+
+[LoadBalancerClass "web"]
+monitor-type = HTTPS
+monitor-request-path = /healthz
+monitor-response-status-code = 200
+monitor-response-status-code = 204
+`
+	config, err := ReadConfigINI([]byte(contents))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if m := config.LoadBalancer.Monitor; m.Interval != 10 || m.Type != "" {
+		t.Errorf("unexpected LoadBalancer monitor %+v", m)
+	}
+	m := config.LoadBalancerClass["web"].Monitor
+	if m.Type != MonitorTypeHTTPS || m.RequestPath != "/healthz" || len(m.ResponseStatusCodes) != 2 {
+		t.Errorf("unexpected LoadBalancerClass web monitor %+v", m)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadLBConfigEnv(t *testing.T) {
+	defer func() {
+		for _, env := range []string{EnvSize, EnvLBServiceID, EnvIPPoolName, EnvTCPAppProfileName, EnvUDPAppProfileName, EnvTags} {
+			os.Unsetenv(env)
+		}
+	}()
+	os.Setenv(EnvSize, "LARGE")
+	os.Setenv(EnvIPPoolName, "envPool")
+	os.Setenv(EnvTags, `{"env": "tag"}`)
+
+	for name, contents := range map[string]string{
+		"YAML": `
+loadBalancer:
+  size: MEDIUM
+  ipPoolName: pool1
+  lbServiceId: 4711
+  tcpAppProfileName: tcp
+  udpAppProfileName: udp
+  tags:
+    file: tag
+loadBalancerClass:
+  public:
+    tcpAppProfileName: tcp2
+`,
+		"INI": `
+[LoadBalancer]
+size = MEDIUM
+ip-pool-name = pool1
+lb-service-id = 4711
+tcp-app-profile-name = tcp
+udp-app-profile-name = udp
+tags = {\"file\": \"tag\"}
+
+[LoadBalancerClass "public"]
+tcp-app-profile-name = tcp2
+`,
+	} {
+		cfg, err := ReadLBConfig([]byte(contents))
+		if err != nil {
+			t.Fatalf("%s: ReadLBConfig err=%v", name, err)
+		}
+		// the environment takes precedence over the file
+		if cfg.LoadBalancer.Size != "LARGE" || cfg.LoadBalancer.IPPoolName != "envPool" || cfg.LoadBalancer.LBServiceID != "4711" {
+			t.Errorf("%s: unexpected loadBalancer %+v", name, cfg.LoadBalancer)
+		}
+		if !reflect.DeepEqual(cfg.LoadBalancer.AdditionalTags, map[string]string{"env": "tag"}) {
+			t.Errorf("%s: unexpected tags %v", name, cfg.LoadBalancer.AdditionalTags)
+		}
+		// and is applied before the classes are completed
+		if cfg.LoadBalancerClass["public"].IPPoolName != "envPool" {
+			t.Errorf("%s: unexpected public class %+v", name, cfg.LoadBalancerClass["public"])
+		}
+	}
+
+	// the load balancer can be configured by the environment only
+	os.Setenv(EnvLBServiceID, "lbs")
+	os.Setenv(EnvTCPAppProfileName, "tcp")
+	os.Setenv(EnvUDPAppProfileName, "udp")
+	cfg, err := ReadLBConfig([]byte("route:\n  routerPath: /infra/tier-1s/t1\n"))
+	if err != nil {
+		t.Fatalf("ReadLBConfig err=%v", err)
+	}
+	if !cfg.IsEnabled() || cfg.LoadBalancer.LBServiceID != "lbs" || cfg.LoadBalancer.IPPoolName != "envPool" {
+		t.Errorf("unexpected loadBalancer %+v", cfg.LoadBalancer)
+	}
+
+	os.Setenv(EnvTags, "tag")
+	if _, err := ReadLBConfig([]byte("route:\n  routerPath: /infra/tier-1s/t1\n")); err == nil {
+		t.Errorf("expected invalid tags to fail")
+	}
+}
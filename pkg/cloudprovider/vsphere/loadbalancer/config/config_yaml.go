@@ -17,11 +17,14 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
-	yaml "gopkg.in/yaml.v2"
 	klog "k8s.io/klog/v2"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 )
 
 /*
@@ -44,6 +47,7 @@ func (lbc *LBConfigYAML) CreateConfig() *LBConfig {
 	cfg.LoadBalancer.TCPAppProfilePath = lbc.LoadBalancer.TCPAppProfilePath
 	cfg.LoadBalancer.UDPAppProfileName = lbc.LoadBalancer.UDPAppProfileName
 	cfg.LoadBalancer.UDPAppProfilePath = lbc.LoadBalancer.UDPAppProfilePath
+	cfg.LoadBalancer.Monitor = MonitorConfig(lbc.LoadBalancer.Monitor)
 	//LoadBalancerClassConfig -> LoadBalancerConfig
 	cfg.LoadBalancer.Size = lbc.LoadBalancer.Size
 	cfg.LoadBalancer.LBServiceID = lbc.LoadBalancer.LBServiceID
@@ -59,6 +63,7 @@ func (lbc *LBConfigYAML) CreateConfig() *LBConfig {
 			TCPAppProfilePath: value.TCPAppProfilePath,
 			UDPAppProfileName: value.UDPAppProfileName,
 			UDPAppProfilePath: value.UDPAppProfilePath,
+			Monitor:           MonitorConfig(value.Monitor),
 		}
 	}
 	return cfg
@@ -107,6 +112,39 @@ func (lbc *LBConfigYAML) validateConfig() error {
 			return fmt.Errorf(msg)
 		}
 	}
+	if err := MonitorConfig(lbc.LoadBalancer.Monitor).Validate(); err != nil {
+		klog.Errorf("load balancer: %s", err)
+		return fmt.Errorf("load balancer: %s", err)
+	}
+	for name, class := range lbc.LoadBalancerClass {
+		if err := MonitorConfig(class.Monitor).Validate(); err != nil {
+			klog.Errorf("load balancer class %s: %s", name, err)
+			return fmt.Errorf("load balancer class %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// fromEnv overrides the settings by the environment variables that are set
+func (lbc *LoadBalancerConfigYAML) fromEnv() error {
+	stringsFromEnv(map[string]*string{
+		EnvSize:              &lbc.Size,
+		EnvLBServiceID:       &lbc.LBServiceID,
+		EnvTier1GatewayPath:  &lbc.Tier1GatewayPath,
+		EnvIPPoolName:        &lbc.IPPoolName,
+		EnvIPPoolID:          &lbc.IPPoolID,
+		EnvTCPAppProfileName: &lbc.TCPAppProfileName,
+		EnvTCPAppProfilePath: &lbc.TCPAppProfilePath,
+		EnvUDPAppProfileName: &lbc.UDPAppProfileName,
+		EnvUDPAppProfilePath: &lbc.UDPAppProfilePath,
+	})
+	if v := os.Getenv(EnvTags); v != "" {
+		tags := map[string]string{}
+		if err := json.Unmarshal([]byte(v), &tags); err != nil {
+			return fmt.Errorf("unmarshalling %s failed: %s", EnvTags, err)
+		}
+		lbc.AdditionalTags = tags
+	}
 	return nil
 }
 
@@ -118,6 +156,11 @@ func (lbc *LoadBalancerConfigYAML) isEmpty() bool {
 
 // CompleteAndValidate sets default values, overrides by env and validates the resulting config
 func (lbc *LBConfigYAML) CompleteAndValidate() error {
+	// Env Vars should override config file entries if present
+	if err := lbc.LoadBalancer.fromEnv(); err != nil {
+		return err
+	}
+
 	if !lbc.isEnabled() {
 		return nil
 	}
@@ -147,7 +190,7 @@ func ReadRawConfigYAML(byConfig []byte) (*LBConfigYAML, error) {
 		LoadBalancerClass: make(map[string]*LoadBalancerClassConfigYAML),
 	}
 
-	if err := yaml.Unmarshal(byConfig, &cfg); err != nil {
+	if err := vcfg.UnmarshalYAML(byConfig, &cfg); err != nil {
 		klog.Errorf("Unmarshal failed: %s", err)
 		return nil, err
 	}
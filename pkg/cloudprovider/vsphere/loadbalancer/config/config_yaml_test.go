@@ -17,6 +17,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -99,3 +100,87 @@ loadBalancer:
 	assertEquals("loadBalancer.tcpAppProfilePath", config.LoadBalancer.TCPAppProfilePath, "infra/xxx/tcp1234")
 	assertEquals("loadBalancer.udpAppProfilePath", config.LoadBalancer.UDPAppProfilePath, "infra/xxx/udp1234")
 }
+
+func TestReadYAMLConfigStrict(t *testing.T) {
+	contents := `
+global:
+  strictConfig: true
+vcenter:
+  vc1:
+    server: 10.0.0.1
+loadBalancer:
+  ipPoolNme: pool1
+  size: MEDIUM
+  lbServiceId: 4711
+  tcpAppProfileName: tcp
+  udpAppProfileName: udp
+loadBalancerClass:
+  public:
+    ipPoolName: poolPublic
+    udpAppProfile: udp2
+`
+	_, err := ReadRawConfigYAML([]byte(contents))
+	if err == nil {
+		t.Fatal("expected unknown keys to fail in strict mode")
+	}
+	for _, expected := range []string{
+		`line 8: unknown field "loadBalancer.ipPoolNme"`,
+		`line 16: unknown field "loadBalancerClass.public.udpAppProfile"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %s in error, got %s", expected, err)
+		}
+	}
+
+	contents = strings.Replace(contents, "strictConfig: true", "strictConfig: false", 1)
+	// without strict mode the misspelled ipPoolName only surfaces as a missing pool
+	if _, err := ReadRawConfigYAML([]byte(contents)); err == nil || strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("expected unknown keys to be ignored, got %v", err)
+	}
+}
+
+func TestReadYAMLConfigMonitor(t *testing.T) {
+	contents := `
+loadBalancer:
+  ipPoolName: pool1
+  size: MEDIUM
+  lbServiceId: 4711
+  tcpAppProfileName: tcp
+  udpAppProfileName: udp
+  monitor:
+    interval: 10
+    fallCount: 2
+loadBalancerClass:
+  web:
+    monitor:
+      type: HTTP
+      port: 8080
+      requestPath: /healthz
+      responseStatusCodes: [200, 204]
+`
+	config, err := ReadConfigYAML([]byte(contents))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if m := config.LoadBalancer.Monitor; m.Interval != 10 || m.FallCount != 2 || m.Type != "" {
+		t.Errorf("unexpected loadBalancer.monitor %+v", m)
+	}
+	m := config.LoadBalancerClass["web"].Monitor
+	if m.Type != MonitorTypeHTTP || m.Port != 8080 || m.RequestPath != "/healthz" ||
+		len(m.ResponseStatusCodes) != 2 || m.ResponseStatusCodes[1] != 204 {
+		t.Errorf("unexpected loadBalancerClass.web.monitor %+v", m)
+	}
+
+	for name, replacement := range map[string][]string{
+		"type":   {"type: HTTP", "type: GRPC"},
+		"port":   {"port: 8080", "port: 70000"},
+		"status": {"[200, 204]", "[42]"},
+		"udp":    {"type: HTTP", "type: UDP"},
+	} {
+		invalid := strings.Replace(contents, replacement[0], replacement[1], 1)
+		if _, err := ReadConfigYAML([]byte(invalid)); err == nil {
+			t.Errorf("%s: expected error for invalid monitor", name)
+		}
+	}
+}
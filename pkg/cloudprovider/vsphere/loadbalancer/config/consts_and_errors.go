@@ -27,6 +27,39 @@ const (
 	DefaultLoadBalancerClass = "default"
 )
 
+// Environment variables overriding the settings of the loadBalancer section
+const (
+	EnvSize              = "VSPHERE_LB_SIZE"
+	EnvLBServiceID       = "VSPHERE_LB_SERVICE_ID"
+	EnvTier1GatewayPath  = "VSPHERE_LB_TIER1_GATEWAY_PATH"
+	EnvIPPoolName        = "VSPHERE_LB_IP_POOL_NAME"
+	EnvIPPoolID          = "VSPHERE_LB_IP_POOL_ID"
+	EnvTCPAppProfileName = "VSPHERE_LB_TCP_APP_PROFILE_NAME"
+	EnvTCPAppProfilePath = "VSPHERE_LB_TCP_APP_PROFILE_PATH"
+	EnvUDPAppProfileName = "VSPHERE_LB_UDP_APP_PROFILE_NAME"
+	EnvUDPAppProfilePath = "VSPHERE_LB_UDP_APP_PROFILE_PATH"
+	// EnvTags holds the additional tags as JSON object, like the INI tags setting
+	EnvTags = "VSPHERE_LB_TAGS"
+)
+
+// Health monitor types of a load balancer class
+const (
+	MonitorTypeTCP   = "TCP"
+	MonitorTypeHTTP  = "HTTP"
+	MonitorTypeHTTPS = "HTTPS"
+	MonitorTypeUDP   = "UDP"
+	MonitorTypeICMP  = "ICMP"
+)
+
+// MonitorTypes contains the valid health monitor types
+var MonitorTypes = sets.NewString(
+	MonitorTypeTCP,
+	MonitorTypeHTTP,
+	MonitorTypeHTTPS,
+	MonitorTypeUDP,
+	MonitorTypeICMP,
+)
+
 // LoadBalancerSizes contains the valid size names
 var LoadBalancerSizes = sets.NewString(
 	model.LBService_SIZE_SMALL,
@@ -39,4 +39,30 @@ type LoadBalancerClassConfig struct {
 	TCPAppProfilePath string
 	UDPAppProfileName string
 	UDPAppProfilePath string
+	Monitor           MonitorConfig
+}
+
+// MonitorConfig contains the configuration of the health monitors probing the
+// pool members of a load balancer class. Zero values select the defaults.
+type MonitorConfig struct {
+	// Type is one of TCP, HTTP, HTTPS, UDP or ICMP
+	Type string
+	// Port overrides the node port of the service
+	Port int
+	// RequestPath is the URL path requested by HTTP and HTTPS monitors
+	RequestPath string
+	// ResponseStatusCodes are the status codes expected by HTTP and HTTPS monitors
+	ResponseStatusCodes []int
+	// Interval is the time in seconds between two probes
+	Interval int
+	// Timeout is the time in seconds to wait for a response
+	Timeout int
+	// FallCount is the number of failed probes after which a member is down
+	FallCount int
+	// RiseCount is the number of successful probes after which a member is up
+	RiseCount int
+	// Send is the data sent by TCP and UDP monitors
+	Send string
+	// Receive is the data expected by TCP and UDP monitors
+	Receive string
 }
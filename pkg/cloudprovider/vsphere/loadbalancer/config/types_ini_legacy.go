@@ -40,4 +40,15 @@ type LoadBalancerClassConfigINI struct {
 	TCPAppProfilePath string `gcfg:"tcp-app-profile-path"`
 	UDPAppProfileName string `gcfg:"udp-app-profile-name"`
 	UDPAppProfilePath string `gcfg:"udp-app-profile-path"`
+
+	MonitorType                string `gcfg:"monitor-type"`
+	MonitorPort                int    `gcfg:"monitor-port"`
+	MonitorRequestPath         string `gcfg:"monitor-request-path"`
+	MonitorResponseStatusCodes []int  `gcfg:"monitor-response-status-code"`
+	MonitorInterval            int    `gcfg:"monitor-interval"`
+	MonitorTimeout             int    `gcfg:"monitor-timeout"`
+	MonitorFallCount           int    `gcfg:"monitor-fall-count"`
+	MonitorRiseCount           int    `gcfg:"monitor-rise-count"`
+	MonitorSend                string `gcfg:"monitor-send"`
+	MonitorReceive             string `gcfg:"monitor-receive"`
 }
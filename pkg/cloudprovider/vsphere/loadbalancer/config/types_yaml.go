@@ -41,20 +41,36 @@ type LoadBalancerConfigYAML struct {
 
 	// this struct use to inherit from LoadBalancerClassConfigYAML, but the YAML parser
 	// wasnt able to indirectly parse inherited fields
-	IPPoolName        string `yaml:"ipPoolName"`
-	IPPoolID          string `yaml:"ipPoolId"`
-	TCPAppProfileName string `yaml:"tcpAppProfileName"`
-	TCPAppProfilePath string `yaml:"tcpAppProfilePath"`
-	UDPAppProfileName string `yaml:"udpAppProfileName"`
-	UDPAppProfilePath string `yaml:"udpAppProfilePath"`
+	IPPoolName        string            `yaml:"ipPoolName"`
+	IPPoolID          string            `yaml:"ipPoolId"`
+	TCPAppProfileName string            `yaml:"tcpAppProfileName"`
+	TCPAppProfilePath string            `yaml:"tcpAppProfilePath"`
+	UDPAppProfileName string            `yaml:"udpAppProfileName"`
+	UDPAppProfilePath string            `yaml:"udpAppProfilePath"`
+	Monitor           MonitorConfigYAML `yaml:"monitor"`
 }
 
 // LoadBalancerClassConfigYAML contains the configuration for a load balancer class
 type LoadBalancerClassConfigYAML struct {
-	IPPoolName        string `yaml:"ipPoolName"`
-	IPPoolID          string `yaml:"ipPoolId"`
-	TCPAppProfileName string `yaml:"tcpAppProfileName"`
-	TCPAppProfilePath string `yaml:"tcpAppProfilePath"`
-	UDPAppProfileName string `yaml:"udpAppProfileName"`
-	UDPAppProfilePath string `yaml:"udpAppProfilePath"`
+	IPPoolName        string            `yaml:"ipPoolName"`
+	IPPoolID          string            `yaml:"ipPoolId"`
+	TCPAppProfileName string            `yaml:"tcpAppProfileName"`
+	TCPAppProfilePath string            `yaml:"tcpAppProfilePath"`
+	UDPAppProfileName string            `yaml:"udpAppProfileName"`
+	UDPAppProfilePath string            `yaml:"udpAppProfilePath"`
+	Monitor           MonitorConfigYAML `yaml:"monitor"`
+}
+
+// MonitorConfigYAML contains the configuration of the health monitors of a load balancer class
+type MonitorConfigYAML struct {
+	Type                string `yaml:"type"`
+	Port                int    `yaml:"port"`
+	RequestPath         string `yaml:"requestPath"`
+	ResponseStatusCodes []int  `yaml:"responseStatusCodes"`
+	Interval            int    `yaml:"interval"`
+	Timeout             int    `yaml:"timeout"`
+	FallCount           int    `yaml:"fallCount"`
+	RiseCount           int    `yaml:"riseCount"`
+	Send                string `yaml:"send"`
+	Receive             string `yaml:"receive"`
 }
@@ -17,12 +17,18 @@
 package loadbalancer
 
 import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	servicehelpers "k8s.io/cloud-provider/service/helpers"
 
 	vapi_errors "github.com/vmware/vsphere-automation-sdk-go/lib/vapi/std/errors"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
 )
 
 func namespacedNameFromService(service *corev1.Service) types.NamespacedName {
@@ -70,3 +76,76 @@ func safeEquals(a, b *string) bool {
 	}
 	return *a == *b
 }
+
+func strval(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func int64val(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// parseLoadBalancerIP parses the load balancer IP requested by a service
+func parseLoadBalancerIP(ipAddress string) (net.IP, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("load balancer IP %q is not a valid IP address", ipAddress)
+	}
+	return ip, nil
+}
+
+// staticSubnetContains returns true if the IP address is in one of the
+// allocation ranges of the subnet
+func staticSubnetContains(subnet model.IpAddressPoolStaticSubnet, ip net.IP) bool {
+	ip = ip.To16()
+	for _, r := range subnet.AllocationRanges {
+		start := net.ParseIP(strval(r.Start))
+		end := net.ParseIP(strval(r.End))
+		if start == nil || end == nil {
+			continue
+		}
+		if bytes.Compare(ip, start.To16()) >= 0 && bytes.Compare(ip, end.To16()) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getSourceRanges returns the sorted source ranges a service is restricted to,
+// from its loadBalancerSourceRanges or annotation, or nil if all sources are
+// allowed
+func getSourceRanges(service *corev1.Service) ([]string, error) {
+	ipnets, err := servicehelpers.GetLoadBalancerSourceRanges(service)
+	if err != nil {
+		return nil, err
+	}
+	sourceRanges := ipnets.StringSlice()
+	for _, sourceRange := range sourceRanges {
+		if sourceRange == "0.0.0.0/0" || sourceRange == "::/0" {
+			return nil, nil
+		}
+	}
+	sort.Strings(sourceRanges)
+	return sourceRanges, nil
+}
+
+// accessListEquals returns true if both access lists filter the same sources,
+// a disabled access list equals none
+func accessListEquals(a, b *model.LBAccessListControl) bool {
+	if a != nil && a.Enabled != nil && !*a.Enabled {
+		a = nil
+	}
+	if b != nil && b.Enabled != nil && !*b.Enabled {
+		b = nil
+	}
+	if a == nil || b == nil {
+		return a == b
+	}
+	return safeEquals(a.Action, b.Action) && safeEquals(a.GroupPath, b.GroupPath)
+}
@@ -0,0 +1,112 @@
+/*
+ Copyright 2021 The Kubernetes Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStaticSubnetContains(t *testing.T) {
+	subnet := model.IpAddressPoolStaticSubnet{
+		AllocationRanges: []model.IpPoolRange{
+			{Start: strptr("10.0.0.10"), End: strptr("10.0.0.20")},
+			{Start: strptr("10.0.1.1"), End: strptr("10.0.1.1")},
+		},
+	}
+
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{"10.0.0.10", true},
+		{"10.0.0.15", true},
+		{"10.0.0.20", true},
+		{"10.0.0.21", false},
+		{"10.0.0.9", false},
+		{"10.0.1.1", true},
+		{"10.0.2.1", false},
+		{"fd00::10", false},
+	}
+	for _, test := range tests {
+		if actual := staticSubnetContains(subnet, net.ParseIP(test.ip)); actual != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.ip, test.expected, actual)
+		}
+	}
+}
+
+func TestGetSourceRanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        []string
+		annotation  string
+		expected    []string
+		expectedErr bool
+	}{
+		{name: "none"},
+		{name: "spec", spec: []string{"192.168.0.0/16", "10.0.0.0/8"}, expected: []string{"10.0.0.0/8", "192.168.0.0/16"}},
+		{name: "annotation", annotation: "10.0.0.0/8, 172.16.0.0/12", expected: []string{"10.0.0.0/8", "172.16.0.0/12"}},
+		{name: "spec over annotation", spec: []string{"10.0.0.0/8"}, annotation: "172.16.0.0/12", expected: []string{"10.0.0.0/8"}},
+		{name: "allow all", spec: []string{"10.0.0.0/8", "0.0.0.0/0"}},
+		{name: "allow all IPv6", annotation: "::/0"},
+		{name: "invalid", spec: []string{"10.0.0.1"}, expectedErr: true},
+	}
+	for _, test := range tests {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+			Spec:       corev1.ServiceSpec{LoadBalancerSourceRanges: test.spec},
+		}
+		if test.annotation != "" {
+			service.Annotations[corev1.AnnotationLoadBalancerSourceRangesKey] = test.annotation
+		}
+		actual, err := getSourceRanges(service)
+		if test.expectedErr != (err != nil) {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestAccessListEquals(t *testing.T) {
+	allow := &model.LBAccessListControl{
+		Action:    strptr(model.LBAccessListControl_ACTION_ALLOW),
+		Enabled:   boolptr(true),
+		GroupPath: strptr("/infra/domains/default/groups/a"),
+	}
+	other := *allow
+	other.GroupPath = strptr("/infra/domains/default/groups/b")
+	disabled := *allow
+	disabled.Enabled = boolptr(false)
+
+	if !accessListEquals(nil, nil) || !accessListEquals(allow, allow) {
+		t.Error("expected equal access lists")
+	}
+	if accessListEquals(allow, nil) || accessListEquals(allow, &other) {
+		t.Error("expected different access lists")
+	}
+	if !accessListEquals(&disabled, nil) {
+		t.Error("expected a disabled access list to equal none")
+	}
+}
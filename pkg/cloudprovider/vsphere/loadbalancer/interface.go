@@ -17,12 +17,17 @@
 package loadbalancer
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clientset "k8s.io/client-go/kubernetes"
 	cloudprovider "k8s.io/cloud-provider"
 
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	pb "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/proto"
 )
 
 // LBProvider is the interface used call the load balancer functionality
@@ -31,7 +36,15 @@ import (
 type LBProvider interface {
 	cloudprovider.LoadBalancer
 	Initialize(clusterName string, client clientset.Interface, stop <-chan struct{})
-	CleanupServices(clusterName string, services map[types.NamespacedName]corev1.Service) error
+	CleanupServices(ctx context.Context, clusterName string, services map[types.NamespacedName]corev1.Service) error
+	// DescribeLoadBalancer fills lb with the NSX-T objects backing the given service
+	DescribeLoadBalancer(clusterName string, objectName types.NamespacedName, lb *pb.LoadBalancer) error
+	// ExportLoadBalancers appends the NSX-T objects of all services of the cluster to lbList,
+	// optionally restricted to a namespace
+	ExportLoadBalancers(clusterName string, namespace string, lbList *[]*pb.LoadBalancer) error
+	// UpdateClasses replaces the load balancer classes by the ones of a reloaded
+	// configuration and returns a description of every change
+	UpdateClasses(cfg *config.LBConfig) ([]string, error)
 }
 
 // NSXTAccess provides methods for dealing with NSX-T objects
@@ -45,9 +58,10 @@ type NSXTAccess interface {
 	// DeleteLoadBalancerService deletes a LbService by id
 	DeleteLoadBalancerService(id string) error
 
-	// CreateVirtualServer creates a virtual server
+	// CreateVirtualServer creates a virtual server, restricted to the sources of
+	// the access list unless it is nil
 	CreateVirtualServer(clusterName string, objectName types.NamespacedName, class LBClass, ipAddress string, mapping Mapping,
-		lbServicePath, applicationProfilePath string, poolPath *string) (*model.LBVirtualServer, error)
+		lbServicePath, applicationProfilePath string, poolPath *string, accessList *model.LBAccessListControl) (*model.LBVirtualServer, error)
 	// FindVirtualServers finds a virtual server by cluster and object name
 	FindVirtualServers(clusterName string, objectName types.NamespacedName) ([]*model.LBVirtualServer, error)
 	// ListVirtualServers finds all virtual servers for a cluster
@@ -79,25 +93,43 @@ type NSXTAccess interface {
 	// GetAppProfilePath gets the application profile for given loadbalancer class and protocol
 	GetAppProfilePath(class LBClass, protocol corev1.Protocol) (string, error)
 
-	// AllocateExternalIPAddress allocates an IP address from the given IP pool
-	AllocateExternalIPAddress(ipPoolID string, clusterName string, objectName types.NamespacedName) (allocation *model.IpAddressAllocation, ipAddress *string, err error)
+	// AllocateExternalIPAddress allocates an IP address from the given IP pool,
+	// the requested IP address unless it is empty
+	AllocateExternalIPAddress(ipPoolID string, clusterName string, objectName types.NamespacedName,
+		requestedIPAddress string) (allocation *model.IpAddressAllocation, ipAddress *string, err error)
 	// ListExternalIPAddresses finds all IP addresses belonging to a clusterName from the given IP pool
 	ListExternalIPAddresses(ipPoolID string, clusterName string) ([]*model.IpAddressAllocation, error)
-	// FindExternalIPAddressForObject finds an IP address belonging to an object
-	FindExternalIPAddressForObject(ipPoolID string, clusterName string, objectName types.NamespacedName) (allocation *model.IpAddressAllocation, ipAddress *string, err error)
+	// FindExternalIPAddressForObject finds an IP address belonging to an object. Allocations of the object
+	// replaced by it, left over if changing its load balancer IP was interrupted, are returned as stale
+	FindExternalIPAddressForObject(ipPoolID string, clusterName string, objectName types.NamespacedName) (allocation *model.IpAddressAllocation,
+		ipAddress *string, stale []*model.IpAddressAllocation, err error)
 	// ReleaseExternalIPAddress releases an allocated IP address
 	ReleaseExternalIPAddress(ipPoolID string, id string) error
 
-	// CreateTCPMonitorProfile creates a LBTcpMonitorProfile
-	CreateTCPMonitorProfile(clusterName string, objectName types.NamespacedName, mapping Mapping) (*model.LBTcpMonitorProfile, error)
-	// FindTCPMonitors finds a LBTcpMonitorProfile by cluster and object name
-	FindTCPMonitorProfiles(clusterName string, objectName types.NamespacedName) ([]*model.LBTcpMonitorProfile, error)
-	// ListTCPMonitorProfile lists LBTcpMonitorProfile by cluster
-	ListTCPMonitorProfiles(clusterName string) ([]*model.LBTcpMonitorProfile, error)
-	// UpdateTCPMonitorProfile updates a LBTcpMonitorProfile
-	UpdateTCPMonitorProfile(monitor *model.LBTcpMonitorProfile) error
-	// DeleteTCPMonitorProfile deletes a LBTcpMonitorProfile by id
-	DeleteTCPMonitorProfile(id string) error
+	// CreateMonitorProfile creates a MonitorProfile of the given settings for a port mapping
+	CreateMonitorProfile(clusterName string, objectName types.NamespacedName, mapping Mapping, settings MonitorSettings) (*MonitorProfile, error)
+	// FindMonitorProfiles finds the MonitorProfiles of all types by cluster and object name
+	FindMonitorProfiles(clusterName string, objectName types.NamespacedName) ([]*MonitorProfile, error)
+	// ListMonitorProfiles lists the MonitorProfiles of all types by cluster
+	ListMonitorProfiles(clusterName string) ([]*MonitorProfile, error)
+	// UpdateMonitorProfile updates a MonitorProfile
+	UpdateMonitorProfile(monitor *MonitorProfile) error
+	// DeleteMonitorProfile deletes a MonitorProfile by id
+	DeleteMonitorProfile(id string) error
+
+	// CreateSourceRangesGroup creates a Group of the source ranges of a service
+	CreateSourceRangesGroup(clusterName string, objectName types.NamespacedName, sourceRanges []string) (*model.Group, error)
+	// FindSourceRangesGroups finds the source ranges Groups by cluster and object name
+	FindSourceRangesGroups(clusterName string, objectName types.NamespacedName) ([]*model.Group, error)
+	// ListSourceRangesGroups lists the source ranges Groups by cluster
+	ListSourceRangesGroups(clusterName string) ([]*model.Group, error)
+	// UpdateSourceRangesGroup replaces the source ranges of a Group
+	UpdateSourceRangesGroup(group *model.Group, sourceRanges []string) error
+	// DeleteSourceRangesGroup deletes a source ranges Group by id
+	DeleteSourceRangesGroup(id string) error
+
+	// GetRealizedState gets the realized state of a policy object by its path
+	GetRealizedState(path string) (string, error)
 }
 
 // Reference references an object either by identifier or name
@@ -0,0 +1,250 @@
+/*
+ Copyright 2020 The Kubernetes Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	klog "k8s.io/klog/v2"
+
+	pb "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/proto"
+)
+
+// serviceArtefacts collects the NSX-T objects created for a single service
+type serviceArtefacts struct {
+	servers    []*model.LBVirtualServer
+	pools      []*model.LBPool
+	monitors   []*MonitorProfile
+	ipPoolID   string
+	allocation *model.IpAddressAllocation
+	ipAddress  *string
+}
+
+func (a *serviceArtefacts) isEmpty() bool {
+	return len(a.servers) == 0 && len(a.pools) == 0 && len(a.monitors) == 0 && a.allocation == nil
+}
+
+// DescribeLoadBalancer fills lb with the NSX-T objects backing the given service
+func (p *lbProvider) DescribeLoadBalancer(clusterName string, objectName types.NamespacedName, lb *pb.LoadBalancer) error {
+	artefacts := &serviceArtefacts{}
+	var err error
+	artefacts.servers, err = p.access.FindVirtualServers(clusterName, objectName)
+	if err != nil {
+		return err
+	}
+	artefacts.pools, err = p.access.FindPools(clusterName, objectName)
+	if err != nil {
+		return err
+	}
+	artefacts.monitors, err = p.access.FindMonitorProfiles(clusterName, objectName)
+	if err != nil {
+		return err
+	}
+	for ipPoolID := range p.ipPoolIDs(artefacts.servers) {
+		allocation, ipAddress, _, err := p.access.FindExternalIPAddressForObject(ipPoolID, clusterName, objectName)
+		if err != nil {
+			return err
+		}
+		if allocation != nil {
+			artefacts.ipPoolID = ipPoolID
+			artefacts.allocation = allocation
+			artefacts.ipAddress = ipAddress
+			break
+		}
+	}
+	if artefacts.isEmpty() {
+		return fmt.Errorf("no load balancer found for service %s", objectName)
+	}
+	p.fillLoadBalancer(objectName, artefacts, lb)
+	return nil
+}
+
+// ExportLoadBalancers appends the NSX-T objects of all services of the cluster to lbList,
+// optionally restricted to a namespace
+func (p *lbProvider) ExportLoadBalancers(clusterName string, namespace string, lbList *[]*pb.LoadBalancer) error {
+	lbs := map[types.NamespacedName]*serviceArtefacts{}
+	artefactsFor := func(tags []model.Tag) *serviceArtefacts {
+		tag := getTag(tags, ScopeService)
+		if tag == "" {
+			return nil
+		}
+		objectName := parseNamespacedName(tag)
+		if namespace != "" && objectName.Namespace != namespace {
+			return nil
+		}
+		artefacts, ok := lbs[objectName]
+		if !ok {
+			artefacts = &serviceArtefacts{}
+			lbs[objectName] = artefacts
+		}
+		return artefacts
+	}
+
+	servers, err := p.access.ListVirtualServers(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		if artefacts := artefactsFor(server.Tags); artefacts != nil {
+			artefacts.servers = append(artefacts.servers, server)
+		}
+	}
+
+	pools, err := p.access.ListPools(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, pool := range pools {
+		if artefacts := artefactsFor(pool.Tags); artefacts != nil {
+			artefacts.pools = append(artefacts.pools, pool)
+		}
+	}
+
+	monitors, err := p.access.ListMonitorProfiles(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, monitor := range monitors {
+		if artefacts := artefactsFor(monitor.Tags); artefacts != nil {
+			artefacts.monitors = append(artefacts.monitors, monitor)
+		}
+	}
+
+	for ipPoolID := range p.ipPoolIDs(servers) {
+		ipAddressAllocs, err := p.access.ListExternalIPAddresses(ipPoolID, clusterName)
+		if err != nil {
+			return err
+		}
+		for _, ipAddressAlloc := range ipAddressAllocs {
+			if artefacts := artefactsFor(ipAddressAlloc.Tags); artefacts != nil {
+				artefacts.ipPoolID = ipPoolID
+				artefacts.allocation = ipAddressAlloc
+				artefacts.ipAddress = ipAddressAlloc.AllocationIp
+			}
+		}
+	}
+
+	names := make([]types.NamespacedName, 0, len(lbs))
+	for objectName := range lbs {
+		names = append(names, objectName)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+	for _, objectName := range names {
+		lb := &pb.LoadBalancer{}
+		p.fillLoadBalancer(objectName, lbs[objectName], lb)
+		*lbList = append(*lbList, lb)
+	}
+	return nil
+}
+
+// ipPoolIDs returns the IP pools of all load balancer classes and the ones
+// referenced by the given virtual servers
+func (p *lbProvider) ipPoolIDs(servers []*model.LBVirtualServer) sets.String {
+	ipPoolIds := sets.NewString()
+	classes := p.getClasses()
+	for _, name := range classes.GetClassNames() {
+		ipPoolIds.Insert(classes.GetClass(name).ipPool.Identifier)
+	}
+	for _, server := range servers {
+		ipPoolIds.Insert(getTag(server.Tags, ScopeIPPoolID))
+	}
+	ipPoolIds.Delete("")
+	return ipPoolIds
+}
+
+func (p *lbProvider) fillLoadBalancer(objectName types.NamespacedName, artefacts *serviceArtefacts, lb *pb.LoadBalancer) {
+	lb.Namespace = objectName.Namespace
+	lb.Name = objectName.Name
+	lb.IpAddress = strval(artefacts.ipAddress)
+
+	for _, server := range artefacts.servers {
+		if lb.Class == "" {
+			lb.Class = getTag(server.Tags, ScopeLBClass)
+		}
+		if lb.IpAddress == "" {
+			lb.IpAddress = strval(server.IpAddress)
+		}
+		lb.VirtualServers = append(lb.VirtualServers, &pb.VirtualServer{
+			Id:                     strval(server.Id),
+			Path:                   strval(server.Path),
+			RealizedState:          p.realizedState(server.Path),
+			IpAddress:              strval(server.IpAddress),
+			Ports:                  server.Ports,
+			DefaultPoolMemberPorts: server.DefaultPoolMemberPorts,
+			PoolPath:               strval(server.PoolPath),
+			ApplicationProfilePath: strval(server.ApplicationProfilePath),
+		})
+	}
+
+	for _, pool := range artefacts.pools {
+		item := &pb.Pool{
+			Id:                 strval(pool.Id),
+			Path:               strval(pool.Path),
+			RealizedState:      p.realizedState(pool.Path),
+			ActiveMonitorPaths: pool.ActiveMonitorPaths,
+		}
+		for _, member := range pool.Members {
+			item.Members = append(item.Members, &pb.PoolMember{
+				DisplayName: strval(member.DisplayName),
+				IpAddress:   strval(member.IpAddress),
+				AdminState:  strval(member.AdminState),
+			})
+		}
+		lb.Pools = append(lb.Pools, item)
+	}
+
+	for _, monitor := range artefacts.monitors {
+		item := &pb.Monitor{
+			Id:            strval(monitor.ID),
+			Path:          strval(monitor.Path),
+			RealizedState: p.realizedState(monitor.Path),
+			ResourceType:  monitor.ResourceType,
+		}
+		if monitor.MonitorPort != nil {
+			item.Port = *monitor.MonitorPort
+		}
+		lb.Monitors = append(lb.Monitors, item)
+	}
+
+	if artefacts.allocation != nil {
+		lb.IpAllocation = &pb.IPAllocation{
+			Id:            strval(artefacts.allocation.Id),
+			Path:          strval(artefacts.allocation.Path),
+			RealizedState: p.realizedState(artefacts.allocation.Path),
+			IpPoolId:      artefacts.ipPoolID,
+			IpAddress:     strval(artefacts.ipAddress),
+		}
+	}
+}
+
+// realizedState returns the realized state of a policy object. Failures are
+// only logged, as the realized state is informational.
+func (p *lbProvider) realizedState(path *string) string {
+	if path == nil {
+		return ""
+	}
+	state, err := p.access.GetRealizedState(*path)
+	if err != nil {
+		klog.Warningf("%s", err)
+		return ""
+	}
+	return state
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/protocol/client"
@@ -27,6 +29,7 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 )
 
 const (
@@ -43,8 +46,9 @@ var (
 
 type lbProvider struct {
 	*lbService
-	classes *loadBalancerClasses
-	keyLock *keyLock
+	classesLock sync.RWMutex
+	classes     *loadBalancerClasses
+	keyLock     *keyLock
 }
 
 // ClusterName contains the cluster-name flag injected from main, needed for cleanup
@@ -73,6 +77,7 @@ func NewLBProvider(cfg *config.LBConfig, connector client.Connector) (LBProvider
 	if err != nil {
 		return nil, errors.Wrap(err, "creating load balancer classes failed")
 	}
+	registerMetrics()
 	return &lbProvider{
 		lbService: newLbService(access, cfg.LoadBalancer.LBServiceID),
 		classes:   classes,
@@ -80,6 +85,27 @@ func NewLBProvider(cfg *config.LBConfig, connector client.Connector) (LBProvider
 	}, nil
 }
 
+// UpdateClasses replaces the load balancer classes by the ones of the given
+// configuration. The classes are left unchanged if the configuration is invalid.
+func (p *lbProvider) UpdateClasses(cfg *config.LBConfig) ([]string, error) {
+	classes, err := setupClasses(p.access, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating load balancer classes failed")
+	}
+
+	p.classesLock.Lock()
+	defer p.classesLock.Unlock()
+	changes := p.classes.diff(classes)
+	p.classes = classes
+	return changes, nil
+}
+
+func (p *lbProvider) getClasses() *loadBalancerClasses {
+	p.classesLock.RLock()
+	defer p.classesLock.RUnlock()
+	return p.classes
+}
+
 func (p *lbProvider) Initialize(clusterName string, client clientset.Interface, stop <-chan struct{}) {
 	if clusterName != "" {
 		go p.cleanup(clusterName, client.CoreV1().Services(""), stop)
@@ -89,8 +115,12 @@ func (p *lbProvider) Initialize(clusterName string, client clientset.Interface,
 // GetLoadBalancer returns the LoadBalancerStatus
 // Implementations must treat the *corev1.Service parameter as read-only and not modify it.
 // Parameter 'clusterName' is the name of the cluster as presented to kube-controller-manager
-func (p *lbProvider) GetLoadBalancer(_ context.Context, clusterName string, service *corev1.Service) (status *corev1.LoadBalancerStatus, exists bool, err error) {
-	servers, err := p.access.FindVirtualServers(clusterName, namespacedNameFromService(service))
+func (p *lbProvider) GetLoadBalancer(ctx context.Context, clusterName string, service *corev1.Service) (status *corev1.LoadBalancerStatus, exists bool, err error) {
+	objectName := namespacedNameFromService(service)
+	ctx, span := tracing.Start(ctx, "loadbalancer.GetLoadBalancer", tracing.String("service", objectName.String()))
+	defer func() { span.End(err) }()
+
+	servers, err := withTracing(ctx, p.access).FindVirtualServers(clusterName, objectName)
 	if err != nil {
 		return nil, false, err
 	}
@@ -120,17 +150,30 @@ func (p *lbProvider) GetLoadBalancerName(_ context.Context, clusterName string,
 // Implementations must treat the *corev1.Service and *corev1.Node
 // parameters as read-only and not modify them.
 // Parameter 'clusterName' is the name of the cluster as presented to kube-controller-manager
-func (p *lbProvider) EnsureLoadBalancer(_ context.Context, clusterName string, service *corev1.Service, nodes []*corev1.Node) (*corev1.LoadBalancerStatus, error) {
+func (p *lbProvider) EnsureLoadBalancer(ctx context.Context, clusterName string, service *corev1.Service, nodes []*corev1.Node) (*corev1.LoadBalancerStatus, error) {
+	return p.ensureLoadBalancer(ctx, reconcileEnsure, clusterName, service, nodes)
+}
+
+// ensureLoadBalancer implements EnsureLoadBalancer, recording the reconciliation
+// as the given operation.
+func (p *lbProvider) ensureLoadBalancer(ctx context.Context, operation string, clusterName string, service *corev1.Service, nodes []*corev1.Node) (status *corev1.LoadBalancerStatus, err error) {
 	key := namespacedNameFromService(service).String()
-	p.keyLock.Lock(key)
+	ctx, span := tracing.Start(ctx, "loadbalancer.Reconcile",
+		tracing.String("operation", operation), tracing.String("service", key))
+	defer func() { span.End(err) }()
+
+	p.keyLock.Lock(ctx, key)
 	defer p.keyLock.Unlock(key)
 
+	start := time.Now()
+	defer func() { recordReconcile(operation, start, err) }()
+
 	class, err := p.classFromService(service)
 	if err != nil {
 		return nil, err
 	}
 
-	state := newState(p.lbService, clusterName, service, nodes)
+	state := newState(ctx, p.lbService, clusterName, service, nodes)
 	err = state.Process(class)
 	status, err2 := state.Finish()
 	if err != nil {
@@ -150,7 +193,7 @@ func (p *lbProvider) classFromService(service *corev1.Service) (*loadBalancerCla
 		name = config.DefaultLoadBalancerClass
 	}
 
-	class := p.classes.GetClass(name)
+	class := p.getClasses().GetClass(name)
 	if class == nil {
 		return nil, fmt.Errorf("invalid load balancer class %s", name)
 	}
@@ -161,14 +204,19 @@ func (p *lbProvider) classFromService(service *corev1.Service) (*loadBalancerCla
 // Implementations must treat the *corev1.Service and *corev1.Node
 // parameters as read-only and not modify them.
 // Parameter 'clusterName' is the name of the cluster as presented to kube-controller-manager
-func (p *lbProvider) UpdateLoadBalancer(_ context.Context, clusterName string, service *corev1.Service, nodes []*corev1.Node) error {
+func (p *lbProvider) UpdateLoadBalancer(ctx context.Context, clusterName string, service *corev1.Service, nodes []*corev1.Node) error {
 	key := namespacedNameFromService(service).String()
-	p.keyLock.Lock(key)
+	ctx, span := tracing.Start(ctx, "loadbalancer.Reconcile",
+		tracing.String("operation", reconcileUpdate), tracing.String("service", key))
+	p.keyLock.Lock(ctx, key)
 	defer p.keyLock.Unlock(key)
 
-	state := newState(p.lbService, clusterName, service, nodes)
-
-	return state.UpdatePoolMembers()
+	start := time.Now()
+	state := newState(ctx, p.lbService, clusterName, service, nodes)
+	err := state.UpdatePoolMembers()
+	recordReconcile(reconcileUpdate, start, err)
+	span.End(err)
+	return err
 }
 
 // EnsureLoadBalancerDeleted deletes the specified load balancer if it
@@ -182,6 +230,6 @@ func (p *lbProvider) UpdateLoadBalancer(_ context.Context, clusterName string, s
 func (p *lbProvider) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *corev1.Service) error {
 	emptyService := service.DeepCopy()
 	emptyService.Spec.Ports = nil
-	_, err := p.EnsureLoadBalancer(ctx, clusterName, emptyService, nil)
+	_, err := p.ensureLoadBalancer(ctx, reconcileDelete, clusterName, emptyService, nil)
 	return err
 }
@@ -17,8 +17,11 @@
 package loadbalancer
 
 import (
+	"context"
 	"fmt"
 	"sync"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 )
 
 type lbService struct {
@@ -32,11 +35,12 @@ func newLbService(access NSXTAccess, lbServiceID string) *lbService {
 	return &lbService{access: access, lbServiceID: lbServiceID, managed: lbServiceID == ""}
 }
 
-func (s *lbService) getOrCreateLoadBalancerService(clusterName string) (string, error) {
-	s.lbLock.Lock()
+func (s *lbService) getOrCreateLoadBalancerService(ctx context.Context, clusterName string) (string, error) {
+	tracing.Lock(ctx, "loadbalancer.lbLock", &s.lbLock)
 	defer s.lbLock.Unlock()
 
-	lbService, err := s.access.FindLoadBalancerService(clusterName, s.lbServiceID)
+	access := withTracing(ctx, s.access)
+	lbService, err := access.FindLoadBalancerService(clusterName, s.lbServiceID)
 	if err != nil {
 		return "", err
 	}
@@ -44,7 +48,7 @@ func (s *lbService) getOrCreateLoadBalancerService(clusterName string) (string,
 		return *lbService.Path, nil
 	}
 	if s.managed {
-		lbService, err = s.access.CreateLoadBalancerService(clusterName)
+		lbService, err = access.CreateLoadBalancerService(clusterName)
 		if err != nil {
 			return "", err
 		}
@@ -54,27 +58,28 @@ func (s *lbService) getOrCreateLoadBalancerService(clusterName string) (string,
 	return "", fmt.Errorf("no load balancer service found with id %s", s.lbServiceID)
 }
 
-func (s *lbService) removeLoadBalancerServiceIfUnused(clusterName string) error {
-	s.lbLock.Lock()
+func (s *lbService) removeLoadBalancerServiceIfUnused(ctx context.Context, clusterName string) error {
+	tracing.Lock(ctx, "loadbalancer.lbLock", &s.lbLock)
 	defer s.lbLock.Unlock()
 
 	if !s.managed {
 		return nil
 	}
 
-	lbService, err := s.access.FindLoadBalancerService(clusterName, s.lbServiceID)
+	access := withTracing(ctx, s.access)
+	lbService, err := access.FindLoadBalancerService(clusterName, s.lbServiceID)
 	if err != nil {
 		return err
 	}
 	if lbService == nil {
 		return nil
 	}
-	virtualServers, err := s.access.ListVirtualServers(clusterName)
+	virtualServers, err := access.ListVirtualServers(clusterName)
 	if err != nil {
 		return err
 	}
 	if len(virtualServers) == 0 {
-		err := s.access.DeleteLoadBalancerService(*lbService.Id)
+		err := access.DeleteLoadBalancerService(*lbService.Id)
 		if err != nil {
 			return err
 		}
@@ -17,8 +17,11 @@
 package loadbalancer
 
 import (
+	"context"
 	"fmt"
 	"sync"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 )
 
 type keyLock struct {
@@ -30,8 +33,8 @@ func newKeyLock() *keyLock {
 	return &keyLock{keys: map[string]*sync.Mutex{}}
 }
 
-// Lock locks the key
-func (l *keyLock) Lock(key string) {
+// Lock locks the key, recording the wait in a span of ctx
+func (l *keyLock) Lock(ctx context.Context, key string) {
 	l.lock.Lock()
 	lock := l.keys[key]
 	if lock == nil {
@@ -40,7 +43,7 @@ func (l *keyLock) Lock(key string) {
 	}
 	l.lock.Unlock()
 
-	lock.Lock()
+	tracing.Lock(ctx, "loadbalancer.keyLock", lock, tracing.String("key", key))
 }
 
 // Unlock unlocks the key
@@ -58,8 +58,8 @@ func (m Mapping) MatchPool(pool *model.LBPool) bool {
 	return checkTags(pool.Tags, portTag(m))
 }
 
-// MatchTCPMonitor returns true if the monitor has the correct port tag
-func (m Mapping) MatchTCPMonitor(monitor *model.LBTcpMonitorProfile) bool {
+// MatchMonitor returns true if the monitor has the correct port tag
+func (m Mapping) MatchMonitor(monitor *MonitorProfile) bool {
 	return checkTags(monitor.Tags, portTag(m))
 }
 
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// Values of the operation label of the reconcile metric
+const (
+	reconcileEnsure = "ensure"
+	reconcileUpdate = "update"
+	reconcileDelete = "delete"
+)
+
+var (
+	reconcileDurationMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "loadbalancer_reconcile_duration_seconds",
+			Help:           "Latency of reconciling the NSX-T load balancer of a service by operation and result",
+			Buckets:        metrics.ExponentialBuckets(0.05, 2, 12),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"operation", "result"},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// registerMetrics registers the load balancer metrics with the global registry
+// used by the cloud controller manager.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(reconcileDurationMetric)
+	})
+}
+
+// recordReconcile records a reconciliation of a load balancer which started
+// at start and failed with err, if not nil.
+func recordReconcile(operation string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileDurationMetric.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,269 @@
+/*
+ Copyright 2021 The Kubernetes Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	servicehelpers "k8s.io/cloud-provider/service/helpers"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+
+	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+)
+
+// Annotations at the service overriding the health monitor settings of its load balancer class
+const (
+	MonitorTypeAnnotation                = "loadbalancer.vmware.io/monitor-type"
+	MonitorPortAnnotation                = "loadbalancer.vmware.io/monitor-port"
+	MonitorRequestPathAnnotation         = "loadbalancer.vmware.io/monitor-request-path"
+	MonitorResponseStatusCodesAnnotation = "loadbalancer.vmware.io/monitor-response-status-codes"
+	MonitorIntervalAnnotation            = "loadbalancer.vmware.io/monitor-interval"
+	MonitorTimeoutAnnotation             = "loadbalancer.vmware.io/monitor-timeout"
+	MonitorFallCountAnnotation           = "loadbalancer.vmware.io/monitor-fall-count"
+	MonitorRiseCountAnnotation           = "loadbalancer.vmware.io/monitor-rise-count"
+	MonitorSendAnnotation                = "loadbalancer.vmware.io/monitor-send"
+	MonitorReceiveAnnotation             = "loadbalancer.vmware.io/monitor-receive"
+)
+
+// The defaults of NSX-T for the monitor settings. They are always set
+// explicitly, so that existing monitor profiles compare to the configuration.
+const (
+	defaultMonitorInterval           = 5
+	defaultMonitorTimeout            = 15
+	defaultMonitorFallCount          = 3
+	defaultMonitorRiseCount          = 3
+	defaultMonitorRequestPath        = "/"
+	defaultMonitorResponseStatusCode = 200
+)
+
+// monitorResourceTypes maps the monitor types to the resource types of the NSX-T monitor profiles
+var monitorResourceTypes = map[string]string{
+	config.MonitorTypeTCP:   model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE,
+	config.MonitorTypeHTTP:  model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPMONITORPROFILE,
+	config.MonitorTypeHTTPS: model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPSMONITORPROFILE,
+	config.MonitorTypeUDP:   model.LBMonitorProfile_RESOURCE_TYPE_LBUDPMONITORPROFILE,
+	config.MonitorTypeICMP:  model.LBMonitorProfile_RESOURCE_TYPE_LBICMPMONITORPROFILE,
+}
+
+// isMonitorResourceType returns true if the resource type is one of monitorResourceTypes
+func isMonitorResourceType(resourceType string) bool {
+	for _, t := range monitorResourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// MonitorProfile is an active NSX-T monitor profile of any of the monitor types
+type MonitorProfile struct {
+	ID          *string
+	Path        *string
+	DisplayName *string
+	Description *string
+	Revision    *int64
+	Tags        []model.Tag
+	MonitorSettings
+}
+
+// MonitorSettings are the settings of a monitor profile probing the pool members.
+// The settings not supported by the resource type are nil.
+type MonitorSettings struct {
+	ResourceType string
+	MonitorPort  *int64
+	Interval     *int64
+	Timeout      *int64
+	FallCount    *int64
+	RiseCount    *int64
+	// RequestMethod, RequestURL and ResponseStatusCodes are used by HTTP and HTTPS monitors
+	RequestMethod       *string
+	RequestURL          *string
+	ResponseStatusCodes []int64
+	// Send and Receive are used by TCP and UDP monitors
+	Send    *string
+	Receive *string
+}
+
+// mergeMonitorConfig returns the monitor settings of cfg, taking the unset ones from defaults
+func mergeMonitorConfig(cfg, defaults config.MonitorConfig) config.MonitorConfig {
+	if cfg.Type == "" {
+		cfg.Type = defaults.Type
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaults.Port
+	}
+	if cfg.RequestPath == "" {
+		cfg.RequestPath = defaults.RequestPath
+	}
+	if len(cfg.ResponseStatusCodes) == 0 {
+		cfg.ResponseStatusCodes = defaults.ResponseStatusCodes
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = defaults.Interval
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.FallCount == 0 {
+		cfg.FallCount = defaults.FallCount
+	}
+	if cfg.RiseCount == 0 {
+		cfg.RiseCount = defaults.RiseCount
+	}
+	if cfg.Send == "" {
+		cfg.Send = defaults.Send
+	}
+	if cfg.Receive == "" {
+		cfg.Receive = defaults.Receive
+	}
+	return cfg
+}
+
+// serviceMonitorConfig overrides the monitor settings of the load balancer class
+// by the monitor annotations of the service
+func serviceMonitorConfig(classMonitor config.MonitorConfig, service *corev1.Service) (config.MonitorConfig, error) {
+	annos := service.GetAnnotations()
+	cfg := config.MonitorConfig{
+		Type:        annos[MonitorTypeAnnotation],
+		RequestPath: annos[MonitorRequestPathAnnotation],
+		Send:        annos[MonitorSendAnnotation],
+		Receive:     annos[MonitorReceiveAnnotation],
+	}
+	for _, setting := range []struct {
+		annotation string
+		value      *int
+	}{
+		{MonitorPortAnnotation, &cfg.Port},
+		{MonitorIntervalAnnotation, &cfg.Interval},
+		{MonitorTimeoutAnnotation, &cfg.Timeout},
+		{MonitorFallCountAnnotation, &cfg.FallCount},
+		{MonitorRiseCountAnnotation, &cfg.RiseCount},
+	} {
+		if value, ok := annos[setting.annotation]; ok {
+			i, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return config.MonitorConfig{}, fmt.Errorf("invalid annotation %s: %s", setting.annotation, err)
+			}
+			*setting.value = i
+		}
+	}
+	if value, ok := annos[MonitorResponseStatusCodesAnnotation]; ok {
+		for _, item := range strings.Split(value, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil {
+				return config.MonitorConfig{}, fmt.Errorf("invalid annotation %s: %s", MonitorResponseStatusCodesAnnotation, err)
+			}
+			cfg.ResponseStatusCodes = append(cfg.ResponseStatusCodes, code)
+		}
+	}
+	cfg = mergeMonitorConfig(cfg, classMonitor)
+	if err := cfg.Validate(); err != nil {
+		return config.MonitorConfig{}, fmt.Errorf("invalid monitor annotations: %s", err)
+	}
+	return cfg, nil
+}
+
+// monitorFitsProtocol returns true if the monitor type can probe members of the protocol
+func monitorFitsProtocol(monitorType string, protocol corev1.Protocol) bool {
+	switch monitorType {
+	case config.MonitorTypeTCP, config.MonitorTypeHTTP, config.MonitorTypeHTTPS:
+		return protocol == corev1.ProtocolTCP
+	case config.MonitorTypeUDP:
+		return protocol == corev1.ProtocolUDP
+	case config.MonitorTypeICMP:
+		return true
+	default:
+		return false
+	}
+}
+
+// newMonitorSettings returns the settings of the monitor probing the pool
+// members of a mapping, or nil if the pool is not monitored.
+// The health check node port of a service with the Local external traffic
+// policy is always probed by HTTP. Otherwise the configured monitor type is
+// used if it fits the protocol of the mapping, falling back to a TCP monitor
+// on the node port for TCP and to no monitor for UDP.
+func newMonitorSettings(cfg config.MonitorConfig, mapping Mapping, service *corev1.Service) *MonitorSettings {
+	settings := &MonitorSettings{
+		Interval:  int64ptr(int64(intOrDefault(cfg.Interval, defaultMonitorInterval))),
+		Timeout:   int64ptr(int64(intOrDefault(cfg.Timeout, defaultMonitorTimeout))),
+		FallCount: int64ptr(int64(intOrDefault(cfg.FallCount, defaultMonitorFallCount))),
+		RiseCount: int64ptr(int64(intOrDefault(cfg.RiseCount, defaultMonitorRiseCount))),
+	}
+	if path, port := servicehelpers.GetServiceHealthCheckPathPort(service); port != 0 {
+		settings.ResourceType = model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPMONITORPROFILE
+		settings.MonitorPort = int64ptr(int64(port))
+		settings.RequestMethod = strptr(model.LBHttpMonitorProfile_REQUEST_METHOD_GET)
+		settings.RequestURL = strptr(path)
+		settings.ResponseStatusCodes = []int64{defaultMonitorResponseStatusCode}
+		return settings
+	}
+
+	monitorType := cfg.Type
+	if monitorType == "" && mapping.Protocol == corev1.ProtocolTCP {
+		monitorType = config.MonitorTypeTCP
+	}
+	if !monitorFitsProtocol(monitorType, mapping.Protocol) {
+		if mapping.Protocol != corev1.ProtocolTCP {
+			return nil
+		}
+		// the configured monitor is meant for the UDP mappings
+		monitorType = config.MonitorTypeTCP
+		cfg.Port = 0
+		cfg.Send = ""
+		cfg.Receive = ""
+	}
+	settings.ResourceType = monitorResourceTypes[monitorType]
+
+	switch monitorType {
+	case config.MonitorTypeHTTP, config.MonitorTypeHTTPS:
+		settings.RequestMethod = strptr(model.LBHttpMonitorProfile_REQUEST_METHOD_GET)
+		settings.RequestURL = strptr(defaultMonitorRequestPath)
+		if cfg.RequestPath != "" {
+			settings.RequestURL = strptr(cfg.RequestPath)
+		}
+		settings.ResponseStatusCodes = []int64{defaultMonitorResponseStatusCode}
+		if len(cfg.ResponseStatusCodes) > 0 {
+			settings.ResponseStatusCodes = make([]int64, len(cfg.ResponseStatusCodes))
+			for i, code := range cfg.ResponseStatusCodes {
+				settings.ResponseStatusCodes[i] = int64(code)
+			}
+		}
+	case config.MonitorTypeTCP, config.MonitorTypeUDP:
+		if cfg.Send != "" {
+			settings.Send = strptr(cfg.Send)
+		}
+		if cfg.Receive != "" {
+			settings.Receive = strptr(cfg.Receive)
+		}
+	}
+	if monitorType != config.MonitorTypeICMP {
+		settings.MonitorPort = int64ptr(int64(intOrDefault(cfg.Port, mapping.NodePort)))
+	}
+	return settings
+}
+
+func intOrDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,235 @@
+/*
+ Copyright 2021 The Kubernetes Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+)
+
+func TestServiceMonitorConfig(t *testing.T) {
+	classMonitor := config.MonitorConfig{Type: config.MonitorTypeHTTP, RequestPath: "/healthz", Interval: 10}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    config.MonitorConfig
+		expectedErr bool
+	}{
+		{name: "class", expected: classMonitor},
+		{
+			name: "override",
+			annotations: map[string]string{
+				MonitorPortAnnotation:                "8080",
+				MonitorResponseStatusCodesAnnotation: "200, 204",
+				MonitorFallCountAnnotation:           "2",
+			},
+			expected: config.MonitorConfig{Type: config.MonitorTypeHTTP, Port: 8080, RequestPath: "/healthz",
+				ResponseStatusCodes: []int{200, 204}, Interval: 10, FallCount: 2},
+		},
+		{
+			name:        "type",
+			annotations: map[string]string{MonitorTypeAnnotation: config.MonitorTypeICMP},
+			expected:    config.MonitorConfig{Type: config.MonitorTypeICMP, RequestPath: "/healthz", Interval: 10},
+		},
+		{name: "invalid number", annotations: map[string]string{MonitorIntervalAnnotation: "5s"}, expectedErr: true},
+		{name: "invalid type", annotations: map[string]string{MonitorTypeAnnotation: "GRPC"}, expectedErr: true},
+		{name: "invalid status code", annotations: map[string]string{MonitorResponseStatusCodesAnnotation: "200,x"}, expectedErr: true},
+	}
+	for _, test := range tests {
+		service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+		actual, err := serviceMonitorConfig(classMonitor, service)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestNewMonitorSettings(t *testing.T) {
+	tcp := Mapping{SourcePort: 80, NodePort: 30080, Protocol: corev1.ProtocolTCP}
+	udp := Mapping{SourcePort: 53, NodePort: 30053, Protocol: corev1.ProtocolUDP}
+	local := &corev1.Service{Spec: corev1.ServiceSpec{
+		Type:                  corev1.ServiceTypeLoadBalancer,
+		ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+		HealthCheckNodePort:   32000,
+	}}
+
+	tests := []struct {
+		name         string
+		cfg          config.MonitorConfig
+		mapping      Mapping
+		service      *corev1.Service
+		resourceType string
+		port         int64
+	}{
+		{name: "default TCP", mapping: tcp, resourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE, port: 30080},
+		{name: "default UDP", mapping: udp},
+		{name: "HTTP", cfg: config.MonitorConfig{Type: config.MonitorTypeHTTP, Port: 8080}, mapping: tcp,
+			resourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPMONITORPROFILE, port: 8080},
+		{name: "HTTP on UDP", cfg: config.MonitorConfig{Type: config.MonitorTypeHTTP}, mapping: udp},
+		{name: "UDP", cfg: config.MonitorConfig{Type: config.MonitorTypeUDP, Send: "ping", Receive: "pong"}, mapping: udp,
+			resourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBUDPMONITORPROFILE, port: 30053},
+		{name: "UDP on TCP", cfg: config.MonitorConfig{Type: config.MonitorTypeUDP, Port: 53, Send: "ping", Receive: "pong"}, mapping: tcp,
+			resourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE, port: 30080},
+		{name: "ICMP", cfg: config.MonitorConfig{Type: config.MonitorTypeICMP}, mapping: udp,
+			resourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBICMPMONITORPROFILE},
+		{name: "Local", cfg: config.MonitorConfig{Type: config.MonitorTypeICMP}, mapping: udp, service: local,
+			resourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPMONITORPROFILE, port: 32000},
+	}
+	for _, test := range tests {
+		service := test.service
+		if service == nil {
+			service = &corev1.Service{}
+		}
+		settings := newMonitorSettings(test.cfg, test.mapping, service)
+		if test.resourceType == "" {
+			if settings != nil {
+				t.Errorf("%s: expected no monitor, got %+v", test.name, settings)
+			}
+			continue
+		}
+		if settings == nil {
+			t.Errorf("%s: expected monitor %s, got none", test.name, test.resourceType)
+			continue
+		}
+		if settings.ResourceType != test.resourceType {
+			t.Errorf("%s: expected type %s, got %s", test.name, test.resourceType, settings.ResourceType)
+		}
+		if port := settings.MonitorPort; (port == nil) != (test.port == 0) || (port != nil && *port != test.port) {
+			t.Errorf("%s: expected port %d, got %v", test.name, test.port, port)
+		}
+		if *settings.Interval != defaultMonitorInterval || *settings.RiseCount != defaultMonitorRiseCount {
+			t.Errorf("%s: expected default interval and rise count, got %+v", test.name, settings)
+		}
+	}
+}
+
+func TestGetMonitorSwitchPolicy(t *testing.T) {
+	servicePort := corev1.ServicePort{Port: 80, NodePort: 30080, Protocol: corev1.ProtocolTCP}
+	cluster := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeLoadBalancer,
+			Ports:                 []corev1.ServicePort{servicePort},
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeCluster,
+		},
+	}
+	local := cluster.DeepCopy()
+	local.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	local.Spec.HealthCheckNodePort = 32000
+
+	type step struct {
+		service *corev1.Service
+		calls   []string
+		port    int64
+		url     string
+	}
+	http := model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPMONITORPROFILE
+	tcp := model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE
+	tests := []struct {
+		name  string
+		cfg   config.MonitorConfig
+		steps []step
+	}{
+		{
+			// the monitor of the health check node port has the same type
+			// as the one of the class, so it is updated in place
+			name: "HTTP",
+			cfg:  config.MonitorConfig{Type: config.MonitorTypeHTTP, RequestPath: "/ready"},
+			steps: []step{
+				{service: cluster, calls: []string{"create " + http}, port: 30080, url: "/ready"},
+				{service: local, calls: []string{"update monitor-0"}, port: 32000, url: "/healthz"},
+				{service: cluster, calls: []string{"update monitor-0"}, port: 30080, url: "/ready"},
+			},
+		},
+		{
+			// the type of a monitor cannot be changed, so it is replaced
+			name: "TCP",
+			steps: []step{
+				{service: cluster, calls: []string{"create " + tcp}, port: 30080},
+				{service: local, calls: []string{"create " + http, "delete monitor-0"}, port: 32000, url: "/healthz"},
+				{service: cluster, calls: []string{"create " + tcp, "delete monitor-1"}, port: 30080},
+			},
+		},
+	}
+	for _, test := range tests {
+		access := &fakeAccess{}
+		for i, step := range test.steps {
+			access.calls = nil
+			s := newTestState(access, step.service)
+			s.monitorConfig = test.cfg
+			s.monitors, _ = access.FindMonitorProfiles(s.clusterName, s.objectName)
+			monitor, err := s.getMonitor(NewMapping(servicePort))
+			if err != nil {
+				t.Fatalf("%s %d: %s", test.name, i, err)
+			}
+			if err := s.deleteOrphanMonitors(sets.NewString(*monitor.Path)); err != nil {
+				t.Fatalf("%s %d: %s", test.name, i, err)
+			}
+			if !reflect.DeepEqual(access.calls, step.calls) {
+				t.Errorf("%s %d: expected calls %v, got %v", test.name, i, step.calls, access.calls)
+			}
+			if len(access.monitors) != 1 {
+				t.Errorf("%s %d: expected a single monitor, got %d", test.name, i, len(access.monitors))
+			}
+			if *monitor.MonitorPort != step.port || strval(monitor.RequestURL) != step.url {
+				t.Errorf("%s %d: expected port %d and URL %q, got %d and %q", test.name, i, step.port, step.url,
+					*monitor.MonitorPort, strval(monitor.RequestURL))
+			}
+		}
+	}
+}
+
+func TestConvertMonitorProfile(t *testing.T) {
+	converter := newNsxtTypeConverter()
+	for _, settings := range []MonitorSettings{
+		{ResourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE, MonitorPort: int64ptr(30080), Send: strptr("ping")},
+		{ResourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPSMONITORPROFILE, MonitorPort: int64ptr(443), Interval: int64ptr(10),
+			RequestMethod: strptr(model.LBHttpMonitorProfile_REQUEST_METHOD_GET), RequestURL: strptr("/healthz"), ResponseStatusCodes: []int64{200, 204}},
+		{ResourceType: model.LBMonitorProfile_RESOURCE_TYPE_LBICMPMONITORPROFILE, FallCount: int64ptr(2)},
+	} {
+		monitor := MonitorProfile{ID: strptr("id"), Tags: []model.Tag{newTag(ScopeService, "ns/name")}, MonitorSettings: settings}
+		value, err := converter.convertMonitorProfileToStructValue(monitor)
+		if err != nil {
+			t.Errorf("%s: %s", settings.ResourceType, err)
+			continue
+		}
+		actual, err := converter.convertStructValueToMonitorProfile(value)
+		if err != nil {
+			t.Errorf("%s: %s", settings.ResourceType, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, monitor) {
+			t.Errorf("%s: expected %+v, got %+v", settings.ResourceType, monitor, actual)
+		}
+	}
+
+	if _, err := converter.convertMonitorProfileToStructValue(MonitorProfile{}); err == nil {
+		t.Errorf("expected error for missing resource type")
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/protocol/client"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra"
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/domains"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/ip_pools"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/infra/realized_state"
 	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
@@ -53,26 +54,38 @@ type NsxtBroker interface {
 	ListIPPools() ([]model.IpAddressPool, error)
 	AllocateFromIPPool(ipPoolID string, allocation model.IpAddressAllocation) (model.IpAddressAllocation, string, error)
 	ListIPPoolAllocations(ipPoolID string) ([]model.IpAddressAllocation, error)
+	ListIPPoolSubnets(ipPoolID string) ([]*data.StructValue, error)
 	ReleaseFromIPPool(ipPoolID, ipAllocationID string) error
 	GetRealizedExternalIPAddress(ipAllocationPath string, timeout time.Duration) (*string, error)
+	GetRealizedState(intentPath string) (string, error)
 	ListAppProfiles() ([]*data.StructValue, error)
 
-	CreateLoadBalancerTCPMonitorProfile(monitor model.LBTcpMonitorProfile) (model.LBTcpMonitorProfile, error)
+	CreateLoadBalancerMonitorProfile(monitor MonitorProfile) (MonitorProfile, error)
 	ListLoadBalancerMonitorProfiles() ([]*data.StructValue, error)
-	ReadLoadBalancerTCPMonitorProfile(id string) (model.LBTcpMonitorProfile, error)
-	UpdateLoadBalancerTCPMonitorProfile(monitor model.LBTcpMonitorProfile) (model.LBTcpMonitorProfile, error)
+	ReadLoadBalancerMonitorProfile(id string) (MonitorProfile, error)
+	UpdateLoadBalancerMonitorProfile(monitor MonitorProfile) (MonitorProfile, error)
 	DeleteLoadBalancerMonitorProfile(id string) error
+
+	CreateGroup(group model.Group) (model.Group, error)
+	ListGroups() ([]model.Group, error)
+	UpdateGroup(group model.Group) (model.Group, error)
+	DeleteGroup(id string) error
 }
 
+// groupDomain is the domain of the groups of the source ranges
+const groupDomain = "default"
+
 type nsxtBroker struct {
 	lbServicesClient        infra.LbServicesClient
 	lbVirtServersClient     infra.LbVirtualServersClient
 	lbPoolsClient           infra.LbPoolsClient
 	ipPoolsClient           infra.IpPoolsClient
 	ipAllocationsClient     ip_pools.IpAllocationsClient
+	ipSubnetsClient         ip_pools.IpSubnetsClient
 	lbAppProfilesClient     infra.LbAppProfilesClient
 	lbMonitorProfilesClient infra.LbMonitorProfilesClient
 	realizedEntitiesClient  realized_state.RealizedEntitiesClient
+	groupsClient            domains.GroupsClient
 }
 
 // NewNsxtBroker creates a new NsxtBroker using the configuration
@@ -93,9 +106,11 @@ func NewNsxtBrokerFromConnector(connector client.Connector) NsxtBroker {
 		lbPoolsClient:           infra.NewDefaultLbPoolsClient(connector),
 		ipPoolsClient:           infra.NewDefaultIpPoolsClient(connector),
 		ipAllocationsClient:     ip_pools.NewDefaultIpAllocationsClient(connector),
+		ipSubnetsClient:         ip_pools.NewDefaultIpSubnetsClient(connector),
 		lbAppProfilesClient:     infra.NewDefaultLbAppProfilesClient(connector),
 		lbMonitorProfilesClient: infra.NewDefaultLbMonitorProfilesClient(connector),
 		realizedEntitiesClient:  realized_state.NewDefaultRealizedEntitiesClient(connector),
+		groupsClient:            domains.NewDefaultGroupsClient(connector),
 	}
 }
 
@@ -224,24 +239,23 @@ func (b *nsxtBroker) ListAppProfiles() ([]*data.StructValue, error) {
 	return list, nil
 }
 
-func (b *nsxtBroker) CreateLoadBalancerTCPMonitorProfile(monitor model.LBTcpMonitorProfile) (model.LBTcpMonitorProfile, error) {
+func (b *nsxtBroker) CreateLoadBalancerMonitorProfile(monitor MonitorProfile) (MonitorProfile, error) {
 	id := uuid.New().String()
-	result, err := b.createOrUpdateLoadBalancerTCPMonitorProfile(id, monitor)
+	result, err := b.createOrUpdateLoadBalancerMonitorProfile(id, monitor)
 	return result, nicerVAPIError(err)
 }
 
-func (b *nsxtBroker) createOrUpdateLoadBalancerTCPMonitorProfile(id string, monitor model.LBTcpMonitorProfile) (model.LBTcpMonitorProfile, error) {
-	monitor.ResourceType = model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE
+func (b *nsxtBroker) createOrUpdateLoadBalancerMonitorProfile(id string, monitor MonitorProfile) (MonitorProfile, error) {
 	converter := newNsxtTypeConverter()
-	value, err := converter.convertLBTCPMonitorProfileToStructValue(monitor)
+	value, err := converter.convertMonitorProfileToStructValue(monitor)
 	if err != nil {
-		return model.LBTcpMonitorProfile{}, errors.Wrapf(err, "converting LBTcpMonitorProfile failed")
+		return MonitorProfile{}, errors.Wrapf(err, "converting %s failed", monitor.ResourceType)
 	}
 	result, err := b.lbMonitorProfilesClient.Update(id, value)
 	if err != nil {
-		return model.LBTcpMonitorProfile{}, nicerVAPIError(err)
+		return MonitorProfile{}, nicerVAPIError(err)
 	}
-	return converter.convertStructValueToLBTCPMonitorProfile(result)
+	return converter.convertStructValueToMonitorProfile(result)
 }
 
 func (b *nsxtBroker) ListLoadBalancerMonitorProfiles() ([]*data.StructValue, error) {
@@ -261,16 +275,16 @@ func (b *nsxtBroker) ListLoadBalancerMonitorProfiles() ([]*data.StructValue, err
 	return list, nil
 }
 
-func (b *nsxtBroker) ReadLoadBalancerTCPMonitorProfile(id string) (model.LBTcpMonitorProfile, error) {
+func (b *nsxtBroker) ReadLoadBalancerMonitorProfile(id string) (MonitorProfile, error) {
 	itf, err := b.lbMonitorProfilesClient.Get(id)
 	if err != nil {
-		return model.LBTcpMonitorProfile{}, errors.Wrapf(nicerVAPIError(err), "getting LBTcpMonitorProfile %s failed", id)
+		return MonitorProfile{}, errors.Wrapf(nicerVAPIError(err), "getting LBMonitorProfile %s failed", id)
 	}
-	return newNsxtTypeConverter().convertStructValueToLBTCPMonitorProfile(itf)
+	return newNsxtTypeConverter().convertStructValueToMonitorProfile(itf)
 }
 
-func (b *nsxtBroker) UpdateLoadBalancerTCPMonitorProfile(monitor model.LBTcpMonitorProfile) (model.LBTcpMonitorProfile, error) {
-	result, err := b.createOrUpdateLoadBalancerTCPMonitorProfile(*monitor.Id, monitor)
+func (b *nsxtBroker) UpdateLoadBalancerMonitorProfile(monitor MonitorProfile) (MonitorProfile, error) {
+	result, err := b.createOrUpdateLoadBalancerMonitorProfile(*monitor.ID, monitor)
 	return result, nicerVAPIError(err)
 }
 
@@ -279,6 +293,39 @@ func (b *nsxtBroker) DeleteLoadBalancerMonitorProfile(id string) error {
 	return nicerVAPIError(err)
 }
 
+func (b *nsxtBroker) CreateGroup(group model.Group) (model.Group, error) {
+	id := uuid.New().String()
+	result, err := b.groupsClient.Update(groupDomain, id, group)
+	return result, nicerVAPIError(err)
+}
+
+func (b *nsxtBroker) ListGroups() ([]model.Group, error) {
+	result, err := b.groupsClient.List(groupDomain, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return nil, nicerVAPIError(err)
+	}
+	list := result.Results
+	count := int(*result.ResultCount)
+	for len(list) < count {
+		result, err = b.groupsClient.List(groupDomain, result.Cursor, nil, nil, nil, nil, nil, nil)
+		if err != nil {
+			return nil, nicerVAPIError(err)
+		}
+		list = append(list, result.Results...)
+	}
+	return list, nil
+}
+
+func (b *nsxtBroker) UpdateGroup(group model.Group) (model.Group, error) {
+	result, err := b.groupsClient.Update(groupDomain, *group.Id, group)
+	return result, nicerVAPIError(err)
+}
+
+func (b *nsxtBroker) DeleteGroup(id string) error {
+	err := b.groupsClient.Delete(groupDomain, id, nil, nil)
+	return nicerVAPIError(err)
+}
+
 func (b *nsxtBroker) ListIPPools() ([]model.IpAddressPool, error) {
 	result, err := b.ipPoolsClient.List(nil, nil, nil, nil, nil, nil)
 	if err != nil {
@@ -333,6 +380,23 @@ func (b *nsxtBroker) ListIPPoolAllocations(ipPoolID string) ([]model.IpAddressAl
 	return list, nil
 }
 
+func (b *nsxtBroker) ListIPPoolSubnets(ipPoolID string) ([]*data.StructValue, error) {
+	result, err := b.ipSubnetsClient.List(ipPoolID, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return nil, nicerVAPIError(err)
+	}
+	list := result.Results
+	count := int(*result.ResultCount)
+	for len(list) < count {
+		result, err = b.ipSubnetsClient.List(ipPoolID, result.Cursor, nil, nil, nil, nil, nil)
+		if err != nil {
+			return nil, nicerVAPIError(err)
+		}
+		list = append(list, result.Results...)
+	}
+	return list, nil
+}
+
 func (b *nsxtBroker) ReleaseFromIPPool(ipPoolID, ipAllocationID string) error {
 	err := b.ipAllocationsClient.Delete(ipPoolID, ipAllocationID)
 	return nicerVAPIError(err)
@@ -365,6 +429,25 @@ func (b *nsxtBroker) GetRealizedExternalIPAddress(ipAllocationPath string, timeo
 	return nil, fmt.Errorf("Timeout of wait for realized state of IP allocation")
 }
 
+func (b *nsxtBroker) GetRealizedState(intentPath string) (string, error) {
+	list, err := b.realizedEntitiesClient.List(intentPath, nil)
+	if err != nil {
+		return "", nicerVAPIError(err)
+	}
+	// an intent may be realized by multiple entities, report the first one
+	// not being realized (yet)
+	state := ""
+	for _, realizedResource := range list.Results {
+		if realizedResource.State == nil {
+			continue
+		}
+		if state == "" || *realizedResource.State != model.GenericPolicyRealizedResource_STATE_REALIZED {
+			state = *realizedResource.State
+		}
+	}
+	return state, nil
+}
+
 func nicerVAPIError(err error) error {
 	switch vapiError := err.(type) {
 	case vapi_errors.InvalidRequest:
@@ -47,8 +47,178 @@ func (c *nsxtTypeConverter) createLBSnatAutoMap() (*data.StructValue, error) {
 	return dataValue.(*data.StructValue), nil
 }
 
-func (c *nsxtTypeConverter) convertLBTCPMonitorProfileToStructValue(monitor model.LBTcpMonitorProfile) (*data.StructValue, error) {
-	dataValue, errs := c.ConvertToVapi(monitor, model.LBTcpMonitorProfileBindingType())
+func (c *nsxtTypeConverter) convertMonitorProfileToStructValue(monitor MonitorProfile) (*data.StructValue, error) {
+	var value interface{}
+	var bindingType bindings.BindingType
+	switch monitor.ResourceType {
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE:
+		value = model.LBTcpMonitorProfile{
+			Id: monitor.ID, Path: monitor.Path, DisplayName: monitor.DisplayName, Description: monitor.Description,
+			Revision: monitor.Revision, Tags: monitor.Tags, ResourceType: monitor.ResourceType,
+			MonitorPort: monitor.MonitorPort, Interval: monitor.Interval, Timeout: monitor.Timeout,
+			FallCount: monitor.FallCount, RiseCount: monitor.RiseCount,
+			Send: monitor.Send, Receive: monitor.Receive,
+		}
+		bindingType = model.LBTcpMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBUDPMONITORPROFILE:
+		value = model.LBUdpMonitorProfile{
+			Id: monitor.ID, Path: monitor.Path, DisplayName: monitor.DisplayName, Description: monitor.Description,
+			Revision: monitor.Revision, Tags: monitor.Tags, ResourceType: monitor.ResourceType,
+			MonitorPort: monitor.MonitorPort, Interval: monitor.Interval, Timeout: monitor.Timeout,
+			FallCount: monitor.FallCount, RiseCount: monitor.RiseCount,
+			Send: monitor.Send, Receive: monitor.Receive,
+		}
+		bindingType = model.LBUdpMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPMONITORPROFILE:
+		value = model.LBHttpMonitorProfile{
+			Id: monitor.ID, Path: monitor.Path, DisplayName: monitor.DisplayName, Description: monitor.Description,
+			Revision: monitor.Revision, Tags: monitor.Tags, ResourceType: monitor.ResourceType,
+			MonitorPort: monitor.MonitorPort, Interval: monitor.Interval, Timeout: monitor.Timeout,
+			FallCount: monitor.FallCount, RiseCount: monitor.RiseCount,
+			RequestMethod: monitor.RequestMethod, RequestUrl: monitor.RequestURL, ResponseStatusCodes: monitor.ResponseStatusCodes,
+		}
+		bindingType = model.LBHttpMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPSMONITORPROFILE:
+		value = model.LBHttpsMonitorProfile{
+			Id: monitor.ID, Path: monitor.Path, DisplayName: monitor.DisplayName, Description: monitor.Description,
+			Revision: monitor.Revision, Tags: monitor.Tags, ResourceType: monitor.ResourceType,
+			MonitorPort: monitor.MonitorPort, Interval: monitor.Interval, Timeout: monitor.Timeout,
+			FallCount: monitor.FallCount, RiseCount: monitor.RiseCount,
+			RequestMethod: monitor.RequestMethod, RequestUrl: monitor.RequestURL, ResponseStatusCodes: monitor.ResponseStatusCodes,
+		}
+		bindingType = model.LBHttpsMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBICMPMONITORPROFILE:
+		value = model.LBIcmpMonitorProfile{
+			Id: monitor.ID, Path: monitor.Path, DisplayName: monitor.DisplayName, Description: monitor.Description,
+			Revision: monitor.Revision, Tags: monitor.Tags, ResourceType: monitor.ResourceType,
+			MonitorPort: monitor.MonitorPort, Interval: monitor.Interval, Timeout: monitor.Timeout,
+			FallCount: monitor.FallCount, RiseCount: monitor.RiseCount,
+		}
+		bindingType = model.LBIcmpMonitorProfileBindingType()
+	default:
+		return nil, fmt.Errorf("unsupported monitor profile type %q", monitor.ResourceType)
+	}
+
+	dataValue, errs := c.ConvertToVapi(value, bindingType)
+	if errs != nil {
+		return nil, errs[0]
+	}
+
+	return dataValue.(*data.StructValue), nil
+}
+
+// convertStructValueToMonitorProfile converts the monitor profiles of the
+// resource types of monitorResourceTypes, it fails for all other types
+func (c *nsxtTypeConverter) convertStructValueToMonitorProfile(dataValue *data.StructValue) (MonitorProfile, error) {
+	resourceType, err := dataValue.String("resource_type")
+	if err != nil {
+		return MonitorProfile{}, err
+	}
+	var bindingType bindings.BindingType
+	switch resourceType {
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBTCPMONITORPROFILE:
+		bindingType = model.LBTcpMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBUDPMONITORPROFILE:
+		bindingType = model.LBUdpMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPMONITORPROFILE:
+		bindingType = model.LBHttpMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBHTTPSMONITORPROFILE:
+		bindingType = model.LBHttpsMonitorProfileBindingType()
+	case model.LBMonitorProfile_RESOURCE_TYPE_LBICMPMONITORPROFILE:
+		bindingType = model.LBIcmpMonitorProfileBindingType()
+	default:
+		return MonitorProfile{}, fmt.Errorf("unsupported monitor profile type %q", resourceType)
+	}
+	itf, errs := c.ConvertToGolang(dataValue, bindingType)
+	if errs != nil {
+		return MonitorProfile{}, errs[0]
+	}
+
+	switch profile := itf.(type) {
+	case model.LBTcpMonitorProfile:
+		return MonitorProfile{
+			ID: profile.Id, Path: profile.Path, DisplayName: profile.DisplayName, Description: profile.Description,
+			Revision: profile.Revision, Tags: profile.Tags,
+			MonitorSettings: MonitorSettings{
+				ResourceType: profile.ResourceType,
+				MonitorPort:  profile.MonitorPort, Interval: profile.Interval, Timeout: profile.Timeout,
+				FallCount: profile.FallCount, RiseCount: profile.RiseCount,
+				Send: profile.Send, Receive: profile.Receive,
+			},
+		}, nil
+	case model.LBUdpMonitorProfile:
+		return MonitorProfile{
+			ID: profile.Id, Path: profile.Path, DisplayName: profile.DisplayName, Description: profile.Description,
+			Revision: profile.Revision, Tags: profile.Tags,
+			MonitorSettings: MonitorSettings{
+				ResourceType: profile.ResourceType,
+				MonitorPort:  profile.MonitorPort, Interval: profile.Interval, Timeout: profile.Timeout,
+				FallCount: profile.FallCount, RiseCount: profile.RiseCount,
+				Send: profile.Send, Receive: profile.Receive,
+			},
+		}, nil
+	case model.LBHttpMonitorProfile:
+		return MonitorProfile{
+			ID: profile.Id, Path: profile.Path, DisplayName: profile.DisplayName, Description: profile.Description,
+			Revision: profile.Revision, Tags: profile.Tags,
+			MonitorSettings: MonitorSettings{
+				ResourceType: profile.ResourceType,
+				MonitorPort:  profile.MonitorPort, Interval: profile.Interval, Timeout: profile.Timeout,
+				FallCount: profile.FallCount, RiseCount: profile.RiseCount,
+				RequestMethod: profile.RequestMethod, RequestURL: profile.RequestUrl,
+				ResponseStatusCodes: nonEmptyInt64s(profile.ResponseStatusCodes),
+			},
+		}, nil
+	case model.LBHttpsMonitorProfile:
+		return MonitorProfile{
+			ID: profile.Id, Path: profile.Path, DisplayName: profile.DisplayName, Description: profile.Description,
+			Revision: profile.Revision, Tags: profile.Tags,
+			MonitorSettings: MonitorSettings{
+				ResourceType: profile.ResourceType,
+				MonitorPort:  profile.MonitorPort, Interval: profile.Interval, Timeout: profile.Timeout,
+				FallCount: profile.FallCount, RiseCount: profile.RiseCount,
+				RequestMethod: profile.RequestMethod, RequestURL: profile.RequestUrl,
+				ResponseStatusCodes: nonEmptyInt64s(profile.ResponseStatusCodes),
+			},
+		}, nil
+	case model.LBIcmpMonitorProfile:
+		return MonitorProfile{
+			ID: profile.Id, Path: profile.Path, DisplayName: profile.DisplayName, Description: profile.Description,
+			Revision: profile.Revision, Tags: profile.Tags,
+			MonitorSettings: MonitorSettings{
+				ResourceType: profile.ResourceType,
+				MonitorPort:  profile.MonitorPort, Interval: profile.Interval, Timeout: profile.Timeout,
+				FallCount: profile.FallCount, RiseCount: profile.RiseCount,
+			},
+		}, nil
+	default:
+		return MonitorProfile{}, fmt.Errorf("converting struct value to %s failed", resourceType)
+	}
+}
+
+// nonEmptyInt64s returns nil for an empty slice, so that settings compare alike
+func nonEmptyInt64s(values []int64) []int64 {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func (c *nsxtTypeConverter) convertStructValueToIPPoolStaticSubnet(dataValue *data.StructValue) (model.IpAddressPoolStaticSubnet, error) {
+	itf, errs := c.ConvertToGolang(dataValue, model.IpAddressPoolStaticSubnetBindingType())
+	if errs != nil {
+		return model.IpAddressPoolStaticSubnet{}, errs[0]
+	}
+
+	subnet, ok := itf.(model.IpAddressPoolStaticSubnet)
+	if !ok {
+		return model.IpAddressPoolStaticSubnet{}, fmt.Errorf("converting struct value to IpAddressPoolStaticSubnet failed")
+	}
+	return subnet, nil
+}
+
+func (c *nsxtTypeConverter) convertIPAddressExpressionToStructValue(expression model.IPAddressExpression) (*data.StructValue, error) {
+	dataValue, errs := c.ConvertToVapi(expression, model.IPAddressExpressionBindingType())
 	if errs != nil {
 		return nil, errs[0]
 	}
@@ -56,15 +226,15 @@ func (c *nsxtTypeConverter) convertLBTCPMonitorProfileToStructValue(monitor mode
 	return dataValue.(*data.StructValue), nil
 }
 
-func (c *nsxtTypeConverter) convertStructValueToLBTCPMonitorProfile(dataValue *data.StructValue) (model.LBTcpMonitorProfile, error) {
-	itf, errs := c.ConvertToGolang(dataValue, model.LBTcpMonitorProfileBindingType())
+func (c *nsxtTypeConverter) convertStructValueToIPAddressExpression(dataValue *data.StructValue) (model.IPAddressExpression, error) {
+	itf, errs := c.ConvertToGolang(dataValue, model.IPAddressExpressionBindingType())
 	if errs != nil {
-		return model.LBTcpMonitorProfile{}, errs[0]
+		return model.IPAddressExpression{}, errs[0]
 	}
 
-	profile, ok := itf.(model.LBTcpMonitorProfile)
+	expression, ok := itf.(model.IPAddressExpression)
 	if !ok {
-		return model.LBTcpMonitorProfile{}, fmt.Errorf("converting struct value to LBTcpMonitorProfile failed")
+		return model.IPAddressExpression{}, fmt.Errorf("converting struct value to IPAddressExpression failed")
 	}
-	return profile, nil
+	return expression, nil
 }
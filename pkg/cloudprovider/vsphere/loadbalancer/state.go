@@ -17,8 +17,11 @@
 package loadbalancer
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"reflect"
+	"sort"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -33,21 +36,36 @@ import (
 
 type state struct {
 	*lbService
-	clusterName    string
-	objectName     types.NamespacedName
-	service        *corev1.Service
-	nodes          []*corev1.Node
-	servers        []*model.LBVirtualServer
-	pools          []*model.LBPool
-	tcpMonitors    []*model.LBTcpMonitorProfile
+	// ctx carries the span of the reconciliation
+	ctx context.Context
+	// access records its calls in spans, it shadows the access of the lbService
+	access      NSXTAccess
+	clusterName string
+	objectName  types.NamespacedName
+	service     *corev1.Service
+	nodes       []*corev1.Node
+	servers     []*model.LBVirtualServer
+	pools       []*model.LBPool
+	monitors    []*MonitorProfile
+	groups      []*model.Group
+	// accessList restricts the virtual servers to the source ranges of the
+	// service, nil if all sources are allowed
+	accessList     *model.LBAccessListControl
 	ipAddressAlloc *model.IpAddressAllocation
 	ipAddress      *string
-	class          *loadBalancerClass
+	// staleIPAddressAllocs are the allocations replaced by ipAddressAlloc,
+	// released once the virtual servers are moved to its IP address
+	staleIPAddressAllocs []*model.IpAddressAllocation
+	class                *loadBalancerClass
+	// monitorConfig are the monitor settings of the class overridden by the service
+	monitorConfig config.MonitorConfig
 }
 
-func newState(lbService *lbService, clusterName string, service *corev1.Service, nodes []*corev1.Node) *state {
+func newState(ctx context.Context, lbService *lbService, clusterName string, service *corev1.Service, nodes []*corev1.Node) *state {
 	return &state{
 		lbService:   lbService,
+		ctx:         ctx,
+		access:      withTracing(ctx, lbService.access),
 		clusterName: clusterName,
 		service:     service,
 		nodes:       nodes,
@@ -63,7 +81,7 @@ func (s *state) CtxInfof(format string, args ...interface{}) {
 // Process processes a load balancer and ensures that all needed objects are existing
 func (s *state) Process(class *loadBalancerClass) error {
 	var err error
-	s.ipAddressAlloc, s.ipAddress, err = s.access.FindExternalIPAddressForObject(class.ipPool.Identifier, s.clusterName, s.objectName)
+	s.ipAddressAlloc, s.ipAddress, s.staleIPAddressAllocs, err = s.access.FindExternalIPAddressForObject(class.ipPool.Identifier, s.clusterName, s.objectName)
 	if err != nil {
 		return err
 	}
@@ -75,7 +93,11 @@ func (s *state) Process(class *loadBalancerClass) error {
 	if err != nil {
 		return err
 	}
-	s.tcpMonitors, err = s.access.FindTCPMonitorProfiles(s.clusterName, s.objectName)
+	s.monitors, err = s.access.FindMonitorProfiles(s.clusterName, s.objectName)
+	if err != nil {
+		return err
+	}
+	s.groups, err = s.access.FindSourceRangesGroups(s.clusterName, s.objectName)
 	if err != nil {
 		return err
 	}
@@ -93,11 +115,26 @@ func (s *state) Process(class *loadBalancerClass) error {
 		}
 	}
 	s.class = class
+	if len(s.service.Spec.Ports) > 0 {
+		// invalid monitor annotations must not block the deletion
+		s.monitorConfig, err = serviceMonitorConfig(class.monitor, s.service)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.reallocateResources()
+	if err != nil {
+		return err
+	}
+	err = s.updateAccessList()
+	if err != nil {
+		return err
+	}
 
 	for _, servicePort := range s.service.Spec.Ports {
 		mapping := NewMapping(servicePort)
 
-		monitor, err := s.getTCPMonitor(mapping)
+		monitor, err := s.getMonitor(mapping)
 		if err != nil {
 			return err
 		}
@@ -114,16 +151,86 @@ func (s *state) Process(class *loadBalancerClass) error {
 	if err != nil {
 		return err
 	}
+	err = s.releaseStaleResources()
+	if err != nil {
+		return err
+	}
 	s.CtxInfof("validPoolPaths: %v", validPoolPaths.List())
-	validTCPMonitorPaths, err := s.deleteOrphanPools(validPoolPaths)
+	validMonitorPaths, err := s.deleteOrphanPools(validPoolPaths)
 	if err != nil {
 		return err
 	}
-	s.CtxInfof("validTCPMonitorPaths: %v", validTCPMonitorPaths.List())
-	err = s.deleteOrphanTCPMonitors(validTCPMonitorPaths)
+	s.CtxInfof("validMonitorPaths: %v", validMonitorPaths.List())
+	err = s.deleteOrphanMonitors(validMonitorPaths)
 	if err != nil {
 		return err
 	}
+	err = s.deleteOrphanGroups()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// updateAccessList creates or updates the group of the source ranges of the
+// service and sets the access list of the virtual servers to it
+func (s *state) updateAccessList() error {
+	s.accessList = nil
+	if len(s.service.Spec.Ports) == 0 {
+		return nil
+	}
+	sourceRanges, err := getSourceRanges(s.service)
+	if err != nil {
+		return err
+	}
+	if sourceRanges == nil {
+		return nil
+	}
+
+	var group *model.Group
+	if len(s.groups) > 0 {
+		group = s.groups[0]
+		ipAddresses, err := groupIPAddresses(group)
+		if err != nil {
+			return err
+		}
+		sort.Strings(ipAddresses)
+		if !reflect.DeepEqual(ipAddresses, sourceRanges) {
+			s.CtxInfof("updating source ranges group %s: %v", *group.Id, sourceRanges)
+			err = s.access.UpdateSourceRangesGroup(group, sourceRanges)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		group, err = s.access.CreateSourceRangesGroup(s.clusterName, s.objectName, sourceRanges)
+		if err != nil {
+			return err
+		}
+		s.CtxInfof("created source ranges group %s: %v", *group.Id, sourceRanges)
+		s.groups = append(s.groups, group)
+	}
+	s.accessList = &model.LBAccessListControl{
+		Action:    strptr(model.LBAccessListControl_ACTION_ALLOW),
+		Enabled:   boolptr(true),
+		GroupPath: group.Path,
+	}
+	return nil
+}
+
+// deleteOrphanGroups deletes the source ranges groups not used by the access
+// list of the virtual servers
+func (s *state) deleteOrphanGroups() error {
+	for _, group := range s.groups {
+		if s.accessList != nil && safeEquals(group.Path, s.accessList.GroupPath) {
+			continue
+		}
+		s.CtxInfof("deleting source ranges group %s", *group.Id)
+		err := s.access.DeleteSourceRangesGroup(*group.Id)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -152,14 +259,14 @@ func (s *state) deleteOrphanVirtualServers() (sets.String, error) {
 }
 
 func (s *state) deleteOrphanPools(validPoolPaths sets.String) (sets.String, error) {
-	validTCPMonitorPaths := sets.String{}
+	validMonitorPaths := sets.String{}
 	for _, pool := range s.pools {
 		found := false
 		for _, servicePort := range s.service.Spec.Ports {
 			mapping := NewMapping(servicePort)
 			if mapping.MatchPool(pool) && validPoolPaths.Has(*pool.Path) {
 				if len(pool.ActiveMonitorPaths) > 0 {
-					validTCPMonitorPaths.Insert(pool.ActiveMonitorPaths...)
+					validMonitorPaths.Insert(pool.ActiveMonitorPaths...)
 				}
 				found = true
 				break
@@ -172,21 +279,21 @@ func (s *state) deleteOrphanPools(validPoolPaths sets.String) (sets.String, erro
 			}
 		}
 	}
-	return validTCPMonitorPaths, nil
+	return validMonitorPaths, nil
 }
 
-func (s *state) deleteOrphanTCPMonitors(validTCPMonitorPaths sets.String) error {
-	for _, monitor := range s.tcpMonitors {
+func (s *state) deleteOrphanMonitors(validMonitorPaths sets.String) error {
+	for _, monitor := range s.monitors {
 		found := false
 		for _, servicePort := range s.service.Spec.Ports {
 			mapping := NewMapping(servicePort)
-			if mapping.MatchTCPMonitor(monitor) && monitor.Path != nil && validTCPMonitorPaths.Has(*monitor.Path) {
+			if mapping.MatchMonitor(monitor) && monitor.Path != nil && validMonitorPaths.Has(*monitor.Path) {
 				found = true
 				break
 			}
 		}
 		if !found {
-			err := s.deleteTCPMonitor(monitor)
+			err := s.deleteMonitor(monitor)
 			if err != nil {
 				return err
 			}
@@ -198,7 +305,8 @@ func (s *state) deleteOrphanTCPMonitors(validTCPMonitorPaths sets.String) error
 func (s *state) allocateResources() (allocated bool, err error) {
 	if s.ipAddressAlloc == nil {
 		ipPoolID := s.class.ipPool.Identifier
-		s.ipAddressAlloc, s.ipAddress, err = s.access.AllocateExternalIPAddress(ipPoolID, s.clusterName, s.objectName)
+		s.ipAddressAlloc, s.ipAddress, err = s.access.AllocateExternalIPAddress(ipPoolID, s.clusterName, s.objectName,
+			s.service.Spec.LoadBalancerIP)
 		if err != nil {
 			return
 		}
@@ -208,7 +316,67 @@ func (s *state) allocateResources() (allocated bool, err error) {
 	return
 }
 
+// reallocateResources allocates the IP address requested by the service if it
+// differs from the allocated one, or takes back a replaced allocation of the
+// service which has not been released yet. The virtual servers are moved to the new IP
+// address when they are updated, and the previous allocation is released
+// after all of them are.
+func (s *state) reallocateResources() error {
+	if s.ipAddressAlloc == nil || s.service.Spec.LoadBalancerIP == "" {
+		return nil
+	}
+	requested, err := parseLoadBalancerIP(s.service.Spec.LoadBalancerIP)
+	if err != nil {
+		return err
+	}
+	if requested.Equal(net.ParseIP(strval(s.ipAddress))) {
+		return nil
+	}
+	for i, stale := range s.staleIPAddressAllocs {
+		if stale.AllocationIp != nil && requested.Equal(net.ParseIP(*stale.AllocationIp)) {
+			// the service changes back to an IP address whose replacement was
+			// interrupted before it was released, so it is still allocated
+			s.CtxInfof("reusing IP address %s to replace %s", *stale.AllocationIp, strval(s.ipAddress))
+			s.staleIPAddressAllocs[i] = s.ipAddressAlloc
+			s.ipAddressAlloc = stale
+			s.ipAddress = stale.AllocationIp
+			return nil
+		}
+	}
+	ipPoolID := s.class.ipPool.Identifier
+	allocation, ipAddress, err := s.access.AllocateExternalIPAddress(ipPoolID, s.clusterName, s.objectName,
+		s.service.Spec.LoadBalancerIP)
+	if err != nil {
+		return err
+	}
+	s.CtxInfof("allocated IP address %s from pool %s to replace %s", *ipAddress, ipPoolID, strval(s.ipAddress))
+	s.staleIPAddressAllocs = append(s.staleIPAddressAllocs, s.ipAddressAlloc)
+	s.ipAddressAlloc = allocation
+	s.ipAddress = ipAddress
+	return nil
+}
+
+// releaseStaleResources releases the allocations replaced by the current one,
+// which must not be used by any virtual server anymore
+func (s *state) releaseStaleResources() error {
+	for len(s.staleIPAddressAllocs) > 0 {
+		ipPoolID := s.class.ipPool.Identifier
+		allocation := s.staleIPAddressAllocs[0]
+		s.CtxInfof("releasing replaced IP address %s to pool %s", strval(allocation.AllocationIp), ipPoolID)
+		err := s.access.ReleaseExternalIPAddress(ipPoolID, *allocation.Id)
+		if err != nil {
+			return err
+		}
+		s.staleIPAddressAllocs = s.staleIPAddressAllocs[1:]
+	}
+	return nil
+}
+
 func (s *state) releaseResources() error {
+	err := s.releaseStaleResources()
+	if err != nil {
+		return err
+	}
 	if s.ipAddressAlloc != nil {
 		ipPoolID := s.class.ipPool.Identifier
 		err := s.access.ReleaseExternalIPAddress(ipPoolID, *s.ipAddressAlloc.Id)
@@ -241,46 +409,48 @@ func (s *state) Finish() (*corev1.LoadBalancerStatus, error) {
 	return newLoadBalancerStatus(s.ipAddress), nil
 }
 
-func (s *state) getTCPMonitor(mapping Mapping) (*model.LBTcpMonitorProfile, error) {
-	if mapping.Protocol == corev1.ProtocolTCP {
-		for _, m := range s.tcpMonitors {
-			if mapping.MatchTCPMonitor(m) {
-				err := s.updateTCPMonitor(m, mapping)
-				if err != nil {
-					return nil, err
-				}
-				return m, nil
+func (s *state) getMonitor(mapping Mapping) (*MonitorProfile, error) {
+	settings := newMonitorSettings(s.monitorConfig, mapping, s.service)
+	if settings == nil {
+		return nil, nil
+	}
+	for _, m := range s.monitors {
+		// the type of a monitor profile cannot be changed, it is replaced instead
+		if mapping.MatchMonitor(m) && m.ResourceType == settings.ResourceType {
+			err := s.updateMonitor(m, mapping, settings)
+			if err != nil {
+				return nil, err
 			}
+			return m, nil
 		}
-		return s.createTCPMonitor(mapping)
 	}
-	return nil, nil
+	return s.createMonitor(mapping, settings)
 }
 
-func (s *state) createTCPMonitor(mapping Mapping) (*model.LBTcpMonitorProfile, error) {
-	monitor, err := s.access.CreateTCPMonitorProfile(s.clusterName, s.objectName, mapping)
+func (s *state) createMonitor(mapping Mapping, settings *MonitorSettings) (*MonitorProfile, error) {
+	monitor, err := s.access.CreateMonitorProfile(s.clusterName, s.objectName, mapping, *settings)
 	if err == nil {
-		s.CtxInfof("created LbTcpMonitor %s for %s", *monitor.Id, mapping)
-		s.tcpMonitors = append(s.tcpMonitors, monitor)
+		s.CtxInfof("created %s %s for %s", monitor.ResourceType, *monitor.ID, mapping)
+		s.monitors = append(s.monitors, monitor)
 	}
 	return monitor, err
 }
 
-func (s *state) updateTCPMonitor(monitor *model.LBTcpMonitorProfile, mapping Mapping) error {
-	if monitor.MonitorPort != nil && *monitor.MonitorPort == int64(mapping.NodePort) {
+func (s *state) updateMonitor(monitor *MonitorProfile, mapping Mapping, settings *MonitorSettings) error {
+	if reflect.DeepEqual(monitor.MonitorSettings, *settings) {
 		return nil
 	}
-	monitor.MonitorPort = int64ptr(int64(mapping.NodePort))
-	s.CtxInfof("updating LbTcpMonitor %s for %s", *monitor.Id, mapping)
-	return s.access.UpdateTCPMonitorProfile(monitor)
+	monitor.MonitorSettings = *settings
+	s.CtxInfof("updating %s %s for %s", monitor.ResourceType, *monitor.ID, mapping)
+	return s.access.UpdateMonitorProfile(monitor)
 }
 
-func (s *state) deleteTCPMonitor(monitor *model.LBTcpMonitorProfile) error {
-	s.CtxInfof("deleting LbTcpMonitor %s for %s", *monitor.Id, getTag(monitor.Tags, ScopePort))
-	return s.access.DeleteTCPMonitorProfile(*monitor.Id)
+func (s *state) deleteMonitor(monitor *MonitorProfile) error {
+	s.CtxInfof("deleting %s %s for %s", monitor.ResourceType, *monitor.ID, getTag(monitor.Tags, ScopePort))
+	return s.access.DeleteMonitorProfile(*monitor.ID)
 }
 
-func (s *state) getPool(mapping Mapping, monitor *model.LBTcpMonitorProfile) (*model.LBPool, error) {
+func (s *state) getPool(mapping Mapping, monitor *MonitorProfile) (*model.LBPool, error) {
 	var activeMonitorPaths []string
 	if monitor != nil {
 		activeMonitorPaths = []string{*monitor.Path}
@@ -399,7 +569,7 @@ func (s *state) createVirtualServer(mapping Mapping, poolPath *string) (*model.L
 		return nil, err
 	}
 
-	lbServicePath, err := s.lbService.getOrCreateLoadBalancerService(s.clusterName)
+	lbServicePath, err := s.lbService.getOrCreateLoadBalancerService(s.ctx, s.clusterName)
 	if err != nil {
 		return nil, errors.Wrapf(err, "get or create LBService failed")
 	}
@@ -410,7 +580,7 @@ func (s *state) createVirtualServer(mapping Mapping, poolPath *string) (*model.L
 	}
 
 	server, err := s.access.CreateVirtualServer(s.clusterName, s.objectName, s.class, *s.ipAddress, mapping,
-		lbServicePath, applicationProfilePath, poolPath)
+		lbServicePath, applicationProfilePath, poolPath, s.accessList)
 	if err != nil {
 		if allocated {
 			s.loggedReleaseResources()
@@ -427,10 +597,15 @@ func (s *state) updateVirtualServer(server *model.LBVirtualServer, mapping Mappi
 	if err != nil {
 		return errors.Wrapf(err, "Lookup of application profile failed for %s", mapping.Protocol)
 	}
-	if !mapping.MatchNodePort(server) || !safeEquals(server.PoolPath, poolPath) || !safeEquals(server.ApplicationProfilePath, &applicationProfilePath) {
+	if !mapping.MatchNodePort(server) || !safeEquals(server.PoolPath, poolPath) || !safeEquals(server.ApplicationProfilePath, &applicationProfilePath) ||
+		(s.ipAddress != nil && !safeEquals(server.IpAddress, s.ipAddress)) || !accessListEquals(server.AccessListControl, s.accessList) {
 		server.ApplicationProfilePath = strptr(applicationProfilePath)
 		server.DefaultPoolMemberPorts = []string{formatPort(mapping.NodePort)}
 		server.PoolPath = poolPath
+		if s.ipAddress != nil {
+			server.IpAddress = s.ipAddress
+		}
+		server.AccessListControl = s.accessList
 		s.CtxInfof("updating LbVirtualServer %s for %s", *server.Id, mapping)
 		err = s.access.UpdateVirtualServer(server)
 		if err != nil {
@@ -450,5 +625,5 @@ func (s *state) deleteVirtualServer(server *model.LBVirtualServer) error {
 	if err != nil {
 		return err
 	}
-	return s.lbService.removeLoadBalancerServiceIfUnused(s.clusterName)
+	return s.lbService.removeLoadBalancerServiceIfUnused(s.ctx, s.clusterName)
 }
@@ -0,0 +1,210 @@
+/*
+ Copyright 2021 The Kubernetes Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fakeAccess records the calls of the state to NSX-T. Methods not
+// implemented here panic through the nil embedded interface.
+type fakeAccess struct {
+	NSXTAccess
+	calls    []string
+	monitors map[string]*MonitorProfile
+	// nextID is the number of the next created object
+	nextID int
+}
+
+func (a *fakeAccess) AllocateExternalIPAddress(ipPoolID string, clusterName string, objectName types.NamespacedName,
+	requestedIPAddress string) (*model.IpAddressAllocation, *string, error) {
+	a.calls = append(a.calls, "allocate "+requestedIPAddress)
+	id := "alloc-" + requestedIPAddress
+	return &model.IpAddressAllocation{Id: &id, AllocationIp: &requestedIPAddress}, &requestedIPAddress, nil
+}
+
+func (a *fakeAccess) ReleaseExternalIPAddress(ipPoolID string, id string) error {
+	a.calls = append(a.calls, "release "+id)
+	return nil
+}
+
+func (a *fakeAccess) CreateMonitorProfile(clusterName string, objectName types.NamespacedName, mapping Mapping,
+	settings MonitorSettings) (*MonitorProfile, error) {
+	id := fmt.Sprintf("monitor-%d", a.nextID)
+	a.nextID++
+	a.calls = append(a.calls, "create "+settings.ResourceType)
+	if a.monitors == nil {
+		a.monitors = map[string]*MonitorProfile{}
+	}
+	a.monitors[id] = &MonitorProfile{ID: &id, Path: strptr("/monitors/" + id), Tags: []model.Tag{portTag(mapping)}, MonitorSettings: settings}
+	return a.monitor(id), nil
+}
+
+// monitor returns a copy of a monitor profile, as read from NSX-T
+func (a *fakeAccess) monitor(id string) *MonitorProfile {
+	monitor := *a.monitors[id]
+	return &monitor
+}
+
+func (a *fakeAccess) FindMonitorProfiles(clusterName string, objectName types.NamespacedName) ([]*MonitorProfile, error) {
+	var ids []string
+	for id := range a.monitors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var monitors []*MonitorProfile
+	for _, id := range ids {
+		monitors = append(monitors, a.monitor(id))
+	}
+	return monitors, nil
+}
+
+func (a *fakeAccess) UpdateMonitorProfile(monitor *MonitorProfile) error {
+	a.calls = append(a.calls, "update "+*monitor.ID)
+	updated := *monitor
+	a.monitors[*monitor.ID] = &updated
+	return nil
+}
+
+func (a *fakeAccess) DeleteMonitorProfile(id string) error {
+	a.calls = append(a.calls, "delete "+id)
+	delete(a.monitors, id)
+	return nil
+}
+
+func newTestState(access *fakeAccess, service *corev1.Service) *state {
+	s := newState(context.Background(), newLbService(access, "lbs"), "cluster", service, nil)
+	s.class = &loadBalancerClass{className: "default", ipPool: Reference{Identifier: "pool"}}
+	return s
+}
+
+func TestReallocateResources(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec:       corev1.ServiceSpec{LoadBalancerIP: "10.0.0.2"},
+	}
+	access := &fakeAccess{}
+	s := newTestState(access, service)
+	oldID, oldIP := "alloc-10.0.0.1", "10.0.0.1"
+	s.ipAddressAlloc = &model.IpAddressAllocation{Id: &oldID, AllocationIp: &oldIP}
+	s.ipAddress = &oldIP
+
+	if err := s.reallocateResources(); err != nil {
+		t.Fatal(err)
+	}
+	// the virtual servers still use the previous IP address until they are updated
+	if expected := []string{"allocate 10.0.0.2"}; !reflect.DeepEqual(access.calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, access.calls)
+	}
+	if strval(s.ipAddress) != "10.0.0.2" {
+		t.Errorf("expected IP address 10.0.0.2, got %s", strval(s.ipAddress))
+	}
+	if len(s.staleIPAddressAllocs) != 1 || *s.staleIPAddressAllocs[0].Id != oldID {
+		t.Errorf("expected stale allocation %s, got %v", oldID, s.staleIPAddressAllocs)
+	}
+
+	if err := s.releaseStaleResources(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"allocate 10.0.0.2", "release " + oldID}; !reflect.DeepEqual(access.calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, access.calls)
+	}
+	if len(s.staleIPAddressAllocs) != 0 {
+		t.Errorf("expected no stale allocation, got %v", s.staleIPAddressAllocs)
+	}
+
+	// the allocation already has the requested IP address
+	access.calls = nil
+	if err := s.reallocateResources(); err != nil {
+		t.Fatal(err)
+	}
+	if len(access.calls) != 0 {
+		t.Errorf("expected no calls, got %v", access.calls)
+	}
+}
+
+func TestReallocateResourcesInterrupted(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec:       corev1.ServiceSpec{LoadBalancerIP: "10.0.0.2"},
+	}
+	access := &fakeAccess{}
+	s := newTestState(access, service)
+	oldID, oldIP := "alloc-10.0.0.1", "10.0.0.1"
+	s.ipAddressAlloc = &model.IpAddressAllocation{Id: &oldID, AllocationIp: &oldIP}
+	s.ipAddress = &oldIP
+	if err := s.reallocateResources(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the change is interrupted before the previous allocation is released,
+	// then the service is changed back to its IP address
+	service.Spec.LoadBalancerIP = oldIP
+	s = newTestState(access, service)
+	newID, newIP := "alloc-10.0.0.2", "10.0.0.2"
+	s.ipAddressAlloc = &model.IpAddressAllocation{Id: &newID, AllocationIp: &newIP}
+	s.ipAddress = &newIP
+	s.staleIPAddressAllocs = []*model.IpAddressAllocation{{Id: &oldID, AllocationIp: &oldIP}}
+	access.calls = nil
+	if err := s.reallocateResources(); err != nil {
+		t.Fatal(err)
+	}
+	if len(access.calls) != 0 {
+		t.Errorf("expected no calls, got %v", access.calls)
+	}
+	if strval(s.ipAddress) != oldIP || *s.ipAddressAlloc.Id != oldID {
+		t.Errorf("expected allocation %s of IP address %s, got %s of %s", oldID, oldIP, *s.ipAddressAlloc.Id, strval(s.ipAddress))
+	}
+	if len(s.staleIPAddressAllocs) != 1 || *s.staleIPAddressAllocs[0].Id != newID {
+		t.Errorf("expected stale allocation %s, got %v", newID, s.staleIPAddressAllocs)
+	}
+
+	if err := s.releaseStaleResources(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"release " + newID}; !reflect.DeepEqual(access.calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, access.calls)
+	}
+}
+
+func TestReallocateResourcesInvalidIP(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+		Spec:       corev1.ServiceSpec{LoadBalancerIP: "10.0.0"},
+	}
+	access := &fakeAccess{}
+	s := newTestState(access, service)
+	oldID, oldIP := "alloc-10.0.0.1", "10.0.0.1"
+	s.ipAddressAlloc = &model.IpAddressAllocation{Id: &oldID, AllocationIp: &oldIP}
+	s.ipAddress = &oldIP
+
+	if err := s.reallocateResources(); err == nil {
+		t.Error("expected an error for an invalid load balancer IP")
+	}
+	if len(access.calls) != 0 {
+		t.Errorf("expected no calls, got %v", access.calls)
+	}
+}
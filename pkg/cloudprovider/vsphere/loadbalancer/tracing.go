@@ -0,0 +1,317 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"context"
+
+	"github.com/vmware/vsphere-automation-sdk-go/services/nsxt/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
+)
+
+// tracedAccess records every call of the wrapped NSXTAccess in a span, as a
+// child of the span of the reconciliation it belongs to.
+type tracedAccess struct {
+	access NSXTAccess
+	ctx    context.Context
+}
+
+var _ NSXTAccess = &tracedAccess{}
+
+// withTracing returns an NSXTAccess recording its calls as children of the span of ctx.
+func withTracing(ctx context.Context, access NSXTAccess) NSXTAccess {
+	if traced, ok := access.(*tracedAccess); ok {
+		access = traced.access
+	}
+	return &tracedAccess{access: access, ctx: ctx}
+}
+
+func (a *tracedAccess) start(method string, attributes ...tracing.Attribute) *tracing.Span {
+	_, span := tracing.Start(a.ctx, "NSXTAccess."+method, attributes...)
+	return span
+}
+
+// CreateLoadBalancerService implements NSXTAccess.
+func (a *tracedAccess) CreateLoadBalancerService(clusterName string) (*model.LBService, error) {
+	span := a.start("CreateLoadBalancerService")
+	result, err := a.access.CreateLoadBalancerService(clusterName)
+	span.End(err)
+	return result, err
+}
+
+// FindLoadBalancerService implements NSXTAccess.
+func (a *tracedAccess) FindLoadBalancerService(clusterName string, lbServiceID string) (*model.LBService, error) {
+	span := a.start("FindLoadBalancerService")
+	result, err := a.access.FindLoadBalancerService(clusterName, lbServiceID)
+	span.End(err)
+	return result, err
+}
+
+// UpdateLoadBalancerService implements NSXTAccess.
+func (a *tracedAccess) UpdateLoadBalancerService(lbService *model.LBService) error {
+	span := a.start("UpdateLoadBalancerService")
+	err := a.access.UpdateLoadBalancerService(lbService)
+	span.End(err)
+	return err
+}
+
+// DeleteLoadBalancerService implements NSXTAccess.
+func (a *tracedAccess) DeleteLoadBalancerService(id string) error {
+	span := a.start("DeleteLoadBalancerService", tracing.String("id", id))
+	err := a.access.DeleteLoadBalancerService(id)
+	span.End(err)
+	return err
+}
+
+// CreateVirtualServer implements NSXTAccess.
+func (a *tracedAccess) CreateVirtualServer(clusterName string, objectName types.NamespacedName, class LBClass, ipAddress string, mapping Mapping,
+	lbServicePath, applicationProfilePath string, poolPath *string, accessList *model.LBAccessListControl) (*model.LBVirtualServer, error) {
+	span := a.start("CreateVirtualServer", tracing.String("object", objectName.String()))
+	result, err := a.access.CreateVirtualServer(clusterName, objectName, class, ipAddress, mapping, lbServicePath, applicationProfilePath,
+		poolPath, accessList)
+	span.End(err)
+	return result, err
+}
+
+// FindVirtualServers implements NSXTAccess.
+func (a *tracedAccess) FindVirtualServers(clusterName string, objectName types.NamespacedName) ([]*model.LBVirtualServer, error) {
+	span := a.start("FindVirtualServers", tracing.String("object", objectName.String()))
+	result, err := a.access.FindVirtualServers(clusterName, objectName)
+	span.End(err)
+	return result, err
+}
+
+// ListVirtualServers implements NSXTAccess.
+func (a *tracedAccess) ListVirtualServers(clusterName string) ([]*model.LBVirtualServer, error) {
+	span := a.start("ListVirtualServers")
+	result, err := a.access.ListVirtualServers(clusterName)
+	span.End(err)
+	return result, err
+}
+
+// UpdateVirtualServer implements NSXTAccess.
+func (a *tracedAccess) UpdateVirtualServer(server *model.LBVirtualServer) error {
+	span := a.start("UpdateVirtualServer")
+	err := a.access.UpdateVirtualServer(server)
+	span.End(err)
+	return err
+}
+
+// DeleteVirtualServer implements NSXTAccess.
+func (a *tracedAccess) DeleteVirtualServer(id string) error {
+	span := a.start("DeleteVirtualServer", tracing.String("id", id))
+	err := a.access.DeleteVirtualServer(id)
+	span.End(err)
+	return err
+}
+
+// CreatePool implements NSXTAccess.
+func (a *tracedAccess) CreatePool(clusterName string, objectName types.NamespacedName, mapping Mapping, members []model.LBPoolMember,
+	activeMonitorPaths []string) (*model.LBPool, error) {
+	span := a.start("CreatePool", tracing.String("object", objectName.String()))
+	result, err := a.access.CreatePool(clusterName, objectName, mapping, members, activeMonitorPaths)
+	span.End(err)
+	return result, err
+}
+
+// GetPool implements NSXTAccess.
+func (a *tracedAccess) GetPool(id string) (*model.LBPool, error) {
+	span := a.start("GetPool", tracing.String("id", id))
+	result, err := a.access.GetPool(id)
+	span.End(err)
+	return result, err
+}
+
+// FindPool implements NSXTAccess.
+func (a *tracedAccess) FindPool(clusterName string, objectName types.NamespacedName, mapping Mapping) (*model.LBPool, error) {
+	span := a.start("FindPool", tracing.String("object", objectName.String()))
+	result, err := a.access.FindPool(clusterName, objectName, mapping)
+	span.End(err)
+	return result, err
+}
+
+// FindPools implements NSXTAccess.
+func (a *tracedAccess) FindPools(clusterName string, objectName types.NamespacedName) ([]*model.LBPool, error) {
+	span := a.start("FindPools", tracing.String("object", objectName.String()))
+	result, err := a.access.FindPools(clusterName, objectName)
+	span.End(err)
+	return result, err
+}
+
+// ListPools implements NSXTAccess.
+func (a *tracedAccess) ListPools(clusterName string) ([]*model.LBPool, error) {
+	span := a.start("ListPools")
+	result, err := a.access.ListPools(clusterName)
+	span.End(err)
+	return result, err
+}
+
+// UpdatePool implements NSXTAccess.
+func (a *tracedAccess) UpdatePool(pool *model.LBPool) error {
+	span := a.start("UpdatePool")
+	err := a.access.UpdatePool(pool)
+	span.End(err)
+	return err
+}
+
+// DeletePool implements NSXTAccess.
+func (a *tracedAccess) DeletePool(id string) error {
+	span := a.start("DeletePool", tracing.String("id", id))
+	err := a.access.DeletePool(id)
+	span.End(err)
+	return err
+}
+
+// FindIPPoolByName implements NSXTAccess.
+func (a *tracedAccess) FindIPPoolByName(poolName string) (string, error) {
+	span := a.start("FindIPPoolByName")
+	result, err := a.access.FindIPPoolByName(poolName)
+	span.End(err)
+	return result, err
+}
+
+// GetAppProfilePath implements NSXTAccess.
+func (a *tracedAccess) GetAppProfilePath(class LBClass, protocol corev1.Protocol) (string, error) {
+	span := a.start("GetAppProfilePath")
+	result, err := a.access.GetAppProfilePath(class, protocol)
+	span.End(err)
+	return result, err
+}
+
+// AllocateExternalIPAddress implements NSXTAccess.
+func (a *tracedAccess) AllocateExternalIPAddress(ipPoolID string, clusterName string, objectName types.NamespacedName,
+	requestedIPAddress string) (*model.IpAddressAllocation, *string, error) {
+	span := a.start("AllocateExternalIPAddress", tracing.String("object", objectName.String()))
+	allocation, ipAddress, err := a.access.AllocateExternalIPAddress(ipPoolID, clusterName, objectName, requestedIPAddress)
+	span.End(err)
+	return allocation, ipAddress, err
+}
+
+// ListExternalIPAddresses implements NSXTAccess.
+func (a *tracedAccess) ListExternalIPAddresses(ipPoolID string, clusterName string) ([]*model.IpAddressAllocation, error) {
+	span := a.start("ListExternalIPAddresses")
+	result, err := a.access.ListExternalIPAddresses(ipPoolID, clusterName)
+	span.End(err)
+	return result, err
+}
+
+// FindExternalIPAddressForObject implements NSXTAccess.
+func (a *tracedAccess) FindExternalIPAddressForObject(ipPoolID string, clusterName string, objectName types.NamespacedName) (*model.IpAddressAllocation, *string, []*model.IpAddressAllocation, error) {
+	span := a.start("FindExternalIPAddressForObject", tracing.String("object", objectName.String()))
+	allocation, ipAddress, stale, err := a.access.FindExternalIPAddressForObject(ipPoolID, clusterName, objectName)
+	span.End(err)
+	return allocation, ipAddress, stale, err
+}
+
+// ReleaseExternalIPAddress implements NSXTAccess.
+func (a *tracedAccess) ReleaseExternalIPAddress(ipPoolID string, id string) error {
+	span := a.start("ReleaseExternalIPAddress", tracing.String("id", id))
+	err := a.access.ReleaseExternalIPAddress(ipPoolID, id)
+	span.End(err)
+	return err
+}
+
+// CreateMonitorProfile implements NSXTAccess.
+func (a *tracedAccess) CreateMonitorProfile(clusterName string, objectName types.NamespacedName, mapping Mapping, settings MonitorSettings) (*MonitorProfile, error) {
+	span := a.start("CreateMonitorProfile", tracing.String("object", objectName.String()), tracing.String("type", settings.ResourceType))
+	result, err := a.access.CreateMonitorProfile(clusterName, objectName, mapping, settings)
+	span.End(err)
+	return result, err
+}
+
+// FindMonitorProfiles implements NSXTAccess.
+func (a *tracedAccess) FindMonitorProfiles(clusterName string, objectName types.NamespacedName) ([]*MonitorProfile, error) {
+	span := a.start("FindMonitorProfiles", tracing.String("object", objectName.String()))
+	result, err := a.access.FindMonitorProfiles(clusterName, objectName)
+	span.End(err)
+	return result, err
+}
+
+// ListMonitorProfiles implements NSXTAccess.
+func (a *tracedAccess) ListMonitorProfiles(clusterName string) ([]*MonitorProfile, error) {
+	span := a.start("ListMonitorProfiles")
+	result, err := a.access.ListMonitorProfiles(clusterName)
+	span.End(err)
+	return result, err
+}
+
+// UpdateMonitorProfile implements NSXTAccess.
+func (a *tracedAccess) UpdateMonitorProfile(monitor *MonitorProfile) error {
+	span := a.start("UpdateMonitorProfile")
+	err := a.access.UpdateMonitorProfile(monitor)
+	span.End(err)
+	return err
+}
+
+// DeleteMonitorProfile implements NSXTAccess.
+func (a *tracedAccess) DeleteMonitorProfile(id string) error {
+	span := a.start("DeleteMonitorProfile", tracing.String("id", id))
+	err := a.access.DeleteMonitorProfile(id)
+	span.End(err)
+	return err
+}
+
+// CreateSourceRangesGroup implements NSXTAccess.
+func (a *tracedAccess) CreateSourceRangesGroup(clusterName string, objectName types.NamespacedName, sourceRanges []string) (*model.Group, error) {
+	span := a.start("CreateSourceRangesGroup", tracing.String("object", objectName.String()))
+	result, err := a.access.CreateSourceRangesGroup(clusterName, objectName, sourceRanges)
+	span.End(err)
+	return result, err
+}
+
+// FindSourceRangesGroups implements NSXTAccess.
+func (a *tracedAccess) FindSourceRangesGroups(clusterName string, objectName types.NamespacedName) ([]*model.Group, error) {
+	span := a.start("FindSourceRangesGroups", tracing.String("object", objectName.String()))
+	result, err := a.access.FindSourceRangesGroups(clusterName, objectName)
+	span.End(err)
+	return result, err
+}
+
+// ListSourceRangesGroups implements NSXTAccess.
+func (a *tracedAccess) ListSourceRangesGroups(clusterName string) ([]*model.Group, error) {
+	span := a.start("ListSourceRangesGroups")
+	result, err := a.access.ListSourceRangesGroups(clusterName)
+	span.End(err)
+	return result, err
+}
+
+// UpdateSourceRangesGroup implements NSXTAccess.
+func (a *tracedAccess) UpdateSourceRangesGroup(group *model.Group, sourceRanges []string) error {
+	span := a.start("UpdateSourceRangesGroup", tracing.String("id", strval(group.Id)))
+	err := a.access.UpdateSourceRangesGroup(group, sourceRanges)
+	span.End(err)
+	return err
+}
+
+// DeleteSourceRangesGroup implements NSXTAccess.
+func (a *tracedAccess) DeleteSourceRangesGroup(id string) error {
+	span := a.start("DeleteSourceRangesGroup", tracing.String("id", id))
+	err := a.access.DeleteSourceRangesGroup(id)
+	span.End(err)
+	return err
+}
+
+// GetRealizedState implements NSXTAccess.
+func (a *tracedAccess) GetRealizedState(path string) (string, error) {
+	span := a.start("GetRealizedState", tracing.String("path", path))
+	result, err := a.access.GetRealizedState(path)
+	span.End(err)
+	return result, err
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const metricsSubsystem = "cloudprovider_vsphere"
+
+// Values of the cache label of the node cache metric
+const (
+	nodeCacheByName = "name"
+	nodeCacheByUUID = "uuid"
+)
+
+var (
+	nodeDiscoveryDurationMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_discovery_duration_seconds",
+			Help:           "Latency of discovering the VM of a node in vCenter by search type and result",
+			Buckets:        metrics.ExponentialBuckets(0.01, 2, 14),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"search", "result"},
+	)
+
+	zoneLookupDurationMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "zone_lookup_duration_seconds",
+			Help:           "Latency of looking up the zone and region of a node by lookup type and result",
+			Buckets:        metrics.ExponentialBuckets(0.01, 2, 14),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"by", "result"},
+	)
+
+	nodeCacheLookupsMetric = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_cache_lookups_total",
+			Help:           "Number of lookups of discovered nodes in the node cache by key and result, hit or miss",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"cache", "result"},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// registerMetrics registers the node metrics with the global registry used by
+// the cloud controller manager.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(nodeDiscoveryDurationMetric)
+		legacyregistry.MustRegister(zoneLookupDurationMetric)
+		legacyregistry.MustRegister(nodeCacheLookupsMetric)
+	})
+}
+
+// searchType returns the search label of the node discovery metric.
+func searchType(searchBy cm.FindVM) string {
+	switch searchBy {
+	case cm.FindVMByName:
+		return "name"
+	case cm.FindVMByIP:
+		return "ip"
+	}
+	return "uuid"
+}
+
+// recordNodeDiscovery records a node discovery which started at start and
+// failed with err, if not nil.
+func recordNodeDiscovery(searchBy cm.FindVM, start time.Time, err error) {
+	result := "found"
+	if errors.Is(err, vclib.ErrNoVMFound) {
+		result = "not_found"
+	} else if err != nil {
+		result = "error"
+	}
+	nodeDiscoveryDurationMetric.WithLabelValues(searchType(searchBy), result).Observe(time.Since(start).Seconds())
+}
+
+// recordZoneLookup records a zone lookup by the given key which started at
+// start and failed with err, if not nil.
+func recordZoneLookup(by string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	zoneLookupDurationMetric.WithLabelValues(by, result).Observe(time.Since(start).Seconds())
+}
+
+// recordNodeCacheLookup records a lookup in the node cache by the given key.
+func recordNodeCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	nodeCacheLookupsMetric.WithLabelValues(cache, result).Inc()
+}
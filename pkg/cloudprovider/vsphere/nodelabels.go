@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	klog "k8s.io/klog/v2"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
+)
+
+// LabelNode sets the labels of the tag categories of the Labels section, or of
+// the vCenter of the node if it overrides them, to the tags of the host of the
+// node's VM, the VM or their ancestors. The cloud-provider node controller
+// only sets the zone and region labels, so the node manager sets these.
+func (nm *NodeManager) LabelNode(ctx context.Context, node *v1.Node) error {
+	if nm.client == nil {
+		return nil
+	}
+	if cfg := nm.config(); cfg != nil && cfg.Global.OperationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Global.OperationTimeout)*time.Second)
+		defer cancel()
+	}
+	ctx, span := tracing.Start(ctx, "NodeManager.LabelNode", tracing.String("node", node.Name))
+	err := nm.labelNode(ctx, node)
+	span.End(err)
+	return err
+}
+
+// labelNode implements LabelNode.
+func (nm *NodeManager) labelNode(ctx context.Context, node *v1.Node) error {
+	uuid := ConvertK8sUUIDtoNormal(node.Status.NodeInfo.SystemUUID)
+	nodeInfo, ok := nm.cachedNodeByUUID(uuid)
+	if !ok {
+		return ErrVMNotFound
+	}
+	vsi := nm.connectionManager.Instance(nodeInfo.tenantRef)
+	if vsi == nil {
+		return ErrVCenterNotFound
+	}
+	var defaults []string
+	if cfg := nm.config(); cfg != nil {
+		defaults = cfg.Labels.Categories
+	}
+	categories := vsi.Cfg.LabelCategories(defaults)
+	if len(categories) == 0 {
+		return nil
+	}
+
+	// like the zones, the tags of the host are preferred over the ones of the
+	// folders of the VM
+	host, err := nodeInfo.vm.HostSystem(ctx)
+	if err != nil {
+		return err
+	}
+	tags, err := nm.connectionManager.LookupTagsByMoref(ctx, nodeInfo.tenantRef, host.Reference(), categories)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, category := range categories {
+		if _, ok := tags[category]; !ok {
+			missing = append(missing, category)
+		}
+	}
+	if len(missing) > 0 {
+		vmTags, err := nm.connectionManager.LookupTagsByMoref(ctx, nodeInfo.tenantRef, nodeInfo.vm.Reference(), missing)
+		if err != nil {
+			return err
+		}
+		for category, tag := range vmTags {
+			tags[category] = tag
+		}
+	}
+
+	patch, err := tagLabelsPatch(node.Labels, categories, tags)
+	if err != nil || patch == nil {
+		return err
+	}
+	klog.V(2).Infof("Setting the tag labels of node %s: %s", node.Name, patch)
+	_, err = nm.client.CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// tagLabelsPatch returns the merge patch setting the labels of the categories
+// to their tags, and removing the labels of the categories without a tag, or
+// nil if the labels are up to date. A tag which is not a valid label value is
+// treated as missing.
+func tagLabelsPatch(labels map[string]string, categories []string, tags map[string]string) ([]byte, error) {
+	changed := make(map[string]*string)
+	for _, category := range categories {
+		key := vcfg.TagLabelPrefix + category
+		current, labeled := labels[key]
+		tag, tagged := tags[category]
+		if tagged {
+			if errs := validation.IsValidLabelValue(tag); len(errs) > 0 {
+				klog.Warningf("Tag %q of category %s is not a valid label value: %s", tag, category, strings.Join(errs, ", "))
+				tagged = false
+			}
+		}
+		switch {
+		case tagged && (!labeled || current != tag):
+			value := tag
+			changed[key] = &value
+		case !tagged && labeled:
+			changed[key] = nil
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": changed},
+	})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
+)
+
+func TestTagLabelsPatch(t *testing.T) {
+	team := vcfg.TagLabelPrefix + "team"
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		tags     map[string]string
+		expected string
+	}{
+		{name: "added", tags: map[string]string{"team": "payments"},
+			expected: `{"metadata":{"labels":{"tags.vsphere.cloudprovider.k8s.io/team":"payments"}}}`},
+		{name: "unchanged", labels: map[string]string{team: "payments"}, tags: map[string]string{"team": "payments"}},
+		{name: "changed", labels: map[string]string{team: "payments"}, tags: map[string]string{"team": "platform"},
+			expected: `{"metadata":{"labels":{"tags.vsphere.cloudprovider.k8s.io/team":"platform"}}}`},
+		{name: "removed", labels: map[string]string{team: "payments"},
+			expected: `{"metadata":{"labels":{"tags.vsphere.cloudprovider.k8s.io/team":null}}}`},
+		{name: "invalid value", labels: map[string]string{team: "payments"}, tags: map[string]string{"team": "Payments & Billing"},
+			expected: `{"metadata":{"labels":{"tags.vsphere.cloudprovider.k8s.io/team":null}}}`},
+		{name: "not configured", tags: map[string]string{"other": "value"}},
+	}
+	for _, test := range tests {
+		patch, err := tagLabelsPatch(test.labels, []string{"team"}, test.tags)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if string(patch) != test.expected {
+			t.Errorf("%s: expected patch %s, got %s", test.name, test.expected, patch)
+		}
+	}
+}
+
+func TestLabelNode(t *testing.T) {
+	cfg, ok := configFromEnvOrSim(false)
+	defer ok()
+	ctx := context.Background()
+
+	connMgr := cm.NewConnectionManager(cfg, nil, nil)
+	defer connMgr.Logout()
+	cpiConfig := &ccfg.CPIConfig{Config: *cfg}
+	cpiConfig.Labels.Categories = []string{"team", "cost-center"}
+	nm := newNodeManager(cpiConfig, connMgr)
+
+	vm := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+	host := simulator.Map.Get(*vm.Runtime.Host).(*simulator.HostSystem)
+	vm.Guest.HostName = vm.Name
+	vm.Guest.Net = []vimtypes.GuestNicInfo{{Network: "foo-bar", IpAddress: []string{"10.0.0.1"}}}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   vm.Name,
+			Labels: map[string]string{vcfg.TagLabelPrefix + "cost-center": "stale"},
+		},
+		Status: v1.NodeStatus{
+			NodeInfo: v1.NodeSystemInfo{
+				SystemUUID: ConvertK8sUUIDtoNormal(vm.Config.Uuid),
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(node)
+	nm.client = client
+
+	vsi := connMgr.Instance(cfg.Global.VCenterIP)
+	if err := connMgr.Connect(ctx, vsi); err != nil {
+		t.Fatal(err)
+	}
+	restClient := rest.NewClient(vsi.Conn.Client)
+	if err := restClient.Login(ctx, url.UserPassword(vsi.Conn.Username, vsi.Conn.Password)); err != nil {
+		t.Fatalf("Rest login failed. err=%v", err)
+	}
+	m := tags.NewManager(restClient)
+	attach := func(categoryName string, tagName string, ref mo.Reference) {
+		categoryID, err := m.CreateCategory(ctx, &tags.Category{Name: categoryName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tagID, err := m.CreateTag(ctx, &tags.Tag{CategoryID: categoryID, Name: tagName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.AttachTag(ctx, tagID, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	attach("team", "payments", host)
+	attach("owner", "alice", vm)
+
+	// the labels are set as the node is registered
+	nm.RegisterNode(node)
+	labeled, err := client.CoreV1().Nodes().Get(ctx, node.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{vcfg.TagLabelPrefix + "team": "payments"}
+	if !reflect.DeepEqual(labeled.Labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labeled.Labels)
+	}
+
+	// the vCenter overrides the categories of the Labels section
+	vsi.Cfg.Labels.Categories = []string{"owner"}
+	if err := nm.LabelNode(ctx, labeled); err != nil {
+		t.Fatal(err)
+	}
+	labeled, err = client.CoreV1().Nodes().Get(ctx, node.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected[vcfg.TagLabelPrefix+"owner"] = "alice"
+	if !reflect.DeepEqual(labeled.Labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labeled.Labels)
+	}
+}
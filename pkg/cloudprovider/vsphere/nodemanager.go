@@ -22,12 +22,14 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
 	pb "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/proto"
 	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 	v1helper "k8s.io/cloud-provider/node/helpers"
 	klog "k8s.io/klog/v2"
@@ -50,6 +52,7 @@ var (
 )
 
 func newNodeManager(cfg *ccfg.CPIConfig, cm *cm.ConnectionManager) *NodeManager {
+	registerMetrics()
 	return &NodeManager{
 		nodeNameMap:       make(map[string]*NodeInfo),
 		nodeUUIDMap:       make(map[string]*NodeInfo),
@@ -60,17 +63,34 @@ func newNodeManager(cfg *ccfg.CPIConfig, cm *cm.ConnectionManager) *NodeManager
 	}
 }
 
+// config returns the current CPI configuration.
+func (nm *NodeManager) config() *ccfg.CPIConfig {
+	nm.cfgLock.RLock()
+	defer nm.cfgLock.RUnlock()
+	return nm.cfg
+}
+
+// setConfig replaces the CPI configuration after it has been reloaded.
+func (nm *NodeManager) setConfig(cfg *ccfg.CPIConfig) {
+	nm.cfgLock.Lock()
+	defer nm.cfgLock.Unlock()
+	nm.cfg = cfg
+}
+
 // RegisterNode is the handler for when a node is added to a K8s cluster.
 func (nm *NodeManager) RegisterNode(node *v1.Node) {
 	klog.V(4).Info("RegisterNode ENTER: ", node.Name)
 
 	uuid := ConvertK8sUUIDtoNormal(node.Status.NodeInfo.SystemUUID)
-	if err := nm.DiscoverNode(uuid, cm.FindVMByUUID); err != nil {
+	if err := nm.DiscoverNode(context.Background(), uuid, cm.FindVMByUUID); err != nil {
 		klog.Errorf("error discovering node %s: %v", node.Name, err)
 		return
 	}
 
 	nm.addNode(uuid, node)
+	if err := nm.LabelNode(context.Background(), node); err != nil {
+		klog.Errorf("error labeling node %s: %v", node.Name, err)
+	}
 	klog.V(4).Info("RegisterNode LEAVE: ", node.Name)
 }
 
@@ -82,6 +102,24 @@ func (nm *NodeManager) UnregisterNode(node *v1.Node) {
 	klog.V(4).Info("UnregisterNode LEAVE: ", node.Name)
 }
 
+// cachedNodeByName returns the discovered node with the given name, if any.
+func (nm *NodeManager) cachedNodeByName(name string) (*NodeInfo, bool) {
+	nm.nodeInfoLock.RLock()
+	node, ok := nm.nodeNameMap[name]
+	nm.nodeInfoLock.RUnlock()
+	recordNodeCacheLookup(nodeCacheByName, ok)
+	return node, ok
+}
+
+// cachedNodeByUUID returns the discovered node with the given UUID, if any.
+func (nm *NodeManager) cachedNodeByUUID(uuid string) (*NodeInfo, bool) {
+	nm.nodeInfoLock.RLock()
+	node, ok := nm.nodeUUIDMap[uuid]
+	nm.nodeInfoLock.RUnlock()
+	recordNodeCacheLookup(nodeCacheByUUID, ok)
+	return node, ok
+}
+
 func (nm *NodeManager) addNodeInfo(node *NodeInfo) {
 	nm.nodeInfoLock.Lock()
 	klog.V(4).Info("addNodeInfo NodeName: ", node.NodeName, ", UUID: ", node.UUID)
@@ -173,10 +211,25 @@ func returnIPsFromSpecificFamily(family string, ips []string) []string {
 }
 
 // DiscoverNode finds a node's VM using the specified search value and search
-// type.
-func (nm *NodeManager) DiscoverNode(nodeID string, searchBy cm.FindVM) error {
-	ctx := context.Background()
+// type. The discovery is bound by the configured discovery timeout, and its
+// latency and result are recorded in the node discovery metric.
+func (nm *NodeManager) DiscoverNode(ctx context.Context, nodeID string, searchBy cm.FindVM) error {
+	if cfg := nm.config(); cfg != nil && cfg.Global.DiscoveryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Global.DiscoveryTimeout)*time.Second)
+		defer cancel()
+	}
+	ctx, span := tracing.Start(ctx, "NodeManager.DiscoverNode",
+		tracing.String("node", nodeID), tracing.String("search", searchType(searchBy)))
+	start := time.Now()
+	err := nm.discoverNode(ctx, nodeID, searchBy)
+	recordNodeDiscovery(searchBy, start, err)
+	span.End(err)
+	return err
+}
 
+// discoverNode implements DiscoverNode.
+func (nm *NodeManager) discoverNode(ctx context.Context, nodeID string, searchBy cm.FindVM) error {
 	vmDI, err := nm.shakeOutNodeIDLookup(ctx, nodeID, searchBy)
 	if err != nil {
 		klog.Errorf("shakeOutNodeIDLookup failed. Err=%v", err)
@@ -203,7 +256,7 @@ func (nm *NodeManager) DiscoverNode(nodeID string, searchBy cm.FindVM) error {
 	if vmDI.TenantRef != "" {
 		tenantRef = vmDI.TenantRef
 	}
-	vcInstance := nm.connectionManager.VsphereInstanceMap[tenantRef]
+	vcInstance := nm.connectionManager.Instance(tenantRef)
 
 	ipFamily := []string{vcfg.DefaultIPFamily}
 	if vcInstance != nil {
@@ -217,21 +270,21 @@ func (nm *NodeManager) DiscoverNode(nodeID string, searchBy cm.FindVM) error {
 	var internalVMNetworkName string
 	var externalVMNetworkName string
 
-	if nm.cfg != nil {
-		if nm.cfg.Nodes.InternalNetworkSubnetCIDR != "" {
-			_, internalNetworkSubnet, err = net.ParseCIDR(nm.cfg.Nodes.InternalNetworkSubnetCIDR)
+	if cfg := nm.config(); cfg != nil {
+		if cfg.Nodes.InternalNetworkSubnetCIDR != "" {
+			_, internalNetworkSubnet, err = net.ParseCIDR(cfg.Nodes.InternalNetworkSubnetCIDR)
 			if err != nil {
 				return err
 			}
 		}
-		if nm.cfg.Nodes.ExternalNetworkSubnetCIDR != "" {
-			_, externalNetworkSubnet, err = net.ParseCIDR(nm.cfg.Nodes.ExternalNetworkSubnetCIDR)
+		if cfg.Nodes.ExternalNetworkSubnetCIDR != "" {
+			_, externalNetworkSubnet, err = net.ParseCIDR(cfg.Nodes.ExternalNetworkSubnetCIDR)
 			if err != nil {
 				return err
 			}
 		}
-		internalVMNetworkName = nm.cfg.Nodes.InternalVMNetworkName
-		externalVMNetworkName = nm.cfg.Nodes.ExternalVMNetworkName
+		internalVMNetworkName = cfg.Nodes.InternalVMNetworkName
+		externalVMNetworkName = cfg.Nodes.ExternalVMNetworkName
 	}
 
 	foundInternal := false
@@ -30,6 +30,8 @@ import (
 	pb "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/proto"
 	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+	"k8s.io/component-base/metrics/testutil"
 )
 
 func TestRegUnregNode(t *testing.T) {
@@ -114,7 +116,7 @@ func TestDiscoverNodeByName(t *testing.T) {
 		t.Errorf("Failed to Connect to vSphere: %s", err)
 	}
 
-	err = nm.DiscoverNode(name, cm.FindVMByName)
+	err = nm.DiscoverNode(context.Background(), name, cm.FindVMByName)
 	if err != nil {
 		t.Errorf("Failed DiscoverNode: %s", err)
 	}
@@ -127,6 +129,77 @@ func TestDiscoverNodeByName(t *testing.T) {
 	}
 }
 
+func TestDiscoverNodeCancelled(t *testing.T) {
+	cfg, ok := configFromEnvOrSim(true)
+	defer ok()
+
+	connMgr := cm.NewConnectionManager(cfg, nil, nil)
+	defer connMgr.Logout()
+
+	nm := newNodeManager(nil, connMgr)
+
+	vm := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+	vm.Guest.HostName = strings.ToLower(vm.Name)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := nm.DiscoverNode(ctx, vm.Name, cm.FindVMByName); err != context.Canceled {
+		t.Fatalf("DiscoverNode should stop once its context is cancelled: %v", err)
+	}
+	if len(nm.nodeNameMap) != 0 {
+		t.Errorf("Failed: nodeNameMap should be empty")
+	}
+}
+
+func TestNodeMetrics(t *testing.T) {
+	cfg, ok := configFromEnvOrSim(true)
+	defer ok()
+
+	connMgr := cm.NewConnectionManager(cfg, nil, nil)
+	defer connMgr.Logout()
+
+	nm := newNodeManager(nil, connMgr)
+
+	vm := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+	vm.Guest.HostName = strings.ToLower(vm.Name)
+	vm.Guest.Net = []vimtypes.GuestNicInfo{
+		{
+			Network:   "foo-bar",
+			IpAddress: []string{"10.0.0.1"},
+		},
+	}
+	name := vm.Name
+
+	hits, _ := testutil.GetCounterMetricValue(nodeCacheLookupsMetric.WithLabelValues(nodeCacheByName, "hit"))
+	misses, _ := testutil.GetCounterMetricValue(nodeCacheLookupsMetric.WithLabelValues(nodeCacheByName, "miss"))
+
+	if _, ok := nm.cachedNodeByName(name); ok {
+		t.Fatal("node should not be cached before it is discovered")
+	}
+	if err := nm.DiscoverNode(context.Background(), name, cm.FindVMByName); err != nil {
+		t.Fatalf("Failed DiscoverNode: %s", err)
+	}
+	if _, ok := nm.cachedNodeByName(vm.Guest.HostName); !ok {
+		t.Fatal("node should be cached by its hostname once it is discovered")
+	}
+	if err := nm.DiscoverNode(context.Background(), "missing-node", cm.FindVMByName); err != vclib.ErrNoVMFound {
+		t.Fatalf("DiscoverNode should not find a missing node: %v", err)
+	}
+
+	if v, _ := testutil.GetCounterMetricValue(nodeCacheLookupsMetric.WithLabelValues(nodeCacheByName, "hit")); v != hits+1 {
+		t.Errorf("cache hit should be counted, got %v", v-hits)
+	}
+	if v, _ := testutil.GetCounterMetricValue(nodeCacheLookupsMetric.WithLabelValues(nodeCacheByName, "miss")); v != misses+1 {
+		t.Errorf("cache miss should be counted, got %v", v-misses)
+	}
+	for _, result := range []string{"found", "not_found"} {
+		if v, _ := testutil.GetHistogramMetricValue(nodeDiscoveryDurationMetric.WithLabelValues("name", result)); v <= 0 {
+			t.Errorf("latency of the node discovery with result %s should be recorded", result)
+		}
+	}
+}
+
 func TestExport(t *testing.T) {
 	cfg, ok := configFromEnvOrSim(true)
 	defer ok()
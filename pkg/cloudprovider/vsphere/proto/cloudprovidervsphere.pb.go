@@ -352,6 +352,801 @@ func (m *VersionReply) GetVersion() string {
 	return ""
 }
 
+type VirtualServer struct {
+	Id                     string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Path                   string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	RealizedState          string   `protobuf:"bytes,3,opt,name=realized_state,json=realizedState,proto3" json:"realized_state,omitempty"`
+	IpAddress              string   `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Ports                  []string `protobuf:"bytes,5,rep,name=ports,proto3" json:"ports,omitempty"`
+	DefaultPoolMemberPorts []string `protobuf:"bytes,6,rep,name=default_pool_member_ports,json=defaultPoolMemberPorts,proto3" json:"default_pool_member_ports,omitempty"`
+	PoolPath               string   `protobuf:"bytes,7,opt,name=pool_path,json=poolPath,proto3" json:"pool_path,omitempty"`
+	ApplicationProfilePath string   `protobuf:"bytes,8,opt,name=application_profile_path,json=applicationProfilePath,proto3" json:"application_profile_path,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{} `json:"-"`
+	XXX_unrecognized       []byte   `json:"-"`
+	XXX_sizecache          int32    `json:"-"`
+}
+
+func (m *VirtualServer) Reset()         { *m = VirtualServer{} }
+func (m *VirtualServer) String() string { return proto.CompactTextString(m) }
+func (*VirtualServer) ProtoMessage()    {}
+func (*VirtualServer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{7}
+}
+
+func (m *VirtualServer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VirtualServer.Unmarshal(m, b)
+}
+func (m *VirtualServer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VirtualServer.Marshal(b, m, deterministic)
+}
+func (m *VirtualServer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VirtualServer.Merge(m, src)
+}
+func (m *VirtualServer) XXX_Size() int {
+	return xxx_messageInfo_VirtualServer.Size(m)
+}
+func (m *VirtualServer) XXX_DiscardUnknown() {
+	xxx_messageInfo_VirtualServer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VirtualServer proto.InternalMessageInfo
+
+func (m *VirtualServer) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *VirtualServer) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *VirtualServer) GetRealizedState() string {
+	if m != nil {
+		return m.RealizedState
+	}
+	return ""
+}
+
+func (m *VirtualServer) GetIpAddress() string {
+	if m != nil {
+		return m.IpAddress
+	}
+	return ""
+}
+
+func (m *VirtualServer) GetPorts() []string {
+	if m != nil {
+		return m.Ports
+	}
+	return nil
+}
+
+func (m *VirtualServer) GetDefaultPoolMemberPorts() []string {
+	if m != nil {
+		return m.DefaultPoolMemberPorts
+	}
+	return nil
+}
+
+func (m *VirtualServer) GetPoolPath() string {
+	if m != nil {
+		return m.PoolPath
+	}
+	return ""
+}
+
+func (m *VirtualServer) GetApplicationProfilePath() string {
+	if m != nil {
+		return m.ApplicationProfilePath
+	}
+	return ""
+}
+
+type PoolMember struct {
+	DisplayName          string   `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	IpAddress            string   `protobuf:"bytes,2,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	AdminState           string   `protobuf:"bytes,3,opt,name=admin_state,json=adminState,proto3" json:"admin_state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PoolMember) Reset()         { *m = PoolMember{} }
+func (m *PoolMember) String() string { return proto.CompactTextString(m) }
+func (*PoolMember) ProtoMessage()    {}
+func (*PoolMember) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{8}
+}
+
+func (m *PoolMember) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PoolMember.Unmarshal(m, b)
+}
+func (m *PoolMember) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PoolMember.Marshal(b, m, deterministic)
+}
+func (m *PoolMember) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PoolMember.Merge(m, src)
+}
+func (m *PoolMember) XXX_Size() int {
+	return xxx_messageInfo_PoolMember.Size(m)
+}
+func (m *PoolMember) XXX_DiscardUnknown() {
+	xxx_messageInfo_PoolMember.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PoolMember proto.InternalMessageInfo
+
+func (m *PoolMember) GetDisplayName() string {
+	if m != nil {
+		return m.DisplayName
+	}
+	return ""
+}
+
+func (m *PoolMember) GetIpAddress() string {
+	if m != nil {
+		return m.IpAddress
+	}
+	return ""
+}
+
+func (m *PoolMember) GetAdminState() string {
+	if m != nil {
+		return m.AdminState
+	}
+	return ""
+}
+
+type Pool struct {
+	Id                   string        `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Path                 string        `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	RealizedState        string        `protobuf:"bytes,3,opt,name=realized_state,json=realizedState,proto3" json:"realized_state,omitempty"`
+	Members              []*PoolMember `protobuf:"bytes,4,rep,name=members,proto3" json:"members,omitempty"`
+	ActiveMonitorPaths   []string      `protobuf:"bytes,5,rep,name=active_monitor_paths,json=activeMonitorPaths,proto3" json:"active_monitor_paths,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Pool) Reset()         { *m = Pool{} }
+func (m *Pool) String() string { return proto.CompactTextString(m) }
+func (*Pool) ProtoMessage()    {}
+func (*Pool) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{9}
+}
+
+func (m *Pool) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Pool.Unmarshal(m, b)
+}
+func (m *Pool) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Pool.Marshal(b, m, deterministic)
+}
+func (m *Pool) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Pool.Merge(m, src)
+}
+func (m *Pool) XXX_Size() int {
+	return xxx_messageInfo_Pool.Size(m)
+}
+func (m *Pool) XXX_DiscardUnknown() {
+	xxx_messageInfo_Pool.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Pool proto.InternalMessageInfo
+
+func (m *Pool) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Pool) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Pool) GetRealizedState() string {
+	if m != nil {
+		return m.RealizedState
+	}
+	return ""
+}
+
+func (m *Pool) GetMembers() []*PoolMember {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func (m *Pool) GetActiveMonitorPaths() []string {
+	if m != nil {
+		return m.ActiveMonitorPaths
+	}
+	return nil
+}
+
+type Monitor struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	RealizedState        string   `protobuf:"bytes,3,opt,name=realized_state,json=realizedState,proto3" json:"realized_state,omitempty"`
+	ResourceType         string   `protobuf:"bytes,4,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	Port                 int64    `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Monitor) Reset()         { *m = Monitor{} }
+func (m *Monitor) String() string { return proto.CompactTextString(m) }
+func (*Monitor) ProtoMessage()    {}
+func (*Monitor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{10}
+}
+
+func (m *Monitor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Monitor.Unmarshal(m, b)
+}
+func (m *Monitor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Monitor.Marshal(b, m, deterministic)
+}
+func (m *Monitor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Monitor.Merge(m, src)
+}
+func (m *Monitor) XXX_Size() int {
+	return xxx_messageInfo_Monitor.Size(m)
+}
+func (m *Monitor) XXX_DiscardUnknown() {
+	xxx_messageInfo_Monitor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Monitor proto.InternalMessageInfo
+
+func (m *Monitor) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Monitor) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Monitor) GetRealizedState() string {
+	if m != nil {
+		return m.RealizedState
+	}
+	return ""
+}
+
+func (m *Monitor) GetResourceType() string {
+	if m != nil {
+		return m.ResourceType
+	}
+	return ""
+}
+
+func (m *Monitor) GetPort() int64 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+type IPAllocation struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Path                 string   `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	RealizedState        string   `protobuf:"bytes,3,opt,name=realized_state,json=realizedState,proto3" json:"realized_state,omitempty"`
+	IpPoolId             string   `protobuf:"bytes,4,opt,name=ip_pool_id,json=ipPoolId,proto3" json:"ip_pool_id,omitempty"`
+	IpAddress            string   `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *IPAllocation) Reset()         { *m = IPAllocation{} }
+func (m *IPAllocation) String() string { return proto.CompactTextString(m) }
+func (*IPAllocation) ProtoMessage()    {}
+func (*IPAllocation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{11}
+}
+
+func (m *IPAllocation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IPAllocation.Unmarshal(m, b)
+}
+func (m *IPAllocation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IPAllocation.Marshal(b, m, deterministic)
+}
+func (m *IPAllocation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IPAllocation.Merge(m, src)
+}
+func (m *IPAllocation) XXX_Size() int {
+	return xxx_messageInfo_IPAllocation.Size(m)
+}
+func (m *IPAllocation) XXX_DiscardUnknown() {
+	xxx_messageInfo_IPAllocation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IPAllocation proto.InternalMessageInfo
+
+func (m *IPAllocation) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *IPAllocation) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *IPAllocation) GetRealizedState() string {
+	if m != nil {
+		return m.RealizedState
+	}
+	return ""
+}
+
+func (m *IPAllocation) GetIpPoolId() string {
+	if m != nil {
+		return m.IpPoolId
+	}
+	return ""
+}
+
+func (m *IPAllocation) GetIpAddress() string {
+	if m != nil {
+		return m.IpAddress
+	}
+	return ""
+}
+
+type LoadBalancer struct {
+	Namespace            string           `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string           `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Class                string           `protobuf:"bytes,3,opt,name=class,proto3" json:"class,omitempty"`
+	IpAddress            string           `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	VirtualServers       []*VirtualServer `protobuf:"bytes,5,rep,name=virtual_servers,json=virtualServers,proto3" json:"virtual_servers,omitempty"`
+	Pools                []*Pool          `protobuf:"bytes,6,rep,name=pools,proto3" json:"pools,omitempty"`
+	Monitors             []*Monitor       `protobuf:"bytes,7,rep,name=monitors,proto3" json:"monitors,omitempty"`
+	IpAllocation         *IPAllocation    `protobuf:"bytes,8,opt,name=ip_allocation,json=ipAllocation,proto3" json:"ip_allocation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *LoadBalancer) Reset()         { *m = LoadBalancer{} }
+func (m *LoadBalancer) String() string { return proto.CompactTextString(m) }
+func (*LoadBalancer) ProtoMessage()    {}
+func (*LoadBalancer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{12}
+}
+
+func (m *LoadBalancer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoadBalancer.Unmarshal(m, b)
+}
+func (m *LoadBalancer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoadBalancer.Marshal(b, m, deterministic)
+}
+func (m *LoadBalancer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoadBalancer.Merge(m, src)
+}
+func (m *LoadBalancer) XXX_Size() int {
+	return xxx_messageInfo_LoadBalancer.Size(m)
+}
+func (m *LoadBalancer) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoadBalancer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoadBalancer proto.InternalMessageInfo
+
+func (m *LoadBalancer) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *LoadBalancer) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *LoadBalancer) GetClass() string {
+	if m != nil {
+		return m.Class
+	}
+	return ""
+}
+
+func (m *LoadBalancer) GetIpAddress() string {
+	if m != nil {
+		return m.IpAddress
+	}
+	return ""
+}
+
+func (m *LoadBalancer) GetVirtualServers() []*VirtualServer {
+	if m != nil {
+		return m.VirtualServers
+	}
+	return nil
+}
+
+func (m *LoadBalancer) GetPools() []*Pool {
+	if m != nil {
+		return m.Pools
+	}
+	return nil
+}
+
+func (m *LoadBalancer) GetMonitors() []*Monitor {
+	if m != nil {
+		return m.Monitors
+	}
+	return nil
+}
+
+func (m *LoadBalancer) GetIpAllocation() *IPAllocation {
+	if m != nil {
+		return m.IpAllocation
+	}
+	return nil
+}
+
+type GetLoadBalancerRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLoadBalancerRequest) Reset()         { *m = GetLoadBalancerRequest{} }
+func (m *GetLoadBalancerRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLoadBalancerRequest) ProtoMessage()    {}
+func (*GetLoadBalancerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{13}
+}
+
+func (m *GetLoadBalancerRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLoadBalancerRequest.Unmarshal(m, b)
+}
+func (m *GetLoadBalancerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLoadBalancerRequest.Marshal(b, m, deterministic)
+}
+func (m *GetLoadBalancerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLoadBalancerRequest.Merge(m, src)
+}
+func (m *GetLoadBalancerRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLoadBalancerRequest.Size(m)
+}
+func (m *GetLoadBalancerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLoadBalancerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLoadBalancerRequest proto.InternalMessageInfo
+
+func (m *GetLoadBalancerRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *GetLoadBalancerRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type GetLoadBalancerReply struct {
+	LoadBalancer         *LoadBalancer `protobuf:"bytes,1,opt,name=load_balancer,json=loadBalancer,proto3" json:"load_balancer,omitempty"`
+	Error                string        `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *GetLoadBalancerReply) Reset()         { *m = GetLoadBalancerReply{} }
+func (m *GetLoadBalancerReply) String() string { return proto.CompactTextString(m) }
+func (*GetLoadBalancerReply) ProtoMessage()    {}
+func (*GetLoadBalancerReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{14}
+}
+
+func (m *GetLoadBalancerReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLoadBalancerReply.Unmarshal(m, b)
+}
+func (m *GetLoadBalancerReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLoadBalancerReply.Marshal(b, m, deterministic)
+}
+func (m *GetLoadBalancerReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLoadBalancerReply.Merge(m, src)
+}
+func (m *GetLoadBalancerReply) XXX_Size() int {
+	return xxx_messageInfo_GetLoadBalancerReply.Size(m)
+}
+func (m *GetLoadBalancerReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLoadBalancerReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLoadBalancerReply proto.InternalMessageInfo
+
+func (m *GetLoadBalancerReply) GetLoadBalancer() *LoadBalancer {
+	if m != nil {
+		return m.LoadBalancer
+	}
+	return nil
+}
+
+func (m *GetLoadBalancerReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ListLoadBalancersRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLoadBalancersRequest) Reset()         { *m = ListLoadBalancersRequest{} }
+func (m *ListLoadBalancersRequest) String() string { return proto.CompactTextString(m) }
+func (*ListLoadBalancersRequest) ProtoMessage()    {}
+func (*ListLoadBalancersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{15}
+}
+
+func (m *ListLoadBalancersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLoadBalancersRequest.Unmarshal(m, b)
+}
+func (m *ListLoadBalancersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLoadBalancersRequest.Marshal(b, m, deterministic)
+}
+func (m *ListLoadBalancersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLoadBalancersRequest.Merge(m, src)
+}
+func (m *ListLoadBalancersRequest) XXX_Size() int {
+	return xxx_messageInfo_ListLoadBalancersRequest.Size(m)
+}
+func (m *ListLoadBalancersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLoadBalancersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLoadBalancersRequest proto.InternalMessageInfo
+
+func (m *ListLoadBalancersRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type ListLoadBalancersReply struct {
+	LoadBalancers        []*LoadBalancer `protobuf:"bytes,1,rep,name=load_balancers,json=loadBalancers,proto3" json:"load_balancers,omitempty"`
+	Error                string          `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ListLoadBalancersReply) Reset()         { *m = ListLoadBalancersReply{} }
+func (m *ListLoadBalancersReply) String() string { return proto.CompactTextString(m) }
+func (*ListLoadBalancersReply) ProtoMessage()    {}
+func (*ListLoadBalancersReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{16}
+}
+
+func (m *ListLoadBalancersReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLoadBalancersReply.Unmarshal(m, b)
+}
+func (m *ListLoadBalancersReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLoadBalancersReply.Marshal(b, m, deterministic)
+}
+func (m *ListLoadBalancersReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLoadBalancersReply.Merge(m, src)
+}
+func (m *ListLoadBalancersReply) XXX_Size() int {
+	return xxx_messageInfo_ListLoadBalancersReply.Size(m)
+}
+func (m *ListLoadBalancersReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLoadBalancersReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLoadBalancersReply proto.InternalMessageInfo
+
+func (m *ListLoadBalancersReply) GetLoadBalancers() []*LoadBalancer {
+	if m != nil {
+		return m.LoadBalancers
+	}
+	return nil
+}
+
+func (m *ListLoadBalancersReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type Route struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Node                 string   `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+	DestinationCidr      string   `protobuf:"bytes,3,opt,name=destination_cidr,json=destinationCidr,proto3" json:"destination_cidr,omitempty"`
+	Path                 string   `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	RealizedState        string   `protobuf:"bytes,5,opt,name=realized_state,json=realizedState,proto3" json:"realized_state,omitempty"`
+	NextHops             []string `protobuf:"bytes,6,rep,name=next_hops,json=nextHops,proto3" json:"next_hops,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Route) Reset()         { *m = Route{} }
+func (m *Route) String() string { return proto.CompactTextString(m) }
+func (*Route) ProtoMessage()    {}
+func (*Route) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{17}
+}
+
+func (m *Route) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Route.Unmarshal(m, b)
+}
+func (m *Route) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Route.Marshal(b, m, deterministic)
+}
+func (m *Route) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Route.Merge(m, src)
+}
+func (m *Route) XXX_Size() int {
+	return xxx_messageInfo_Route.Size(m)
+}
+func (m *Route) XXX_DiscardUnknown() {
+	xxx_messageInfo_Route.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Route proto.InternalMessageInfo
+
+func (m *Route) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Route) GetNode() string {
+	if m != nil {
+		return m.Node
+	}
+	return ""
+}
+
+func (m *Route) GetDestinationCidr() string {
+	if m != nil {
+		return m.DestinationCidr
+	}
+	return ""
+}
+
+func (m *Route) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Route) GetRealizedState() string {
+	if m != nil {
+		return m.RealizedState
+	}
+	return ""
+}
+
+func (m *Route) GetNextHops() []string {
+	if m != nil {
+		return m.NextHops
+	}
+	return nil
+}
+
+type ListRoutesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRoutesRequest) Reset()         { *m = ListRoutesRequest{} }
+func (m *ListRoutesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRoutesRequest) ProtoMessage()    {}
+func (*ListRoutesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{18}
+}
+
+func (m *ListRoutesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRoutesRequest.Unmarshal(m, b)
+}
+func (m *ListRoutesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRoutesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRoutesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRoutesRequest.Merge(m, src)
+}
+func (m *ListRoutesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRoutesRequest.Size(m)
+}
+func (m *ListRoutesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRoutesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRoutesRequest proto.InternalMessageInfo
+
+type ListRoutesReply struct {
+	Routes               []*Route `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRoutesReply) Reset()         { *m = ListRoutesReply{} }
+func (m *ListRoutesReply) String() string { return proto.CompactTextString(m) }
+func (*ListRoutesReply) ProtoMessage()    {}
+func (*ListRoutesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b637d4c33cef7514, []int{19}
+}
+
+func (m *ListRoutesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRoutesReply.Unmarshal(m, b)
+}
+func (m *ListRoutesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRoutesReply.Marshal(b, m, deterministic)
+}
+func (m *ListRoutesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRoutesReply.Merge(m, src)
+}
+func (m *ListRoutesReply) XXX_Size() int {
+	return xxx_messageInfo_ListRoutesReply.Size(m)
+}
+func (m *ListRoutesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRoutesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRoutesReply proto.InternalMessageInfo
+
+func (m *ListRoutesReply) GetRoutes() []*Route {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+func (m *ListRoutesReply) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Node)(nil), "cloudprovidervsphere.Node")
 	proto.RegisterType((*GetNodeRequest)(nil), "cloudprovidervsphere.GetNodeRequest")
@@ -360,36 +1155,92 @@ func init() {
 	proto.RegisterType((*ListNodesReply)(nil), "cloudprovidervsphere.ListNodesReply")
 	proto.RegisterType((*VersionRequest)(nil), "cloudprovidervsphere.VersionRequest")
 	proto.RegisterType((*VersionReply)(nil), "cloudprovidervsphere.VersionReply")
+	proto.RegisterType((*VirtualServer)(nil), "cloudprovidervsphere.VirtualServer")
+	proto.RegisterType((*PoolMember)(nil), "cloudprovidervsphere.PoolMember")
+	proto.RegisterType((*Pool)(nil), "cloudprovidervsphere.Pool")
+	proto.RegisterType((*Monitor)(nil), "cloudprovidervsphere.Monitor")
+	proto.RegisterType((*IPAllocation)(nil), "cloudprovidervsphere.IPAllocation")
+	proto.RegisterType((*LoadBalancer)(nil), "cloudprovidervsphere.LoadBalancer")
+	proto.RegisterType((*GetLoadBalancerRequest)(nil), "cloudprovidervsphere.GetLoadBalancerRequest")
+	proto.RegisterType((*GetLoadBalancerReply)(nil), "cloudprovidervsphere.GetLoadBalancerReply")
+	proto.RegisterType((*ListLoadBalancersRequest)(nil), "cloudprovidervsphere.ListLoadBalancersRequest")
+	proto.RegisterType((*ListLoadBalancersReply)(nil), "cloudprovidervsphere.ListLoadBalancersReply")
+	proto.RegisterType((*Route)(nil), "cloudprovidervsphere.Route")
+	proto.RegisterType((*ListRoutesRequest)(nil), "cloudprovidervsphere.ListRoutesRequest")
+	proto.RegisterType((*ListRoutesReply)(nil), "cloudprovidervsphere.ListRoutesReply")
 }
 
 func init() { proto.RegisterFile("cloudprovidervsphere.proto", fileDescriptor_b637d4c33cef7514) }
 
 var fileDescriptor_b637d4c33cef7514 = []byte{
-	// 375 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x53, 0x4d, 0x6b, 0xea, 0x40,
-	0x14, 0x35, 0x1a, 0xf5, 0xe5, 0x3e, 0x11, 0x19, 0x5c, 0x0c, 0xe1, 0xf1, 0x08, 0x83, 0x3c, 0x5c,
-	0x85, 0x87, 0xdd, 0x75, 0x69, 0x17, 0x6e, 0xa4, 0x88, 0xb4, 0x22, 0x74, 0x95, 0x3a, 0x17, 0x1a,
-	0xd0, 0x4c, 0x3a, 0x93, 0xa4, 0xf8, 0x77, 0x4a, 0x7f, 0x68, 0x99, 0x99, 0x98, 0xc6, 0x12, 0x11,
-	0xba, 0xbb, 0x1f, 0xe7, 0x9e, 0x73, 0xe6, 0x84, 0x80, 0xbf, 0xdb, 0x8b, 0x9c, 0xa7, 0x52, 0x14,
-	0x31, 0x47, 0x59, 0xa8, 0xf4, 0x05, 0x25, 0x86, 0xa9, 0x14, 0x99, 0x20, 0xe3, 0xa6, 0x1d, 0x7b,
-	0x77, 0xc0, 0xbd, 0x17, 0x1c, 0x09, 0x85, 0x7e, 0xb1, 0xc3, 0x24, 0x43, 0x49, 0x9d, 0xc0, 0x99,
-	0x7a, 0xeb, 0x53, 0x4b, 0xfe, 0x02, 0xf0, 0x28, 0x8b, 0xca, 0x65, 0xdb, 0x2c, 0x6b, 0x13, 0x42,
-	0xc0, 0x4d, 0xa2, 0x03, 0xd2, 0x8e, 0xd9, 0x98, 0x9a, 0xf8, 0xf0, 0x8b, 0x27, 0x4a, 0x97, 0x8a,
-	0xba, 0x41, 0x67, 0xea, 0xad, 0xab, 0x9e, 0xfc, 0x01, 0x2f, 0xe2, 0x5c, 0xa2, 0x52, 0xa8, 0x68,
-	0xd7, 0x2c, 0xbf, 0x06, 0x9a, 0x2d, 0xcf, 0x63, 0x4e, 0x7b, 0x96, 0x4d, 0xd7, 0x6c, 0x02, 0xc3,
-	0x05, 0x66, 0xda, 0xe6, 0x1a, 0x5f, 0x73, 0x54, 0x59, 0x85, 0x72, 0x6a, 0xa8, 0x07, 0x18, 0x54,
-	0xa8, 0x74, 0x7f, 0x24, 0x21, 0xb8, 0x89, 0xe0, 0x68, 0x30, 0xbf, 0x67, 0x7e, 0xd8, 0x98, 0x8d,
-	0x81, 0x1b, 0x1c, 0x19, 0x43, 0x17, 0xa5, 0x14, 0xa7, 0x27, 0xda, 0x86, 0x2d, 0x61, 0xb4, 0x8c,
-	0x95, 0xa1, 0x55, 0x27, 0xf5, 0x1f, 0x67, 0xc5, 0xb6, 0x30, 0xac, 0xb1, 0x69, 0x97, 0xff, 0xa1,
-	0xab, 0xd5, 0x15, 0x75, 0x82, 0xce, 0x15, 0x9b, 0x16, 0x78, 0xc1, 0xe7, 0x08, 0x86, 0x1b, 0x94,
-	0x2a, 0x16, 0x49, 0xe9, 0x92, 0x4d, 0x61, 0x50, 0x4d, 0xb4, 0x92, 0x76, 0x6d, 0xfb, 0xca, 0xb5,
-	0x6d, 0x67, 0x1f, 0x6d, 0x18, 0xdf, 0x69, 0xd9, 0x55, 0x29, 0xbb, 0xb1, 0xb2, 0xe4, 0x11, 0xfa,
-	0x65, 0xa4, 0x64, 0xd2, 0x6c, 0xec, 0xfc, 0xbb, 0xf8, 0xec, 0x0a, 0x2a, 0xdd, 0x1f, 0x59, 0x8b,
-	0x3c, 0x81, 0x57, 0xa5, 0x40, 0xfe, 0x35, 0x9f, 0x7c, 0x0f, 0xdd, 0x9f, 0x5c, 0xc5, 0x59, 0xf2,
-	0x2d, 0xc0, 0x02, 0xb3, 0xf2, 0xe5, 0x97, 0x6c, 0x9f, 0x47, 0x75, 0xc9, 0x76, 0x3d, 0x3e, 0xd6,
-	0x9a, 0xdf, 0x42, 0xb0, 0x13, 0x87, 0xb0, 0x38, 0xbc, 0x45, 0x12, 0xcf, 0x2f, 0xc2, 0xf2, 0x64,
-	0xde, 0x98, 0xe3, 0xca, 0x79, 0xee, 0x99, 0x9f, 0xf0, 0xe6, 0x33, 0x00, 0x00, 0xff, 0xff, 0x4d,
-	0x6a, 0x77, 0xb3, 0xa2, 0x03, 0x00, 0x00,
+	// 1058 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0xdd, 0x6e, 0x1b, 0x45,
+	0x14, 0x66, 0x1d, 0x3b, 0xb6, 0x8f, 0x7f, 0x12, 0x06, 0x2b, 0x5a, 0x9c, 0x16, 0xcc, 0x36, 0x85,
+	0x80, 0x2a, 0xab, 0x4a, 0x6f, 0xda, 0xde, 0x35, 0xbd, 0x08, 0x41, 0x69, 0x65, 0xb9, 0x25, 0xaa,
+	0x04, 0x62, 0x35, 0xf1, 0x4c, 0x95, 0x91, 0xd6, 0x3b, 0xc3, 0xcc, 0xd8, 0x60, 0xde, 0x81, 0x5b,
+	0xee, 0xe1, 0x11, 0x78, 0x01, 0x5e, 0x87, 0x57, 0xe0, 0x0e, 0xcd, 0xcf, 0xae, 0xd7, 0x66, 0x5d,
+	0x87, 0x2a, 0x77, 0x7b, 0xce, 0x7c, 0x67, 0xe6, 0xcc, 0x37, 0xdf, 0x39, 0x33, 0x0b, 0xfd, 0x49,
+	0xc2, 0x67, 0x44, 0x48, 0x3e, 0x67, 0x84, 0xca, 0xb9, 0x12, 0xd7, 0x54, 0xd2, 0xa1, 0x90, 0x5c,
+	0x73, 0xd4, 0x2b, 0x1b, 0x8b, 0xfe, 0x08, 0xa0, 0xfa, 0x92, 0x13, 0x8a, 0x42, 0xa8, 0xcf, 0x27,
+	0x34, 0xd5, 0x54, 0x86, 0xc1, 0x20, 0x38, 0x6e, 0x8e, 0x33, 0x13, 0x7d, 0x02, 0x40, 0xb0, 0xc6,
+	0x7e, 0xb0, 0x62, 0x07, 0x0b, 0x1e, 0x84, 0xa0, 0x9a, 0xe2, 0x29, 0x0d, 0x77, 0xec, 0x88, 0xfd,
+	0x46, 0x7d, 0x68, 0x90, 0x54, 0x99, 0x4f, 0x15, 0x56, 0x07, 0x3b, 0xc7, 0xcd, 0x71, 0x6e, 0xa3,
+	0x3b, 0xd0, 0xc4, 0x84, 0x48, 0xaa, 0x14, 0x55, 0x61, 0xcd, 0x0e, 0x2e, 0x1d, 0x66, 0xb6, 0xd9,
+	0x8c, 0x91, 0x70, 0xd7, 0xcd, 0x66, 0xbe, 0xa3, 0x23, 0xe8, 0x9e, 0x51, 0x6d, 0xd2, 0x1c, 0xd3,
+	0x1f, 0x67, 0x54, 0xe9, 0x1c, 0x15, 0x14, 0x50, 0xaf, 0xa1, 0x9d, 0xa3, 0x44, 0xb2, 0x40, 0x43,
+	0xa8, 0xa6, 0x9c, 0x50, 0x8b, 0x69, 0x9d, 0xf4, 0x87, 0xa5, 0xdc, 0x58, 0xb8, 0xc5, 0xa1, 0x1e,
+	0xd4, 0xa8, 0x94, 0x3c, 0xdb, 0xa2, 0x33, 0xa2, 0x0b, 0xd8, 0xbf, 0x60, 0xca, 0x4e, 0xab, 0xb2,
+	0xd5, 0xdf, 0x9b, 0xab, 0xe8, 0x0d, 0x74, 0x0b, 0xb3, 0x99, 0x2c, 0x1f, 0x42, 0xcd, 0xac, 0xae,
+	0xc2, 0x60, 0xb0, 0xb3, 0x25, 0x4d, 0x07, 0xdc, 0x90, 0xe7, 0x3e, 0x74, 0x2f, 0xa9, 0x54, 0x8c,
+	0xa7, 0x3e, 0xcb, 0xe8, 0x18, 0xda, 0xb9, 0xc7, 0xac, 0x64, 0xb2, 0x76, 0x76, 0x9e, 0xb5, 0x33,
+	0xa3, 0xdf, 0x2b, 0xd0, 0xb9, 0x64, 0x52, 0xcf, 0x70, 0xf2, 0x8a, 0xca, 0x39, 0x95, 0xa8, 0x0b,
+	0x95, 0x9c, 0xdd, 0x0a, 0x23, 0x86, 0x6f, 0x81, 0xf5, 0xb5, 0x5f, 0xd2, 0x7e, 0xa3, 0xfb, 0xd0,
+	0x95, 0x14, 0x27, 0xec, 0x17, 0x4a, 0x62, 0xa5, 0xb1, 0xce, 0x14, 0xd0, 0xc9, 0xbc, 0xaf, 0x8c,
+	0x13, 0xdd, 0x05, 0x60, 0x22, 0xf6, 0x07, 0x1c, 0x56, 0x2d, 0xa4, 0xc9, 0xc4, 0x33, 0xe7, 0x30,
+	0xbb, 0x11, 0x5c, 0xea, 0x4c, 0x09, 0xce, 0x40, 0x4f, 0xe0, 0x63, 0x42, 0xdf, 0xe2, 0x59, 0xa2,
+	0x63, 0xc1, 0x79, 0x12, 0x4f, 0xe9, 0xf4, 0x8a, 0xca, 0xd8, 0x21, 0x77, 0x2d, 0xf2, 0xc0, 0x03,
+	0x46, 0x9c, 0x27, 0x2f, 0xec, 0xf0, 0xc8, 0x86, 0x1e, 0x42, 0xd3, 0x86, 0xd8, 0x7c, 0xeb, 0x76,
+	0xb9, 0x86, 0x71, 0x8c, 0x4c, 0xce, 0x8f, 0x21, 0xc4, 0x42, 0x24, 0x6c, 0x82, 0x35, 0xe3, 0x69,
+	0x2c, 0x24, 0x7f, 0xcb, 0x12, 0xea, 0xb0, 0x0d, 0x8b, 0x3d, 0x28, 0x8c, 0x8f, 0xdc, 0xb0, 0x89,
+	0x8c, 0x38, 0xc0, 0x72, 0x25, 0xf4, 0x19, 0xb4, 0x09, 0x53, 0x22, 0xc1, 0x8b, 0xd8, 0x6a, 0xdf,
+	0x31, 0xd5, 0xf2, 0xbe, 0x97, 0x78, 0xba, 0xbe, 0xef, 0xca, 0xfa, 0xbe, 0x3f, 0x85, 0x16, 0x26,
+	0x53, 0x96, 0xae, 0x50, 0x07, 0xd6, 0x65, 0x79, 0x8b, 0xfe, 0x0a, 0xa0, 0x6a, 0x56, 0xbc, 0xcd,
+	0xb3, 0x78, 0x0a, 0x75, 0xc7, 0xa4, 0xab, 0xca, 0xd6, 0xc9, 0xa0, 0x5c, 0x6e, 0xcb, 0x9d, 0x8e,
+	0xb3, 0x00, 0xf4, 0x10, 0x7a, 0x78, 0xa2, 0xd9, 0x9c, 0xc6, 0x53, 0x9e, 0x32, 0xcd, 0xa5, 0x65,
+	0x2d, 0x3b, 0x37, 0xe4, 0xc6, 0x5e, 0xb8, 0x21, 0xc3, 0x98, 0x8a, 0x7e, 0x0d, 0xa0, 0xee, 0x1d,
+	0xb7, 0xb9, 0x89, 0x7b, 0xd0, 0x91, 0x54, 0xf1, 0x99, 0x9c, 0xd0, 0x58, 0x2f, 0x04, 0xf5, 0x9a,
+	0x6a, 0x67, 0xce, 0xd7, 0x0b, 0x41, 0xed, 0xfc, 0x5c, 0xea, 0xb0, 0x36, 0x08, 0x8e, 0x77, 0xc6,
+	0xf6, 0x3b, 0xfa, 0x2d, 0x80, 0xf6, 0xf9, 0xe8, 0x59, 0x92, 0x70, 0x77, 0xbc, 0xb7, 0x99, 0xd4,
+	0x1d, 0x7b, 0xda, 0x56, 0x78, 0x8c, 0xf8, 0x8c, 0x1a, 0x4c, 0x18, 0x22, 0xcf, 0xc9, 0x9a, 0x16,
+	0x6a, 0x6b, 0x5a, 0x88, 0xfe, 0xa9, 0x40, 0xfb, 0x82, 0x63, 0x72, 0x8a, 0x13, 0x9c, 0x4e, 0xa8,
+	0x34, 0x2d, 0xd2, 0xf6, 0x4a, 0x81, 0x27, 0x99, 0xb6, 0x96, 0x8e, 0xbc, 0xe1, 0x56, 0x0a, 0x0d,
+	0xb7, 0x07, 0xb5, 0x49, 0x82, 0x95, 0xf2, 0xd9, 0x39, 0x63, 0x5b, 0xed, 0x5d, 0xc0, 0xde, 0xdc,
+	0x95, 0x7d, 0xac, 0x6c, 0xdd, 0xbb, 0xd3, 0x6c, 0x9d, 0xdc, 0x2b, 0x97, 0xc5, 0x4a, 0x8f, 0x18,
+	0x77, 0xe7, 0x45, 0xd3, 0x08, 0xa4, 0x66, 0xf6, 0xef, 0xea, 0x73, 0x63, 0x27, 0x33, 0x8c, 0x8c,
+	0x1d, 0x10, 0x3d, 0x81, 0x86, 0xd7, 0x92, 0x0a, 0xeb, 0x36, 0xe8, 0x6e, 0x79, 0x90, 0x57, 0xd1,
+	0x38, 0x87, 0xa3, 0x33, 0xe8, 0x98, 0x9d, 0xe5, 0x67, 0x69, 0xab, 0xb7, 0x75, 0x12, 0x95, 0xc7,
+	0x17, 0x4f, 0x7d, 0xdc, 0x66, 0x62, 0x69, 0x45, 0xdf, 0xc0, 0xc1, 0x19, 0xd5, 0x45, 0xf6, 0xb3,
+	0x2e, 0xff, 0xbf, 0x0f, 0x21, 0x9a, 0x41, 0xef, 0x3f, 0x73, 0x99, 0xce, 0x7b, 0x06, 0x9d, 0x84,
+	0x63, 0x12, 0x5f, 0x79, 0x6f, 0x18, 0xbc, 0x2b, 0xd9, 0x95, 0xf8, 0x76, 0x52, 0xb0, 0x36, 0xb4,
+	0xfe, 0xc7, 0x10, 0x9a, 0x4b, 0xa5, 0x18, 0xa7, 0x6e, 0xb4, 0x89, 0x68, 0x01, 0x07, 0x25, 0x91,
+	0x26, 0xe5, 0x73, 0xe8, 0xae, 0xa4, 0x9c, 0xdd, 0x4f, 0x37, 0xc9, 0xb9, 0x53, 0xcc, 0x79, 0xd3,
+	0x7d, 0xf5, 0x67, 0x00, 0xb5, 0x31, 0x9f, 0xe9, 0x25, 0x93, 0x41, 0x41, 0xce, 0xc8, 0xdf, 0xdd,
+	0x19, 0xbb, 0x9c, 0x50, 0xf4, 0x25, 0xec, 0x13, 0xaa, 0x34, 0x4b, 0x5d, 0xef, 0x9e, 0x30, 0x22,
+	0xbd, 0xda, 0xf7, 0x0a, 0xfe, 0xe7, 0x8c, 0xc8, 0xbc, 0x90, 0xab, 0xef, 0x2c, 0xe4, 0x5a, 0x59,
+	0x21, 0x1f, 0x42, 0x33, 0xa5, 0x3f, 0xeb, 0xf8, 0x9a, 0x8b, 0xec, 0xa6, 0x69, 0x18, 0xc7, 0xd7,
+	0x5c, 0xa8, 0xe8, 0x23, 0xf8, 0xd0, 0xf0, 0x65, 0xf3, 0xce, 0x28, 0x8e, 0xbe, 0x87, 0xbd, 0xa2,
+	0xd3, 0xb0, 0xf7, 0x08, 0x76, 0xa5, 0x35, 0x3d, 0x6b, 0x87, 0xe5, 0xac, 0xd9, 0x90, 0xb1, 0x87,
+	0x96, 0xf3, 0x74, 0xf2, 0x77, 0x15, 0x7a, 0xcf, 0x4d, 0xf0, 0xc8, 0x07, 0x5f, 0xba, 0x60, 0xf4,
+	0x2d, 0xd4, 0xfd, 0x73, 0x07, 0x1d, 0x95, 0x4f, 0xbf, 0xfa, 0x66, 0xea, 0x47, 0x5b, 0x50, 0x22,
+	0x59, 0x44, 0x1f, 0xa0, 0xef, 0xa0, 0x99, 0xbf, 0x50, 0xd0, 0xe7, 0x1b, 0x4e, 0x7b, 0xed, 0x41,
+	0xd4, 0x3f, 0xda, 0x8a, 0x73, 0x93, 0xbf, 0x01, 0x38, 0xa3, 0xda, 0xbf, 0x4a, 0x36, 0xa5, 0xbd,
+	0xfa, 0x8c, 0xe9, 0x47, 0x5b, 0x50, 0x6e, 0xe6, 0x29, 0xec, 0xad, 0x95, 0x1e, 0x7a, 0xb0, 0x71,
+	0xbf, 0x25, 0xd5, 0xde, 0xff, 0xea, 0x86, 0x68, 0xb7, 0x9c, 0x72, 0x42, 0xb8, 0x58, 0x11, 0xfa,
+	0x70, 0x33, 0x0b, 0x65, 0xb5, 0xd9, 0x7f, 0x70, 0x63, 0xbc, 0x5b, 0xf4, 0x07, 0x80, 0xa5, 0xd0,
+	0xd0, 0x17, 0x9b, 0xa3, 0x57, 0xf4, 0xd9, 0xbf, 0xbf, 0x1d, 0x68, 0xe7, 0x3f, 0x7d, 0x0a, 0x83,
+	0x09, 0x9f, 0x0e, 0xe7, 0xd3, 0x9f, 0xb0, 0xa4, 0xab, 0x41, 0x43, 0x1f, 0x75, 0x5a, 0xaa, 0xc5,
+	0x51, 0x70, 0xb5, 0x6b, 0x7f, 0x32, 0x1e, 0xfd, 0x3b, 0x00, 0xbf, 0x6f, 0x00, 0x94, 0x82, 0x0c,
+	0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -407,6 +1258,9 @@ type CloudProviderVsphereClient interface {
 	GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*GetNodeReply, error)
 	ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesReply, error)
 	GetVersion(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionReply, error)
+	GetLoadBalancer(ctx context.Context, in *GetLoadBalancerRequest, opts ...grpc.CallOption) (*GetLoadBalancerReply, error)
+	ListLoadBalancers(ctx context.Context, in *ListLoadBalancersRequest, opts ...grpc.CallOption) (*ListLoadBalancersReply, error)
+	ListRoutes(ctx context.Context, in *ListRoutesRequest, opts ...grpc.CallOption) (*ListRoutesReply, error)
 }
 
 type cloudProviderVsphereClient struct {
@@ -444,11 +1298,41 @@ func (c *cloudProviderVsphereClient) GetVersion(ctx context.Context, in *Version
 	return out, nil
 }
 
+func (c *cloudProviderVsphereClient) GetLoadBalancer(ctx context.Context, in *GetLoadBalancerRequest, opts ...grpc.CallOption) (*GetLoadBalancerReply, error) {
+	out := new(GetLoadBalancerReply)
+	err := c.cc.Invoke(ctx, "/cloudprovidervsphere.CloudProviderVsphere/GetLoadBalancer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cloudProviderVsphereClient) ListLoadBalancers(ctx context.Context, in *ListLoadBalancersRequest, opts ...grpc.CallOption) (*ListLoadBalancersReply, error) {
+	out := new(ListLoadBalancersReply)
+	err := c.cc.Invoke(ctx, "/cloudprovidervsphere.CloudProviderVsphere/ListLoadBalancers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cloudProviderVsphereClient) ListRoutes(ctx context.Context, in *ListRoutesRequest, opts ...grpc.CallOption) (*ListRoutesReply, error) {
+	out := new(ListRoutesReply)
+	err := c.cc.Invoke(ctx, "/cloudprovidervsphere.CloudProviderVsphere/ListRoutes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CloudProviderVsphereServer is the server API for CloudProviderVsphere service.
 type CloudProviderVsphereServer interface {
 	GetNode(context.Context, *GetNodeRequest) (*GetNodeReply, error)
 	ListNodes(context.Context, *ListNodesRequest) (*ListNodesReply, error)
 	GetVersion(context.Context, *VersionRequest) (*VersionReply, error)
+	GetLoadBalancer(context.Context, *GetLoadBalancerRequest) (*GetLoadBalancerReply, error)
+	ListLoadBalancers(context.Context, *ListLoadBalancersRequest) (*ListLoadBalancersReply, error)
+	ListRoutes(context.Context, *ListRoutesRequest) (*ListRoutesReply, error)
 }
 
 // UnimplementedCloudProviderVsphereServer can be embedded to have forward compatible implementations.
@@ -464,6 +1348,15 @@ func (*UnimplementedCloudProviderVsphereServer) ListNodes(ctx context.Context, r
 func (*UnimplementedCloudProviderVsphereServer) GetVersion(ctx context.Context, req *VersionRequest) (*VersionReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
 }
+func (*UnimplementedCloudProviderVsphereServer) GetLoadBalancer(ctx context.Context, req *GetLoadBalancerRequest) (*GetLoadBalancerReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoadBalancer not implemented")
+}
+func (*UnimplementedCloudProviderVsphereServer) ListLoadBalancers(ctx context.Context, req *ListLoadBalancersRequest) (*ListLoadBalancersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLoadBalancers not implemented")
+}
+func (*UnimplementedCloudProviderVsphereServer) ListRoutes(ctx context.Context, req *ListRoutesRequest) (*ListRoutesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRoutes not implemented")
+}
 
 func RegisterCloudProviderVsphereServer(s *grpc.Server, srv CloudProviderVsphereServer) {
 	s.RegisterService(&_CloudProviderVsphere_serviceDesc, srv)
@@ -523,6 +1416,60 @@ func _CloudProviderVsphere_GetVersion_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CloudProviderVsphere_GetLoadBalancer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLoadBalancerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudProviderVsphereServer).GetLoadBalancer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudprovidervsphere.CloudProviderVsphere/GetLoadBalancer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudProviderVsphereServer).GetLoadBalancer(ctx, req.(*GetLoadBalancerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CloudProviderVsphere_ListLoadBalancers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLoadBalancersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudProviderVsphereServer).ListLoadBalancers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudprovidervsphere.CloudProviderVsphere/ListLoadBalancers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudProviderVsphereServer).ListLoadBalancers(ctx, req.(*ListLoadBalancersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CloudProviderVsphere_ListRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CloudProviderVsphereServer).ListRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cloudprovidervsphere.CloudProviderVsphere/ListRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CloudProviderVsphereServer).ListRoutes(ctx, req.(*ListRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _CloudProviderVsphere_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "cloudprovidervsphere.CloudProviderVsphere",
 	HandlerType: (*CloudProviderVsphereServer)(nil),
@@ -539,6 +1486,18 @@ var _CloudProviderVsphere_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetVersion",
 			Handler:    _CloudProviderVsphere_GetVersion_Handler,
 		},
+		{
+			MethodName: "GetLoadBalancer",
+			Handler:    _CloudProviderVsphere_GetLoadBalancer_Handler,
+		},
+		{
+			MethodName: "ListLoadBalancers",
+			Handler:    _CloudProviderVsphere_ListLoadBalancers_Handler,
+		},
+		{
+			MethodName: "ListRoutes",
+			Handler:    _CloudProviderVsphere_ListRoutes_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cloudprovidervsphere.proto",
@@ -0,0 +1,243 @@
+/*
+ Copyright 2020 The Kubernetes Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	klog "k8s.io/klog/v2"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+	lcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+)
+
+var (
+	// CloudConfigFile is set by the main program to the path of the cloud config
+	// file. If set, the file is watched and changes are applied without restart.
+	CloudConfigFile string
+
+	// CloudConfigDir is set by the main program to the drop-in directory of
+	// YAML fragments merged into the cloud config, in the order of their file
+	// names. It is watched along with the cloud config file.
+	CloudConfigDir string
+
+	// ConfigReloadInterval is the interval at which the cloud config file and
+	// the CA files it references are checked for changes.
+	ConfigReloadInterval = 30 * time.Second
+)
+
+// globalFieldsRequiringRestart are the Global settings that are not reloaded.
+// The remaining ones are defaults of the VirtualCenter sections and are
+// reloaded as part of them.
+var globalFieldsRequiringRestart = sets.NewString(
+	"SecretName",
+	"SecretNamespace",
+	"SecretsDirectory",
+	"APIDisable",
+	"APIBinding",
+	"SessionKeepAliveInterval",
+	"SessionMaxAge",
+	"CircuitBreakerThreshold",
+	"CircuitBreakerOpenInterval",
+	"CircuitBreakerMaxOpenInterval",
+	"OperationTimeout",
+)
+
+// globalFieldsReloaded are the Global settings that are not defaults of the
+// VirtualCenter sections but are still applied on reload.
+var globalFieldsReloaded = sets.NewString(
+	"DiscoveryTimeout",
+	"StrictConfig",
+)
+
+// readCloudConfig reads the cloud config file and merges the fragments of the
+// drop-in directory into it
+func readCloudConfig(path string) ([]byte, error) {
+	byConfig, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return vcfg.MergeDropInDir(path, byConfig, CloudConfigDir)
+}
+
+// watchConfig polls the cloud config file, as a ConfigMap mounted as volume
+// is updated by swapping symlinks, and applies it whenever it, a fragment of
+// the drop-in directory or one of the CA files it references changed.
+func (vs *VSphere) watchConfig(path string, stop <-chan struct{}) {
+	byConfig, err := readCloudConfig(path)
+	if err != nil {
+		klog.Errorf("Failed to read cloud config %s, it will not be reloaded: %v", path, err)
+		return
+	}
+	fingerprint := configFingerprint(byConfig, vs.cfg)
+	klog.V(2).Infof("Watching cloud config %s for changes every %s", path, ConfigReloadInterval)
+
+	go wait.Until(func() {
+		byConfig, err := readCloudConfig(path)
+		if err != nil {
+			klog.Errorf("Failed to read cloud config %s: %v", path, err)
+			return
+		}
+		if configFingerprint(byConfig, vs.cfg) == fingerprint {
+			return
+		}
+		klog.Infof("Cloud config %s changed, reloading", path)
+		if err := vs.reloadConfig(byConfig); err != nil {
+			klog.Errorf("Not applying the changed cloud config %s: %v", path, err)
+		}
+		// do not retry an invalid config until it changes again
+		fingerprint = configFingerprint(byConfig, vs.cfg)
+	}, ConfigReloadInterval, stop)
+}
+
+// reloadConfig validates the given cloud config and applies the changes to
+// the vCenters, the node address settings, tracing and the load balancer classes.
+// Nothing is applied if the config is invalid. Changes of other settings are
+// logged as requiring a restart.
+func (vs *VSphere) reloadConfig(byConfig []byte) error {
+	cfg, err := ccfg.ReadCPIConfig(byConfig)
+	if err != nil {
+		return err
+	}
+	if err := checkConfigSections(byConfig); err != nil {
+		return err
+	}
+	if err := validateDualStack(cfg); err != nil {
+		return err
+	}
+	nsxtcfg, err := ncfg.ReadNsxtConfig(byConfig)
+	if err != nil {
+		nsxtcfg = nil
+	}
+	lbcfg, err := lcfg.ReadLBConfig(byConfig)
+	if err != nil {
+		lbcfg = nil
+	}
+	routecfg, err := rcfg.ReadRouteConfig(byConfig)
+	if err != nil {
+		routecfg = nil
+	}
+
+	var applied []string
+	var restartRequired []string
+
+	if vs.isLoadBalancerSupportEnabled() && lbcfg != nil && lbcfg.IsEnabled() {
+		// applied first, as resolving the classes validates them against NSX-T
+		changes, err := vs.loadbalancer.UpdateClasses(lbcfg)
+		if err != nil {
+			return err
+		}
+		applied = append(applied, changes...)
+		oldLB := lcfg.LoadBalancerConfig{}
+		if vs.cfgLB != nil {
+			oldLB = vs.cfgLB.LoadBalancer
+		}
+		newLB := lbcfg.LoadBalancer
+		oldLB.LoadBalancerClassConfig = lcfg.LoadBalancerClassConfig{}
+		newLB.LoadBalancerClassConfig = lcfg.LoadBalancerClassConfig{}
+		restartRequired = append(restartRequired, vcfg.DiffFields("LoadBalancer", &oldLB, &newLB)...)
+	} else if vs.isLoadBalancerSupportEnabled() != (lbcfg != nil && lbcfg.IsEnabled()) {
+		restartRequired = append(restartRequired, "LoadBalancer enabled or disabled")
+	}
+
+	if changes := vcfg.DiffFields("Tracing", &vs.cfg.Tracing, &cfg.Tracing); len(changes) > 0 {
+		if err := configureTracing(&cfg.Tracing); err != nil {
+			klog.Errorf("Tracing disabled: %v", err)
+		}
+		applied = append(applied, changes...)
+	}
+
+	applied = append(applied, vcfg.DiffFields("Nodes", &vs.cfg.Nodes, &cfg.Nodes)...)
+	vs.nodeManager.setConfig(cfg)
+
+	if vs.connectionManager != nil {
+		applied = append(applied, vs.connectionManager.UpdateConfig(&cfg.Config)...)
+	}
+
+	for _, change := range vcfg.DiffFields("Global", &vs.cfg.Global, &cfg.Global) {
+		field := strings.TrimPrefix(strings.Fields(change)[0], "Global.")
+		if globalFieldsRequiringRestart.Has(field) {
+			restartRequired = append(restartRequired, change)
+		} else if globalFieldsReloaded.Has(field) {
+			applied = append(applied, change)
+		}
+	}
+	restartRequired = append(restartRequired, vcfg.DiffFields("Labels", &vs.cfg.Labels, &cfg.Labels)...)
+	restartRequired = append(restartRequired, diffOptional("NSXT", vs.cfgNSXT, nsxtcfg)...)
+	restartRequired = append(restartRequired, diffOptional("Route", vs.cfgRoute, routecfg)...)
+
+	vs.cfg = cfg
+	vs.cfgLB = lbcfg
+	vs.cfgNSXT = nsxtcfg
+	vs.cfgRoute = routecfg
+
+	if len(applied) == 0 && len(restartRequired) == 0 {
+		klog.Info("Cloud config reloaded without changes")
+	}
+	for _, change := range applied {
+		klog.Infof("Cloud config reloaded: %s", change)
+	}
+	for _, change := range restartRequired {
+		klog.Warningf("Cloud config reloaded: %s (takes effect after a restart)", change)
+	}
+	return nil
+}
+
+// diffOptional is vcfg.DiffFields for optional config sections
+func diffOptional(section string, old interface{}, new interface{}) []string {
+	oldNil := old == nil || reflect.ValueOf(old).IsNil()
+	newNil := new == nil || reflect.ValueOf(new).IsNil()
+	switch {
+	case oldNil && newNil:
+		return nil
+	case oldNil:
+		return []string{fmt.Sprintf("%s added", section)}
+	case newNil:
+		return []string{fmt.Sprintf("%s removed", section)}
+	}
+	return vcfg.DiffFields(section, old, new)
+}
+
+// configFingerprint returns a digest of the cloud config and of the CA files it references
+func configFingerprint(byConfig []byte, cfg *ccfg.CPIConfig) string {
+	h := sha256.New()
+	h.Write(byConfig)
+
+	caFiles := sets.NewString(cfg.Global.CAFile)
+	for _, vcConfig := range cfg.VirtualCenter {
+		caFiles.Insert(vcConfig.CAFile)
+	}
+	caFiles.Delete("")
+	for _, caFile := range caFiles.List() {
+		h.Write([]byte(caFile))
+		if content, err := ioutil.ReadFile(caFile); err == nil {
+			h.Write(content)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
+)
+
+const reloadConfigYAML = `
+global:
+  server: 0.0.0.0
+  port: 443
+  user: user
+  password: password
+  insecureFlag: true
+  datacenters:
+    - us-west
+nodes:
+  internalNetworkSubnetCidr: %s
+`
+
+func TestReloadConfig(t *testing.T) {
+	cfg, err := ccfg.ReadCPIConfig([]byte(fmt.Sprintf(reloadConfigYAML, "10.0.0.0/24")))
+	if err != nil {
+		t.Fatalf("ReadCPIConfig err=%v", err)
+	}
+	vs, err := buildVSphereFromConfig(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("buildVSphereFromConfig err=%v", err)
+	}
+
+	if err := vs.reloadConfig([]byte(fmt.Sprintf(reloadConfigYAML, "10.0.0.0/33"))); err == nil {
+		t.Error("Should fail when an invalid subnet is provided")
+	}
+	if vs.cfg != cfg || vs.nodeManager.config() != cfg {
+		t.Error("invalid config should not be applied")
+	}
+
+	if err := vs.reloadConfig([]byte(fmt.Sprintf(reloadConfigYAML, "192.168.0.0/16"))); err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %v", err)
+	}
+	if subnet := vs.nodeManager.config().Nodes.InternalNetworkSubnetCIDR; subnet != "192.168.0.0/16" {
+		t.Errorf("incorrect internalNetworkSubnetCidr: %s", subnet)
+	}
+	if vs.cfg.Nodes.InternalNetworkSubnetCIDR != "192.168.0.0/16" {
+		t.Errorf("config not replaced: %s", vs.cfg.Nodes.InternalNetworkSubnetCIDR)
+	}
+}
+
+func TestReadCloudConfigDropIns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cloud-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "vsphere.conf")
+	if err := ioutil.WriteFile(path, []byte(fmt.Sprintf(reloadConfigYAML, "10.0.0.0/24")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dropIns := filepath.Join(dir, "vsphere.conf.d")
+	if err := os.Mkdir(dropIns, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dropIns, "10-nodes.yaml"), []byte("nodes:\n  internalNetworkSubnetCidr: 192.168.0.0/16\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	CloudConfigDir = dropIns
+	defer func() { CloudConfigDir = "" }()
+	byConfig, err := readCloudConfig(path)
+	if err != nil {
+		t.Fatalf("readCloudConfig err=%v", err)
+	}
+	cfg, err := ccfg.ReadCPIConfig(byConfig)
+	if err != nil {
+		t.Fatalf("ReadCPIConfig err=%v", err)
+	}
+	if cfg.Nodes.InternalNetworkSubnetCIDR != "192.168.0.0/16" || cfg.Global.User != "user" {
+		t.Errorf("fragment not merged: %+v %+v", cfg.Nodes, cfg.Global)
+	}
+}
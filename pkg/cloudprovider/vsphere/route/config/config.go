@@ -18,10 +18,21 @@ package config
 
 import (
 	"fmt"
+	"os"
 
 	klog "k8s.io/klog/v2"
 )
 
+// FromEnv initializes the provided configuration object with values
+// obtained from environment variables. If an environment variable is set
+// for a property that's already initialized, the environment variable's value
+// takes precedence.
+func (cfg *Config) FromEnv() {
+	if v := os.Getenv("VSPHERE_ROUTE_ROUTER_PATH"); v != "" {
+		cfg.Route.RouterPath = v
+	}
+}
+
 /*
 	TODO:
 	When the INI based cloud-config is deprecated, the references to the
@@ -45,6 +45,11 @@ func (rci *RouteConfigINI) validateConfig() error {
 
 // CompleteAndValidate sets default values, overrides by env and validates the resulting config
 func (rci *RouteConfigINI) CompleteAndValidate() error {
+	// Env Vars should override config file entries if present
+	cfg := Config{Route: RouteConfig(rci.Route)}
+	cfg.FromEnv()
+	rci.Route = RouteINI(cfg.Route)
+
 	return rci.validateConfig()
 }
 
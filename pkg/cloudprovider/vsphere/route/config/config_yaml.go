@@ -20,7 +20,7 @@ import (
 	"errors"
 	"fmt"
 
-	yaml "gopkg.in/yaml.v2"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 )
 
 /*
@@ -46,6 +46,11 @@ func (rcy *RouteConfigYAML) validateConfig() error {
 
 // CompleteAndValidate sets default values, overrides by env and validates the resulting config
 func (rcy *RouteConfigYAML) CompleteAndValidate() error {
+	// Env Vars should override config file entries if present
+	cfg := Config{Route: RouteConfig(rcy.Route)}
+	cfg.FromEnv()
+	rcy.Route = RouteYAML(cfg.Route)
+
 	return rcy.validateConfig()
 }
 
@@ -57,7 +62,7 @@ func ReadRawConfigYAML(configData []byte) (*RouteConfigYAML, error) {
 
 	cfg := RouteConfigYAML{}
 
-	if err := yaml.Unmarshal(configData, &cfg); err != nil {
+	if err := vcfg.UnmarshalYAML(configData, &cfg); err != nil {
 		return nil, err
 	}
 
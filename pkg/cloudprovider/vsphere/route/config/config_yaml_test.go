@@ -17,6 +17,7 @@
 package config
 
 import (
+	"os"
 	"testing"
 )
 
@@ -43,3 +44,26 @@ route:
 	}
 	assertEquals("route.routerPath", config.Route.RouterPath, "/infra/tier-1s/test-router")
 }
+
+func TestReadYAMLConfigEnv(t *testing.T) {
+	os.Setenv("VSPHERE_ROUTE_ROUTER_PATH", "/infra/tier-1s/env-router")
+	defer os.Unsetenv("VSPHERE_ROUTE_ROUTER_PATH")
+
+	// the environment takes precedence over the file
+	config, err := ReadRawConfigYAML([]byte("route:\n  routerPath: /infra/tier-1s/test-router\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Route.RouterPath != "/infra/tier-1s/env-router" {
+		t.Errorf("route.routerPath %s != /infra/tier-1s/env-router", config.Route.RouterPath)
+	}
+
+	// and completes it before validation
+	config, err = ReadRawConfigYAML([]byte("route:\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Route.RouterPath != "/infra/tier-1s/env-router" {
+		t.Errorf("route.routerPath %s != /infra/tier-1s/env-router", config.Route.RouterPath)
+	}
+}
@@ -32,7 +32,9 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	cloudprovider "k8s.io/cloud-provider"
+	pb "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/proto"
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 	klog "k8s.io/klog/v2"
 )
 
@@ -41,6 +43,8 @@ type RoutesProvider interface {
 	cloudprovider.Routes
 	AddNode(*v1.Node)
 	DeleteNode(*v1.Node)
+	// ExportRoutes appends the static routes of the cluster to routeList
+	ExportRoutes(clusterName string, routeList *[]*pb.Route) error
 }
 
 type routeProvider struct {
@@ -69,12 +73,12 @@ func NewRouteProvider(cfg *config.Config, connector client.Connector) (RoutesPro
 }
 
 // ListRoutes returns a list of routes which have static routes on NSXT
-func (p *routeProvider) ListRoutes(ctx context.Context, clusterName string) ([]*cloudprovider.Route, error) {
-	queryParam := fmt.Sprintf("resource_type:StaticRoutes AND tags.scope:%s AND tags.tag:%s",
-		config.ClusterNameTagScope, clusterName)
-	staticRoutes, err := p.broker.QueryEntities(queryParam)
+func (p *routeProvider) ListRoutes(ctx context.Context, clusterName string) (_ []*cloudprovider.Route, err error) {
+	_, span := tracing.Start(ctx, "routes.ListRoutes", tracing.String("cluster", clusterName))
+	defer func() { span.End(err) }()
+
+	staticRoutes, err := p.queryStaticRoutes(clusterName)
 	if err != nil {
-		klog.Errorf("querying static routes for cluster %s failed", clusterName)
 		return nil, err
 	}
 	if *staticRoutes.ResultCount == 0 {
@@ -83,6 +87,72 @@ func (p *routeProvider) ListRoutes(ctx context.Context, clusterName string) ([]*
 	return p.generateRoutes(staticRoutes), nil
 }
 
+// ExportRoutes appends the static routes of the cluster, together with their
+// NSX-T path, next hops and realized state, to routeList
+func (p *routeProvider) ExportRoutes(clusterName string, routeList *[]*pb.Route) error {
+	staticRoutes, err := p.queryStaticRoutes(clusterName)
+	if err != nil {
+		return err
+	}
+	routes := p.generateRoutes(staticRoutes)
+	for i, item := range staticRoutes.Results {
+		route := routes[i]
+		path, err := item.String("path")
+		if err != nil || path == "" {
+			path = p.routerPath + "/static-routes/" + route.Name
+		}
+		state, err := p.staticRouteRealizedState(path)
+		if err != nil {
+			klog.Warningf("%s", err)
+		}
+		*routeList = append(*routeList, &pb.Route{
+			Name:            route.Name,
+			Node:            string(route.TargetNode),
+			DestinationCidr: route.DestinationCIDR,
+			Path:            path,
+			RealizedState:   state,
+			NextHops:        staticRouteNextHops(item),
+		})
+	}
+	return nil
+}
+
+// queryStaticRoutes searches the static routes tagged with the cluster name
+func (p *routeProvider) queryStaticRoutes(clusterName string) (model.SearchResponse, error) {
+	queryParam := fmt.Sprintf("resource_type:StaticRoutes AND tags.scope:%s AND tags.tag:%s",
+		config.ClusterNameTagScope, clusterName)
+	staticRoutes, err := p.broker.QueryEntities(queryParam)
+	if err != nil {
+		klog.Errorf("querying static routes for cluster %s failed", clusterName)
+		return model.SearchResponse{}, err
+	}
+	return staticRoutes, nil
+}
+
+// staticRouteNextHops returns the next hop IP addresses of a static route search result
+func staticRouteNextHops(item *data.StructValue) []string {
+	var nextHops []string
+	field, err := item.Field("next_hops")
+	if err != nil {
+		return nextHops
+	}
+	list, ok := field.(*data.ListValue)
+	if !ok {
+		return nextHops
+	}
+	for _, hop := range list.List() {
+		hopValue, ok := hop.(*data.StructValue)
+		if !ok {
+			continue
+		}
+		ipAddress, err := hopValue.String("ip_address")
+		if err == nil {
+			nextHops = append(nextHops, ipAddress)
+		}
+	}
+	return nextHops
+}
+
 // generateRoutes generates cloudprovider Routes based on NSXT static routes
 func (p *routeProvider) generateRoutes(staticRoutes model.SearchResponse) []*cloudprovider.Route {
 	var routes []*cloudprovider.Route
@@ -111,8 +181,11 @@ func (p *routeProvider) generateRoutes(staticRoutes model.SearchResponse) []*clo
 }
 
 // CreateRoute creates a static route on NSXT for a Node
-func (p *routeProvider) CreateRoute(ctx context.Context, clusterName string, nameHint string, route *cloudprovider.Route) error {
+func (p *routeProvider) CreateRoute(ctx context.Context, clusterName string, nameHint string, route *cloudprovider.Route) (err error) {
 	nodeName := string(route.TargetNode)
+	_, span := tracing.Start(ctx, "routes.CreateRoute",
+		tracing.String("node", nodeName), tracing.String("cidr", route.DestinationCIDR))
+	defer func() { span.End(err) }()
 	klog.V(6).Infof("Creating static route for node %s", nodeName)
 
 	nodeIP, err := p.getNodeIPAddress(nodeName, IsIPv4(route.DestinationCIDR))
@@ -154,10 +227,13 @@ func (p *routeProvider) generateStaticRoute(clusterName string, nameHint string,
 }
 
 // DeleteRoute deletes Node's static route on NSXT
-func (p *routeProvider) DeleteRoute(ctx context.Context, clusterName string, route *cloudprovider.Route) error {
+func (p *routeProvider) DeleteRoute(ctx context.Context, clusterName string, route *cloudprovider.Route) (err error) {
 	klog.V(6).Infof("Deleting static route %s on router %s in cluster %s",
 		route.Name, p.routerPath, clusterName)
-	err := p.broker.DeleteStaticRoute(p.routerPath, route.Name)
+	_, span := tracing.Start(ctx, "routes.DeleteRoute", tracing.String("route", route.Name))
+	defer func() { span.End(err) }()
+
+	err = p.broker.DeleteStaticRoute(p.routerPath, route.Name)
 	if err != nil {
 		klog.Errorf("deleting static route %s failed: %s", route.Name, err)
 		return err
@@ -178,20 +254,37 @@ func (p *routeProvider) checkStaticRouteRealizedState(routeID string) error {
 		case <-timeout:
 			return fmt.Errorf("timed out waiting for static route %s", path)
 		case <-ticker.C:
-			list, err := p.broker.ListRealizedEntities(path)
+			state, err := p.staticRouteRealizedState(path)
 			if err != nil {
-				return fmt.Errorf("get route %s realized state failed: %s", path, err)
+				return err
+			}
+			if state == config.RealizedState {
+				return nil
 			}
-			for _, resource := range list.Results {
-				if len(resource.IntentPaths) == 0 {
-					continue
-				}
-				if resource.IntentPaths[0] == path && *resource.State == config.RealizedState {
-					return nil
-				}
+		}
+	}
+}
+
+// staticRouteRealizedState returns the realized state of the static route with the given path,
+// preferring a realized entity if there are several
+func (p *routeProvider) staticRouteRealizedState(path string) (string, error) {
+	list, err := p.broker.ListRealizedEntities(path)
+	if err != nil {
+		return "", fmt.Errorf("get route %s realized state failed: %s", path, err)
+	}
+	state := ""
+	for _, resource := range list.Results {
+		if len(resource.IntentPaths) == 0 || resource.State == nil {
+			continue
+		}
+		if resource.IntentPaths[0] == path {
+			state = *resource.State
+			if state == config.RealizedState {
+				break
 			}
 		}
 	}
+	return state, nil
 }
 
 // getNodeIPAddress gets node IP address
@@ -29,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	cloudprovider "k8s.io/cloud-provider"
+	pb "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/proto"
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
 
 	"github.com/vmware/vsphere-automation-sdk-go/runtime/bindings"
@@ -109,6 +110,78 @@ func TestListRoutes(t *testing.T) {
 	assert.Equal(t, "100.96.1.0/24", route.DestinationCIDR, "DestinationCIDR should be 100.96.      1.0/24")
 }
 
+func TestExportRoutes(t *testing.T) {
+	response := `
+{
+  "results" : [ {
+    "display_name" : "62d347a4-1b70-435e-b92a-9a61453843ee_100.96.0.0_24",
+    "network" : "100.96.0.0/24",
+    "tags" : [ {
+      "scope" : "vsphere.k8s.io/cluster-name",
+      "tag" : "kubernetes"
+    }, {
+      "scope" : "vsphere.k8s.io/node-name",
+      "tag" : "node1"
+    } ],
+    "path" : "/infra/tier-1s/test-t1/static-routes/62d347a4-1b70-435e-b92a-9a61453843ee_100.96.0.0_24",
+    "id" : "62d347a4-1b70-435e-b92a-9a61453843ee_100.96.0.0_24",
+    "next_hops" : [ {
+      "ip_address" : "172.50.0.13",
+      "admin_distance" : 1
+    } ]
+  } ],
+  "result_count" : 1
+}
+`
+	realizedResponse := `
+{
+  "results" : [ {
+    "intent_paths" : [ "/infra/tier-1s/test-t1/static-routes/62d347a4-1b70-435e-b92a-9a61453843ee_100.96.0.0_24" ],
+    "resource_type" : "GenericPolicyRealizedResource",
+    "state" : "REALIZED"
+  } ],
+  "result_count" : 1
+}
+`
+	typeConverter := bindings.NewTypeConverter()
+	decode := func(response string, bindingType bindings.BindingType) interface{} {
+		d := json.NewDecoder(strings.NewReader(response))
+		d.UseNumber()
+		var jsondata interface{}
+		d.Decode(&jsondata)
+		decoder := cleanjson.NewJsonToDataValueDecoder()
+		dataValue, _ := decoder.Decode(jsondata)
+		output, _ := typeConverter.ConvertToGolang(dataValue, bindingType)
+		return output
+	}
+	output := decode(response, bindings.NewReferenceType(model.SearchResponseBindingType))
+	realized := decode(realizedResponse, bindings.NewReferenceType(model.GenericPolicyRealizedResourceListResultBindingType))
+	queryParam := "resource_type:StaticRoutes AND tags.scope:vsphere.k8s.io/cluster-name AND tags.tag:kubernetes"
+	routePath := "/infra/tier-1s/test-t1/static-routes/62d347a4-1b70-435e-b92a-9a61453843ee_100.96.0.0_24"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBroker := NewMockNsxtBroker(ctrl)
+	mockBroker.EXPECT().QueryEntities(queryParam).Return(output, nil)
+	mockBroker.EXPECT().ListRealizedEntities(routePath).Return(realized, nil)
+	p := &routeProvider{
+		routerPath: "/infra/tier-1s/test-t1",
+		broker:     mockBroker,
+	}
+	var routes []*pb.Route
+	err := p.ExportRoutes("kubernetes", &routes)
+
+	assert.Equal(t, nil, err, "Should not return error")
+	assert.Equal(t, 1, len(routes), "Should have 1 route")
+	route := routes[0]
+	assert.Equal(t, "62d347a4-1b70-435e-b92a-9a61453843ee_100.96.0.0_24", route.Name)
+	assert.Equal(t, "node1", route.Node, "Node name should be node1")
+	assert.Equal(t, "100.96.0.0/24", route.DestinationCidr, "DestinationCidr should be 100.96.0.0/24")
+	assert.Equal(t, routePath, route.Path)
+	assert.Equal(t, config.RealizedState, route.RealizedState, "Route should be realized")
+	assert.Equal(t, []string{"172.50.0.13"}, route.NextHops)
+}
+
 func TestGenerateRoute(t *testing.T) {
 	response := `
 {
@@ -19,6 +19,7 @@ limitations under the License.
 package server
 
 import (
+	"errors"
 	"log"
 	"net"
 	"time"
@@ -40,6 +41,16 @@ const (
 	RetryAttempts int = 3
 )
 
+var (
+	// ErrLoadBalancerNotEnabled is returned when load balancer inventory is
+	// requested but load balancer support is not configured.
+	ErrLoadBalancerNotEnabled = errors.New("load balancer support is not enabled")
+
+	// ErrRoutesNotEnabled is returned when route inventory is requested but
+	// route support is not configured.
+	ErrRoutesNotEnabled = errors.New("route support is not enabled")
+)
+
 // NodeManagerInterface describes types that can export a list of Kubernetes
 // nodes into the supplied slice address.
 type NodeManagerInterface interface {
@@ -47,24 +58,42 @@ type NodeManagerInterface interface {
 	ExportNodes(vcenter string, datacenter string, nodeList *[]*pb.Node) error
 }
 
+// LoadBalancerManagerInterface describes types that can export the NSX-T
+// load balancer objects backing Kubernetes services.
+type LoadBalancerManagerInterface interface {
+	GetLoadBalancer(namespace string, name string, lb *pb.LoadBalancer) error
+	ExportLoadBalancers(namespace string, lbList *[]*pb.LoadBalancer) error
+}
+
+// RouteManagerInterface describes types that can export the NSX-T static
+// routes of the cluster into the supplied slice address.
+type RouteManagerInterface interface {
+	ExportRoutes(routeList *[]*pb.Route) error
+}
+
 // GRPCServer describes an object that can start a gRPC server.
 type GRPCServer interface {
 	Start()
 }
 
 type server struct {
-	binding string
-	s       *grpc.Server
-	nodeMgr NodeManagerInterface
+	binding  string
+	s        *grpc.Server
+	nodeMgr  NodeManagerInterface
+	lbMgr    LoadBalancerManagerInterface
+	routeMgr RouteManagerInterface
 }
 
-// NewServer generates a new gRPC Server
-func NewServer(binding string, nodeMgr NodeManagerInterface) GRPCServer {
+// NewServer generates a new gRPC Server. The load balancer and route managers
+// are optional and may be nil if the corresponding support is not enabled.
+func NewServer(binding string, nodeMgr NodeManagerInterface, lbMgr LoadBalancerManagerInterface, routeMgr RouteManagerInterface) GRPCServer {
 	s := grpc.NewServer()
 	myServer := &server{
-		binding: binding,
-		s:       s,
-		nodeMgr: nodeMgr,
+		binding:  binding,
+		s:        s,
+		nodeMgr:  nodeMgr,
+		lbMgr:    lbMgr,
+		routeMgr: routeMgr,
 	}
 	pb.RegisterCloudProviderVsphereServer(s, myServer)
 	reflection.Register(s)
@@ -99,6 +128,54 @@ func (s *server) ListNodes(ctx context.Context, request *pb.ListNodesRequest) (*
 	return reply, nil
 }
 
+// GetLoadBalancer implements CloudProviderVsphere interface
+func (s *server) GetLoadBalancer(ctx context.Context, request *pb.GetLoadBalancerRequest) (*pb.GetLoadBalancerReply, error) {
+	reply := &pb.GetLoadBalancerReply{
+		LoadBalancer: &pb.LoadBalancer{},
+	}
+	if s.lbMgr == nil {
+		reply.Error = ErrLoadBalancerNotEnabled.Error()
+		return reply, nil
+	}
+	err := s.lbMgr.GetLoadBalancer(request.Namespace, request.Name, reply.LoadBalancer)
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	return reply, nil
+}
+
+// ListLoadBalancers implements CloudProviderVsphere interface
+func (s *server) ListLoadBalancers(ctx context.Context, request *pb.ListLoadBalancersRequest) (*pb.ListLoadBalancersReply, error) {
+	reply := &pb.ListLoadBalancersReply{
+		LoadBalancers: make([]*pb.LoadBalancer, 0),
+	}
+	if s.lbMgr == nil {
+		reply.Error = ErrLoadBalancerNotEnabled.Error()
+		return reply, nil
+	}
+	err := s.lbMgr.ExportLoadBalancers(request.Namespace, &reply.LoadBalancers)
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	return reply, nil
+}
+
+// ListRoutes implements CloudProviderVsphere interface
+func (s *server) ListRoutes(ctx context.Context, request *pb.ListRoutesRequest) (*pb.ListRoutesReply, error) {
+	reply := &pb.ListRoutesReply{
+		Routes: make([]*pb.Route, 0),
+	}
+	if s.routeMgr == nil {
+		reply.Error = ErrRoutesNotEnabled.Error()
+		return reply, nil
+	}
+	err := s.routeMgr.ExportRoutes(&reply.Routes)
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	return reply, nil
+}
+
 // GetVersion implements obtaining the version of the API server
 func (s *server) GetVersion(ctx context.Context, request *pb.VersionRequest) (*pb.VersionReply, error) {
 	return &pb.VersionReply{
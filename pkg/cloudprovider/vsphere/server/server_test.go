@@ -65,6 +65,48 @@ func (nm *fakeNodeMgr) ExportNodes(vcenter string, datacenter string, nodeList *
 	return nil
 }
 
+type fakeLBMgr struct{}
+
+func (lm *fakeLBMgr) GetLoadBalancer(namespace string, name string, lb *pb.LoadBalancer) error {
+	lb.Namespace = namespace
+	lb.Name = name
+	lb.Class = "default"
+	lb.IpAddress = "192.168.0.10"
+	lb.VirtualServers = append(lb.VirtualServers, &pb.VirtualServer{
+		Id:            "vs-1",
+		Path:          "/infra/lb-virtual-servers/vs-1",
+		RealizedState: "REALIZED",
+		IpAddress:     "192.168.0.10",
+		Ports:         []string{"80"},
+	})
+	return nil
+}
+
+func (lm *fakeLBMgr) ExportLoadBalancers(namespace string, lbList *[]*pb.LoadBalancer) error {
+	lb := &pb.LoadBalancer{}
+	if err := lm.GetLoadBalancer("default", "my-service", lb); err != nil {
+		return err
+	}
+	if namespace == "" || namespace == lb.Namespace {
+		*lbList = append(*lbList, lb)
+	}
+	return nil
+}
+
+type fakeRouteMgr struct{}
+
+func (rm *fakeRouteMgr) ExportRoutes(routeList *[]*pb.Route) error {
+	*routeList = append(*routeList, &pb.Route{
+		Name:            "node1_100.96.0.0_24",
+		Node:            "node1",
+		DestinationCidr: "100.96.0.0/24",
+		Path:            "/infra/tier-1s/t1/static-routes/node1_100.96.0.0_24",
+		RealizedState:   "REALIZED",
+		NextHops:        []string{"10.0.0.1"},
+	})
+	return nil
+}
+
 func TestGRPCServerNode(t *testing.T) {
 	//server
 	s := grpc.NewServer()
@@ -170,3 +212,131 @@ func TestGRPCServerVersion(t *testing.T) {
 		t.Errorf("GetVersion mismatch %s != %s", APIVersion, r.GetVersion())
 	}
 }
+
+func TestGRPCServerLoadBalancers(t *testing.T) {
+	//server
+	s := grpc.NewServer()
+	myServer := &server{
+		binding: vcfg.DefaultAPIBinding,
+		s:       s,
+		nodeMgr: &fakeNodeMgr{},
+		lbMgr:   &fakeLBMgr{},
+	}
+	pb.RegisterCloudProviderVsphereServer(s, myServer)
+	reflection.Register(s)
+
+	myServer.Start()
+	defer myServer.Stop()
+
+	//client
+	ctx, cancel := context.WithTimeout(context.Background(), (5 * time.Second))
+	defer cancel()
+
+	c, err := NewVSphereCloudProviderClient(ctx)
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+
+	r, err := c.GetLoadBalancer(ctx, &pb.GetLoadBalancerRequest{Namespace: "default", Name: "my-service"})
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+	if r.Error != "" {
+		t.Fatalf("unexpected error: %s", r.Error)
+	}
+	if r.LoadBalancer.Name != "my-service" || len(r.LoadBalancer.VirtualServers) != 1 {
+		t.Errorf("load balancer mismatch: %v", r.LoadBalancer)
+	}
+	if r.LoadBalancer.VirtualServers[0].RealizedState != "REALIZED" {
+		t.Errorf("realized state mismatch: %v", r.LoadBalancer.VirtualServers[0])
+	}
+
+	l, err := c.ListLoadBalancers(ctx, &pb.ListLoadBalancersRequest{})
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+	if len(l.LoadBalancers) != 1 {
+		t.Errorf("expected 1 load balancer, got %d", len(l.LoadBalancers))
+	}
+
+	l, err = c.ListLoadBalancers(ctx, &pb.ListLoadBalancersRequest{Namespace: "other"})
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+	if len(l.LoadBalancers) != 0 {
+		t.Errorf("expected no load balancers, got %d", len(l.LoadBalancers))
+	}
+}
+
+func TestGRPCServerRoutes(t *testing.T) {
+	//server
+	s := grpc.NewServer()
+	myServer := &server{
+		binding:  vcfg.DefaultAPIBinding,
+		s:        s,
+		nodeMgr:  &fakeNodeMgr{},
+		routeMgr: &fakeRouteMgr{},
+	}
+	pb.RegisterCloudProviderVsphereServer(s, myServer)
+	reflection.Register(s)
+
+	myServer.Start()
+	defer myServer.Stop()
+
+	//client
+	ctx, cancel := context.WithTimeout(context.Background(), (5 * time.Second))
+	defer cancel()
+
+	c, err := NewVSphereCloudProviderClient(ctx)
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+
+	r, err := c.ListRoutes(ctx, &pb.ListRoutesRequest{})
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+	if len(r.Routes) != 1 || r.Routes[0].Node != "node1" || len(r.Routes[0].NextHops) != 1 {
+		t.Errorf("routes mismatch: %v", r.Routes)
+	}
+}
+
+func TestGRPCServerInventoryNotEnabled(t *testing.T) {
+	//server
+	s := grpc.NewServer()
+	myServer := &server{
+		binding: vcfg.DefaultAPIBinding,
+		s:       s,
+		nodeMgr: &fakeNodeMgr{},
+	}
+	pb.RegisterCloudProviderVsphereServer(s, myServer)
+	reflection.Register(s)
+
+	myServer.Start()
+	defer myServer.Stop()
+
+	//client
+	ctx, cancel := context.WithTimeout(context.Background(), (5 * time.Second))
+	defer cancel()
+
+	c, err := NewVSphereCloudProviderClient(ctx)
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+
+	l, err := c.ListLoadBalancers(ctx, &pb.ListLoadBalancersRequest{})
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+	if l.Error != ErrLoadBalancerNotEnabled.Error() {
+		t.Errorf("unexpected error: %q", l.Error)
+	}
+
+	r, err := c.ListRoutes(ctx, &pb.ListRoutesRequest{})
+	if err != nil {
+		t.Fatalf("could not greet: %v", err)
+	}
+	if r.Error != ErrRoutesNotEnabled.Error() {
+		t.Errorf("unexpected error: %q", r.Error)
+	}
+}
@@ -20,16 +20,19 @@ import (
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
 	cloudprovider "k8s.io/cloud-provider"
 
 	ccfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/config"
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer"
 	lbcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/loadbalancer/config"
 	"k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route"
+	rcfg "k8s.io/cloud-provider-vsphere/pkg/cloudprovider/vsphere/route/config"
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
 	k8s "k8s.io/cloud-provider-vsphere/pkg/common/kubernetes"
 	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 	"k8s.io/cloud-provider-vsphere/pkg/nsxt"
+	ncfg "k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
 )
 
 // GRPCServer describes an object that can start a gRPC server.
@@ -48,6 +51,8 @@ type VSphere struct {
 	*/
 	// pluggable interfaces (tbd)
 	cfgLB        *lbcfg.LBConfig
+	cfgNSXT      *ncfg.Config
+	cfgRoute     *rcfg.Config
 	loadbalancer loadbalancer.LBProvider
 	routes       route.RoutesProvider
 
@@ -101,6 +106,9 @@ type NodeManager struct {
 	nodeRegUUIDMap map[string]*v1.Node
 	// ConnectionManager
 	connectionManager *cm.ConnectionManager
+	// client labels the nodes with the tags of the label categories, nil
+	// until the cloud provider is initialized
+	client clientset.Interface
 
 	// Reference to CPI-specific configuration
 	cfg *ccfg.CPIConfig
@@ -108,6 +116,7 @@ type NodeManager struct {
 	// Mutexes
 	nodeInfoLock    sync.RWMutex
 	nodeRegInfoLock sync.RWMutex
+	cfgLock         sync.RWMutex
 }
 
 type instances struct {
@@ -19,6 +19,7 @@ package vsphere
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/vmware/govmomi/vim25/mo"
 	klog "k8s.io/klog/v2"
@@ -26,7 +27,9 @@ import (
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	cloudprovider "k8s.io/cloud-provider"
 
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 )
 
 func newZones(nodeManager *NodeManager, zone string, region string) cloudprovider.Zones {
@@ -39,13 +42,39 @@ func newZones(nodeManager *NodeManager, zone string, region string) cloudprovide
 
 var _ cloudprovider.Zones = &zones{}
 
+// labels returns the tag categories of the zones and regions of the vCenter of
+// tenantRef, the ones of the Labels section unless the vCenter overrides them
+func (z *zones) labels(tenantRef string) vcfg.Labels {
+	labels := vcfg.Labels{Zone: z.zone, Region: z.region}
+	if vsi := z.nodeManager.connectionManager.Instance(tenantRef); vsi != nil {
+		labels = vsi.Cfg.ZoneLabels(labels)
+	}
+	return labels
+}
+
+// enabled returns true if the zones and regions of the nodes of any vCenter
+// are looked up
+func (z *zones) enabled() bool {
+	if len(z.region) != 0 && len(z.zone) != 0 {
+		return true
+	}
+	for tenantRef := range z.nodeManager.connectionManager.Instances() {
+		if labels := z.labels(tenantRef); len(labels.Region) != 0 && len(labels.Zone) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // GetZone implements Zones.GetZone for In-Tree providers
-func (z *zones) GetZone(ctx context.Context) (cloudprovider.Zone, error) {
+func (z *zones) GetZone(ctx context.Context) (_ cloudprovider.Zone, err error) {
 	klog.V(4).Info("zones.GetZone() called")
+	ctx, span := tracing.Start(ctx, "zones.GetZone")
+	defer func() { span.End(err) }()
 
 	zone := cloudprovider.Zone{}
 
-	if len(z.region) == 0 || len(z.zone) == 0 {
+	if !z.enabled() {
 		return zone, nil
 	}
 
@@ -55,11 +84,14 @@ func (z *zones) GetZone(ctx context.Context) (cloudprovider.Zone, error) {
 		return zone, err
 	}
 
-	node, ok := z.nodeManager.nodeNameMap[nodeName]
+	node, ok := z.nodeManager.cachedNodeByName(nodeName)
 	if !ok {
 		klog.V(2).Info("zones.GetZone() NOT FOUND with ", nodeName)
 		return zone, ErrVMNotFound
 	}
+	if labels := z.labels(node.tenantRef); len(labels.Region) == 0 || len(labels.Zone) == 0 {
+		return zone, nil
+	}
 
 	vmHost, err := node.vm.HostSystem(ctx)
 	if err != nil {
@@ -94,17 +126,30 @@ func (z *zones) GetZone(ctx context.Context) (cloudprovider.Zone, error) {
 func (z *zones) GetZoneByNodeName(ctx context.Context, nodeName k8stypes.NodeName) (cloudprovider.Zone, error) {
 	klog.V(4).Info("zones.GetZoneByNodeName() called with ", string(nodeName))
 
+	ctx, span := tracing.Start(ctx, "zones.GetZoneByNodeName", tracing.String("node", string(nodeName)))
+	start := time.Now()
+	zone, err := z.getZoneByNodeName(ctx, nodeName)
+	recordZoneLookup("node_name", start, err)
+	span.End(err)
+	return zone, err
+}
+
+func (z *zones) getZoneByNodeName(ctx context.Context, nodeName k8stypes.NodeName) (cloudprovider.Zone, error) {
+
 	zone := cloudprovider.Zone{}
 
-	if len(z.region) == 0 || len(z.zone) == 0 {
+	if !z.enabled() {
 		return zone, nil
 	}
 
-	node, ok := z.nodeManager.nodeNameMap[string(nodeName)]
+	node, ok := z.nodeManager.cachedNodeByName(string(nodeName))
 	if !ok {
 		klog.V(2).Info("zones.GetZoneByNodeName() NOT FOUND with ", string(nodeName))
 		return zone, ErrVMNotFound
 	}
+	if labels := z.labels(node.tenantRef); len(labels.Region) == 0 || len(labels.Zone) == 0 {
+		return zone, nil
+	}
 	klog.V(4).Infof("Getting zone/region for VM %s", node.NodeName)
 
 	vmHost, err := node.vm.HostSystem(ctx)
@@ -164,18 +209,31 @@ func (z *zones) GetZoneByNodeName(ctx context.Context, nodeName k8stypes.NodeNam
 func (z *zones) GetZoneByProviderID(ctx context.Context, providerID string) (cloudprovider.Zone, error) {
 	klog.V(4).Info("zones.GetZoneByProviderID() called with ", providerID)
 
+	ctx, span := tracing.Start(ctx, "zones.GetZoneByProviderID", tracing.String("provider_id", providerID))
+	start := time.Now()
+	zone, err := z.getZoneByProviderID(ctx, providerID)
+	recordZoneLookup("provider_id", start, err)
+	span.End(err)
+	return zone, err
+}
+
+func (z *zones) getZoneByProviderID(ctx context.Context, providerID string) (cloudprovider.Zone, error) {
+
 	zone := cloudprovider.Zone{}
 
-	if len(z.region) == 0 || len(z.zone) == 0 {
+	if !z.enabled() {
 		return zone, nil
 	}
 
 	uid := GetUUIDFromProviderID(providerID)
-	node, ok := z.nodeManager.nodeUUIDMap[uid]
+	node, ok := z.nodeManager.cachedNodeByUUID(uid)
 	if !ok {
 		klog.V(2).Info("zones.GetZoneByProviderID() NOT FOUND with ", uid)
 		return zone, ErrVMNotFound
 	}
+	if labels := z.labels(node.tenantRef); len(labels.Region) == 0 || len(labels.Zone) == 0 {
+		return zone, nil
+	}
 	klog.V(4).Infof("Getting zone/region for VM %s", node.NodeName)
 
 	vmHost, err := node.vm.HostSystem(ctx)
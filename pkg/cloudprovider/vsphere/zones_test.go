@@ -28,6 +28,7 @@ import (
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/connectionmanager"
 	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 )
@@ -230,3 +231,90 @@ func TestZones(t *testing.T) {
 		}
 	}
 }
+
+func TestZonesVirtualCenterLabels(t *testing.T) {
+	ctx := context.Background()
+
+	cfg, close := configFromEnvOrSim(false)
+	defer close()
+
+	// the categories of the vCenter override the ones of the Labels section
+	cfg.VirtualCenter[cfg.Global.VCenterIP].Labels = vcfg.Labels{
+		Zone:   "failure-domain",
+		Region: "failure-region",
+	}
+	cfg.Labels = vcfg.Labels{Zone: "k8s-zone"}
+
+	connMgr := cm.NewConnectionManager(cfg, nil, nil)
+	defer connMgr.Logout()
+
+	nm := newNodeManager(nil, connMgr)
+	zones := newZones(nm, cfg.Labels.Zone, cfg.Labels.Region)
+
+	vsi := connMgr.VsphereInstanceMap[cfg.Global.VCenterIP]
+	if err := connMgr.Connect(ctx, vsi); err != nil {
+		t.Fatalf("Failed to connect to vSphere: %s", err)
+	}
+
+	myvm := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+	myvm.Guest.HostName = myvm.Name
+	myvm.Guest.Net = []types.GuestNicInfo{
+		{
+			Network:   "foo-bar",
+			IpAddress: []string{"10.0.0.1"},
+		},
+	}
+	nm.RegisterNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: myvm.Name,
+		},
+		Status: v1.NodeStatus{
+			NodeInfo: v1.NodeSystemInfo{
+				SystemUUID: ConvertK8sUUIDtoNormal(myvm.Config.Uuid),
+			},
+		},
+	})
+
+	c := rest.NewClient(vsi.Conn.Client)
+	if err := c.Login(ctx, url.UserPassword(vsi.Conn.Username, vsi.Conn.Password)); err != nil {
+		t.Fatalf("Rest login failed. err=%v", err)
+	}
+	m := tags.NewManager(c)
+
+	host := myvm.Runtime.Host
+	for category, tag := range map[string]string{
+		"k8s-zone":       "k8s-zone-US-CA1",
+		"failure-domain": "fd-1",
+		"failure-region": "fr-1",
+	} {
+		categoryID, err := m.CreateCategory(ctx, &tags.Category{Name: category})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tagID, err := m.CreateTag(ctx, &tags.Tag{CategoryID: categoryID, Name: tag})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = m.AttachTag(ctx, tagID, host); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zone, err := zones.GetZoneByProviderID(ctx, myvm.Config.Uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone.FailureDomain != "fd-1" || zone.Region != "fr-1" {
+		t.Errorf("Expected the zone of the categories of the vCenter, got %#v", zone)
+	}
+
+	// without categories, the zone of the node is not looked up
+	vsi.Cfg.Labels = vcfg.Labels{}
+	zone, err = zones.GetZoneByProviderID(ctx, myvm.Config.Uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone.FailureDomain != "" || zone.Region != "" {
+		t.Errorf("Expected no zone without region category, got %#v", zone)
+	}
+}
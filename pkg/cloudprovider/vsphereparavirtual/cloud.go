@@ -48,6 +48,11 @@ const (
 var (
 	// SupervisorClusterSecret is the name of vsphere paravirtual supervisor cluster cloud provider secret
 	SupervisorClusterSecret = "cloud-provider-creds"
+
+	// CloudConfigDir is set by the main program to the drop-in directory of
+	// YAML fragments merged into the cloud config, in the order of their file
+	// names.
+	CloudConfigDir string
 )
 
 func init() {
@@ -61,6 +66,10 @@ func init() {
 		if err != nil {
 			return nil, fmt.Errorf("unable to read cloud configuration from %q [%v]", config, err)
 		}
+		data, err = cpcfg.MergeDropInDir("cloud config", data, CloudConfigDir)
+		if err != nil {
+			return nil, err
+		}
 
 		var cfg cpcfg.Config
 		err = yaml.Unmarshal(data, &cfg)
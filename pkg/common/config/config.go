@@ -17,11 +17,14 @@ limitations under the License.
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/validation"
 	klog "k8s.io/klog/v2"
 )
 
@@ -62,6 +65,15 @@ func getEnvKeyValue(match string, partial bool) (string, string, error) {
 // for a property that's already initialized, the environment variable's value
 // takes precedence.
 func (cfg *Config) FromEnv() error {
+	cfg.fromEnv()
+	cfg.Global.SecretsDirectory = secretsDirectory(cfg.Global.SecretsDirectory)
+	return nil
+}
+
+// fromEnv overrides the settings by the environment variables that are set and
+// returns the tenant refs of the vCenters configured by environment variables.
+func (cfg *Config) fromEnv() []string {
+	var vcenters []string
 
 	//Init
 	if cfg.VirtualCenter == nil {
@@ -122,14 +134,80 @@ func (cfg *Config) FromEnv() error {
 		cfg.Global.APIBinding = v
 	}
 
-	if v := os.Getenv("VSPHERE_SECRETS_DIRECTORY"); v != "" {
-		cfg.Global.SecretsDirectory = v
+	if v := os.Getenv("VSPHERE_SESSION_KEEPALIVE_INTERVAL"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_SESSION_KEEPALIVE_INTERVAL: %s", err)
+		} else {
+			cfg.Global.SessionKeepAliveInterval = uint(tmp)
+		}
+	}
+
+	if v := os.Getenv("VSPHERE_SESSION_MAX_AGE"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_SESSION_MAX_AGE: %s", err)
+		} else {
+			cfg.Global.SessionMaxAge = uint(tmp)
+		}
+	}
+
+	if v := os.Getenv("VSPHERE_DISCOVERY_TIMEOUT"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_DISCOVERY_TIMEOUT: %s", err)
+		} else {
+			cfg.Global.DiscoveryTimeout = uint(tmp)
+		}
+	}
+
+	if v := os.Getenv("VSPHERE_OPERATION_TIMEOUT"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_OPERATION_TIMEOUT: %s", err)
+		} else {
+			cfg.Global.OperationTimeout = uint(tmp)
+		}
+	}
+
+	if v := os.Getenv("VSPHERE_CIRCUIT_BREAKER_THRESHOLD"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_CIRCUIT_BREAKER_THRESHOLD: %s", err)
+		} else {
+			cfg.Global.CircuitBreakerThreshold = uint(tmp)
+		}
 	}
-	if cfg.Global.SecretsDirectory == "" {
-		cfg.Global.SecretsDirectory = DefaultSecretDirectory
+
+	if v := os.Getenv("VSPHERE_CIRCUIT_BREAKER_OPEN_INTERVAL"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_CIRCUIT_BREAKER_OPEN_INTERVAL: %s", err)
+		} else {
+			cfg.Global.CircuitBreakerOpenInterval = uint(tmp)
+		}
 	}
-	if _, err := os.Stat(cfg.Global.SecretsDirectory); os.IsNotExist(err) {
-		cfg.Global.SecretsDirectory = "" //Dir does not exist, set to empty string
+
+	if v := os.Getenv("VSPHERE_CIRCUIT_BREAKER_MAX_OPEN_INTERVAL"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_CIRCUIT_BREAKER_MAX_OPEN_INTERVAL: %s", err)
+		} else {
+			cfg.Global.CircuitBreakerMaxOpenInterval = uint(tmp)
+		}
+	}
+
+	if v := os.Getenv(StrictConfigEnv); v != "" {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			klog.Errorf("Failed to parse %s: %s", StrictConfigEnv, err)
+		} else {
+			cfg.Global.StrictConfig = strict
+		}
+	}
+
+	if v := os.Getenv("VSPHERE_SECRETS_DIRECTORY"); v != "" {
+		cfg.Global.SecretsDirectory = v
 	}
 
 	if v := os.Getenv("VSPHERE_CAFILE"); v != "" {
@@ -138,12 +216,59 @@ func (cfg *Config) FromEnv() error {
 	if v := os.Getenv("VSPHERE_THUMBPRINT"); v != "" {
 		cfg.Global.Thumbprint = v
 	}
+	if v := os.Getenv("VSPHERE_CERT_FILE"); v != "" {
+		cfg.Global.CertFile = v
+	}
+	if v := os.Getenv("VSPHERE_KEY_FILE"); v != "" {
+		cfg.Global.KeyFile = v
+	}
+	if v := os.Getenv("VSPHERE_PROXY_URL"); v != "" {
+		cfg.Global.ProxyURL = v
+	}
+	if v := os.Getenv("VSPHERE_CONNECT_TIMEOUT"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_CONNECT_TIMEOUT: %s", err)
+		} else {
+			cfg.Global.ConnectTimeout = uint(tmp)
+		}
+	}
+	if v := os.Getenv("VSPHERE_REQUEST_TIMEOUT"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_REQUEST_TIMEOUT: %s", err)
+		} else {
+			cfg.Global.RequestTimeout = uint(tmp)
+		}
+	}
+	if v := os.Getenv("VSPHERE_TLS_MIN_VERSION"); v != "" {
+		cfg.Global.TLSMinVersion = v
+	}
+	if v := os.Getenv("VSPHERE_RATE_LIMIT_QPS"); v != "" {
+		tmp, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_RATE_LIMIT_QPS: %s", err)
+		} else {
+			cfg.Global.RateLimitQPS = float32(tmp)
+		}
+	}
+	if v := os.Getenv("VSPHERE_RATE_LIMIT_BURST"); v != "" {
+		tmp, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			klog.Errorf("Failed to parse VSPHERE_RATE_LIMIT_BURST: %s", err)
+		} else {
+			cfg.Global.RateLimitBurst = uint(tmp)
+		}
+	}
 	if v := os.Getenv("VSPHERE_LABEL_REGION"); v != "" {
 		cfg.Labels.Region = v
 	}
 	if v := os.Getenv("VSPHERE_LABEL_ZONE"); v != "" {
 		cfg.Labels.Zone = v
 	}
+	if v := os.Getenv("VSPHERE_LABEL_CATEGORIES"); v != "" {
+		cfg.Labels.Categories = SplitLabelCategories(v)
+	}
 
 	//Build VirtualCenter from ENVs
 	for _, e := range os.Environ() {
@@ -204,6 +329,46 @@ func (cfg *Config) FromEnv() error {
 			if errThumbprint != nil {
 				thumbprint = cfg.Global.Thumbprint
 			}
+			_, certFile, errCertFile := getEnvKeyValue("VCENTER_"+id+"_CERT_FILE", false)
+			if errCertFile != nil {
+				certFile = cfg.Global.CertFile
+			}
+			_, keyFile, errKeyFile := getEnvKeyValue("VCENTER_"+id+"_KEY_FILE", false)
+			if errKeyFile != nil {
+				keyFile = cfg.Global.KeyFile
+			}
+			_, proxyURL, errProxyURL := getEnvKeyValue("VCENTER_"+id+"_PROXY_URL", false)
+			if errProxyURL != nil {
+				proxyURL = cfg.Global.ProxyURL
+			}
+			connectTimeout := cfg.Global.ConnectTimeout
+			if _, v, err := getEnvKeyValue("VCENTER_"+id+"_CONNECT_TIMEOUT", false); err == nil {
+				if tmp, err := strconv.ParseUint(v, 10, 32); err == nil {
+					connectTimeout = uint(tmp)
+				}
+			}
+			requestTimeout := cfg.Global.RequestTimeout
+			if _, v, err := getEnvKeyValue("VCENTER_"+id+"_REQUEST_TIMEOUT", false); err == nil {
+				if tmp, err := strconv.ParseUint(v, 10, 32); err == nil {
+					requestTimeout = uint(tmp)
+				}
+			}
+			_, tlsMinVersion, errTLSMinVersion := getEnvKeyValue("VCENTER_"+id+"_TLS_MIN_VERSION", false)
+			if errTLSMinVersion != nil {
+				tlsMinVersion = cfg.Global.TLSMinVersion
+			}
+			rateLimitQPS := cfg.Global.RateLimitQPS
+			if _, v, err := getEnvKeyValue("VCENTER_"+id+"_RATE_LIMIT_QPS", false); err == nil {
+				if tmp, err := strconv.ParseFloat(v, 32); err == nil {
+					rateLimitQPS = float32(tmp)
+				}
+			}
+			rateLimitBurst := cfg.Global.RateLimitBurst
+			if _, v, err := getEnvKeyValue("VCENTER_"+id+"_RATE_LIMIT_BURST", false); err == nil {
+				if tmp, err := strconv.ParseUint(v, 10, 32); err == nil {
+					rateLimitBurst = uint(tmp)
+				}
+			}
 
 			_, secretName, secretNameErr := getEnvKeyValue("VCENTER_"+id+"_SECRET_NAME", false)
 			_, secretNamespace, secretNamespaceErr := getEnvKeyValue("VCENTER_"+id+"_SECRET_NAMESPACE", false)
@@ -249,14 +414,35 @@ func (cfg *Config) FromEnv() error {
 			vcc.RoundTripperCount = roundtrip
 			vcc.CAFile = caFile
 			vcc.Thumbprint = thumbprint
+			vcc.CertFile = certFile
+			vcc.KeyFile = keyFile
+			vcc.ProxyURL = proxyURL
+			vcc.ConnectTimeout = connectTimeout
+			vcc.RequestTimeout = requestTimeout
+			vcc.TLSMinVersion = tlsMinVersion
+			vcc.RateLimitQPS = rateLimitQPS
+			vcc.RateLimitBurst = rateLimitBurst
 			vcc.SecretRef = secretRef
 			vcc.SecretName = secretName
 			vcc.SecretNamespace = secretNamespace
 			vcc.IPFamilyPriority = iPFamilyPriority
+			vcenters = append(vcenters, tenantRef)
 		}
 	}
 
-	return nil
+	return vcenters
+}
+
+// secretsDirectory returns the secrets directory to read, the default one if
+// dir is empty, or an empty string if it does not exist.
+func secretsDirectory(dir string) string {
+	if dir == "" {
+		dir = DefaultSecretDirectory
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "" //Dir does not exist, set to empty string
+	}
+	return dir
 }
 
 /*
@@ -266,7 +452,7 @@ func (cfg *Config) FromEnv() error {
 */
 
 // ReadConfig parses vSphere cloud config file and stores it into VSphereConfig.
-// Environment variables are also checked
+// Environment variables are also checked, before the config is validated
 func ReadConfig(byConfig []byte) (*Config, error) {
 	if len(byConfig) == 0 {
 		return nil, fmt.Errorf("Invalid YAML/INI file")
@@ -287,12 +473,92 @@ func ReadConfig(byConfig []byte) (*Config, error) {
 		klog.Info("ReadConfig YAML succeeded")
 	}
 
-	// Env Vars should override config file entries if present
-	if err := cfg.FromEnv(); err != nil {
-		klog.Errorf("FromEnv failed: %s", err)
-		return nil, err
-	}
-
 	klog.Info("Config initialized")
 	return cfg, nil
 }
+
+// ZoneLabels returns the tag categories of the zones and regions of the
+// vCenter. A category not overridden for the vCenter is the one of defaults,
+// usually the Labels section.
+func (vcc *VirtualCenterConfig) ZoneLabels(defaults Labels) Labels {
+	labels := defaults
+	if vcc.Labels.Zone != "" {
+		labels.Zone = vcc.Labels.Zone
+	}
+	if vcc.Labels.Region != "" {
+		labels.Region = vcc.Labels.Region
+	}
+	return labels
+}
+
+// LabelCategories returns the tag categories whose tags are set as labels of
+// the nodes of the vCenter, the ones of defaults unless the vCenter overrides
+// them.
+func (vcc *VirtualCenterConfig) LabelCategories(defaults []string) []string {
+	if len(vcc.Labels.Categories) > 0 {
+		return vcc.Labels.Categories
+	}
+	return defaults
+}
+
+// SplitLabelCategories splits a comma separated list of tag categories.
+func SplitLabelCategories(categories string) []string {
+	var result []string
+	for _, category := range strings.Split(categories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			result = append(result, category)
+		}
+	}
+	return result
+}
+
+// validateLabelCategories validates that the tag categories, prefixed with
+// TagLabelPrefix, are valid names of labels.
+func validateLabelCategories(categories []string) error {
+	for _, category := range categories {
+		if errs := validation.IsQualifiedName(TagLabelPrefix + category); len(errs) > 0 {
+			klog.Errorf("Invalid label category %q: %s", category, strings.Join(errs, ", "))
+			return ErrInvalidLabelCategory
+		}
+	}
+	return nil
+}
+
+// IsExternal returns true if the credentials are obtained from an external
+// provider rather than from the configured secret or secrets directory.
+func (cp CredentialProvider) IsExternal() bool {
+	return cp.Type != "" && cp.Type != CredentialProviderSecret
+}
+
+// tlsVersions maps the supported minimum TLS versions to their crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSVersion returns the crypto/tls constant of a TLS version like 1.2, or zero
+// if the version is empty.
+func TLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return 0, nil
+	}
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, ErrInvalidTLSMinVersion
+	}
+	return v, nil
+}
+
+// validateTransport validates the proxy URL and minimum TLS version of a vCenter.
+func validateTransport(proxyURL string, tlsMinVersion string) error {
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return ErrInvalidProxyURL
+		}
+	}
+	_, err := TLSVersion(tlsMinVersion)
+	return err
+}
@@ -45,11 +45,29 @@ func (cci *CommonConfigINI) CreateConfig() *Config {
 	cfg.Global.RoundTripperCount = cci.Global.RoundTripperCount
 	cfg.Global.CAFile = cci.Global.CAFile
 	cfg.Global.Thumbprint = cci.Global.Thumbprint
+	cfg.Global.CertFile = cci.Global.CertFile
+	cfg.Global.KeyFile = cci.Global.KeyFile
+	cfg.Global.ProxyURL = cci.Global.ProxyURL
+	cfg.Global.ConnectTimeout = cci.Global.ConnectTimeout
+	cfg.Global.RequestTimeout = cci.Global.RequestTimeout
+	cfg.Global.TLSMinVersion = cci.Global.TLSMinVersion
+	cfg.Global.RateLimitQPS = cci.Global.RateLimitQPS
+	cfg.Global.RateLimitBurst = cci.Global.RateLimitBurst
 	cfg.Global.SecretName = cci.Global.SecretName
 	cfg.Global.SecretNamespace = cci.Global.SecretNamespace
 	cfg.Global.SecretsDirectory = cci.Global.SecretsDirectory
 	cfg.Global.APIDisable = cci.Global.APIDisable
 	cfg.Global.APIBinding = cci.Global.APIBinding
+	cfg.Global.SessionKeepAliveInterval = DefaultSessionKeepAliveInterval
+	if cci.Global.SessionKeepAliveInterval != nil {
+		cfg.Global.SessionKeepAliveInterval = *cci.Global.SessionKeepAliveInterval
+	}
+	cfg.Global.SessionMaxAge = cci.Global.SessionMaxAge
+	cfg.Global.DiscoveryTimeout = cci.Global.DiscoveryTimeout
+	cfg.Global.OperationTimeout = cci.Global.OperationTimeout
+	cfg.Global.CircuitBreakerThreshold = cci.Global.CircuitBreakerThreshold
+	cfg.Global.CircuitBreakerOpenInterval = cci.Global.CircuitBreakerOpenInterval
+	cfg.Global.CircuitBreakerMaxOpenInterval = cci.Global.CircuitBreakerMaxOpenInterval
 
 	for keyVcConfig, valVcConfig := range cci.VirtualCenter {
 		cfg.VirtualCenter[keyVcConfig] = &VirtualCenterConfig{
@@ -63,15 +81,29 @@ func (cci *CommonConfigINI) CreateConfig() *Config {
 			RoundTripperCount: valVcConfig.RoundTripperCount,
 			CAFile:            valVcConfig.CAFile,
 			Thumbprint:        valVcConfig.Thumbprint,
+			CertFile:          valVcConfig.CertFile,
+			KeyFile:           valVcConfig.KeyFile,
+			ProxyURL:          valVcConfig.ProxyURL,
+			ConnectTimeout:    valVcConfig.ConnectTimeout,
+			RequestTimeout:    valVcConfig.RequestTimeout,
+			TLSMinVersion:     valVcConfig.TLSMinVersion,
+			RateLimitQPS:      valVcConfig.RateLimitQPS,
+			RateLimitBurst:    valVcConfig.RateLimitBurst,
 			SecretRef:         valVcConfig.SecretRef,
 			SecretName:        valVcConfig.SecretName,
 			SecretNamespace:   valVcConfig.SecretNamespace,
 			IPFamilyPriority:  valVcConfig.IPFamilyPriority,
+			Labels: Labels{
+				Zone:       valVcConfig.Zone,
+				Region:     valVcConfig.Region,
+				Categories: SplitLabelCategories(valVcConfig.LabelCategories),
+			},
 		}
 	}
 
 	cfg.Labels.Region = cci.Labels.Region
 	cfg.Labels.Zone = cci.Labels.Zone
+	cfg.Labels.Categories = SplitLabelCategories(cci.Labels.Categories)
 
 	return cfg
 }
@@ -113,6 +145,78 @@ func (vcci *VirtualCenterConfigINI) isSecretInfoProvided() bool {
 	return vcci.SecretName != "" && vcci.SecretNamespace != ""
 }
 
+// fromEnv overrides the settings by the environment variables that are set,
+// so that they are validated like the ones of the file.
+func (cci *CommonConfigINI) fromEnv() {
+	cfg := cci.CreateConfig()
+	vcenters := cfg.fromEnv()
+	keepAliveInterval := cfg.Global.SessionKeepAliveInterval
+
+	cci.Global.User = cfg.Global.User
+	cci.Global.Password = cfg.Global.Password
+	cci.Global.VCenterIP = cfg.Global.VCenterIP
+	cci.Global.VCenterPort = cfg.Global.VCenterPort
+	cci.Global.InsecureFlag = cfg.Global.InsecureFlag
+	cci.Global.Datacenters = cfg.Global.Datacenters
+	cci.Global.RoundTripperCount = cfg.Global.RoundTripperCount
+	cci.Global.CAFile = cfg.Global.CAFile
+	cci.Global.Thumbprint = cfg.Global.Thumbprint
+	cci.Global.CertFile = cfg.Global.CertFile
+	cci.Global.KeyFile = cfg.Global.KeyFile
+	cci.Global.ProxyURL = cfg.Global.ProxyURL
+	cci.Global.ConnectTimeout = cfg.Global.ConnectTimeout
+	cci.Global.RequestTimeout = cfg.Global.RequestTimeout
+	cci.Global.TLSMinVersion = cfg.Global.TLSMinVersion
+	cci.Global.RateLimitQPS = cfg.Global.RateLimitQPS
+	cci.Global.RateLimitBurst = cfg.Global.RateLimitBurst
+	cci.Global.SecretName = cfg.Global.SecretName
+	cci.Global.SecretNamespace = cfg.Global.SecretNamespace
+	cci.Global.SecretsDirectory = cfg.Global.SecretsDirectory
+	cci.Global.APIDisable = cfg.Global.APIDisable
+	cci.Global.APIBinding = cfg.Global.APIBinding
+	cci.Global.SessionKeepAliveInterval = &keepAliveInterval
+	cci.Global.SessionMaxAge = cfg.Global.SessionMaxAge
+	cci.Global.DiscoveryTimeout = cfg.Global.DiscoveryTimeout
+	cci.Global.OperationTimeout = cfg.Global.OperationTimeout
+	cci.Global.CircuitBreakerThreshold = cfg.Global.CircuitBreakerThreshold
+	cci.Global.CircuitBreakerOpenInterval = cfg.Global.CircuitBreakerOpenInterval
+	cci.Global.CircuitBreakerMaxOpenInterval = cfg.Global.CircuitBreakerMaxOpenInterval
+	cci.Labels.Region = cfg.Labels.Region
+	cci.Labels.Zone = cfg.Labels.Zone
+	cci.Labels.Categories = strings.Join(cfg.Labels.Categories, ",")
+
+	for _, tenantRef := range vcenters {
+		vcc := cfg.VirtualCenter[tenantRef]
+		vcConfig := cci.VirtualCenter[tenantRef]
+		if vcConfig == nil {
+			vcConfig = &VirtualCenterConfigINI{}
+			cci.VirtualCenter[tenantRef] = vcConfig
+		}
+		vcConfig.User = vcc.User
+		vcConfig.Password = vcc.Password
+		vcConfig.TenantRef = vcc.TenantRef
+		vcConfig.VCenterIP = vcc.VCenterIP
+		vcConfig.VCenterPort = vcc.VCenterPort
+		vcConfig.InsecureFlag = vcc.InsecureFlag
+		vcConfig.Datacenters = vcc.Datacenters
+		vcConfig.RoundTripperCount = vcc.RoundTripperCount
+		vcConfig.CAFile = vcc.CAFile
+		vcConfig.Thumbprint = vcc.Thumbprint
+		vcConfig.CertFile = vcc.CertFile
+		vcConfig.KeyFile = vcc.KeyFile
+		vcConfig.ProxyURL = vcc.ProxyURL
+		vcConfig.ConnectTimeout = vcc.ConnectTimeout
+		vcConfig.RequestTimeout = vcc.RequestTimeout
+		vcConfig.TLSMinVersion = vcc.TLSMinVersion
+		vcConfig.RateLimitQPS = vcc.RateLimitQPS
+		vcConfig.RateLimitBurst = vcc.RateLimitBurst
+		vcConfig.SecretRef = vcc.SecretRef
+		vcConfig.SecretName = vcc.SecretName
+		vcConfig.SecretNamespace = vcc.SecretNamespace
+		vcConfig.IPFamily = strings.Join(vcc.IPFamilyPriority, ",")
+	}
+}
+
 func (cci *CommonConfigINI) validateConfig() error {
 	//Fix default global values
 	if cci.Global.RoundTripperCount == 0 {
@@ -124,6 +228,24 @@ func (cci *CommonConfigINI) validateConfig() error {
 	if cci.Global.APIBinding == "" {
 		cci.Global.APIBinding = DefaultAPIBinding
 	}
+	if cci.Global.DiscoveryTimeout == 0 {
+		cci.Global.DiscoveryTimeout = DefaultDiscoveryTimeout
+	}
+	if cci.Global.OperationTimeout == 0 {
+		cci.Global.OperationTimeout = DefaultOperationTimeout
+	}
+	if cci.Global.CircuitBreakerThreshold == 0 {
+		cci.Global.CircuitBreakerThreshold = DefaultCircuitBreakerThreshold
+	}
+	if cci.Global.CircuitBreakerOpenInterval == 0 {
+		cci.Global.CircuitBreakerOpenInterval = DefaultCircuitBreakerOpenInterval
+	}
+	if cci.Global.CircuitBreakerMaxOpenInterval == 0 {
+		cci.Global.CircuitBreakerMaxOpenInterval = DefaultCircuitBreakerMaxOpenInterval
+	}
+	if cci.Global.RateLimitBurst == 0 {
+		cci.Global.RateLimitBurst = DefaultRateLimitBurst
+	}
 	if cci.Global.IPFamily == "" {
 		cci.Global.IPFamily = DefaultIPFamily
 	}
@@ -142,6 +264,14 @@ func (cci *CommonConfigINI) validateConfig() error {
 			RoundTripperCount: cci.Global.RoundTripperCount,
 			CAFile:            cci.Global.CAFile,
 			Thumbprint:        cci.Global.Thumbprint,
+			CertFile:          cci.Global.CertFile,
+			KeyFile:           cci.Global.KeyFile,
+			ProxyURL:          cci.Global.ProxyURL,
+			ConnectTimeout:    cci.Global.ConnectTimeout,
+			RequestTimeout:    cci.Global.RequestTimeout,
+			TLSMinVersion:     cci.Global.TLSMinVersion,
+			RateLimitQPS:      cci.Global.RateLimitQPS,
+			RateLimitBurst:    cci.Global.RateLimitBurst,
 			SecretRef:         DefaultCredentialManager,
 			SecretName:        cci.Global.SecretName,
 			SecretNamespace:   cci.Global.SecretNamespace,
@@ -149,6 +279,10 @@ func (cci *CommonConfigINI) validateConfig() error {
 		}
 	}
 
+	if err := validateLabelCategories(SplitLabelCategories(cci.Labels.Categories)); err != nil {
+		return err
+	}
+
 	// Must have at least one vCenter defined
 	if len(cci.VirtualCenter) == 0 {
 		klog.Error(ErrMissingVCenter)
@@ -173,7 +307,16 @@ func (cci *CommonConfigINI) validateConfig() error {
 			vcConfig.TenantRef = vcServer
 		}
 
-		if !cci.isSecretInfoProvided() && !vcConfig.isSecretInfoProvided() {
+		if vcConfig.CertFile == "" && vcConfig.KeyFile == "" && vcConfig.User == "" {
+			vcConfig.CertFile = cci.Global.CertFile
+			vcConfig.KeyFile = cci.Global.KeyFile
+		}
+		if (vcConfig.CertFile == "") != (vcConfig.KeyFile == "") {
+			klog.Errorf("vcConfig.CertFile and vcConfig.KeyFile must be set together for vc %s!", vcServer)
+			return ErrCertificateKeyPairIncomplete
+		}
+
+		if !cci.isSecretInfoProvided() && !vcConfig.isSecretInfoProvided() && vcConfig.CertFile == "" {
 			if vcConfig.User == "" {
 				vcConfig.User = cci.Global.User
 				if vcConfig.User == "" {
@@ -212,6 +355,36 @@ func (cci *CommonConfigINI) validateConfig() error {
 		if vcConfig.Thumbprint == "" {
 			vcConfig.Thumbprint = cci.Global.Thumbprint
 		}
+		if vcConfig.ProxyURL == "" {
+			vcConfig.ProxyURL = cci.Global.ProxyURL
+		}
+		if vcConfig.ConnectTimeout == 0 {
+			vcConfig.ConnectTimeout = cci.Global.ConnectTimeout
+		}
+		if vcConfig.RequestTimeout == 0 {
+			vcConfig.RequestTimeout = cci.Global.RequestTimeout
+		}
+		if vcConfig.TLSMinVersion == "" {
+			vcConfig.TLSMinVersion = cci.Global.TLSMinVersion
+		}
+		if vcConfig.RateLimitQPS == 0 {
+			vcConfig.RateLimitQPS = cci.Global.RateLimitQPS
+		}
+		if vcConfig.RateLimitBurst == 0 {
+			vcConfig.RateLimitBurst = cci.Global.RateLimitBurst
+		}
+		if vcConfig.RateLimitQPS < 0 {
+			klog.Errorf("Invalid rate limit for vc %s: %v", vcServer, ErrInvalidRateLimit)
+			return ErrInvalidRateLimit
+		}
+		if err := validateTransport(vcConfig.ProxyURL, vcConfig.TLSMinVersion); err != nil {
+			klog.Errorf("Invalid transport settings for vc %s: %v", vcServer, err)
+			return err
+		}
+		if err := validateLabelCategories(SplitLabelCategories(vcConfig.LabelCategories)); err != nil {
+			klog.Errorf("Invalid label categories for vc %s: %v", vcServer, err)
+			return err
+		}
 
 		if vcConfig.IPFamily == "" {
 			vcConfig.IPFamily = cci.Global.IPFamily
@@ -248,10 +421,14 @@ func ReadRawConfigINI(byConfig []byte) (*CommonConfigINI, error) {
 		return nil, err
 	}
 
+	// Env Vars should override config file entries if present
+	cfg.fromEnv()
+
 	err := cfg.validateConfig()
 	if err != nil {
 		return nil, err
 	}
+	cfg.Global.SecretsDirectory = secretsDirectory(cfg.Global.SecretsDirectory)
 
 	return cfg, nil
 }
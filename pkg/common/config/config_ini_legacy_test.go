@@ -17,6 +17,8 @@ limitations under the License.
 package config
 
 import (
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -218,3 +220,186 @@ func TestTenantRefsINI(t *testing.T) {
 		t.Errorf("vcConfig3 SecretRef should be kube-system/eu-secret but actual=%s", vcConfig3.SecretRef)
 	}
 }
+
+func TestSolutionUserINI(t *testing.T) {
+	cfg, err := ReadConfigINI([]byte(`
+[Global]
+port = 443
+datacenters = us-west
+cert-file = /etc/cloud/k8s-vcp.crt
+key-file = /etc/cloud/k8s-vcp.key
+
+[VirtualCenter "10.0.0.1"]
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	vcConfig := cfg.VirtualCenter["10.0.0.1"]
+	if vcConfig.CertFile != "/etc/cloud/k8s-vcp.crt" || vcConfig.KeyFile != "/etc/cloud/k8s-vcp.key" {
+		t.Errorf("vcConfig should use the global key pair but actual=%s/%s", vcConfig.CertFile, vcConfig.KeyFile)
+	}
+}
+
+func TestTransportINI(t *testing.T) {
+	cfg, err := ReadConfigINI([]byte(`
+[Global]
+port = 443
+user = user
+password = password
+datacenters = us-west
+proxy-url = http://proxy.example.com:3128
+connect-timeout = 10
+tls-min-version = 1.2
+
+[VirtualCenter "10.0.0.1"]
+request-timeout = 300
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	vcConfig := cfg.VirtualCenter["10.0.0.1"]
+	if vcConfig.ProxyURL != "http://proxy.example.com:3128" || vcConfig.ConnectTimeout != 10 ||
+		vcConfig.RequestTimeout != 300 || vcConfig.TLSMinVersion != "1.2" {
+		t.Errorf("vcConfig should use the global transport settings but actual=%+v", vcConfig)
+	}
+
+	_, err = ReadConfigINI([]byte(`
+[Global]
+server = 10.0.0.1
+user = user
+password = password
+datacenters = us-west
+proxy-url = proxy.example.com
+`))
+	if err != ErrInvalidProxyURL {
+		t.Errorf("Should fail when the proxy URL is not absolute: %v", err)
+	}
+}
+
+func TestRateLimitINI(t *testing.T) {
+	cfg, err := ReadConfigINI([]byte(`
+[Global]
+port = 443
+user = user
+password = password
+datacenters = us-west
+rate-limit-qps = 7.5
+
+[VirtualCenter "10.0.0.1"]
+rate-limit-burst = 20
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	vcConfig := cfg.VirtualCenter["10.0.0.1"]
+	if vcConfig.RateLimitQPS != 7.5 || vcConfig.RateLimitBurst != 20 {
+		t.Errorf("vcConfig should use the global QPS and its own burst but actual=%v/%d", vcConfig.RateLimitQPS, vcConfig.RateLimitBurst)
+	}
+}
+
+func TestTimeoutsINI(t *testing.T) {
+	cfg, err := ReadConfigINI([]byte(`
+[Global]
+server = 10.0.0.1
+user = user
+password = password
+datacenters = us-west
+discovery-timeout = 30
+session-keepalive-interval = 0
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	if cfg.Global.DiscoveryTimeout != 30 || cfg.Global.OperationTimeout != DefaultOperationTimeout {
+		t.Errorf("incorrect timeouts: %d/%d", cfg.Global.DiscoveryTimeout, cfg.Global.OperationTimeout)
+	}
+
+	if cfg.Global.SessionKeepAliveInterval != 0 {
+		t.Errorf("session monitor should be disabled, got interval %d", cfg.Global.SessionKeepAliveInterval)
+	}
+}
+
+func TestVirtualCenterLabelsINI(t *testing.T) {
+	cfg, err := ReadConfigINI([]byte(`
+[Global]
+port = 443
+user = user
+password = password
+datacenters = us-west
+
+[VirtualCenter "10.0.0.1"]
+zone = failure-domain
+region = failure-region
+label-categories = "owner"
+
+[Labels]
+zone = k8s-zone
+categories = "team, cost-center"
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	labels := cfg.VirtualCenter["10.0.0.1"].ZoneLabels(cfg.Labels)
+	if labels.Zone != "failure-domain" || labels.Region != "failure-region" {
+		t.Errorf("vcConfig should override the categories but actual=%+v", labels)
+	}
+	if !reflect.DeepEqual(cfg.Labels.Categories, []string{"team", "cost-center"}) {
+		t.Errorf("incorrect label categories: %v", cfg.Labels.Categories)
+	}
+	categories := cfg.VirtualCenter["10.0.0.1"].LabelCategories(cfg.Labels.Categories)
+	if !reflect.DeepEqual(categories, []string{"owner"}) {
+		t.Errorf("vcConfig should override the label categories but actual=%v", categories)
+	}
+
+	_, err = ReadConfigINI([]byte(`
+[Global]
+user = user
+password = password
+
+[VirtualCenter "10.0.0.1"]
+label-categories = "team/name"
+`))
+	if err != ErrInvalidLabelCategory {
+		t.Errorf("expected ErrInvalidLabelCategory, got %v", err)
+	}
+}
+
+func TestEnvOverridesINI(t *testing.T) {
+	os.Setenv("VSPHERE_PASSWORD", "env-password")
+	defer os.Unsetenv("VSPHERE_PASSWORD")
+
+	cfg, err := ReadConfigINI([]byte(strings.Replace(basicConfigINI, "password = password\n", "", 1)))
+	if err != nil {
+		t.Fatalf("Should succeed when the missing password is set by env: %s", err)
+	}
+	if vcConfig := cfg.VirtualCenter["0.0.0.0"]; vcConfig.Password != "env-password" {
+		t.Errorf("vcConfig should use the password of the env but actual=%s", vcConfig.Password)
+	}
+
+	os.Setenv("VSPHERE_VCENTER_ENV", "10.0.0.9")
+	defer os.Unsetenv("VSPHERE_VCENTER_ENV")
+	cfg, err = ReadConfigINI([]byte(`
+[Global]
+user = user
+datacenters = us-west
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when the vCenter is set by env: %s", err)
+	}
+	vcConfig := cfg.VirtualCenter["10.0.0.9"]
+	if vcConfig == nil || vcConfig.VCenterPort != DefaultVCenterPortStr || vcConfig.Password != "env-password" ||
+		vcConfig.Datacenters != "us-west" {
+		t.Errorf("vcConfig of the env should be completed by the global settings but actual=%+v", vcConfig)
+	}
+
+	os.Setenv("VSPHERE_TLS_MIN_VERSION", "1.4")
+	defer os.Unsetenv("VSPHERE_TLS_MIN_VERSION")
+	if _, err = ReadConfigINI([]byte(basicConfigINI)); err != ErrInvalidTLSMinVersion {
+		t.Errorf("Should fail when the minimum TLS version of the env is unknown: %v", err)
+	}
+}
@@ -18,9 +18,9 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
-	yaml "gopkg.in/yaml.v2"
 	klog "k8s.io/klog/v2"
 )
 
@@ -46,33 +46,64 @@ func (ccy *CommonConfigYAML) CreateConfig() *Config {
 	cfg.Global.RoundTripperCount = ccy.Global.RoundTripperCount
 	cfg.Global.CAFile = ccy.Global.CAFile
 	cfg.Global.Thumbprint = ccy.Global.Thumbprint
+	cfg.Global.CertFile = ccy.Global.CertFile
+	cfg.Global.KeyFile = ccy.Global.KeyFile
+	cfg.Global.ProxyURL = ccy.Global.ProxyURL
+	cfg.Global.ConnectTimeout = ccy.Global.ConnectTimeout
+	cfg.Global.RequestTimeout = ccy.Global.RequestTimeout
+	cfg.Global.TLSMinVersion = ccy.Global.TLSMinVersion
+	cfg.Global.RateLimitQPS = ccy.Global.RateLimitQPS
+	cfg.Global.RateLimitBurst = ccy.Global.RateLimitBurst
+	cfg.Global.CredentialProvider = CredentialProvider(ccy.Global.CredentialProvider)
 	cfg.Global.SecretName = ccy.Global.SecretName
 	cfg.Global.SecretNamespace = ccy.Global.SecretNamespace
 	cfg.Global.SecretsDirectory = ccy.Global.SecretsDirectory
 	cfg.Global.APIDisable = ccy.Global.APIDisable
 	cfg.Global.APIBinding = ccy.Global.APIBinding
+	cfg.Global.SessionKeepAliveInterval = DefaultSessionKeepAliveInterval
+	if ccy.Global.SessionKeepAliveInterval != nil {
+		cfg.Global.SessionKeepAliveInterval = *ccy.Global.SessionKeepAliveInterval
+	}
+	cfg.Global.SessionMaxAge = ccy.Global.SessionMaxAge
+	cfg.Global.DiscoveryTimeout = ccy.Global.DiscoveryTimeout
+	cfg.Global.OperationTimeout = ccy.Global.OperationTimeout
+	cfg.Global.CircuitBreakerThreshold = ccy.Global.CircuitBreakerThreshold
+	cfg.Global.CircuitBreakerOpenInterval = ccy.Global.CircuitBreakerOpenInterval
+	cfg.Global.CircuitBreakerMaxOpenInterval = ccy.Global.CircuitBreakerMaxOpenInterval
+	cfg.Global.StrictConfig = ccy.Global.StrictConfig
 
 	for keyVcConfig, valVcConfig := range ccy.Vcenter {
 		cfg.VirtualCenter[keyVcConfig] = &VirtualCenterConfig{
-			User:              valVcConfig.User,
-			Password:          valVcConfig.Password,
-			TenantRef:         valVcConfig.TenantRef,
-			VCenterIP:         valVcConfig.VCenterIP,
-			VCenterPort:       fmt.Sprint(valVcConfig.VCenterPort),
-			InsecureFlag:      valVcConfig.InsecureFlag,
-			Datacenters:       strings.Join(valVcConfig.Datacenters, ","),
-			RoundTripperCount: valVcConfig.RoundTripperCount,
-			CAFile:            valVcConfig.CAFile,
-			Thumbprint:        valVcConfig.Thumbprint,
-			SecretRef:         valVcConfig.SecretRef,
-			SecretName:        valVcConfig.SecretName,
-			SecretNamespace:   valVcConfig.SecretNamespace,
-			IPFamilyPriority:  valVcConfig.IPFamilyPriority,
+			User:               valVcConfig.User,
+			Password:           valVcConfig.Password,
+			TenantRef:          valVcConfig.TenantRef,
+			VCenterIP:          valVcConfig.VCenterIP,
+			VCenterPort:        fmt.Sprint(valVcConfig.VCenterPort),
+			InsecureFlag:       valVcConfig.InsecureFlag,
+			Datacenters:        strings.Join(valVcConfig.Datacenters, ","),
+			RoundTripperCount:  valVcConfig.RoundTripperCount,
+			CAFile:             valVcConfig.CAFile,
+			Thumbprint:         valVcConfig.Thumbprint,
+			CertFile:           valVcConfig.CertFile,
+			KeyFile:            valVcConfig.KeyFile,
+			ProxyURL:           valVcConfig.ProxyURL,
+			ConnectTimeout:     valVcConfig.ConnectTimeout,
+			RequestTimeout:     valVcConfig.RequestTimeout,
+			TLSMinVersion:      valVcConfig.TLSMinVersion,
+			RateLimitQPS:       valVcConfig.RateLimitQPS,
+			RateLimitBurst:     valVcConfig.RateLimitBurst,
+			CredentialProvider: CredentialProvider(valVcConfig.CredentialProvider),
+			SecretRef:          valVcConfig.SecretRef,
+			SecretName:         valVcConfig.SecretName,
+			SecretNamespace:    valVcConfig.SecretNamespace,
+			IPFamilyPriority:   valVcConfig.IPFamilyPriority,
+			Labels:             Labels(valVcConfig.Labels),
 		}
 	}
 
 	cfg.Labels.Region = ccy.Labels.Region
 	cfg.Labels.Zone = ccy.Labels.Zone
+	cfg.Labels.Categories = ccy.Labels.Categories
 
 	return cfg
 }
@@ -89,6 +120,132 @@ func (vccy *VirtualCenterConfigYAML) isSecretInfoProvided() bool {
 	return vccy.SecretName != "" && vccy.SecretNamespace != ""
 }
 
+// isExternal returns true if the credentials are not read from the secret
+func (cpy *CredentialProviderYAML) isExternal() bool {
+	return CredentialProvider(*cpy).IsExternal()
+}
+
+func (cpy *CredentialProviderYAML) validateConfig() error {
+	switch cpy.Type {
+	case "", CredentialProviderSecret:
+	case CredentialProviderExec:
+		if cpy.Command == "" {
+			return ErrCredentialProviderIncomplete
+		}
+	case CredentialProviderVault:
+		if cpy.Address == "" || cpy.Path == "" {
+			return ErrCredentialProviderIncomplete
+		}
+	default:
+		return ErrUnknownCredentialProvider
+	}
+	return nil
+}
+
+// fromEnv overrides the settings by the environment variables that are set,
+// so that they are validated like the ones of the file.
+func (ccy *CommonConfigYAML) fromEnv() error {
+	cfg := ccy.CreateConfig()
+	vcenters := cfg.fromEnv()
+
+	port, err := parsePort(cfg.Global.VCenterPort)
+	if err != nil {
+		return err
+	}
+	keepAliveInterval := cfg.Global.SessionKeepAliveInterval
+
+	ccy.Global.User = cfg.Global.User
+	ccy.Global.Password = cfg.Global.Password
+	ccy.Global.VCenterIP = cfg.Global.VCenterIP
+	ccy.Global.VCenterPort = port
+	ccy.Global.InsecureFlag = cfg.Global.InsecureFlag
+	ccy.Global.Datacenters = splitDatacenters(cfg.Global.Datacenters)
+	ccy.Global.RoundTripperCount = cfg.Global.RoundTripperCount
+	ccy.Global.CAFile = cfg.Global.CAFile
+	ccy.Global.Thumbprint = cfg.Global.Thumbprint
+	ccy.Global.CertFile = cfg.Global.CertFile
+	ccy.Global.KeyFile = cfg.Global.KeyFile
+	ccy.Global.ProxyURL = cfg.Global.ProxyURL
+	ccy.Global.ConnectTimeout = cfg.Global.ConnectTimeout
+	ccy.Global.RequestTimeout = cfg.Global.RequestTimeout
+	ccy.Global.TLSMinVersion = cfg.Global.TLSMinVersion
+	ccy.Global.RateLimitQPS = cfg.Global.RateLimitQPS
+	ccy.Global.RateLimitBurst = cfg.Global.RateLimitBurst
+	ccy.Global.SecretName = cfg.Global.SecretName
+	ccy.Global.SecretNamespace = cfg.Global.SecretNamespace
+	ccy.Global.SecretsDirectory = cfg.Global.SecretsDirectory
+	ccy.Global.APIDisable = cfg.Global.APIDisable
+	ccy.Global.APIBinding = cfg.Global.APIBinding
+	ccy.Global.SessionKeepAliveInterval = &keepAliveInterval
+	ccy.Global.SessionMaxAge = cfg.Global.SessionMaxAge
+	ccy.Global.DiscoveryTimeout = cfg.Global.DiscoveryTimeout
+	ccy.Global.OperationTimeout = cfg.Global.OperationTimeout
+	ccy.Global.CircuitBreakerThreshold = cfg.Global.CircuitBreakerThreshold
+	ccy.Global.CircuitBreakerOpenInterval = cfg.Global.CircuitBreakerOpenInterval
+	ccy.Global.CircuitBreakerMaxOpenInterval = cfg.Global.CircuitBreakerMaxOpenInterval
+	ccy.Global.StrictConfig = cfg.Global.StrictConfig
+	ccy.Labels.Region = cfg.Labels.Region
+	ccy.Labels.Zone = cfg.Labels.Zone
+	ccy.Labels.Categories = cfg.Labels.Categories
+
+	for _, tenantRef := range vcenters {
+		vcc := cfg.VirtualCenter[tenantRef]
+		port, err := parsePort(vcc.VCenterPort)
+		if err != nil {
+			return err
+		}
+		vcConfig := ccy.Vcenter[tenantRef]
+		if vcConfig == nil {
+			vcConfig = &VirtualCenterConfigYAML{}
+			ccy.Vcenter[tenantRef] = vcConfig
+		}
+		vcConfig.User = vcc.User
+		vcConfig.Password = vcc.Password
+		vcConfig.TenantRef = vcc.TenantRef
+		vcConfig.VCenterIP = vcc.VCenterIP
+		vcConfig.VCenterPort = port
+		vcConfig.InsecureFlag = vcc.InsecureFlag
+		vcConfig.Datacenters = splitDatacenters(vcc.Datacenters)
+		vcConfig.RoundTripperCount = vcc.RoundTripperCount
+		vcConfig.CAFile = vcc.CAFile
+		vcConfig.Thumbprint = vcc.Thumbprint
+		vcConfig.CertFile = vcc.CertFile
+		vcConfig.KeyFile = vcc.KeyFile
+		vcConfig.ProxyURL = vcc.ProxyURL
+		vcConfig.ConnectTimeout = vcc.ConnectTimeout
+		vcConfig.RequestTimeout = vcc.RequestTimeout
+		vcConfig.TLSMinVersion = vcc.TLSMinVersion
+		vcConfig.RateLimitQPS = vcc.RateLimitQPS
+		vcConfig.RateLimitBurst = vcc.RateLimitBurst
+		vcConfig.SecretRef = vcc.SecretRef
+		vcConfig.SecretName = vcc.SecretName
+		vcConfig.SecretNamespace = vcc.SecretNamespace
+		vcConfig.IPFamilyPriority = vcc.IPFamilyPriority
+	}
+
+	return nil
+}
+
+// parsePort parses the port of a vCenter, zero if it is empty.
+func parsePort(port string) (uint, error) {
+	if port == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid vCenter port %q", port)
+	}
+	return uint(v), nil
+}
+
+// splitDatacenters splits a comma separated list of datacenters.
+func splitDatacenters(datacenters string) []string {
+	if datacenters == "" {
+		return nil
+	}
+	return strings.Split(datacenters, ",")
+}
+
 func (ccy *CommonConfigYAML) validateConfig() error {
 	//Fix default global values
 	if ccy.Global.RoundTripperCount == 0 {
@@ -100,6 +257,24 @@ func (ccy *CommonConfigYAML) validateConfig() error {
 	if ccy.Global.APIBinding == "" {
 		ccy.Global.APIBinding = DefaultAPIBinding
 	}
+	if ccy.Global.DiscoveryTimeout == 0 {
+		ccy.Global.DiscoveryTimeout = DefaultDiscoveryTimeout
+	}
+	if ccy.Global.OperationTimeout == 0 {
+		ccy.Global.OperationTimeout = DefaultOperationTimeout
+	}
+	if ccy.Global.CircuitBreakerThreshold == 0 {
+		ccy.Global.CircuitBreakerThreshold = DefaultCircuitBreakerThreshold
+	}
+	if ccy.Global.CircuitBreakerOpenInterval == 0 {
+		ccy.Global.CircuitBreakerOpenInterval = DefaultCircuitBreakerOpenInterval
+	}
+	if ccy.Global.CircuitBreakerMaxOpenInterval == 0 {
+		ccy.Global.CircuitBreakerMaxOpenInterval = DefaultCircuitBreakerMaxOpenInterval
+	}
+	if ccy.Global.RateLimitBurst == 0 {
+		ccy.Global.RateLimitBurst = DefaultRateLimitBurst
+	}
 	if len(ccy.Global.IPFamilyPriority) == 0 {
 		ccy.Global.IPFamilyPriority = []string{DefaultIPFamily}
 	}
@@ -118,6 +293,14 @@ func (ccy *CommonConfigYAML) validateConfig() error {
 			RoundTripperCount: ccy.Global.RoundTripperCount,
 			CAFile:            ccy.Global.CAFile,
 			Thumbprint:        ccy.Global.Thumbprint,
+			CertFile:          ccy.Global.CertFile,
+			KeyFile:           ccy.Global.KeyFile,
+			ProxyURL:          ccy.Global.ProxyURL,
+			ConnectTimeout:    ccy.Global.ConnectTimeout,
+			RequestTimeout:    ccy.Global.RequestTimeout,
+			TLSMinVersion:     ccy.Global.TLSMinVersion,
+			RateLimitQPS:      ccy.Global.RateLimitQPS,
+			RateLimitBurst:    ccy.Global.RateLimitBurst,
 			SecretRef:         DefaultCredentialManager,
 			SecretName:        ccy.Global.SecretName,
 			SecretNamespace:   ccy.Global.SecretNamespace,
@@ -125,6 +308,10 @@ func (ccy *CommonConfigYAML) validateConfig() error {
 		}
 	}
 
+	if err := validateLabelCategories(ccy.Labels.Categories); err != nil {
+		return err
+	}
+
 	// Must have at least one vCenter defined
 	if len(ccy.Vcenter) == 0 {
 		klog.Error(ErrMissingVCenter)
@@ -142,7 +329,26 @@ func (ccy *CommonConfigYAML) validateConfig() error {
 		// in the YAML-based config, the tenant ref is required in the config
 		vcConfig.TenantRef = tenantRef
 
-		if !ccy.isSecretInfoProvided() && !vcConfig.isSecretInfoProvided() {
+		if vcConfig.CredentialProvider.Type == "" {
+			vcConfig.CredentialProvider = ccy.Global.CredentialProvider
+		}
+		if err := vcConfig.CredentialProvider.validateConfig(); err != nil {
+			klog.Errorf("Invalid credential provider for vc %s: %v", tenantRef, err)
+			return err
+		}
+
+		if vcConfig.CertFile == "" && vcConfig.KeyFile == "" && vcConfig.User == "" {
+			vcConfig.CertFile = ccy.Global.CertFile
+			vcConfig.KeyFile = ccy.Global.KeyFile
+		}
+		if (vcConfig.CertFile == "") != (vcConfig.KeyFile == "") {
+			klog.Errorf("vcConfig.CertFile and vcConfig.KeyFile must be set together for vc %s!", tenantRef)
+			return ErrCertificateKeyPairIncomplete
+		}
+
+		if vcConfig.CredentialProvider.isExternal() {
+			vcConfig.SecretRef = CredentialProviderRefPrefix + tenantRef
+		} else if !ccy.isSecretInfoProvided() && !vcConfig.isSecretInfoProvided() && vcConfig.CertFile == "" {
 			if vcConfig.User == "" {
 				vcConfig.User = ccy.Global.User
 				if vcConfig.User == "" {
@@ -181,6 +387,36 @@ func (ccy *CommonConfigYAML) validateConfig() error {
 		if vcConfig.Thumbprint == "" {
 			vcConfig.Thumbprint = ccy.Global.Thumbprint
 		}
+		if vcConfig.ProxyURL == "" {
+			vcConfig.ProxyURL = ccy.Global.ProxyURL
+		}
+		if vcConfig.ConnectTimeout == 0 {
+			vcConfig.ConnectTimeout = ccy.Global.ConnectTimeout
+		}
+		if vcConfig.RequestTimeout == 0 {
+			vcConfig.RequestTimeout = ccy.Global.RequestTimeout
+		}
+		if vcConfig.TLSMinVersion == "" {
+			vcConfig.TLSMinVersion = ccy.Global.TLSMinVersion
+		}
+		if vcConfig.RateLimitQPS == 0 {
+			vcConfig.RateLimitQPS = ccy.Global.RateLimitQPS
+		}
+		if vcConfig.RateLimitBurst == 0 {
+			vcConfig.RateLimitBurst = ccy.Global.RateLimitBurst
+		}
+		if vcConfig.RateLimitQPS < 0 {
+			klog.Errorf("Invalid rate limit for vc %s: %v", tenantRef, ErrInvalidRateLimit)
+			return ErrInvalidRateLimit
+		}
+		if err := validateTransport(vcConfig.ProxyURL, vcConfig.TLSMinVersion); err != nil {
+			klog.Errorf("Invalid transport settings for vc %s: %v", tenantRef, err)
+			return err
+		}
+		if err := validateLabelCategories(vcConfig.Labels.Categories); err != nil {
+			klog.Errorf("Invalid label categories for vc %s: %v", tenantRef, err)
+			return err
+		}
 
 		if len(vcConfig.IPFamilyPriority) == 0 {
 			vcConfig.IPFamilyPriority = ccy.Global.IPFamilyPriority
@@ -206,16 +442,23 @@ func ReadRawConfigYAML(byConfig []byte) (*CommonConfigYAML, error) {
 		Vcenter: make(map[string]*VirtualCenterConfigYAML),
 	}
 
-	if err := yaml.Unmarshal(byConfig, &cfg); err != nil {
+	if err := UnmarshalYAML(byConfig, &cfg); err != nil {
 		klog.Errorf("Unmarshal failed: %s", err)
 		return nil, err
 	}
 
+	// Env Vars should override config file entries if present
+	if err := cfg.fromEnv(); err != nil {
+		klog.Errorf("fromEnv failed: %s", err)
+		return nil, err
+	}
+
 	err := cfg.validateConfig()
 	if err != nil {
 		klog.Errorf("validateConfig failed: %s", err)
 		return nil, err
 	}
+	cfg.Global.SecretsDirectory = secretsDirectory(cfg.Global.SecretsDirectory)
 
 	return &cfg, nil
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package config
 
 import (
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -87,6 +89,34 @@ func TestReadConfigYAMLGlobal(t *testing.T) {
 	if cfg.Global.CAFile != "/some/path/to/a/ca.pem" {
 		t.Errorf("incorrect caFile: %s", cfg.Global.CAFile)
 	}
+
+	if cfg.Global.SessionKeepAliveInterval != DefaultSessionKeepAliveInterval {
+		t.Errorf("incorrect sessionKeepAliveInterval: %d", cfg.Global.SessionKeepAliveInterval)
+	}
+
+	if cfg.Global.SessionMaxAge != 0 {
+		t.Errorf("incorrect sessionMaxAge: %d", cfg.Global.SessionMaxAge)
+	}
+
+	if cfg.Global.DiscoveryTimeout != DefaultDiscoveryTimeout {
+		t.Errorf("incorrect discoveryTimeout: %d", cfg.Global.DiscoveryTimeout)
+	}
+
+	if cfg.Global.OperationTimeout != DefaultOperationTimeout {
+		t.Errorf("incorrect operationTimeout: %d", cfg.Global.OperationTimeout)
+	}
+
+	if cfg.Global.CircuitBreakerThreshold != DefaultCircuitBreakerThreshold {
+		t.Errorf("incorrect circuitBreakerThreshold: %d", cfg.Global.CircuitBreakerThreshold)
+	}
+
+	if cfg.Global.CircuitBreakerOpenInterval != DefaultCircuitBreakerOpenInterval {
+		t.Errorf("incorrect circuitBreakerOpenInterval: %d", cfg.Global.CircuitBreakerOpenInterval)
+	}
+
+	if cfg.Global.CircuitBreakerMaxOpenInterval != DefaultCircuitBreakerMaxOpenInterval {
+		t.Errorf("incorrect circuitBreakerMaxOpenInterval: %d", cfg.Global.CircuitBreakerMaxOpenInterval)
+	}
 }
 
 func TestTenantRefsYAML(t *testing.T) {
@@ -137,3 +167,334 @@ func TestTenantRefsYAML(t *testing.T) {
 		t.Errorf("vcConfig3 SecretRef should be kube-system/eu-secret but actual=%s", vcConfig3.SecretRef)
 	}
 }
+
+const solutionUserConfigYAML = `
+global:
+  port: 443
+  datacenters:
+    - us-west
+  certFile: /etc/cloud/k8s-vcp.crt
+  keyFile: /etc/cloud/k8s-vcp.key
+
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+  tenant2:
+    server: 10.0.0.2
+    user: user
+    password: password
+`
+
+func TestSolutionUserYAML(t *testing.T) {
+	cfg, err := ReadConfigYAML([]byte(solutionUserConfigYAML))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	vcConfig1 := cfg.VirtualCenter["tenant1"]
+	if vcConfig1.CertFile != "/etc/cloud/k8s-vcp.crt" || vcConfig1.KeyFile != "/etc/cloud/k8s-vcp.key" {
+		t.Errorf("vcConfig1 should use the global key pair but actual=%s/%s", vcConfig1.CertFile, vcConfig1.KeyFile)
+	}
+	vcConfig2 := cfg.VirtualCenter["tenant2"]
+	if vcConfig2.CertFile != "" || vcConfig2.KeyFile != "" {
+		t.Errorf("vcConfig2 should use its user but actual=%s/%s", vcConfig2.CertFile, vcConfig2.KeyFile)
+	}
+
+	_, err = ReadConfigYAML([]byte(strings.Replace(solutionUserConfigYAML, "  keyFile: /etc/cloud/k8s-vcp.key\n", "", 1)))
+	if err != ErrCertificateKeyPairIncomplete {
+		t.Errorf("Should fail when the key file is missing: %v", err)
+	}
+}
+
+const credentialProviderConfigYAML = `
+global:
+  port: 443
+  secretName: vsphere-creds
+  secretNamespace: kube-system
+  credentialProvider:
+    type: exec
+    command: /usr/local/bin/vcenter-credentials
+    args:
+      - --format=json
+
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+  tenant2:
+    server: 10.0.0.2
+    credentialProvider:
+      type: vault
+      address: https://vault:8200
+      path: secret/data/vsphere
+      tokenFile: /var/run/secrets/vault/token
+  tenant3:
+    server: 10.0.0.3
+    credentialProvider:
+      type: secret
+`
+
+func TestCredentialProviderYAML(t *testing.T) {
+	cfg, err := ReadConfigYAML([]byte(credentialProviderConfigYAML))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	vcConfig1 := cfg.VirtualCenter["tenant1"]
+	expected1 := CredentialProvider{
+		Type:    CredentialProviderExec,
+		Command: "/usr/local/bin/vcenter-credentials",
+		Args:    []string{"--format=json"},
+	}
+	if !reflect.DeepEqual(vcConfig1.CredentialProvider, expected1) {
+		t.Errorf("vcConfig1 should use the global credential provider but actual=%+v", vcConfig1.CredentialProvider)
+	}
+	if vcConfig1.SecretRef != CredentialProviderRefPrefix+"tenant1" {
+		t.Errorf("vcConfig1 SecretRef should refer to its credential provider but actual=%s", vcConfig1.SecretRef)
+	}
+	vcConfig2 := cfg.VirtualCenter["tenant2"]
+	if vcConfig2.CredentialProvider.Type != CredentialProviderVault ||
+		vcConfig2.CredentialProvider.Address != "https://vault:8200" ||
+		vcConfig2.CredentialProvider.Path != "secret/data/vsphere" ||
+		vcConfig2.CredentialProvider.TokenFile != "/var/run/secrets/vault/token" {
+		t.Errorf("vcConfig2 should use its vault credential provider but actual=%+v", vcConfig2.CredentialProvider)
+	}
+	if vcConfig2.SecretRef != CredentialProviderRefPrefix+"tenant2" {
+		t.Errorf("vcConfig2 SecretRef should refer to its credential provider but actual=%s", vcConfig2.SecretRef)
+	}
+	vcConfig3 := cfg.VirtualCenter["tenant3"]
+	if vcConfig3.CredentialProvider.IsExternal() || vcConfig3.SecretRef != DefaultCredentialManager {
+		t.Errorf("vcConfig3 should use the global secret but actual=%+v %s", vcConfig3.CredentialProvider, vcConfig3.SecretRef)
+	}
+
+	_, err = ReadConfigYAML([]byte(strings.Replace(credentialProviderConfigYAML, "type: vault", "type: ldap", 1)))
+	if err != ErrUnknownCredentialProvider {
+		t.Errorf("Should fail when the credential provider is unknown: %v", err)
+	}
+	_, err = ReadConfigYAML([]byte(strings.Replace(credentialProviderConfigYAML, "      path: secret/data/vsphere\n", "", 1)))
+	if err != ErrCredentialProviderIncomplete {
+		t.Errorf("Should fail when the vault path is missing: %v", err)
+	}
+	_, err = ReadConfigYAML([]byte(strings.Replace(credentialProviderConfigYAML, "    command: /usr/local/bin/vcenter-credentials\n", "", 1)))
+	if err != ErrCredentialProviderIncomplete {
+		t.Errorf("Should fail when the exec command is missing: %v", err)
+	}
+}
+
+const transportConfigYAML = `
+global:
+  port: 443
+  user: user
+  password: password
+  proxyURL: http://proxy.example.com:3128
+  connectTimeout: 10
+  requestTimeout: 60
+  tlsMinVersion: "1.2"
+  datacenters:
+    - us-west
+
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+  tenant2:
+    server: 10.0.0.2
+    proxyURL: socks5://proxy.example.com:1080
+    requestTimeout: 300
+    tlsMinVersion: "1.3"
+`
+
+func TestTransportYAML(t *testing.T) {
+	cfg, err := ReadConfigYAML([]byte(transportConfigYAML))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	vcConfig1 := cfg.VirtualCenter["tenant1"]
+	if vcConfig1.ProxyURL != "http://proxy.example.com:3128" || vcConfig1.ConnectTimeout != 10 ||
+		vcConfig1.RequestTimeout != 60 || vcConfig1.TLSMinVersion != "1.2" {
+		t.Errorf("vcConfig1 should use the global transport settings but actual=%+v", vcConfig1)
+	}
+	vcConfig2 := cfg.VirtualCenter["tenant2"]
+	if vcConfig2.ProxyURL != "socks5://proxy.example.com:1080" || vcConfig2.ConnectTimeout != 10 ||
+		vcConfig2.RequestTimeout != 300 || vcConfig2.TLSMinVersion != "1.3" {
+		t.Errorf("vcConfig2 should override the global transport settings but actual=%+v", vcConfig2)
+	}
+
+	_, err = ReadConfigYAML([]byte(strings.Replace(transportConfigYAML, "socks5://", "ftp://", 1)))
+	if err != ErrInvalidProxyURL {
+		t.Errorf("Should fail when the proxy URL scheme is unsupported: %v", err)
+	}
+	_, err = ReadConfigYAML([]byte(strings.Replace(transportConfigYAML, `tlsMinVersion: "1.3"`, `tlsMinVersion: "1.4"`, 1)))
+	if err != ErrInvalidTLSMinVersion {
+		t.Errorf("Should fail when the minimum TLS version is unknown: %v", err)
+	}
+}
+
+func TestRateLimitYAML(t *testing.T) {
+	cfg, err := ReadConfigYAML([]byte(`
+global:
+  port: 443
+  user: user
+  password: password
+  rateLimitQPS: 20
+  datacenters:
+    - us-west
+
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+  tenant2:
+    server: 10.0.0.2
+    rateLimitQPS: 2.5
+    rateLimitBurst: 5
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	vcConfig1 := cfg.VirtualCenter["tenant1"]
+	if vcConfig1.RateLimitQPS != 20 || vcConfig1.RateLimitBurst != DefaultRateLimitBurst {
+		t.Errorf("vcConfig1 should use the global rate limit but actual=%v/%d", vcConfig1.RateLimitQPS, vcConfig1.RateLimitBurst)
+	}
+	vcConfig2 := cfg.VirtualCenter["tenant2"]
+	if vcConfig2.RateLimitQPS != 2.5 || vcConfig2.RateLimitBurst != 5 {
+		t.Errorf("vcConfig2 should override the global rate limit but actual=%v/%d", vcConfig2.RateLimitQPS, vcConfig2.RateLimitBurst)
+	}
+
+	_, err = ReadConfigYAML([]byte(`
+global:
+  server: 10.0.0.1
+  user: user
+  password: password
+  rateLimitQPS: -1
+  datacenters:
+    - us-west
+`))
+	if err != ErrInvalidRateLimit {
+		t.Errorf("Should fail when the rate limit is negative: %v", err)
+	}
+}
+
+func TestTimeoutsYAML(t *testing.T) {
+	cfg, err := ReadConfigYAML([]byte(`
+global:
+  server: 10.0.0.1
+  user: user
+  password: password
+  discoveryTimeout: 30
+  operationTimeout: 10
+  sessionKeepAliveInterval: 0
+  datacenters:
+    - us-west
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	if cfg.Global.DiscoveryTimeout != 30 || cfg.Global.OperationTimeout != 10 {
+		t.Errorf("incorrect timeouts: %d/%d", cfg.Global.DiscoveryTimeout, cfg.Global.OperationTimeout)
+	}
+
+	if cfg.Global.SessionKeepAliveInterval != 0 {
+		t.Errorf("session monitor should be disabled, got interval %d", cfg.Global.SessionKeepAliveInterval)
+	}
+}
+
+func TestVirtualCenterLabelsYAML(t *testing.T) {
+	cfg, err := ReadConfigYAML([]byte(`
+global:
+  port: 443
+  user: user
+  password: password
+  datacenters:
+    - us-west
+
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+  tenant2:
+    server: 10.0.0.2
+    labels:
+      zone: failure-domain
+      categories:
+        - owner
+
+labels:
+  zone: k8s-zone
+  region: k8s-region
+  categories:
+    - team
+    - cost-center
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when a valid config is provided: %s", err)
+	}
+
+	labels1 := cfg.VirtualCenter["tenant1"].ZoneLabels(cfg.Labels)
+	if labels1.Zone != "k8s-zone" || labels1.Region != "k8s-region" {
+		t.Errorf("vcConfig1 should use the global categories but actual=%+v", labels1)
+	}
+	labels2 := cfg.VirtualCenter["tenant2"].ZoneLabels(cfg.Labels)
+	if labels2.Zone != "failure-domain" || labels2.Region != "k8s-region" {
+		t.Errorf("vcConfig2 should override the zone category but actual=%+v", labels2)
+	}
+
+	categories1 := cfg.VirtualCenter["tenant1"].LabelCategories(cfg.Labels.Categories)
+	if !reflect.DeepEqual(categories1, []string{"team", "cost-center"}) {
+		t.Errorf("vcConfig1 should use the global label categories but actual=%v", categories1)
+	}
+	categories2 := cfg.VirtualCenter["tenant2"].LabelCategories(cfg.Labels.Categories)
+	if !reflect.DeepEqual(categories2, []string{"owner"}) {
+		t.Errorf("vcConfig2 should override the label categories but actual=%v", categories2)
+	}
+
+	_, err = ReadConfigYAML([]byte(`
+global:
+  user: user
+  password: password
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+labels:
+  categories:
+    - "cost center"
+`))
+	if err != ErrInvalidLabelCategory {
+		t.Errorf("expected ErrInvalidLabelCategory, got %v", err)
+	}
+}
+
+func TestEnvOverridesYAML(t *testing.T) {
+	os.Setenv("VSPHERE_PASSWORD", "env-password")
+	defer os.Unsetenv("VSPHERE_PASSWORD")
+
+	cfg, err := ReadConfigYAML([]byte(strings.Replace(basicConfigYAML, "  password: password\n", "", 1)))
+	if err != nil {
+		t.Fatalf("Should succeed when the missing password is set by env: %s", err)
+	}
+	if vcConfig := cfg.VirtualCenter["0.0.0.0"]; vcConfig.Password != "env-password" {
+		t.Errorf("vcConfig should use the password of the env but actual=%s", vcConfig.Password)
+	}
+
+	os.Setenv("VSPHERE_VCENTER_ENV", "10.0.0.9")
+	defer os.Unsetenv("VSPHERE_VCENTER_ENV")
+	cfg, err = ReadConfigYAML([]byte(`
+global:
+  user: user
+  datacenters:
+    - us-west
+`))
+	if err != nil {
+		t.Fatalf("Should succeed when the vCenter is set by env: %s", err)
+	}
+	vcConfig := cfg.VirtualCenter["10.0.0.9"]
+	if vcConfig == nil || vcConfig.VCenterPort != DefaultVCenterPortStr || vcConfig.Password != "env-password" ||
+		vcConfig.Datacenters != "us-west" {
+		t.Errorf("vcConfig of the env should be completed by the global settings but actual=%+v", vcConfig)
+	}
+
+	os.Setenv("VSPHERE_TLS_MIN_VERSION", "1.4")
+	defer os.Unsetenv("VSPHERE_TLS_MIN_VERSION")
+	if _, err = ReadConfigYAML([]byte(basicConfigYAML)); err != ErrInvalidTLSMinVersion {
+		t.Errorf("Should fail when the minimum TLS version of the env is unknown: %v", err)
+	}
+}
@@ -29,6 +29,31 @@ const (
 	// exposing the API service.
 	DefaultAPIBinding string = ":43001"
 
+	// DefaultSessionKeepAliveInterval is the default interval in seconds at
+	// which vCenter sessions are checked and kept alive.
+	DefaultSessionKeepAliveInterval uint = 300
+
+	// DefaultDiscoveryTimeout is the default timeout in seconds of a node
+	// discovery.
+	DefaultDiscoveryTimeout uint = 120
+	// DefaultOperationTimeout is the default timeout in seconds of the vCenter
+	// calls that are not bound to a request.
+	DefaultOperationTimeout uint = 60
+
+	// DefaultCircuitBreakerThreshold is the default number of consecutive
+	// connection failures after which a vCenter is skipped by searches.
+	DefaultCircuitBreakerThreshold uint = 3
+	// DefaultCircuitBreakerOpenInterval is the default interval in seconds
+	// during which a failing vCenter is skipped before it is probed again.
+	DefaultCircuitBreakerOpenInterval uint = 30
+	// DefaultCircuitBreakerMaxOpenInterval is the default upper bound in
+	// seconds of the interval during which a failing vCenter is skipped.
+	DefaultCircuitBreakerMaxOpenInterval uint = 600
+
+	// DefaultRateLimitBurst is the default number of requests to vCenter that
+	// may exceed the rate limit in a burst.
+	DefaultRateLimitBurst uint = 10
+
 	// DefaultVCenterPortStr is the default port used to access vCenter in string form
 	DefaultVCenterPortStr string = "443"
 	// DefaultVCenterPort is the default port used to access vCenter in uint form
@@ -45,8 +70,23 @@ const (
 	// DefaultIPFamily is the default IP addressing to use for networking
 	DefaultIPFamily = IPv4Family
 
+	// TagLabelPrefix prefixes the tag categories of the Labels section to
+	// name the labels of the nodes set to the tags of the categories.
+	TagLabelPrefix = "tags.vsphere.cloudprovider.k8s.io/"
+
 	// DefaultCredentialManager used for the Global CredMgr/Lister
 	DefaultCredentialManager string = "Global"
+
+	// CredentialProviderSecret reads the credentials from the configured secret
+	// or secrets directory.
+	CredentialProviderSecret = "secret"
+	// CredentialProviderExec obtains the credentials from the output of a command.
+	CredentialProviderExec = "exec"
+	// CredentialProviderVault reads the credentials from a Vault compatible HTTP server.
+	CredentialProviderVault = "vault"
+	// CredentialProviderRefPrefix prefixes the tenant ref in the SecretRef of a
+	// vCenter using an external credential provider.
+	CredentialProviderRefPrefix = "CredentialProvider/"
 )
 
 var (
@@ -56,6 +96,33 @@ var (
 	// ErrPasswordMissing is returned when the provided password is empty.
 	ErrPasswordMissing = errors.New("Password is missing")
 
+	// ErrCertificateKeyPairIncomplete is returned when only one of the
+	// certificate and private key files is provided.
+	ErrCertificateKeyPairIncomplete = errors.New("CertFile and KeyFile must be set together")
+
+	// ErrUnknownCredentialProvider is returned when the type of the credential
+	// provider is not supported.
+	ErrUnknownCredentialProvider = errors.New("Unknown credential provider type")
+
+	// ErrCredentialProviderIncomplete is returned when a setting required by
+	// the credential provider is missing.
+	ErrCredentialProviderIncomplete = errors.New("Credential provider settings are incomplete")
+
+	// ErrInvalidProxyURL is returned when the proxy URL is not an absolute
+	// http, https or socks5 URL.
+	ErrInvalidProxyURL = errors.New("Invalid proxy URL")
+
+	// ErrInvalidTLSMinVersion is returned when the minimum TLS version is unknown.
+	ErrInvalidTLSMinVersion = errors.New("Invalid minimum TLS version")
+
+	// ErrInvalidRateLimit is returned when the rate limit of vCenter requests
+	// is negative.
+	ErrInvalidRateLimit = errors.New("Invalid rate limit")
+
+	// ErrInvalidLabelCategory is returned when a tag category of the Labels
+	// section does not make a valid name of a node label.
+	ErrInvalidLabelCategory = errors.New("Invalid label category")
+
 	// ErrInvalidVCenterIP is returned when the provided vCenter IP address is
 	// missing from the provided configuration.
 	ErrInvalidVCenterIP = errors.New("vsphere.conf does not have the VirtualCenter IP address specified")
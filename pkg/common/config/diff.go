@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DiffFields compares the exported fields of two structs of the same type and
+// returns a description of every changed field, prefixed with section. The
+// values of fields holding credentials are never included.
+func DiffFields(section string, old interface{}, new interface{}) []string {
+	oldValue := reflect.Indirect(reflect.ValueOf(old))
+	newValue := reflect.Indirect(reflect.ValueOf(new))
+	if oldValue.Type() != newValue.Type() || oldValue.Kind() != reflect.Struct {
+		return []string{fmt.Sprintf("%s changed", section)}
+	}
+
+	var changes []string
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if field.PkgPath != "" || field.Anonymous {
+			continue
+		}
+		o := oldValue.Field(i).Interface()
+		n := newValue.Field(i).Interface()
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		if isSensitiveField(field.Name) {
+			changes = append(changes, fmt.Sprintf("%s.%s changed", section, field.Name))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s.%s changed from %v to %v", section, field.Name, o, n))
+	}
+	return changes
+}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	// the user may hold a PEM encoded certificate, headers may hold tokens
+	return strings.Contains(name, "password") || strings.Contains(name, "user") || name == "headers"
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffFields(t *testing.T) {
+	old := &VirtualCenterConfig{
+		User:        "user",
+		Password:    "password",
+		VCenterPort: "443",
+		Datacenters: "dc0",
+	}
+
+	if changes := DiffFields("vCenter", old, *old); len(changes) != 0 {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+
+	new := *old
+	new.Password = "secret"
+	new.Datacenters = "dc0,dc1"
+	expected := []string{
+		"vCenter.Password changed",
+		"vCenter.Datacenters changed from dc0 to dc0,dc1",
+	}
+	if changes := DiffFields("vCenter", old, &new); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected changes %v, got %v", expected, changes)
+	}
+}
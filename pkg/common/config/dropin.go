@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/sets"
+	klog "k8s.io/klog/v2"
+)
+
+// namedSections are the sections of the cloud config whose entries are named,
+// such as the vCenters and the load balancer classes. An entry may only be
+// defined by one of the merged sources.
+var namedSections = sets.NewString("vcenter", "loadBalancerClass")
+
+// ConfigSource is a YAML cloud config, or a fragment of it, to be merged
+type ConfigSource struct {
+	// Name identifies the source in errors, usually its path
+	Name string
+	Data []byte
+}
+
+// ReadDropInDir returns the YAML fragments of a drop-in directory, the files
+// with the .yaml or .yml extension, ordered by file name. Hidden files are
+// skipped, such as the data directory of a ConfigMap mounted as volume.
+func ReadDropInDir(dir string) ([]ConfigSource, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		name := file.Name()
+		ext := filepath.Ext(name)
+		if strings.HasPrefix(name, ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		// the files of a ConfigMap are symlinks
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sources := make([]ConfigSource, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, ConfigSource{Name: path, Data: data})
+	}
+	return sources, nil
+}
+
+// MergeConfigYAML merges YAML cloud configs in the given order. Sections are
+// merged key by key, and a scalar or list set by a later source overrides the
+// one of an earlier source. A vCenter or load balancer class defined by more
+// than one source is an error.
+func MergeConfigYAML(sources ...ConfigSource) ([]byte, error) {
+	merged := yaml.MapSlice{}
+	origins := map[string]string{}
+	for _, source := range sources {
+		if len(bytes.TrimSpace(source.Data)) == 0 {
+			continue
+		}
+		doc := yaml.MapSlice{}
+		if err := yaml.Unmarshal(source.Data, &doc); err != nil {
+			return nil, fmt.Errorf("%s is not in YAML: %s", source.Name, err)
+		}
+		var err error
+		merged, err = mergeMapSlice(merged, doc, "", source.Name, origins)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return yaml.Marshal(merged)
+}
+
+// MergeDropInDir merges the YAML fragments of a drop-in directory into the
+// given cloud config, named by path in errors. The cloud config is returned
+// unchanged if dir is empty or has no fragments, otherwise it must be in YAML
+// or empty.
+func MergeDropInDir(path string, byConfig []byte, dir string) ([]byte, error) {
+	if dir == "" {
+		return byConfig, nil
+	}
+	fragments, err := ReadDropInDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cloud config drop-in directory: %s", err)
+	}
+	if len(fragments) == 0 {
+		return byConfig, nil
+	}
+	for _, fragment := range fragments {
+		klog.V(2).Infof("Merging cloud config fragment %s", fragment.Name)
+	}
+	return MergeConfigYAML(append([]ConfigSource{{Name: path, Data: byConfig}}, fragments...)...)
+}
+
+// mergeMapSlice merges src into dst. origins records the source every key
+// path was set by, to report conflicting named entries.
+func mergeMapSlice(dst, src yaml.MapSlice, path, source string, origins map[string]string) (yaml.MapSlice, error) {
+	for _, item := range src {
+		key := fmt.Sprint(item.Key)
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		i := indexOfKey(dst, key)
+		if i < 0 {
+			dst = append(dst, yaml.MapItem{Key: item.Key, Value: item.Value})
+			setOrigin(item.Value, keyPath, source, origins)
+			continue
+		}
+		if namedSections.Has(path) {
+			return nil, fmt.Errorf("%s %q of %s is already defined in %s", path, key, source, origins[keyPath])
+		}
+
+		dstMap, dstIsMap := dst[i].Value.(yaml.MapSlice)
+		srcMap, srcIsMap := item.Value.(yaml.MapSlice)
+		if dstIsMap && srcIsMap {
+			merged, err := mergeMapSlice(dstMap, srcMap, keyPath, source, origins)
+			if err != nil {
+				return nil, err
+			}
+			dst[i].Value = merged
+			continue
+		}
+		if dstIsMap != srcIsMap && item.Value != nil && dst[i].Value != nil {
+			return nil, fmt.Errorf("%s of %s cannot be merged with %s, only one of them is a section", keyPath, source, origins[keyPath])
+		}
+		// scalars and lists are overridden
+		dst[i].Value = item.Value
+		setOrigin(item.Value, keyPath, source, origins)
+	}
+	return dst, nil
+}
+
+// setOrigin records the source of the key path and the keys below it
+func setOrigin(value interface{}, path, source string, origins map[string]string) {
+	origins[path] = source
+	if m, ok := value.(yaml.MapSlice); ok {
+		for _, item := range m {
+			setOrigin(item.Value, path+"."+fmt.Sprint(item.Key), source, origins)
+		}
+	}
+}
+
+func indexOfKey(m yaml.MapSlice, key string) int {
+	for i, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			return i
+		}
+	}
+	return -1
+}
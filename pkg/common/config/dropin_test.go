@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const baseDropInConfig = `
+global:
+  port: 443
+  insecureFlag: true
+  secretName: vsphere-creds
+  secretNamespace: kube-system
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+    datacenters:
+      - dc1
+`
+
+func writeDropIns(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "dropin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMergeDropInDir(t *testing.T) {
+	dir := writeDropIns(t, map[string]string{
+		"10-vcenters.yaml": "vcenter:\n  tenant2:\n    server: 10.0.0.2\n    datacenters:\n      - dc2\n",
+		"20-global.yml":    "global:\n  port: 8443\n  datacenters:\n    - dc0\n",
+		"30-global.yaml":   "global:\n  port: 9443\n",
+		"README.md":        "vcenter: [",
+		".hidden.yaml":     "vcenter: [",
+	})
+	defer os.RemoveAll(dir)
+	// the data directory of a ConfigMap mounted as volume
+	if err := os.Mkdir(filepath.Join(dir, "..data.yaml"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.yaml"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := MergeDropInDir("vsphere.conf", []byte(baseDropInConfig), dir)
+	if err != nil {
+		t.Fatalf("MergeDropInDir err=%v", err)
+	}
+	cfg, err := ReadConfigYAML(merged)
+	if err != nil {
+		t.Fatalf("ReadConfigYAML err=%v\n%s", err, merged)
+	}
+	// later fragments override the scalars
+	if cfg.Global.VCenterPort != "9443" || !cfg.Global.InsecureFlag || cfg.Global.Datacenters != "dc0" {
+		t.Errorf("unexpected global config %+v", cfg.Global)
+	}
+	if len(cfg.VirtualCenter) != 2 || cfg.VirtualCenter["tenant1"] == nil || cfg.VirtualCenter["tenant2"] == nil || cfg.VirtualCenter["tenant2"].Datacenters != "dc2" {
+		t.Errorf("unexpected vCenters %+v", cfg.VirtualCenter)
+	}
+
+	// no fragments
+	empty := writeDropIns(t, nil)
+	defer os.RemoveAll(empty)
+	for _, d := range []string{"", empty} {
+		unchanged, err := MergeDropInDir("vsphere.conf", []byte("[Global]\n"), d)
+		if err != nil || string(unchanged) != "[Global]\n" {
+			t.Errorf("expected the cloud config to be unchanged, got %s, %v", unchanged, err)
+		}
+	}
+}
+
+func TestMergeConfigYAMLConflicts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fragment string
+		expected string
+	}{
+		{
+			name:     "duplicate vCenter",
+			fragment: "vcenter:\n  tenant1:\n    server: 10.0.0.3\n",
+			expected: `vcenter "tenant1" of 10-fragment.yaml is already defined in vsphere.conf`,
+		},
+		{
+			name:     "section and value",
+			fragment: "global: true\n",
+			expected: "global of 10-fragment.yaml cannot be merged with vsphere.conf",
+		},
+		{
+			name:     "not YAML",
+			fragment: "[Global]\nuser = \"user\"\n",
+			expected: "10-fragment.yaml is not in YAML",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := MergeConfigYAML(
+			ConfigSource{Name: "vsphere.conf", Data: []byte(baseDropInConfig)},
+			ConfigSource{Name: "10-fragment.yaml", Data: []byte(tc.fragment)},
+		)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s: expected %s, got %v", tc.name, tc.expected, err)
+		}
+	}
+
+	_, err := MergeConfigYAML(
+		ConfigSource{Name: "10-lb.yaml", Data: []byte("loadBalancerClass:\n  public:\n    ipPoolName: pool1\n")},
+		ConfigSource{Name: "20-lb.yaml", Data: []byte("loadBalancerClass:\n  private:\n    ipPoolName: pool2\n  public:\n    ipPoolName: pool3\n")},
+	)
+	expected := `loadBalancerClass "public" of 20-lb.yaml is already defined in 10-lb.yaml`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s, got %v", expected, err)
+	}
+
+	// an INI cloud config cannot be merged
+	_, err = MergeConfigYAML(
+		ConfigSource{Name: "vsphere.conf", Data: []byte("[Global]\nuser = \"user\"\n")},
+		ConfigSource{Name: "10-fragment.yaml", Data: []byte(baseDropInConfig)},
+	)
+	if err == nil || !strings.HasPrefix(err.Error(), "vsphere.conf is not in YAML") {
+		t.Errorf("expected the INI cloud config to fail, got %v", err)
+	}
+}
+
+func TestMergeConfigYAMLLists(t *testing.T) {
+	merged, err := MergeConfigYAML(
+		ConfigSource{Name: "vsphere.conf", Data: []byte("")},
+		ConfigSource{Name: "10-tags.yaml", Data: []byte("loadBalancer:\n  size: SMALL\n  tags:\n    a: b\n")},
+		ConfigSource{Name: "20-tags.yaml", Data: []byte("loadBalancer:\n  tags:\n    c: d\nglobal:\n  datacenters: [dc1, dc2]\n")},
+		ConfigSource{Name: "30-tags.yaml", Data: []byte("global:\n  datacenters: [dc3]\n")},
+	)
+	if err != nil {
+		t.Fatalf("MergeConfigYAML err=%v", err)
+	}
+	expected := "loadBalancer:\n  size: SMALL\n  tags:\n    a: b\n    c: d\nglobal:\n  datacenters:\n  - dc3\n"
+	if !reflect.DeepEqual(string(merged), expected) {
+		t.Errorf("expected\n%s\ngot\n%s", expected, merged)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+	klog "k8s.io/klog/v2"
+)
+
+// StrictConfigEnv enables strict parsing of the YAML cloud config, like the
+// strictConfig global setting.
+const StrictConfigEnv = "VSPHERE_STRICT_CONFIG"
+
+// IsStrictYAML returns true if unknown keys of the given YAML cloud config
+// are rejected, as enabled by the strictConfig global setting or the
+// VSPHERE_STRICT_CONFIG environment variable.
+func IsStrictYAML(byConfig []byte) bool {
+	if v := os.Getenv(StrictConfigEnv); v != "" {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			klog.Errorf("Failed to parse %s: %s", StrictConfigEnv, err)
+		} else {
+			return strict
+		}
+	}
+	cfg := struct {
+		Global struct {
+			StrictConfig bool `yaml:"strictConfig"`
+		}
+	}{}
+	if err := yaml.Unmarshal(byConfig, &cfg); err != nil {
+		return false
+	}
+	return cfg.Global.StrictConfig
+}
+
+// UnmarshalYAML decodes the sections of a YAML cloud config that are fields
+// of out. In strict mode, a key of these sections that is not decoded into a
+// field is an error. Top level keys that are not a field of out are other
+// sections of the cloud config and are left to their readers.
+func UnmarshalYAML(byConfig []byte, out interface{}) error {
+	if err := yaml.Unmarshal(byConfig, out); err != nil {
+		return err
+	}
+	if !IsStrictYAML(byConfig) {
+		return nil
+	}
+	return checkYAMLKeys(byConfig, false, out)
+}
+
+// CheckSectionsYAML returns an error for every key of a strict YAML cloud
+// config that is not read into a field of any of the given section structs,
+// including the top level keys that are not a section.
+func CheckSectionsYAML(byConfig []byte, sections ...interface{}) error {
+	if !IsStrictYAML(byConfig) {
+		return nil
+	}
+	return checkYAMLKeys(byConfig, true, sections...)
+}
+
+func checkYAMLKeys(byConfig []byte, allSections bool, sections ...interface{}) error {
+	var doc yaml3.Node
+	if err := yaml3.Unmarshal(byConfig, &doc); err != nil {
+		// not a YAML cloud config, syntax errors are reported by the readers
+		return nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml3.MappingNode {
+		return nil
+	}
+	root := doc.Content[0]
+
+	var errs []string
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		found := false
+		for _, section := range sections {
+			if field, ok := yamlField(reflect.TypeOf(section), key.Value); ok {
+				errs = append(errs, unknownYAMLKeys(value, field, key.Value)...)
+				found = true
+				break
+			}
+		}
+		if !found && allSections {
+			errs = append(errs, fmt.Sprintf("line %d: unknown section %q", key.Line, key.Value))
+		}
+	}
+	if len(errs) > 0 {
+		return &yaml.TypeError{Errors: errs}
+	}
+	return nil
+}
+
+// unknownYAMLKeys returns an error for every key below node that is not
+// decoded into a field of t
+func unknownYAMLKeys(node *yaml3.Node, t reflect.Type, path string) []string {
+	if node.Kind == yaml3.AliasNode {
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var errs []string
+	switch {
+	case node.Kind == yaml3.MappingNode && t.Kind() == reflect.Struct:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := path + "." + key.Value
+			field, ok := yamlField(t, key.Value)
+			if !ok {
+				errs = append(errs, fmt.Sprintf("line %d: unknown field %q", key.Line, keyPath))
+				continue
+			}
+			errs = append(errs, unknownYAMLKeys(value, field, keyPath)...)
+		}
+	case node.Kind == yaml3.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			errs = append(errs, unknownYAMLKeys(value, t.Elem(), path+"."+key.Value)...)
+		}
+	case node.Kind == yaml3.SequenceNode && t.Kind() == reflect.Slice:
+		for i, item := range node.Content {
+			errs = append(errs, unknownYAMLKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	// values of the wrong type are reported by the decoder
+	return errs
+}
+
+// yamlField returns the type of the field of a struct a key is decoded into
+// by yaml.v2, which names untagged fields in lower case and descends into
+// inlined structs.
+func yamlField(t reflect.Type, key string) (reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("yaml"), ",")
+		if len(tag) > 1 && tag[1] == "inline" {
+			if field, ok := yamlField(f.Type, key); ok {
+				return field, true
+			}
+			continue
+		}
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if name == key {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const misspelledConfigYAML = `
+global:
+  port: 443
+  user: user
+  password: password
+  insecureflag: true
+vcenter:
+  tenant1:
+    server: 10.0.0.1
+    datacenters:
+      - dc1
+    secretNamespce: kube-system
+nodes:
+  internalNetworkSubnetCidr: 10.0.0.0/24
+`
+
+func TestReadConfigYAMLLenient(t *testing.T) {
+	if _, err := ReadConfigYAML([]byte(misspelledConfigYAML)); err != nil {
+		t.Fatalf("Should ignore unknown keys unless strict: %s", err)
+	}
+}
+
+func TestReadConfigYAMLStrict(t *testing.T) {
+	config := strings.Replace(misspelledConfigYAML, "  port: 443\n", "  port: 443\n  strictConfig: true\n", 1)
+	_, err := ReadConfigYAML([]byte(config))
+	if err == nil {
+		t.Fatal("Should fail on unknown keys in strict mode")
+	}
+	for _, expected := range []string{
+		`line 7: unknown field "global.insecureflag"`,
+		`line 13: unknown field "vcenter.tenant1.secretNamespce"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %s in error, got %s", expected, err)
+		}
+	}
+	// other sections are left to their readers
+	if strings.Contains(err.Error(), "nodes") {
+		t.Errorf("Expected the nodes section not to be checked, got %s", err)
+	}
+
+	valid := strings.Replace(strings.Replace(config, "insecureflag", "insecureFlag", 1), "secretNamespce", "secretNamespace", 1)
+	cfg, err := ReadConfigYAML([]byte(valid))
+	if err != nil {
+		t.Fatalf("Should succeed without unknown keys: %s", err)
+	}
+	if !cfg.Global.StrictConfig || !cfg.Global.InsecureFlag {
+		t.Errorf("Unexpected global config %+v", cfg.Global)
+	}
+}
+
+func TestReadConfigYAMLStrictEnv(t *testing.T) {
+	os.Setenv(StrictConfigEnv, "true")
+	defer os.Unsetenv(StrictConfigEnv)
+
+	if _, err := ReadConfigYAML([]byte(misspelledConfigYAML)); err == nil {
+		t.Fatalf("Should fail on unknown keys with %s set", StrictConfigEnv)
+	}
+
+	os.Setenv(StrictConfigEnv, "false")
+	config := strings.Replace(misspelledConfigYAML, "  port: 443\n", "  port: 443\n  strictConfig: true\n", 1)
+	if _, err := ReadConfigYAML([]byte(config)); err != nil {
+		t.Fatalf("Should ignore unknown keys with %s disabled: %s", StrictConfigEnv, err)
+	}
+}
+
+func TestCheckSectionsYAML(t *testing.T) {
+	config := strings.Replace(misspelledConfigYAML, "  port: 443\n", "  port: 443\n  strictConfig: true\n", 1)
+	config = strings.Replace(config, "insecureflag", "insecureFlag", 1)
+	config = strings.Replace(config, "secretNamespce", "secretNamespace", 1)
+
+	if err := CheckSectionsYAML([]byte(config), CommonConfigYAML{}); err == nil ||
+		!strings.Contains(err.Error(), `line 14: unknown section "nodes"`) {
+		t.Errorf("Expected the nodes section to be unknown, got %v", err)
+	}
+
+	nodes := struct {
+		Nodes struct {
+			InternalNetworkSubnetCIDR string `yaml:"internalNetworkSubnetCidr"`
+		}
+	}{}
+	if err := CheckSectionsYAML([]byte(config), CommonConfigYAML{}, nodes); err != nil {
+		t.Errorf("Expected all sections to be known, got %v", err)
+	}
+
+	// the INI cloud config is not checked
+	if err := CheckSectionsYAML([]byte("[Global]\nuser = \"user\"\n"), CommonConfigYAML{}); err != nil {
+		t.Errorf("Expected an INI config not to be checked, got %v", err)
+	}
+}
@@ -44,6 +44,35 @@ type Global struct {
 	CAFile string
 	// Thumbprint of the VCenter's certificate thumbprint
 	Thumbprint string
+	// Specifies the path to the certificate of a solution user in PEM format.
+	// If set, vCenter is logged in to with a SAML token issued by its STS for
+	// the solution user instead of with user and password.
+	CertFile string
+	// Specifies the path to the private key of CertFile in PEM format.
+	KeyFile string
+	// URL of the HTTP, HTTPS or SOCKS5 proxy vCenter is reached through, e.g.
+	// http://proxy.example.com:3128. Optional; if not configured, the
+	// HTTPS_PROXY and NO_PROXY environment variables are honoured.
+	ProxyURL string
+	// Timeout in seconds for establishing a connection to vCenter
+	// Default: 0, only the timeouts of the operating system apply
+	ConnectTimeout uint
+	// Timeout in seconds of a single request to vCenter, including reading
+	// its response
+	// Default: 0, no timeout
+	RequestTimeout uint
+	// Minimum TLS version used to connect to vCenter: 1.0, 1.1, 1.2 or 1.3
+	// Default: the default of Go
+	TLSMinVersion string
+	// Maximum rate of requests per second to vCenter. Requests in excess of it
+	// wait for their turn.
+	// Default: 0, no limit
+	RateLimitQPS float32
+	// Number of requests to vCenter that may exceed RateLimitQPS in a burst
+	// Default: 10
+	RateLimitBurst uint
+	// Default provider of the vCenter credentials, see VirtualCenterConfig.
+	CredentialProvider CredentialProvider
 	// Name of the secret were vCenter credentials are present.
 	SecretName string
 	// Secret Namespace where secret will be present that has vCenter credentials.
@@ -59,6 +88,38 @@ type Global struct {
 	// Configurable vSphere CCM API port
 	// Default: 43001
 	APIBinding string
+	// Interval in seconds at which the vCenter sessions are checked and kept alive.
+	// Zero disables the session monitor.
+	// Default: 300
+	SessionKeepAliveInterval uint
+	// Maximum age in seconds of a vCenter session before it is proactively
+	// re-authenticated. Zero disables proactive re-authentication.
+	// Default: 0
+	SessionMaxAge uint
+	// Timeout in seconds of a node discovery, which searches the VM of a node
+	// across all vCenters and datacenters
+	// Default: 120
+	DiscoveryTimeout uint
+	// Timeout in seconds of the vCenter calls that are not bound to a request,
+	// such as logging out or verifying the connections
+	// Default: 60
+	OperationTimeout uint
+	// Number of consecutive failures to connect to a vCenter after which searches
+	// skip it until its circuit breaker allows a new probe. A failure is a search
+	// which could not connect after its retries, or a failed session check.
+	// Default: 3
+	CircuitBreakerThreshold uint
+	// Interval in seconds during which a vCenter is skipped once its circuit
+	// breaker opened. It doubles, with jitter, on every failed probe.
+	// Default: 30
+	CircuitBreakerOpenInterval uint
+	// Maximum interval in seconds during which a vCenter is skipped
+	// Default: 600
+	CircuitBreakerMaxOpenInterval uint
+	// Reject keys of the YAML cloud config that no setting is read from,
+	// instead of ignoring them
+	// Default: false
+	StrictConfig bool
 }
 
 // VirtualCenterConfig struct
@@ -88,6 +149,36 @@ type VirtualCenterConfig struct {
 	CAFile string
 	// Thumbprint of the VCenter's certificate thumbprint
 	Thumbprint string
+	// Specifies the path to the certificate of a solution user in PEM format.
+	// If set, vCenter is logged in to with a SAML token issued by its STS for
+	// the solution user instead of with user and password.
+	CertFile string
+	// Specifies the path to the private key of CertFile in PEM format.
+	KeyFile string
+	// URL of the HTTP, HTTPS or SOCKS5 proxy vCenter is reached through, e.g.
+	// http://proxy.example.com:3128. Optional; if not configured, the
+	// HTTPS_PROXY and NO_PROXY environment variables are honoured.
+	ProxyURL string
+	// Timeout in seconds for establishing a connection to vCenter
+	// Default: 0, only the timeouts of the operating system apply
+	ConnectTimeout uint
+	// Timeout in seconds of a single request to vCenter, including reading
+	// its response
+	// Default: 0, no timeout
+	RequestTimeout uint
+	// Minimum TLS version used to connect to vCenter: 1.0, 1.1, 1.2 or 1.3
+	// Default: the default of Go
+	TLSMinVersion string
+	// Maximum rate of requests per second to vCenter. Requests in excess of it
+	// wait for their turn.
+	// Default: 0, no limit
+	RateLimitQPS float32
+	// Number of requests to vCenter that may exceed RateLimitQPS in a burst
+	// Default: 10
+	RateLimitBurst uint
+	// Provider of the vCenter credentials. If an external provider is set, the
+	// credentials are obtained from it instead of from the secret.
+	CredentialProvider CredentialProvider
 	// SecretRef (intentionally not exposed via the config) is a key to identify which
 	// InformerManager holds the secret
 	SecretRef string
@@ -100,14 +191,45 @@ type VirtualCenterConfig struct {
 	// ipv4 - IPv4 addresses only (Default)
 	// ipv6 - IPv6 addresses only
 	IPFamilyPriority []string
+	// Tag categories of the zones, regions and node labels of the vCenter,
+	// overriding the ones of the Labels section. Optional.
+	Labels Labels
+}
+
+// CredentialProvider struct
+type CredentialProvider struct {
+	// Type of the provider. Supported values are:
+	// secret - the configured Kubernetes secret or secrets directory (Default)
+	// exec - the output of a command
+	// vault - a secret read from a Vault compatible HTTP server
+	Type string
+	// Command run by the exec provider.
+	Command string
+	// Arguments of Command.
+	Args []string
+	// Address of the Vault compatible HTTP server, e.g. https://vault:8200
+	Address string
+	// Path of the secret holding the credentials, e.g. secret/data/vsphere
+	Path string
+	// File holding the token used to authenticate with the HTTP server.
+	// Default: the VAULT_TOKEN environment variable
+	TokenFile string
+	// Specifies the path to a CA certificate in PEM format used to verify the
+	// HTTP server. Optional; if not configured, the system's CA certificates
+	// will be used.
+	CAFile string
 }
 
-// Labels struct
+// Labels are the tag categories of the zones and regions of the nodes, and
+// of the tags set as labels of the nodes.
 type Labels struct {
 	// Zone describes a zone
 	Zone string
 	// Region describes a region
 	Region string
+	// Categories whose tags are set as labels of the nodes, named by the
+	// category prefixed with TagLabelPrefix.
+	Categories []string
 }
 
 // Config is used to read and store information from the cloud configuration file
@@ -43,6 +43,33 @@ type GlobalINI struct {
 	CAFile string `gcfg:"ca-file"`
 	// Thumbprint of the VCenter's certificate thumbprint
 	Thumbprint string `gcfg:"thumbprint"`
+	// Specifies the path to the certificate of a solution user in PEM format.
+	// If set, vCenter is logged in to with a SAML token issued by its STS for
+	// the solution user instead of with user and password.
+	CertFile string `gcfg:"cert-file"`
+	// Specifies the path to the private key of CertFile in PEM format.
+	KeyFile string `gcfg:"key-file"`
+	// URL of the HTTP, HTTPS or SOCKS5 proxy vCenter is reached through, e.g.
+	// http://proxy.example.com:3128. Optional; if not configured, the
+	// HTTPS_PROXY and NO_PROXY environment variables are honoured.
+	ProxyURL string `gcfg:"proxy-url"`
+	// Timeout in seconds for establishing a connection to vCenter
+	// Default: 0, only the timeouts of the operating system apply
+	ConnectTimeout uint `gcfg:"connect-timeout"`
+	// Timeout in seconds of a single request to vCenter, including reading
+	// its response
+	// Default: 0, no timeout
+	RequestTimeout uint `gcfg:"request-timeout"`
+	// Minimum TLS version used to connect to vCenter: 1.0, 1.1, 1.2 or 1.3
+	// Default: the default of Go
+	TLSMinVersion string `gcfg:"tls-min-version"`
+	// Maximum rate of requests per second to vCenter. Requests in excess of it
+	// wait for their turn.
+	// Default: 0, no limit
+	RateLimitQPS float32 `gcfg:"rate-limit-qps"`
+	// Number of requests to vCenter that may exceed RateLimitQPS in a burst
+	// Default: 10
+	RateLimitBurst uint `gcfg:"rate-limit-burst"`
 	// Name of the secret were vCenter credentials are present.
 	SecretName string `gcfg:"secret-name"`
 	// Secret Namespace where secret will be present that has vCenter credentials.
@@ -58,6 +85,34 @@ type GlobalINI struct {
 	// Configurable vSphere CCM API port
 	// Default: 43001
 	APIBinding string `gcfg:"api-binding"`
+	// Interval in seconds at which the vCenter sessions are checked and kept alive.
+	// Zero disables the session monitor.
+	// Default: 300
+	SessionKeepAliveInterval *uint `gcfg:"session-keepalive-interval"`
+	// Maximum age in seconds of a vCenter session before it is proactively
+	// re-authenticated. Zero disables proactive re-authentication.
+	// Default: 0
+	SessionMaxAge uint `gcfg:"session-max-age"`
+	// Timeout in seconds of a node discovery, which searches the VM of a node
+	// across all vCenters and datacenters
+	// Default: 120
+	DiscoveryTimeout uint `gcfg:"discovery-timeout"`
+	// Timeout in seconds of the vCenter calls that are not bound to a request,
+	// such as logging out or verifying the connections
+	// Default: 60
+	OperationTimeout uint `gcfg:"operation-timeout"`
+	// Number of consecutive failures to connect to a vCenter after which searches
+	// skip it until its circuit breaker allows a new probe. A failure is a search
+	// which could not connect after its retries, or a failed session check.
+	// Default: 3
+	CircuitBreakerThreshold uint `gcfg:"circuit-breaker-threshold"`
+	// Interval in seconds during which a vCenter is skipped once its circuit
+	// breaker opened. It doubles, with jitter, on every failed probe.
+	// Default: 30
+	CircuitBreakerOpenInterval uint `gcfg:"circuit-breaker-open-interval"`
+	// Maximum interval in seconds during which a vCenter is skipped
+	// Default: 600
+	CircuitBreakerMaxOpenInterval uint `gcfg:"circuit-breaker-max-open-interval"`
 	// IP Family enables the ability to support IPv4 or IPv6
 	// Supported values are:
 	// ipv4 - IPv4 addresses only (Default)
@@ -93,6 +148,33 @@ type VirtualCenterConfigINI struct {
 	CAFile string `gcfg:"ca-file"`
 	// Thumbprint of the VCenter's certificate thumbprint
 	Thumbprint string `gcfg:"thumbprint"`
+	// Specifies the path to the certificate of a solution user in PEM format.
+	// If set, vCenter is logged in to with a SAML token issued by its STS for
+	// the solution user instead of with user and password.
+	CertFile string `gcfg:"cert-file"`
+	// Specifies the path to the private key of CertFile in PEM format.
+	KeyFile string `gcfg:"key-file"`
+	// URL of the HTTP, HTTPS or SOCKS5 proxy vCenter is reached through, e.g.
+	// http://proxy.example.com:3128. Optional; if not configured, the
+	// HTTPS_PROXY and NO_PROXY environment variables are honoured.
+	ProxyURL string `gcfg:"proxy-url"`
+	// Timeout in seconds for establishing a connection to vCenter
+	// Default: 0, only the timeouts of the operating system apply
+	ConnectTimeout uint `gcfg:"connect-timeout"`
+	// Timeout in seconds of a single request to vCenter, including reading
+	// its response
+	// Default: 0, no timeout
+	RequestTimeout uint `gcfg:"request-timeout"`
+	// Minimum TLS version used to connect to vCenter: 1.0, 1.1, 1.2 or 1.3
+	// Default: the default of Go
+	TLSMinVersion string `gcfg:"tls-min-version"`
+	// Maximum rate of requests per second to vCenter. Requests in excess of it
+	// wait for their turn.
+	// Default: 0, no limit
+	RateLimitQPS float32 `gcfg:"rate-limit-qps"`
+	// Number of requests to vCenter that may exceed RateLimitQPS in a burst
+	// Default: 10
+	RateLimitBurst uint `gcfg:"rate-limit-burst"`
 	// SecretRef (intentionally not exposed via the config) is a key to identify which
 	// InformerManager holds the secret
 	SecretRef string
@@ -107,12 +189,23 @@ type VirtualCenterConfigINI struct {
 	IPFamily string `gcfg:"ip-family"`
 	// IPFamilyPriority (intentionally not exposed via the config) the list/priority of IP versions
 	IPFamilyPriority []string
+	// Tag category of the zones of the vCenter, overriding the one of the
+	// Labels section. Optional.
+	Zone string `gcfg:"zone"`
+	// Tag category of the regions of the vCenter, overriding the one of the
+	// Labels section. Optional.
+	Region string `gcfg:"region"`
+	// Comma separated tag categories whose tags are set as labels of the
+	// nodes of the vCenter, overriding the ones of the Labels section. Optional.
+	LabelCategories string `gcfg:"label-categories"`
 }
 
 // LabelsINI tags categories and tags which correspond to "built-in node labels: zones and region"
 type LabelsINI struct {
 	Zone   string `gcfg:"zone"`
 	Region string `gcfg:"region"`
+	// Comma separated tag categories whose tags are set as labels of the nodes
+	Categories string `gcfg:"categories"`
 }
 
 // CommonConfigINI is used to read and store information from the cloud configuration file
@@ -49,6 +49,35 @@ type GlobalYAML struct {
 	CAFile string `yaml:"caFile"`
 	// Thumbprint of the VCenter's certificate thumbprint
 	Thumbprint string `yaml:"thumbprint"`
+	// Specifies the path to the certificate of a solution user in PEM format.
+	// If set, vCenter is logged in to with a SAML token issued by its STS for
+	// the solution user instead of with user and password.
+	CertFile string `yaml:"certFile"`
+	// Specifies the path to the private key of CertFile in PEM format.
+	KeyFile string `yaml:"keyFile"`
+	// URL of the HTTP, HTTPS or SOCKS5 proxy vCenter is reached through, e.g.
+	// http://proxy.example.com:3128. Optional; if not configured, the
+	// HTTPS_PROXY and NO_PROXY environment variables are honoured.
+	ProxyURL string `yaml:"proxyURL"`
+	// Timeout in seconds for establishing a connection to vCenter
+	// Default: 0, only the timeouts of the operating system apply
+	ConnectTimeout uint `yaml:"connectTimeout"`
+	// Timeout in seconds of a single request to vCenter, including reading
+	// its response
+	// Default: 0, no timeout
+	RequestTimeout uint `yaml:"requestTimeout"`
+	// Minimum TLS version used to connect to vCenter: 1.0, 1.1, 1.2 or 1.3
+	// Default: the default of Go
+	TLSMinVersion string `yaml:"tlsMinVersion"`
+	// Maximum rate of requests per second to vCenter. Requests in excess of it
+	// wait for their turn.
+	// Default: 0, no limit
+	RateLimitQPS float32 `yaml:"rateLimitQPS"`
+	// Number of requests to vCenter that may exceed RateLimitQPS in a burst
+	// Default: 10
+	RateLimitBurst uint `yaml:"rateLimitBurst"`
+	// Default provider of the vCenter credentials, see VirtualCenterConfigYAML.
+	CredentialProvider CredentialProviderYAML `yaml:"credentialProvider"`
 	// Name of the secret were vCenter credentials are present.
 	SecretName string `yaml:"secretName"`
 	// Secret Namespace where secret will be present that has vCenter credentials.
@@ -64,6 +93,38 @@ type GlobalYAML struct {
 	// Configurable vSphere CCM API port
 	// Default: 43001
 	APIBinding string `yaml:"apiBinding"`
+	// Interval in seconds at which the vCenter sessions are checked and kept alive.
+	// Zero disables the session monitor.
+	// Default: 300
+	SessionKeepAliveInterval *uint `yaml:"sessionKeepAliveInterval"`
+	// Maximum age in seconds of a vCenter session before it is proactively
+	// re-authenticated. Zero disables proactive re-authentication.
+	// Default: 0
+	SessionMaxAge uint `yaml:"sessionMaxAge"`
+	// Timeout in seconds of a node discovery, which searches the VM of a node
+	// across all vCenters and datacenters
+	// Default: 120
+	DiscoveryTimeout uint `yaml:"discoveryTimeout"`
+	// Timeout in seconds of the vCenter calls that are not bound to a request,
+	// such as logging out or verifying the connections
+	// Default: 60
+	OperationTimeout uint `yaml:"operationTimeout"`
+	// Number of consecutive failures to connect to a vCenter after which searches
+	// skip it until its circuit breaker allows a new probe. A failure is a search
+	// which could not connect after its retries, or a failed session check.
+	// Default: 3
+	CircuitBreakerThreshold uint `yaml:"circuitBreakerThreshold"`
+	// Interval in seconds during which a vCenter is skipped once its circuit
+	// breaker opened. It doubles, with jitter, on every failed probe.
+	// Default: 30
+	CircuitBreakerOpenInterval uint `yaml:"circuitBreakerOpenInterval"`
+	// Maximum interval in seconds during which a vCenter is skipped
+	// Default: 600
+	CircuitBreakerMaxOpenInterval uint `yaml:"circuitBreakerMaxOpenInterval"`
+	// Reject keys of the YAML cloud config that no setting is read from,
+	// instead of ignoring them. Also enabled by VSPHERE_STRICT_CONFIG.
+	// Default: false
+	StrictConfig bool `yaml:"strictConfig"`
 	// IP Family enables the ability to support IPv4 or IPv6
 	// Supported values are:
 	// ipv4 - IPv4 addresses only (Default)
@@ -81,7 +142,7 @@ type VirtualCenterConfigYAML struct {
 	// TenantRef (intentionally not exposed via the config) is a unique tenant ref to
 	// be used in place of the vcServer as the primary connection key. If one label is set,
 	// all virtual center configs must have a unique label.
-	TenantRef string
+	TenantRef string `yaml:"-"`
 	// vCenterIP - If this field in the config is set, it is assumed then that value in [VirtualCenter "<value>"]
 	// is now the TenantRef above and this field is the actual VCenterIP. Otherwise for backward
 	// compatibility, the value by default is the IP or FQDN of the vCenter Server.
@@ -99,9 +160,39 @@ type VirtualCenterConfigYAML struct {
 	CAFile string `yaml:"caFile"`
 	// Thumbprint of the VCenter's certificate thumbprint
 	Thumbprint string `yaml:"thumbprint"`
+	// Specifies the path to the certificate of a solution user in PEM format.
+	// If set, vCenter is logged in to with a SAML token issued by its STS for
+	// the solution user instead of with user and password.
+	CertFile string `yaml:"certFile"`
+	// Specifies the path to the private key of CertFile in PEM format.
+	KeyFile string `yaml:"keyFile"`
+	// URL of the HTTP, HTTPS or SOCKS5 proxy vCenter is reached through, e.g.
+	// http://proxy.example.com:3128. Optional; if not configured, the
+	// HTTPS_PROXY and NO_PROXY environment variables are honoured.
+	ProxyURL string `yaml:"proxyURL"`
+	// Timeout in seconds for establishing a connection to vCenter
+	// Default: 0, only the timeouts of the operating system apply
+	ConnectTimeout uint `yaml:"connectTimeout"`
+	// Timeout in seconds of a single request to vCenter, including reading
+	// its response
+	// Default: 0, no timeout
+	RequestTimeout uint `yaml:"requestTimeout"`
+	// Minimum TLS version used to connect to vCenter: 1.0, 1.1, 1.2 or 1.3
+	// Default: the default of Go
+	TLSMinVersion string `yaml:"tlsMinVersion"`
+	// Maximum rate of requests per second to vCenter. Requests in excess of it
+	// wait for their turn.
+	// Default: 0, no limit
+	RateLimitQPS float32 `yaml:"rateLimitQPS"`
+	// Number of requests to vCenter that may exceed RateLimitQPS in a burst
+	// Default: 10
+	RateLimitBurst uint `yaml:"rateLimitBurst"`
+	// Provider of the vCenter credentials. If an external provider is set, the
+	// credentials are obtained from it instead of from the secret.
+	CredentialProvider CredentialProviderYAML `yaml:"credentialProvider"`
 	// SecretRef (intentionally not exposed via the config) is a key to identify which
 	// InformerManager holds the secret
-	SecretRef string
+	SecretRef string `yaml:"-"`
 	// Name of the secret where vCenter credentials are present.
 	SecretName string `yaml:"secretName"`
 	// Namespace where the secret will be present containing vCenter credentials.
@@ -111,12 +202,41 @@ type VirtualCenterConfigYAML struct {
 	// ipv4 - IPv4 addresses only (Default)
 	// ipv6 - IPv6 addresses only
 	IPFamilyPriority []string `yaml:"ipFamily"`
+	// Tag categories of the zones, regions and node labels of the vCenter,
+	// overriding the ones of the labels section. Optional.
+	Labels LabelsYAML `yaml:"labels"`
+}
+
+// CredentialProviderYAML selects where the credentials of a vCenter are obtained from
+type CredentialProviderYAML struct {
+	// Type of the provider. Supported values are:
+	// secret - the configured Kubernetes secret or secrets directory (Default)
+	// exec - the output of a command
+	// vault - a secret read from a Vault compatible HTTP server
+	Type string `yaml:"type"`
+	// Command run by the exec provider.
+	Command string `yaml:"command"`
+	// Arguments of Command.
+	Args []string `yaml:"args"`
+	// Address of the Vault compatible HTTP server, e.g. https://vault:8200
+	Address string `yaml:"address"`
+	// Path of the secret holding the credentials, e.g. secret/data/vsphere
+	Path string `yaml:"path"`
+	// File holding the token used to authenticate with the HTTP server.
+	// Default: the VAULT_TOKEN environment variable
+	TokenFile string `yaml:"tokenFile"`
+	// Specifies the path to a CA certificate in PEM format used to verify the
+	// HTTP server. Optional; if not configured, the system's CA certificates
+	// will be used.
+	CAFile string `yaml:"caFile"`
 }
 
 // LabelsYAML tags categories and tags which correspond to "built-in node labels: zones and region"
 type LabelsYAML struct {
 	Zone   string `yaml:"zone"`
 	Region string `yaml:"region"`
+	// Categories whose tags are set as labels of the nodes
+	Categories []string `yaml:"categories"`
 }
 
 // CommonConfigYAML is used to read and store information from the cloud configuration file
@@ -0,0 +1,224 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	klog "k8s.io/klog/v2"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
+	"k8s.io/cloud-provider-vsphere/pkg/util"
+)
+
+// CircuitState is the state of the circuit breaker of a vCenter.
+type CircuitState string
+
+const (
+	// CircuitClosed means the vCenter is used as usual.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen means the vCenter is skipped until the open interval elapsed.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen means a single probe is allowed to find out whether the
+	// vCenter recovered.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// ConnectBackoff is the backoff used to retry connecting to a vCenter whose
+// circuit is closed.
+var ConnectBackoff = wait.Backoff{
+	Steps:    NumConnectionAttempts,
+	Duration: time.Duration(RetryAttemptDelaySecs) * time.Second,
+	Factor:   2.0,
+	Jitter:   0.5,
+}
+
+// circuitBreaker keeps track of the connection failures of a vCenter, so
+// that searches across vCenters can skip the ones that are known to be down.
+type circuitBreaker struct {
+	lock sync.Mutex
+
+	threshold   int
+	openBackoff wait.Backoff
+	backoff     wait.Backoff
+
+	state     CircuitState
+	failures  int
+	openUntil time.Time
+	probing   bool
+
+	// for unit tests
+	now func() time.Time
+}
+
+func newCircuitBreaker(global *vcfg.Global) *circuitBreaker {
+	threshold := global.CircuitBreakerThreshold
+	if threshold == 0 {
+		threshold = vcfg.DefaultCircuitBreakerThreshold
+	}
+	openInterval := global.CircuitBreakerOpenInterval
+	if openInterval == 0 {
+		openInterval = vcfg.DefaultCircuitBreakerOpenInterval
+	}
+	maxOpenInterval := global.CircuitBreakerMaxOpenInterval
+	if maxOpenInterval < openInterval {
+		maxOpenInterval = openInterval
+	}
+
+	openBackoff := wait.Backoff{
+		Steps:    math.MaxInt32,
+		Duration: time.Duration(openInterval) * time.Second,
+		Factor:   2.0,
+		Jitter:   0.2,
+		Cap:      time.Duration(maxOpenInterval) * time.Second,
+	}
+	return &circuitBreaker{
+		threshold:   int(threshold),
+		openBackoff: openBackoff,
+		backoff:     openBackoff,
+		state:       CircuitClosed,
+		now:         time.Now,
+	}
+}
+
+// State returns the current state of the circuit.
+func (cb *circuitBreaker) State() CircuitState {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	return cb.state
+}
+
+// allow reports whether the vCenter may be used, and whether the caller is
+// the probe of a half-open circuit.
+func (cb *circuitBreaker) allow() (allowed bool, probe bool) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if cb.now().Before(cb.openUntil) {
+			return false, false
+		}
+		cb.state = CircuitHalfOpen
+		cb.probing = true
+		return true, true
+	case CircuitHalfOpen:
+		if cb.probing {
+			return false, false
+		}
+		cb.probing = true
+		return true, true
+	}
+	return true, false
+}
+
+// abort releases the probe of a half-open circuit without recording an outcome.
+func (cb *circuitBreaker) abort() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	cb.probing = false
+}
+
+// record updates the circuit with the outcome of a connection attempt and
+// returns the previous and the new state.
+func (cb *circuitBreaker) record(err error) (CircuitState, CircuitState) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	previous := cb.state
+	cb.probing = false
+	if err == nil {
+		cb.state = CircuitClosed
+		cb.failures = 0
+		cb.backoff = cb.openBackoff
+		return previous, cb.state
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.threshold {
+		cb.state = CircuitOpen
+		cb.openUntil = cb.now().Add(cb.backoff.Step())
+	}
+	return previous, cb.state
+}
+
+// connectForSearch connects to the vCenter for a search across vCenters.
+// It returns ErrCircuitOpen without contacting vCenter if its circuit is
+// open, and otherwise retries with ConnectBackoff.
+func (connMgr *ConnectionManager) connectForSearch(ctx context.Context, vsi *VSphereInstance) error {
+	ctx, span := tracing.Start(ctx, "ConnectionManager.connectForSearch", tracing.String("vcenter", vsi.Cfg.VCenterIP))
+	err := connMgr.connectWithBreaker(ctx, vsi)
+	if err == ErrCircuitOpen {
+		span.SetAttributes(tracing.Bool("skipped", true))
+	}
+	span.End(err)
+	return err
+}
+
+func (connMgr *ConnectionManager) connectWithBreaker(ctx context.Context, vsi *VSphereInstance) error {
+	allowed, probe := vsi.breaker.allow()
+	if !allowed {
+		klog.V(2).Infof("Skipping vCenter %s, its circuit breaker is open", vsi.Cfg.VCenterIP)
+		searchSkippedMetric.WithLabelValues(vsi.Cfg.VCenterIP).Inc()
+		return ErrCircuitOpen
+	}
+
+	backoff := ConnectBackoff
+	if probe {
+		// A single attempt is enough to find out whether vCenter recovered
+		backoff.Steps = 1
+	}
+	err := util.RetryOnError(backoff, func(error) bool { return ctx.Err() == nil }, func() error {
+		return connMgr.Connect(ctx, vsi)
+	})
+	if ctx.Err() != nil {
+		// the caller gave up, this says nothing about vCenter
+		vsi.breaker.abort()
+		return err
+	}
+	connMgr.recordCircuit(vsi, err)
+	return err
+}
+
+// recordCircuit records the outcome of a connection attempt in the circuit
+// breaker of the vCenter.
+func (connMgr *ConnectionManager) recordCircuit(vsi *VSphereInstance, err error) {
+	previous, state := vsi.breaker.record(err)
+	if previous == state {
+		return
+	}
+	klog.Infof("vCenter %s circuit breaker changed from %s to %s", vsi.Cfg.VCenterIP, previous, state)
+	for _, s := range []CircuitState{CircuitClosed, CircuitOpen, CircuitHalfOpen} {
+		value := 0.0
+		if s == state {
+			value = 1.0
+		}
+		circuitStateMetric.WithLabelValues(vsi.Cfg.VCenterIP, string(s)).Set(value)
+	}
+}
+
+// partialSearchError returns an error reporting the vCenters that were skipped
+// by a search which did not find the object it was looking for.
+func partialSearchError(skipped []string) error {
+	return fmt.Errorf("%w: skipped vCenters %v", ErrPartialSearch, skipped)
+}
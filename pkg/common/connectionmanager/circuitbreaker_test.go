@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/simulator"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	cb := newCircuitBreaker(&vcfg.Global{
+		CircuitBreakerThreshold:       2,
+		CircuitBreakerOpenInterval:    10,
+		CircuitBreakerMaxOpenInterval: 15,
+	})
+	cb.now = func() time.Time { return now }
+	failure := errors.New("connection refused")
+
+	if _, state := cb.record(failure); state != CircuitClosed {
+		t.Fatalf("expected circuit to stay %s below the threshold, got %s", CircuitClosed, state)
+	}
+	if _, state := cb.record(failure); state != CircuitOpen {
+		t.Fatalf("expected circuit to be %s at the threshold, got %s", CircuitOpen, state)
+	}
+	if allowed, _ := cb.allow(); allowed {
+		t.Fatalf("expected open circuit to reject requests")
+	}
+
+	// open interval of 10s with a jitter of up to 20%
+	now = now.Add(13 * time.Second)
+	allowed, probe := cb.allow()
+	if !allowed || !probe {
+		t.Fatalf("expected a probe once the open interval elapsed, got allowed=%t probe=%t", allowed, probe)
+	}
+	if allowed, _ := cb.allow(); allowed {
+		t.Fatalf("expected a single probe while half-open")
+	}
+
+	// a failed probe opens the circuit again, for twice as long up to the maximum
+	if _, state := cb.record(failure); state != CircuitOpen {
+		t.Fatalf("expected failed probe to open the circuit, got %s", state)
+	}
+	now = now.Add(13 * time.Second)
+	if allowed, _ := cb.allow(); allowed {
+		t.Fatalf("expected open interval to back off after a failed probe")
+	}
+	now = now.Add(6 * time.Second)
+	if allowed, probe := cb.allow(); !allowed || !probe {
+		t.Fatalf("expected open interval to be capped, got allowed=%t probe=%t", allowed, probe)
+	}
+
+	if _, state := cb.record(nil); state != CircuitClosed {
+		t.Fatalf("expected successful probe to close the circuit, got %s", state)
+	}
+	if allowed, probe := cb.allow(); !allowed || probe {
+		t.Fatalf("expected closed circuit to allow requests, got allowed=%t probe=%t", allowed, probe)
+	}
+}
+
+func TestCircuitBreakerAbortedProbe(t *testing.T) {
+	cb := newCircuitBreaker(&vcfg.Global{CircuitBreakerThreshold: 1})
+	cb.record(errors.New("connection refused"))
+	cb.lock.Lock()
+	cb.openUntil = time.Time{}
+	cb.lock.Unlock()
+
+	if allowed, probe := cb.allow(); !allowed || !probe {
+		t.Fatalf("expected a probe, got allowed=%t probe=%t", allowed, probe)
+	}
+	cb.abort()
+	if allowed, probe := cb.allow(); !allowed || !probe {
+		t.Fatalf("expected a new probe after an aborted one, got allowed=%t probe=%t", allowed, probe)
+	}
+}
+
+func TestWhichVCandDCByNodeIDPartialSearch(t *testing.T) {
+	backoff := ConnectBackoff
+	defer func() { ConnectBackoff = backoff }()
+	ConnectBackoff.Duration = time.Millisecond
+
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	// a vCenter refusing connections
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+	config.VirtualCenter["dead"] = &vcfg.VirtualCenterConfig{
+		User:             config.Global.User,
+		Password:         config.Global.Password,
+		TenantRef:        "dead",
+		VCenterIP:        host,
+		VCenterPort:      port,
+		InsecureFlag:     true,
+		Datacenters:      config.Global.Datacenters,
+		IPFamilyPriority: []string{vcfg.DefaultIPFamily},
+	}
+	config.Global.CircuitBreakerThreshold = 1
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+	ctx := context.Background()
+
+	// the first search trips the circuit of the dead vCenter
+	_, err = connMgr.WhichVCandDCByNodeID(ctx, "unknown-node", FindVMByName)
+	if err == nil || errors.Is(err, ErrPartialSearch) {
+		t.Fatalf("expected the connection error of the dead vCenter, got %v", err)
+	}
+	if state := connMgr.VsphereInstanceMap["dead"].breaker.State(); state != CircuitOpen {
+		t.Fatalf("expected circuit of the dead vCenter to be %s, got %s", CircuitOpen, state)
+	}
+
+	// subsequent searches skip it, and report it when nothing was found
+	_, err = connMgr.WhichVCandDCByNodeID(ctx, "unknown-node", FindVMByName)
+	if !errors.Is(err, ErrPartialSearch) {
+		t.Fatalf("expected %v, got %v", ErrPartialSearch, err)
+	}
+	if !strings.Contains(err.Error(), host) {
+		t.Errorf("expected skipped vCenter in %q", err)
+	}
+
+	vm := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+	info, err := connMgr.WhichVCandDCByNodeID(ctx, vm.Config.Uuid, FindVMByUUID)
+	if err != nil {
+		t.Fatalf("WhichVCandDCByNodeID err=%v", err)
+	}
+	if info.VcServer != config.Global.VCenterIP {
+		t.Errorf("expected VM to be found in vc=%s, got %s", config.Global.VCenterIP, info.VcServer)
+	}
+}
@@ -19,6 +19,7 @@ package connectionmanager
 import (
 	"context"
 	"strings"
+	"time"
 
 	clientset "k8s.io/client-go/kubernetes"
 	listerv1 "k8s.io/client-go/listers/core/v1"
@@ -38,13 +39,27 @@ func NewConnectionManager(cfg *vcfg.Config, informMgr *k8s.InformerManager, clie
 	connMgr := &ConnectionManager{
 		client:             client,
 		VsphereInstanceMap: generateInstanceMap(cfg),
-		credentialManagers: make(map[string]*cm.CredentialManager),
+		credentialManagers: make(map[string]cm.CredentialProvider),
 		informerManagers:   make(map[string]*k8s.InformerManager),
+
+		sessionKeepAliveInterval: time.Duration(cfg.Global.SessionKeepAliveInterval) * time.Second,
+		sessionMaxAge:            time.Duration(cfg.Global.SessionMaxAge) * time.Second,
+		operationTimeout:         time.Duration(cfg.Global.OperationTimeout) * time.Second,
+	}
+	registerMetrics()
+
+	for _, vsi := range connMgr.VsphereInstanceMap {
+		connMgr.initializeCredentialProvider(vsi)
 	}
 
 	if informMgr != nil {
 		klog.V(2).Info("Initializing with K8s SecretLister")
-		credMgr := cm.NewCredentialManager(cfg.Global.SecretName, cfg.Global.SecretNamespace, "", informMgr.GetSecretLister())
+		secretsDirectory := ""
+		if cfg.Global.SecretName == "" {
+			// credentials mounted into the pod rather than read through the API
+			secretsDirectory = cfg.Global.SecretsDirectory
+		}
+		credMgr := cm.NewCredentialManager(cfg.Global.SecretName, cfg.Global.SecretNamespace, secretsDirectory, informMgr.GetSecretLister())
 		connMgr.credentialManagers[vcfg.DefaultCredentialManager] = credMgr
 		connMgr.informerManagers[vcfg.DefaultCredentialManager] = informMgr
 
@@ -72,19 +87,32 @@ func generateInstanceMap(cfg *vcfg.Config) map[string]*VSphereInstance {
 	vsphereInstanceMap := make(map[string]*VSphereInstance)
 
 	for _, vcConfig := range cfg.VirtualCenter {
+		// validated with the config
+		tlsMinVersion, _ := vcfg.TLSVersion(vcConfig.TLSMinVersion)
 		vSphereConn := vclib.VSphereConnection{
 			Username:          vcConfig.User,
 			Password:          vcConfig.Password,
+			CertFile:          vcConfig.CertFile,
+			KeyFile:           vcConfig.KeyFile,
 			Hostname:          vcConfig.VCenterIP,
 			Insecure:          vcConfig.InsecureFlag,
 			RoundTripperCount: vcConfig.RoundTripperCount,
 			Port:              vcConfig.VCenterPort,
 			CACert:            vcConfig.CAFile,
 			Thumbprint:        vcConfig.Thumbprint,
+			ProxyURL:          vcConfig.ProxyURL,
+			ConnectTimeout:    time.Duration(vcConfig.ConnectTimeout) * time.Second,
+			RequestTimeout:    time.Duration(vcConfig.RequestTimeout) * time.Second,
+			TLSMinVersion:     tlsMinVersion,
+			RateLimitQPS:      vcConfig.RateLimitQPS,
+			RateLimitBurst:    int(vcConfig.RateLimitBurst),
 		}
 		vsphereIns := VSphereInstance{
-			Conn: &vSphereConn,
-			Cfg:  vcConfig,
+			Conn:     &vSphereConn,
+			Cfg:      vcConfig,
+			breaker:  newCircuitBreaker(&cfg.Global),
+			caDigest: fileDigest(vcConfig.CAFile),
+			removed:  make(chan struct{}),
 		}
 		vsphereInstanceMap[vcConfig.TenantRef] = &vsphereIns
 	}
@@ -96,19 +124,64 @@ func generateInstanceMap(cfg *vcfg.Config) map[string]*VSphereInstance {
 // handled through the Default/Global lister tied to the default service account.
 func (connMgr *ConnectionManager) InitializeSecretLister() {
 	// For each vsi that has a Secret set createManagersPerTenant
-	for _, vInstance := range connMgr.VsphereInstanceMap {
-		klog.V(3).Infof("Checking vcServer=%s SecretRef=%s", vInstance.Cfg.VCenterIP, vInstance.Cfg.SecretRef)
-		if strings.EqualFold(vInstance.Cfg.SecretRef, vcfg.DefaultCredentialManager) {
-			klog.V(3).Infof("Skipping. vCenter %s is configured using global service account/secret.", vInstance.Cfg.VCenterIP)
-			continue
-		}
+	for _, vInstance := range connMgr.Instances() {
+		connMgr.initializeSecretLister(vInstance)
+	}
+	connMgr.Lock()
+	connMgr.secretListersInitialized = true
+	connMgr.Unlock()
+}
 
-		klog.V(3).Infof("Adding credMgr/informMgr for vcServer=%s", vInstance.Cfg.VCenterIP)
-		credsMgr, informMgr := connMgr.createManagersPerTenant(vInstance.Cfg.SecretName,
-			vInstance.Cfg.SecretNamespace, "", connMgr.client)
-		connMgr.credentialManagers[vInstance.Cfg.SecretRef] = credsMgr
-		connMgr.informerManagers[vInstance.Cfg.SecretRef] = informMgr
+func (connMgr *ConnectionManager) initializeSecretLister(vInstance *VSphereInstance) {
+	klog.V(3).Infof("Checking vcServer=%s SecretRef=%s", vInstance.Cfg.VCenterIP, vInstance.Cfg.SecretRef)
+	if strings.EqualFold(vInstance.Cfg.SecretRef, vcfg.DefaultCredentialManager) {
+		klog.V(3).Infof("Skipping. vCenter %s is configured using global service account/secret.", vInstance.Cfg.VCenterIP)
+		return
+	}
+	if vInstance.Cfg.CredentialProvider.IsExternal() {
+		klog.V(3).Infof("Skipping. vCenter %s is configured using a %s credential provider.",
+			vInstance.Cfg.VCenterIP, vInstance.Cfg.CredentialProvider.Type)
+		return
 	}
+
+	klog.V(3).Infof("Adding credMgr/informMgr for vcServer=%s", vInstance.Cfg.VCenterIP)
+	credsMgr, informMgr := connMgr.createManagersPerTenant(vInstance.Cfg.SecretName,
+		vInstance.Cfg.SecretNamespace, "", connMgr.client)
+	connMgr.Lock()
+	connMgr.credentialManagers[vInstance.Cfg.SecretRef] = credsMgr
+	connMgr.informerManagers[vInstance.Cfg.SecretRef] = informMgr
+	connMgr.Unlock()
+}
+
+// initializeCredentialProvider creates the external credential provider of
+// the vCenter, if it is configured with one.
+func (connMgr *ConnectionManager) initializeCredentialProvider(vInstance *VSphereInstance) {
+	if !vInstance.Cfg.CredentialProvider.IsExternal() {
+		return
+	}
+	klog.V(3).Infof("Adding %s credential provider for vcServer=%s", vInstance.Cfg.CredentialProvider.Type,
+		vInstance.Cfg.VCenterIP)
+	provider, err := cm.NewCredentialProvider(&vInstance.Cfg.CredentialProvider)
+	if err != nil {
+		klog.Errorf("Failed to create the credential provider of vcServer=%s: %v", vInstance.Cfg.VCenterIP, err)
+		return
+	}
+	connMgr.Lock()
+	connMgr.credentialManagers[vInstance.Cfg.SecretRef] = provider
+	connMgr.Unlock()
+}
+
+// Instances returns the current map of vCenter instances keyed by tenantRef.
+// The returned map must not be modified.
+func (connMgr *ConnectionManager) Instances() map[string]*VSphereInstance {
+	connMgr.Lock()
+	defer connMgr.Unlock()
+	return connMgr.VsphereInstanceMap
+}
+
+// Instance returns the vCenter instance for the given tenantRef, or nil.
+func (connMgr *ConnectionManager) Instance(tenantRef string) *VSphereInstance {
+	return connMgr.Instances()[tenantRef]
 }
 
 func (connMgr *ConnectionManager) createManagersPerTenant(secretName string, secretNamespace string,
@@ -136,10 +209,42 @@ func (connMgr *ConnectionManager) createManagersPerTenant(secretName string, sec
 //      2. Update the credentials
 //		3. Connects again to vCenter with fetched credentials
 func (connMgr *ConnectionManager) Connect(ctx context.Context, vcInstance *VSphereInstance) error {
-	connMgr.Lock()
-	defer connMgr.Unlock()
+	// The session monitor keeps the session alive in the background, so there
+	// is no need to probe it again if it has been verified recently and no
+	// request has been rejected as not authenticated since.
+	if vcInstance.isSessionFresh(connMgr.sessionKeepAliveInterval * 3 / 2) {
+		return nil
+	}
+	return connMgr.connect(ctx, vcInstance)
+}
+
+// connect connects to vCenter, reusing the existing session if it is still valid.
+// Concurrent logins are coalesced per vCenter by vclib, so a slow or unreachable
+// vCenter does not hold up connections to the other ones.
+func (connMgr *ConnectionManager) connect(ctx context.Context, vcInstance *VSphereInstance) error {
+	return connMgr.connectWithCredentialRefresh(ctx, vcInstance, vcInstance.Conn.Connect)
+}
+
+// relogin replaces the session of the vCenter with a newly authenticated one.
+func (connMgr *ConnectionManager) relogin(ctx context.Context, vcInstance *VSphereInstance) error {
+	return connMgr.connectWithCredentialRefresh(ctx, vcInstance, vcInstance.Conn.Relogin)
+}
+
+// connectWithCredentialRefresh calls connectFunc and retries it with the credentials
+// from the credential manager if vCenter rejected the current ones. vCenters
+// using an external credential provider have no credentials in the configuration,
+// so they are fetched from the provider before the first login.
+func (connMgr *ConnectionManager) connectWithCredentialRefresh(ctx context.Context, vcInstance *VSphereInstance,
+	connectFunc func(context.Context) error) error {
+	if vcInstance.Cfg.CredentialProvider.IsExternal() && !vcInstance.Conn.Connected() {
+		klog.V(2).Infof("Fetching credentials from the %s credential provider before the first login. vcServer=%s",
+			vcInstance.Cfg.CredentialProvider.Type, vcInstance.Cfg.VCenterIP)
+		if err := connMgr.refreshCredentials(vcInstance); err != nil {
+			return err
+		}
+	}
 
-	err := vcInstance.Conn.Connect(ctx)
+	err := connectFunc(ctx)
 	if err == nil {
 		return nil
 	}
@@ -149,55 +254,84 @@ func (connMgr *ConnectionManager) Connect(ctx context.Context, vcInstance *VSphe
 		return err
 	}
 
-	klog.V(2).Infof("Invalid credentials. Fetching credentials from secrets. vcServer=%s credentialHolder=%s",
+	klog.V(2).Infof("Invalid credentials. Fetching credentials from the credential provider. vcServer=%s credentialHolder=%s",
 		vcInstance.Cfg.VCenterIP, vcInstance.Cfg.SecretRef)
+	if err := connMgr.refreshCredentials(vcInstance); err != nil {
+		return err
+	}
+	return connectFunc(ctx)
+}
 
+// refreshCredentials updates the credentials of the vCenter from its credential manager
+func (connMgr *ConnectionManager) refreshCredentials(vcInstance *VSphereInstance) error {
+	connMgr.Lock()
 	credMgr := connMgr.credentialManagers[vcInstance.Cfg.SecretRef]
+	connMgr.Unlock()
 	if credMgr == nil {
 		klog.Errorf("Unable to find credential manager for vcServer=%s credentialHolder=%s", vcInstance.Cfg.VCenterIP, vcInstance.Cfg.SecretRef)
 		return ErrUnableToFindCredentialManager
 	}
 	credentials, err := credMgr.GetCredential(vcInstance.Cfg.VCenterIP)
 	if err != nil {
-		klog.Error("Failed to get credentials from Secret Credential Manager with err:", err)
+		klog.Error("Failed to get credentials from the credential provider with err:", err)
 		return err
 	}
-	vcInstance.Conn.UpdateCredentials(credentials.User, credentials.Password)
-	return vcInstance.Conn.Connect(ctx)
+	updateCredentials(vcInstance, credentials)
+	return nil
+}
+
+// updateCredentials sets the credentials used for the next login to the vCenter
+func updateCredentials(vcInstance *VSphereInstance, credentials *cm.Credential) {
+	if credentials.Certificate != "" {
+		vcInstance.Conn.UpdateCertificate(credentials.Certificate, credentials.PrivateKey)
+	} else {
+		vcInstance.Conn.UpdateCredentials(credentials.User, credentials.Password)
+	}
+	if credentials.Thumbprint != "" {
+		vcInstance.Conn.UpdateThumbprint(credentials.Thumbprint)
+	}
+}
+
+// withOperationTimeout returns a context bound by the operation timeout, if
+// one is configured.
+func (connMgr *ConnectionManager) withOperationTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if connMgr.operationTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, connMgr.operationTimeout)
 }
 
 // Logout closes existing connections to remote vCenter endpoints.
 func (connMgr *ConnectionManager) Logout() {
-	for _, vsphereIns := range connMgr.VsphereInstanceMap {
-		connMgr.Lock()
-		c := vsphereIns.Conn.Client
-		connMgr.Unlock()
-		if c != nil {
-			vsphereIns.Conn.Logout(context.TODO())
-		}
+	connMgr.LogoutWithContext(context.Background())
+}
+
+// LogoutWithContext is the same as Logout but allows a Go Context
+// to control the lifecycle of the logout calls.
+func (connMgr *ConnectionManager) LogoutWithContext(ctx context.Context) {
+	for _, vsphereIns := range connMgr.Instances() {
+		connMgr.logout(ctx, vsphereIns)
 	}
 }
 
+// logout closes the connection to a single vCenter within the operation timeout.
+func (connMgr *ConnectionManager) logout(ctx context.Context, vcInstance *VSphereInstance) {
+	ctx, cancel := connMgr.withOperationTimeout(ctx)
+	defer cancel()
+	vcInstance.Conn.Logout(ctx)
+}
+
 // Verify validates the configuration by attempting to connect to the
 // configured, remote vCenter endpoints.
 func (connMgr *ConnectionManager) Verify() error {
-	for _, vcInstance := range connMgr.VsphereInstanceMap {
-		err := connMgr.Connect(context.Background(), vcInstance)
-		if err == nil {
-			klog.V(3).Infof("vCenter connect %s succeeded.", vcInstance.Cfg.VCenterIP)
-		} else {
-			klog.Errorf("vCenter %s failed. Err: %q", vcInstance.Cfg.VCenterIP, err)
-			return err
-		}
-	}
-	return nil
+	return connMgr.VerifyWithContext(context.Background())
 }
 
 // VerifyWithContext is the same as Verify but allows a Go Context
 // to control the lifecycle of the connection event.
 func (connMgr *ConnectionManager) VerifyWithContext(ctx context.Context) error {
-	for _, vcInstance := range connMgr.VsphereInstanceMap {
-		err := connMgr.Connect(ctx, vcInstance)
+	for _, vcInstance := range connMgr.Instances() {
+		err := connMgr.connectWithOperationTimeout(ctx, vcInstance)
 		if err == nil {
 			klog.V(3).Infof("vCenter connect %s succeeded.", vcInstance.Cfg.VCenterIP)
 		} else {
@@ -208,11 +342,25 @@ func (connMgr *ConnectionManager) VerifyWithContext(ctx context.Context) error {
 	return nil
 }
 
+// connectWithOperationTimeout connects to a single vCenter within the
+// operation timeout.
+func (connMgr *ConnectionManager) connectWithOperationTimeout(ctx context.Context, vcInstance *VSphereInstance) error {
+	ctx, cancel := connMgr.withOperationTimeout(ctx)
+	defer cancel()
+	return connMgr.Connect(ctx, vcInstance)
+}
+
 // APIVersion returns the version of the vCenter API
 func (connMgr *ConnectionManager) APIVersion(vcInstance *VSphereInstance) (string, error) {
-	if err := connMgr.Connect(context.Background(), vcInstance); err != nil {
+	return connMgr.APIVersionWithContext(context.Background(), vcInstance)
+}
+
+// APIVersionWithContext is the same as APIVersion but allows a Go Context
+// to control the lifecycle of the connection event.
+func (connMgr *ConnectionManager) APIVersionWithContext(ctx context.Context, vcInstance *VSphereInstance) (string, error) {
+	if err := connMgr.connectWithOperationTimeout(ctx, vcInstance); err != nil {
 		return "", err
 	}
 
-	return vcInstance.Conn.Client.ServiceContent.About.ApiVersion, nil
+	return vcInstance.Conn.CurrentClient().ServiceContent.About.ApiVersion, nil
 }
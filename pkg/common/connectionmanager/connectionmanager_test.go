@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+)
+
+const hungTenantRef = "hung"
+
+// hungVCenter accepts connections but never responds, like a vCenter that
+// is stuck or behind a black-holing network.
+type hungVCenter struct {
+	listener net.Listener
+	accepted int32
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+func newHungVCenter(t testing.TB) *hungVCenter {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	h := &hungVCenter{listener: l}
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&h.accepted, 1)
+			h.mu.Lock()
+			h.conns = append(h.conns, conn)
+			h.mu.Unlock()
+		}
+	}()
+	return h
+}
+
+func (h *hungVCenter) Accepted() int {
+	return int(atomic.LoadInt32(&h.accepted))
+}
+
+func (h *hungVCenter) Close() {
+	h.listener.Close()
+	h.wg.Wait()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, conn := range h.conns {
+		conn.Close()
+	}
+}
+
+// addHungVCenter adds the hung vCenter to the config built by configFromSim
+func addHungVCenter(cfg *vcfg.Config, h *hungVCenter) {
+	host, port, _ := net.SplitHostPort(h.listener.Addr().String())
+	cfg.VirtualCenter[hungTenantRef] = &vcfg.VirtualCenterConfig{
+		User:             cfg.Global.User,
+		Password:         cfg.Global.Password,
+		TenantRef:        hungTenantRef,
+		VCenterIP:        host,
+		VCenterPort:      port,
+		InsecureFlag:     true,
+		Datacenters:      cfg.Global.Datacenters,
+		IPFamilyPriority: []string{vcfg.DefaultIPFamily},
+	}
+}
+
+func waitForAccepted(t testing.TB, h *hungVCenter, count int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for h.Accepted() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("hung vCenter accepted %d connections, expected %d", h.Accepted(), count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConnectWithHungVCenter(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+	hung := newHungVCenter(t)
+	defer hung.Close()
+	addHungVCenter(config, hung)
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+
+	hungCtx, cancel := context.WithCancel(context.Background())
+	hungDone := make(chan error)
+	go func() {
+		hungDone <- connMgr.Connect(hungCtx, connMgr.VsphereInstanceMap[hungTenantRef])
+	}()
+	waitForAccepted(t, hung, 1)
+
+	ctx, cancelHealthy := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelHealthy()
+	for i := 0; i < 3; i++ {
+		if err := connMgr.Connect(ctx, connMgr.VsphereInstanceMap[config.Global.VCenterIP]); err != nil {
+			t.Fatalf("Connect to the healthy vCenter failed while another vCenter hangs: %v", err)
+		}
+	}
+
+	select {
+	case err := <-hungDone:
+		t.Fatalf("Connect to the hung vCenter returned early: %v", err)
+	default:
+	}
+	cancel()
+	if err := <-hungDone; err == nil {
+		t.Errorf("expected Connect to the hung vCenter to fail")
+	}
+}
+
+func TestConnectCoalescesLogins(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+	hung := newHungVCenter(t)
+	defer hung.Close()
+	addHungVCenter(config, hung)
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	vsi := connMgr.VsphereInstanceMap[hungTenantRef]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		leaderDone <- connMgr.Connect(ctx, vsi)
+	}()
+	waitForAccepted(t, hung, 1)
+
+	// Concurrent callers share the in-flight login and give up with their own context
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			waiterCtx, cancelWaiter := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancelWaiter()
+			if err := connMgr.Connect(waiterCtx, vsi); err != context.DeadlineExceeded {
+				t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if accepted := hung.Accepted(); accepted != 1 {
+		t.Errorf("expected a single login attempt, got %d", accepted)
+	}
+
+	// The login is not bound to the context of the caller which started it
+	cancel()
+	if err := <-leaderDone; err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	waiterCtx, cancelWaiter := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelWaiter()
+	if err := connMgr.Connect(waiterCtx, vsi); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if accepted := hung.Accepted(); accepted != 1 {
+		t.Errorf("expected the login to outlive its first caller, got %d login attempts", accepted)
+	}
+}
+
+// BenchmarkConnect measures concurrent session checks against a healthy vCenter.
+func BenchmarkConnect(b *testing.B) {
+	benchmarkConnect(b, false)
+}
+
+// BenchmarkConnectWithHungVCenter measures the same while a login to another
+// vCenter is stuck, which should not make a difference.
+func BenchmarkConnectWithHungVCenter(b *testing.B) {
+	benchmarkConnect(b, true)
+}
+
+func benchmarkConnect(b *testing.B, withHungVCenter bool) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	var hung *hungVCenter
+	if withHungVCenter {
+		hung = newHungVCenter(b)
+		defer hung.Close()
+		addHungVCenter(config, hung)
+	}
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+	vsi := connMgr.VsphereInstanceMap[config.Global.VCenterIP]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := connMgr.Connect(ctx, vsi); err != nil {
+		b.Fatalf("Connect failed: %v", err)
+	}
+	if withHungVCenter {
+		go func() {
+			_ = connMgr.Connect(ctx, connMgr.VsphereInstanceMap[hungTenantRef])
+		}()
+		waitForAccepted(b, hung, 1)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			// Bypass the session freshness check to always hit vCenter
+			if err := connMgr.connect(ctx, vsi); err != nil {
+				b.Errorf("Connect failed: %v", err)
+			}
+		}
+	})
+}
@@ -56,6 +56,8 @@ const (
 	MultiDCRequiresZonesErrMsg     = "The use of multiple Datacenters within a vCenter require the use of zones"
 	UnsupportedConfigurationErrMsg = "Unsupported configuration"
 	UnableToFindCredentialManager  = "Unable to find Credential Manager"
+	CircuitOpenErrMsg              = "vCenter skipped, its circuit breaker is open"
+	PartialSearchErrMsg            = "partial search"
 )
 
 // Error constants
@@ -66,4 +68,8 @@ var (
 	ErrMultiDCRequiresZones          = errors.New(MultiDCRequiresZonesErrMsg)
 	ErrUnsupportedConfiguration      = errors.New(UnsupportedConfigurationErrMsg)
 	ErrUnableToFindCredentialManager = errors.New(UnableToFindCredentialManager)
+	ErrCircuitOpen                   = errors.New(CircuitOpenErrMsg)
+	// ErrPartialSearch is returned, wrapped with the skipped vCenters, when an object
+	// was not found but not all vCenters could be searched as their circuit was open.
+	ErrPartialSearch = errors.New(PartialSearchErrMsg)
 )
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	v1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+
+	cm "k8s.io/cloud-provider-vsphere/pkg/common/credentialmanager"
+)
+
+// WatchSecretsDirectory watches the secrets directories of the credential
+// managers and logs in again to the vCenters whose credentials changed,
+// rather than waiting for vCenter to reject the previous ones. It returns
+// immediately; watching ends when stop is closed.
+func (connMgr *ConnectionManager) WatchSecretsDirectory(stop <-chan struct{}) {
+	connMgr.Lock()
+	credentialManagers := make(map[string]*cm.CredentialManager, len(connMgr.credentialManagers))
+	for secretRef, provider := range connMgr.credentialManagers {
+		if credMgr, ok := provider.(*cm.CredentialManager); ok {
+			credentialManagers[secretRef] = credMgr
+		}
+	}
+	connMgr.Unlock()
+
+	for secretRef, credMgr := range credentialManagers {
+		if credMgr.SecretsDirectory == "" {
+			continue
+		}
+		secretRef, credMgr := secretRef, credMgr
+		err := credMgr.WatchSecretsDirectory(stop, func(servers []string) {
+			connMgr.reauthenticate(secretRef, credMgr, servers)
+		})
+		if err != nil {
+			klog.Errorf("Failed to watch secrets directory %s: %v", credMgr.SecretsDirectory, err)
+		}
+	}
+}
+
+// AddSecretListener adds secret informer callbacks which log in again to the
+// vCenters whose credentials changed in their secret, as soon as the secret
+// changes rather than when vCenter rejects the previous credentials.
+func (connMgr *ConnectionManager) AddSecretListener(secretInformer v1.SecretInformer) error {
+	if secretInformer == nil {
+		return errors.New("failed to add vCenter secret listener as secret informer is nil")
+	}
+
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    connMgr.secretAdded,
+		UpdateFunc: connMgr.secretUpdated,
+	})
+
+	return nil
+}
+
+// secretAdded handles secret added event
+func (connMgr *ConnectionManager) secretAdded(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if secret == nil || !ok {
+		return
+	}
+	connMgr.secretChanged(secret)
+}
+
+// secretUpdated handles secret updated event
+func (connMgr *ConnectionManager) secretUpdated(oldObj, newObj interface{}) {
+	oldSecret, ok := oldObj.(*corev1.Secret)
+	if oldSecret == nil || !ok {
+		return
+	}
+	newSecret, ok := newObj.(*corev1.Secret)
+	if newSecret == nil || !ok {
+		return
+	}
+	if !reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+		connMgr.secretChanged(newSecret)
+	}
+}
+
+// secretChanged updates the credential managers reading the secret and
+// re-authenticates the vCenters whose credentials changed.
+func (connMgr *ConnectionManager) secretChanged(secret *corev1.Secret) {
+	connMgr.Lock()
+	credentialManagers := make(map[string]*cm.CredentialManager)
+	for secretRef, provider := range connMgr.credentialManagers {
+		if credMgr, ok := provider.(*cm.CredentialManager); ok && credMgr.SecretLister != nil {
+			credentialManagers[secretRef] = credMgr
+		}
+	}
+	connMgr.Unlock()
+
+	for secretRef, credMgr := range credentialManagers {
+		servers, err := credMgr.UpdateFromSecret(secret)
+		if err != nil {
+			klog.Errorf("Failed to parse secret %s/%s: %v", secret.GetNamespace(), secret.GetName(), err)
+			continue
+		}
+		if len(servers) > 0 {
+			klog.Infof("Credentials in secret %s/%s changed for servers %v", secret.GetNamespace(), secret.GetName(), servers)
+			connMgr.reauthenticate(secretRef, credMgr, servers)
+		}
+	}
+}
+
+// reauthenticate updates the credentials of the given servers from the
+// credential manager and logs in to them again in the background. vCenters
+// which have not been logged in to yet use the credentials on their first
+// login instead.
+func (connMgr *ConnectionManager) reauthenticate(secretRef string, credMgr *cm.CredentialManager, servers []string) {
+	changed := sets.NewString(servers...)
+	for _, vsi := range connMgr.Instances() {
+		if vsi.Cfg.SecretRef != secretRef || !changed.Has(vsi.Cfg.VCenterIP) {
+			continue
+		}
+		credentials, found := credMgr.Cache.GetCredential(vsi.Cfg.VCenterIP)
+		if !found {
+			continue
+		}
+		updateCredentials(vsi, &credentials)
+		if !vsi.Conn.Connected() {
+			klog.V(2).Infof("Credentials of vCenter %s changed before it was logged in to", vsi.Cfg.VCenterIP)
+			continue
+		}
+		klog.Infof("Credentials of vCenter %s changed, logging in again", vsi.Cfg.VCenterIP)
+		sessionReloginMetric.WithLabelValues(vsi.Cfg.VCenterIP).Inc()
+		go func(vsi *VSphereInstance) {
+			ctx, cancel := context.WithTimeout(context.Background(), SessionCheckTimeout)
+			defer cancel()
+			connMgr.recordSession(vsi, vsi.Conn.Relogin(ctx))
+		}(vsi)
+	}
+}
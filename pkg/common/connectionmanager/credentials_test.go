@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	cm "k8s.io/cloud-provider-vsphere/pkg/common/credentialmanager"
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+)
+
+func TestWatchSecretsDirectory(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeSecret := func(user string) {
+		server := config.Global.VCenterIP
+		ioutil.WriteFile(filepath.Join(dir, server+".username"), []byte(user), 0600)
+		ioutil.WriteFile(filepath.Join(dir, server+".password"), []byte(config.Global.Password), 0600)
+	}
+	writeSecret(config.Global.User)
+
+	debounce := cm.SecretsDirectoryDebounce
+	cm.SecretsDirectoryDebounce = 10 * time.Millisecond
+	defer func() { cm.SecretsDirectoryDebounce = debounce }()
+
+	config.Global.SecretsDirectory = dir
+	vcConfig := config.VirtualCenter[config.Global.VCenterIP]
+	vcConfig.User = ""
+	vcConfig.Password = ""
+	vcConfig.SecretRef = vcfg.DefaultCredentialManager
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+	vsi := connMgr.Instance(config.Global.VCenterIP)
+	if err := connMgr.Connect(context.Background(), vsi); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	connMgr.WatchSecretsDirectory(stop)
+
+	writeSecret("rotated")
+	err = wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		return vsi.Health().State == ConnectionStateConnected, nil
+	})
+	if err != nil {
+		t.Fatal("vCenter was not logged in to again after the credentials changed")
+	}
+	if vsi.Conn.Username != "rotated" {
+		t.Errorf("expected rotated username, got %s", vsi.Conn.Username)
+	}
+}
+
+func TestCredentialProvider(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/vsphere" || r.Header.Get("X-Vault-Token") != "s.token" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"data": {"data": {"username": %q, "password": %q}, "metadata": {}}}`,
+			config.Global.User, config.Global.Password)
+	}))
+	defer vault.Close()
+
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("s.token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	vcConfig := config.VirtualCenter[config.Global.VCenterIP]
+	vcConfig.User = ""
+	vcConfig.Password = ""
+	vcConfig.CredentialProvider = vcfg.CredentialProvider{
+		Type:      vcfg.CredentialProviderVault,
+		Address:   vault.URL,
+		Path:      "secret/data/vsphere",
+		TokenFile: tokenFile,
+	}
+	vcConfig.SecretRef = vcfg.CredentialProviderRefPrefix + vcConfig.TenantRef
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+	vsi := connMgr.Instance(config.Global.VCenterIP)
+	failedLogins := failedLoginCount(t, vsi.Cfg.VCenterIP)
+	if err := connMgr.Connect(context.Background(), vsi); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+	if vsi.Conn.Username != config.Global.User {
+		t.Errorf("expected username %s from the credential provider, got %s", config.Global.User, vsi.Conn.Username)
+	}
+	// the credentials are fetched ahead of the first login rather than
+	// after vCenter rejected the empty ones of the configuration
+	if count := failedLoginCount(t, vsi.Cfg.VCenterIP); count != failedLogins {
+		t.Errorf("expected no failed login, got %v", count-failedLogins)
+	}
+}
+
+// failedLoginCount returns the number of logins to the vCenter rejected as invalid
+func failedLoginCount(t *testing.T, vcenter string) float64 {
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string]string{"vcenter": vcenter, "api": vclib.APISOAP, "method": "Login", "fault": "InvalidLogin"}
+	count := 0.0
+	for _, family := range families {
+		if family.GetName() != "cloudprovider_vsphere_vcenter_api_request_errors_total" {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value != label.GetValue() {
+					continue metrics
+				}
+			}
+			count += metric.GetCounter().GetValue()
+		}
+	}
+	return count
+}
+
+func TestAddSecretListener(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	server := config.Global.VCenterIP
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vsphere-creds", Namespace: "kube-system", ResourceVersion: "1"},
+		Data: map[string][]byte{
+			server + ".username": []byte(config.Global.User),
+			server + ".password": []byte(config.Global.Password),
+		},
+	}
+	client := fake.NewSimpleClientset(secret)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	secretInformer := informerFactory.Core().V1().Secrets()
+
+	vcConfig := config.VirtualCenter[server]
+	vcConfig.User = ""
+	vcConfig.Password = ""
+	vcConfig.SecretRef = vcfg.DefaultCredentialManager
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+	credMgr := cm.NewCredentialManager(secret.Name, secret.Namespace, "", secretInformer.Lister())
+	connMgr.credentialManagers[vcfg.DefaultCredentialManager] = credMgr
+	if err := connMgr.AddSecretListener(secretInformer); err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	informerFactory.Start(stop)
+
+	// the credentials of the existing secret are used for the first login
+	vsi := connMgr.Instance(server)
+	err := wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		_, found := credMgr.Cache.GetCredential(server)
+		return found, nil
+	})
+	if err != nil {
+		t.Fatal("credentials were not taken from the added secret")
+	}
+	if vsi.Conn.Connected() {
+		t.Fatal("vCenter should not be logged in to before the first connect")
+	}
+	if err := connMgr.Connect(context.Background(), vsi); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+
+	rotated := secret.DeepCopy()
+	rotated.ResourceVersion = "2"
+	rotated.Data[server+".username"] = []byte("rotated")
+	if _, err := client.CoreV1().Secrets(secret.Namespace).Update(context.Background(), rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	err = wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		return vsi.Health().State == ConnectionStateConnected, nil
+	})
+	if err != nil {
+		t.Fatal("vCenter was not logged in to again after the secret changed")
+	}
+	if vsi.Conn.Username != "rotated" {
+		t.Errorf("expected rotated username, got %s", vsi.Conn.Username)
+	}
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
+	vclib "k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+)
+
+// ConnectionState describes the health of a vCenter connection as observed
+// by the session monitor.
+type ConnectionState string
+
+const (
+	// ConnectionStateUnknown is the state before the connection was checked.
+	ConnectionStateUnknown ConnectionState = "unknown"
+	// ConnectionStateConnected means the session is valid.
+	ConnectionStateConnected ConnectionState = "connected"
+	// ConnectionStateDegraded means the last checks failed, but not often
+	// enough to consider the vCenter unreachable.
+	ConnectionStateDegraded ConnectionState = "degraded"
+	// ConnectionStateUnauthenticated means vCenter rejected the credentials.
+	ConnectionStateUnauthenticated ConnectionState = "unauthenticated"
+	// ConnectionStateUnreachable means vCenter could not be reached for
+	// UnreachableThreshold consecutive checks.
+	ConnectionStateUnreachable ConnectionState = "unreachable"
+)
+
+var connectionStates = []ConnectionState{
+	ConnectionStateUnknown,
+	ConnectionStateConnected,
+	ConnectionStateDegraded,
+	ConnectionStateUnauthenticated,
+	ConnectionStateUnreachable,
+}
+
+const (
+	// UnreachableThreshold is the number of consecutive failed checks after
+	// which a vCenter is considered unreachable.
+	UnreachableThreshold int = 3
+
+	// SessionCheckTimeout is the timeout of a single session check.
+	SessionCheckTimeout = 30 * time.Second
+
+	// eventSourceComponent is the component name used for emitted events.
+	eventSourceComponent = "vsphere-cloud-controller-manager"
+)
+
+// ConnectionHealth is a snapshot of the health of a vCenter connection.
+type ConnectionHealth struct {
+	// State is the current connection state.
+	State ConnectionState
+	// LastTransition is the time the state last changed.
+	LastTransition time.Time
+	// LastCheck is the time of the last check.
+	LastCheck time.Time
+	// LastSuccess is the time of the last successful check.
+	LastSuccess time.Time
+	// LastError is the error of the last failed check, if any.
+	LastError error
+	// ConsecutiveFailures is the number of failed checks since the last
+	// successful one.
+	ConsecutiveFailures int
+}
+
+// Health returns a snapshot of the health of the vSphere instance.
+func (vsi *VSphereInstance) Health() ConnectionHealth {
+	vsi.healthLock.RLock()
+	defer vsi.healthLock.RUnlock()
+	if vsi.health.State == "" {
+		return ConnectionHealth{State: ConnectionStateUnknown}
+	}
+	return vsi.health
+}
+
+// isSessionFresh returns true if the session was successfully verified
+// within maxAge, which allows request paths to skip probing the session.
+// A session vCenter rejected a request of since is never fresh.
+func (vsi *VSphereInstance) isSessionFresh(maxAge time.Duration) bool {
+	if maxAge <= 0 || !vsi.Conn.Connected() || vsi.Conn.SessionFault() {
+		return false
+	}
+	health := vsi.Health()
+	return health.State == ConnectionStateConnected && time.Since(health.LastSuccess) < maxAge
+}
+
+// recordCheck updates the health with the result of a check and returns the
+// previous and the new state.
+func (vsi *VSphereInstance) recordCheck(err error) (ConnectionState, ConnectionState) {
+	vsi.healthLock.Lock()
+	defer vsi.healthLock.Unlock()
+
+	now := time.Now()
+	previous := vsi.health.State
+	if previous == "" {
+		previous = ConnectionStateUnknown
+	}
+
+	vsi.health.LastCheck = now
+	vsi.health.LastError = err
+	state := ConnectionStateConnected
+	if err == nil {
+		vsi.health.LastSuccess = now
+		vsi.health.ConsecutiveFailures = 0
+	} else {
+		vsi.health.ConsecutiveFailures++
+		switch {
+		case vclib.IsInvalidCredentialsError(err):
+			state = ConnectionStateUnauthenticated
+		case vsi.health.ConsecutiveFailures >= UnreachableThreshold:
+			state = ConnectionStateUnreachable
+		default:
+			state = ConnectionStateDegraded
+		}
+	}
+	if state != previous {
+		vsi.health.LastTransition = now
+	}
+	vsi.health.State = state
+	return previous, state
+}
+
+// StartSessionMonitor starts a background routine per vCenter which keeps the
+// session alive, re-authenticates it ahead of expiry and tracks the
+// connection state. It returns immediately; the routines end when stop is closed.
+func (connMgr *ConnectionManager) StartSessionMonitor(stop <-chan struct{}) {
+	if connMgr.sessionKeepAliveInterval <= 0 {
+		klog.V(2).Info("vCenter session monitor is disabled")
+		return
+	}
+	if connMgr.client != nil && connMgr.eventRecorder == nil {
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: connMgr.client.CoreV1().Events("")})
+		connMgr.eventRecorder = broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: eventSourceComponent})
+	}
+
+	connMgr.Lock()
+	connMgr.monitorStop = stop
+	connMgr.Unlock()
+
+	for _, vsi := range connMgr.Instances() {
+		go connMgr.monitorSession(vsi, stop)
+	}
+}
+
+func (connMgr *ConnectionManager) monitorSession(vsi *VSphereInstance, stop <-chan struct{}) {
+	klog.V(2).Infof("Starting session monitor for vCenter %s with interval %s", vsi.Cfg.VCenterIP, connMgr.sessionKeepAliveInterval)
+	ticker := time.NewTicker(connMgr.sessionKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		connMgr.checkSession(vsi)
+		select {
+		case <-stop:
+			return
+		case <-vsi.removed:
+			klog.V(2).Infof("Stopping session monitor for removed vCenter %s", vsi.Cfg.VCenterIP)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSession verifies the session of a vCenter, logs in again if required
+// and records the resulting connection state.
+func (connMgr *ConnectionManager) checkSession(vsi *VSphereInstance) {
+	ctx, cancel := context.WithTimeout(context.Background(), SessionCheckTimeout)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, "ConnectionManager.checkSession", tracing.String("vcenter", vsi.Cfg.VCenterIP))
+	err := connMgr.keepAlive(ctx, vsi)
+	span.End(err)
+	connMgr.recordSession(vsi, err)
+}
+
+// recordSession records the result of checking or logging in to a vCenter
+// as its connection state.
+func (connMgr *ConnectionManager) recordSession(vsi *VSphereInstance, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+		klog.Warningf("Session check for vCenter %s failed: %v", vsi.Cfg.VCenterIP, err)
+	}
+	sessionCheckMetric.WithLabelValues(vsi.Cfg.VCenterIP, result).Inc()
+	connMgr.recordCircuit(vsi, err)
+
+	previous, state := vsi.recordCheck(err)
+	if previous == state {
+		return
+	}
+	klog.Infof("vCenter %s connection state changed from %s to %s", vsi.Cfg.VCenterIP, previous, state)
+	for _, s := range connectionStates {
+		value := 0.0
+		if s == state {
+			value = 1.0
+		}
+		connectionStateMetric.WithLabelValues(vsi.Cfg.VCenterIP, string(s)).Set(value)
+	}
+	connectionStateTransitionMetric.WithLabelValues(vsi.Cfg.VCenterIP).Set(float64(vsi.Health().LastTransition.Unix()))
+	connMgr.emitStateEvent(vsi, previous, state, err)
+}
+
+// keepAlive refreshes the idle timer of the session by querying it. A new
+// session is created if the existing one is no longer valid, if its SAML token
+// is about to expire, or if it is older than the configured maximum session age.
+func (connMgr *ConnectionManager) keepAlive(ctx context.Context, vsi *VSphereInstance) error {
+	userSession, err := vsi.Conn.UserSession(ctx)
+	if err != nil {
+		return err
+	}
+	if userSession == nil {
+		// no client yet or the session expired, connect using the usual
+		// path which also refreshes the credentials if required
+		sessionReloginMetric.WithLabelValues(vsi.Cfg.VCenterIP).Inc()
+		return connMgr.connect(ctx, vsi)
+	}
+	if expiry := vsi.Conn.TokenExpiry(); !expiry.IsZero() && time.Until(expiry) < 2*connMgr.sessionKeepAliveInterval {
+		// the session ends with its SAML token, log in again with a renewed one
+		klog.V(2).Infof("Re-authenticating session of vCenter %s as its SAML token expires at %s", vsi.Cfg.VCenterIP, expiry)
+		sessionReloginMetric.WithLabelValues(vsi.Cfg.VCenterIP).Inc()
+		return connMgr.relogin(ctx, vsi)
+	}
+	if connMgr.sessionMaxAge > 0 && time.Since(userSession.LoginTime) > connMgr.sessionMaxAge {
+		klog.V(2).Infof("Re-authenticating session of vCenter %s logged in at %s", vsi.Cfg.VCenterIP, userSession.LoginTime)
+		sessionReloginMetric.WithLabelValues(vsi.Cfg.VCenterIP).Inc()
+		return connMgr.relogin(ctx, vsi)
+	}
+	return nil
+}
+
+func (connMgr *ConnectionManager) emitStateEvent(vsi *VSphereInstance, previous, state ConnectionState, err error) {
+	if connMgr.eventRecorder == nil {
+		return
+	}
+	ref := &v1.ObjectReference{
+		Kind: "VirtualCenter",
+		Name: vsi.Cfg.VCenterIP,
+	}
+	if state == ConnectionStateConnected {
+		connMgr.eventRecorder.Eventf(ref, v1.EventTypeNormal, "VCenterConnected",
+			"vCenter %s connection state changed from %s to %s", vsi.Cfg.VCenterIP, previous, state)
+		return
+	}
+	connMgr.eventRecorder.Eventf(ref, v1.EventTypeWarning, "VCenterConnectionProblem",
+		"vCenter %s connection state changed from %s to %s: %v", vsi.Cfg.VCenterIP, previous, state, err)
+}
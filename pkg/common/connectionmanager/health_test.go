@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+)
+
+func TestRecordCheck(t *testing.T) {
+	vsi := &VSphereInstance{Conn: &vclib.VSphereConnection{}}
+	if state := vsi.Health().State; state != ConnectionStateUnknown {
+		t.Fatalf("expected initial state %s, got %s", ConnectionStateUnknown, state)
+	}
+
+	previous, state := vsi.recordCheck(nil)
+	if previous != ConnectionStateUnknown || state != ConnectionStateConnected {
+		t.Errorf("unexpected transition %s -> %s", previous, state)
+	}
+	if vsi.Health().LastSuccess.IsZero() {
+		t.Errorf("expected last success to be set")
+	}
+
+	for i := 1; i < UnreachableThreshold; i++ {
+		_, state = vsi.recordCheck(errors.New("connection refused"))
+		if state != ConnectionStateDegraded {
+			t.Errorf("expected state %s after %d failures, got %s", ConnectionStateDegraded, i, state)
+		}
+	}
+	_, state = vsi.recordCheck(errors.New("connection refused"))
+	if state != ConnectionStateUnreachable {
+		t.Errorf("expected state %s, got %s", ConnectionStateUnreachable, state)
+	}
+	if failures := vsi.Health().ConsecutiveFailures; failures != UnreachableThreshold {
+		t.Errorf("expected %d consecutive failures, got %d", UnreachableThreshold, failures)
+	}
+
+	fault := &soap.Fault{}
+	fault.Detail.Fault = types.InvalidLogin{}
+	_, state = vsi.recordCheck(soap.WrapSoapFault(fault))
+	if state != ConnectionStateUnauthenticated {
+		t.Errorf("expected state %s, got %s", ConnectionStateUnauthenticated, state)
+	}
+
+	_, state = vsi.recordCheck(nil)
+	if state != ConnectionStateConnected || vsi.Health().ConsecutiveFailures != 0 {
+		t.Errorf("expected state %s without failures, got %+v", ConnectionStateConnected, vsi.Health())
+	}
+}
+
+func TestSessionMonitor(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+	connMgr.sessionKeepAliveInterval = time.Minute
+
+	vsi := connMgr.VsphereInstanceMap[config.Global.VCenterIP]
+	if vsi.isSessionFresh(connMgr.sessionKeepAliveInterval) {
+		t.Fatalf("session must not be fresh before the first check")
+	}
+
+	connMgr.checkSession(vsi)
+	health := vsi.Health()
+	if health.State != ConnectionStateConnected {
+		t.Fatalf("expected state %s, got %s (%v)", ConnectionStateConnected, health.State, health.LastError)
+	}
+	if vsi.Conn.Client == nil {
+		t.Fatalf("expected the session monitor to log in")
+	}
+	if !vsi.isSessionFresh(connMgr.sessionKeepAliveInterval) {
+		t.Errorf("session should be fresh after a successful check")
+	}
+
+	// request paths use the cached state and keep the client
+	client := vsi.Conn.Client
+	if err := connMgr.Connect(context.Background(), vsi); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if vsi.Conn.Client != client {
+		t.Errorf("Connect should reuse the existing client")
+	}
+
+	// a request rejected as not authenticated makes request paths probe the
+	// session again instead of relying on the cached state
+	vsi.Conn.Logout(context.Background())
+	if _, err := methods.GetCurrentTime(context.Background(), client); err == nil {
+		t.Fatalf("expected a request of the logged out session to fail")
+	}
+	if vsi.isSessionFresh(connMgr.sessionKeepAliveInterval) {
+		t.Errorf("session must not be fresh after it was rejected")
+	}
+	if err := connMgr.Connect(context.Background(), vsi); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if vsi.Conn.Client == client {
+		t.Errorf("expected Connect to log in again")
+	}
+	if !vsi.isSessionFresh(connMgr.sessionKeepAliveInterval) {
+		t.Errorf("session should be fresh after logging in again")
+	}
+	client = vsi.Conn.Client
+
+	// sessions older than the maximum age are re-authenticated
+	connMgr.sessionMaxAge = time.Nanosecond
+	connMgr.checkSession(vsi)
+	if vsi.Conn.Client == client {
+		t.Errorf("expected the session to be re-authenticated")
+	}
+	if state := vsi.Health().State; state != ConnectionStateConnected {
+		t.Errorf("expected state %s, got %s", ConnectionStateConnected, state)
+	}
+}
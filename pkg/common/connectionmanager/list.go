@@ -20,31 +20,31 @@ import (
 	"context"
 	"sort"
 	"strings"
-	"time"
 
 	klog "k8s.io/klog/v2"
 
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 	vclib "k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 )
 
 // ListAllVCandDCPairs returns all VC/DC pairs
 func (cm *ConnectionManager) ListAllVCandDCPairs(ctx context.Context) ([]*ListDiscoveryInfo, error) {
+	ctx, span := tracing.Start(ctx, "ConnectionManager.ListAllVCandDCPairs")
+	pairs, err := cm.listAllVCandDCPairs(ctx)
+	span.SetAttributes(tracing.Int("pairs", len(pairs)))
+	span.End(err)
+	return pairs, err
+}
+
+func (cm *ConnectionManager) listAllVCandDCPairs(ctx context.Context) ([]*ListDiscoveryInfo, error) {
 	klog.V(4).Infof("ListAllVCandDCPairs called")
 
 	listOfVCAndDCPairs := make([]*ListDiscoveryInfo, 0)
 
-	for _, vsi := range cm.VsphereInstanceMap {
+	for _, vsi := range cm.Instances() {
 		var datacenterObjs []*vclib.Datacenter
 
-		var err error
-		for i := 0; i < NumConnectionAttempts; i++ {
-			err = cm.Connect(ctx, vsi)
-			if err == nil {
-				break
-			}
-			time.Sleep(time.Duration(RetryAttemptDelaySecs) * time.Second)
-		}
-
+		err := cm.connectForSearch(ctx, vsi)
 		if err != nil {
 			klog.Error("Connect error vc:", err)
 			continue
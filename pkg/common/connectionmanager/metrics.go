@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const metricsSubsystem = "cloudprovider_vsphere"
+
+var (
+	connectionStateMetric = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "vcenter_connection_state",
+			Help:           "Connection state of a vCenter as observed by the session monitor, 1 for the current state and 0 otherwise",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter", "state"},
+	)
+
+	connectionStateTransitionMetric = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "vcenter_connection_last_transition_timestamp_seconds",
+			Help:           "Unix time of the last connection state transition of a vCenter",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter"},
+	)
+
+	sessionCheckMetric = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "vcenter_session_checks_total",
+			Help:           "Number of vCenter session checks performed by the session monitor, by result",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter", "result"},
+	)
+
+	sessionReloginMetric = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "vcenter_session_relogins_total",
+			Help:           "Number of vCenter sessions re-established by the session monitor",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter"},
+	)
+
+	circuitStateMetric = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "vcenter_circuit_breaker_state",
+			Help:           "State of the circuit breaker of a vCenter, 1 for the current state and 0 otherwise",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter", "state"},
+	)
+
+	searchSkippedMetric = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "vcenter_search_skipped_total",
+			Help:           "Number of times a vCenter was skipped by a search as its circuit breaker was open",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter"},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// registerMetrics registers the connection manager metrics with the global
+// registry used by the cloud controller manager.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(connectionStateMetric)
+		legacyregistry.MustRegister(connectionStateTransitionMetric)
+		legacyregistry.MustRegister(sessionCheckMetric)
+		legacyregistry.MustRegister(sessionReloginMetric)
+		legacyregistry.MustRegister(circuitStateMetric)
+		legacyregistry.MustRegister(searchSkippedMetric)
+	})
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	klog "k8s.io/klog/v2"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+)
+
+// UpdateConfig applies a reloaded configuration to the vCenter connections.
+// vCenters are added and removed according to the new configuration. A vCenter
+// whose connection settings or CA file changed gets a new connection, while
+// its existing connection is kept if only other settings changed. Global
+// settings other than the vCenters are not reloaded. It returns a description
+// of every applied change.
+func (connMgr *ConnectionManager) UpdateConfig(cfg *vcfg.Config) []string {
+	newInstances := generateInstanceMap(cfg)
+
+	connMgr.Lock()
+	oldInstances := connMgr.VsphereInstanceMap
+	var changes []string
+	var added []*VSphereInstance
+	var removed []*VSphereInstance
+	var loggedOut []*VSphereInstance
+
+	for tenantRef, vsi := range newInstances {
+		prev, ok := oldInstances[tenantRef]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("vCenter %s added", tenantRef))
+			added = append(added, vsi)
+			continue
+		}
+
+		prefix := fmt.Sprintf("vCenter %s", tenantRef)
+		diff := vcfg.DiffFields(prefix, prev.Cfg, vsi.Cfg)
+		if prev.caDigest != vsi.caDigest && prev.Cfg.CAFile == vsi.Cfg.CAFile {
+			diff = append(diff, fmt.Sprintf("%s.CAFile %s content changed", prefix, vsi.Cfg.CAFile))
+		}
+		if len(diff) == 0 {
+			newInstances[tenantRef] = prev
+			continue
+		}
+		changes = append(changes, diff...)
+
+		if !connectionSettingsChanged(prev, vsi) {
+			// keep the established session, its health and circuit
+			vsi.Conn = prev.Conn
+			vsi.breaker = prev.breaker
+			vsi.health = prev.Health()
+		} else {
+			loggedOut = append(loggedOut, prev)
+		}
+		removed = append(removed, prev)
+		added = append(added, vsi)
+	}
+	for tenantRef, prev := range oldInstances {
+		if _, ok := newInstances[tenantRef]; !ok {
+			changes = append(changes, fmt.Sprintf("vCenter %s removed", tenantRef))
+			removed = append(removed, prev)
+			loggedOut = append(loggedOut, prev)
+		}
+	}
+
+	connMgr.VsphereInstanceMap = newInstances
+	connMgr.removeCredentialManagers(removed, added)
+	monitorStop := connMgr.monitorStop
+	secretListersInitialized := connMgr.secretListersInitialized
+	connMgr.Unlock()
+
+	for _, vsi := range removed {
+		close(vsi.removed)
+	}
+	for _, vsi := range added {
+		connMgr.initializeCredentialProvider(vsi)
+		if secretListersInitialized {
+			connMgr.initializeSecretLister(vsi)
+		}
+		if monitorStop != nil {
+			go connMgr.monitorSession(vsi, monitorStop)
+		}
+	}
+	for _, vsi := range loggedOut {
+		go connMgr.logout(context.Background(), vsi)
+	}
+
+	sort.Strings(changes)
+	return changes
+}
+
+// removeCredentialManagers removes the credential managers and secret listers
+// of the removed vCenters, unless a vCenter which is kept still uses them. The
+// added vCenters register their own ones again. The secret informer is shared
+// by all the listers through the informer factory, so it keeps running.
+// Must be called with the lock held.
+func (connMgr *ConnectionManager) removeCredentialManagers(removed []*VSphereInstance, added []*VSphereInstance) {
+	readded := make(map[*VSphereInstance]bool, len(added))
+	for _, vsi := range added {
+		readded[vsi] = true
+	}
+	inUse := sets.NewString(vcfg.DefaultCredentialManager)
+	for _, vsi := range connMgr.VsphereInstanceMap {
+		if !readded[vsi] {
+			inUse.Insert(vsi.Cfg.SecretRef)
+		}
+	}
+	for _, vsi := range removed {
+		if inUse.Has(vsi.Cfg.SecretRef) {
+			continue
+		}
+		klog.V(3).Infof("Removing credMgr/informMgr of vcServer=%s credentialHolder=%s", vsi.Cfg.VCenterIP, vsi.Cfg.SecretRef)
+		delete(connMgr.credentialManagers, vsi.Cfg.SecretRef)
+		delete(connMgr.informerManagers, vsi.Cfg.SecretRef)
+	}
+}
+
+// connectionSettingsChanged reports whether the new instance requires a new
+// session to vCenter.
+func connectionSettingsChanged(prev *VSphereInstance, vsi *VSphereInstance) bool {
+	return prev.Cfg.VCenterIP != vsi.Cfg.VCenterIP ||
+		prev.Cfg.VCenterPort != vsi.Cfg.VCenterPort ||
+		prev.Cfg.User != vsi.Cfg.User ||
+		prev.Cfg.Password != vsi.Cfg.Password ||
+		prev.Cfg.CertFile != vsi.Cfg.CertFile ||
+		prev.Cfg.KeyFile != vsi.Cfg.KeyFile ||
+		prev.Cfg.InsecureFlag != vsi.Cfg.InsecureFlag ||
+		prev.Cfg.RoundTripperCount != vsi.Cfg.RoundTripperCount ||
+		prev.Cfg.CAFile != vsi.Cfg.CAFile ||
+		prev.Cfg.Thumbprint != vsi.Cfg.Thumbprint ||
+		prev.Cfg.ProxyURL != vsi.Cfg.ProxyURL ||
+		prev.Cfg.ConnectTimeout != vsi.Cfg.ConnectTimeout ||
+		prev.Cfg.RequestTimeout != vsi.Cfg.RequestTimeout ||
+		prev.Cfg.TLSMinVersion != vsi.Cfg.TLSMinVersion ||
+		prev.Cfg.RateLimitQPS != vsi.Cfg.RateLimitQPS ||
+		prev.Cfg.RateLimitBurst != vsi.Cfg.RateLimitBurst ||
+		prev.Cfg.SecretRef != vsi.Cfg.SecretRef ||
+		prev.caDigest != vsi.caDigest
+}
+
+// fileDigest returns the hex encoded SHA-256 of the file, or an empty string
+// if there is no such file.
+func fileDigest(path string) string {
+	if path == "" {
+		return ""
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		klog.V(4).Infof("Failed to read %s: %v", path, err)
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connectionmanager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+)
+
+// copyConfig returns a copy of cfg that does not share its vCenter configs
+func copyConfig(cfg *vcfg.Config) *vcfg.Config {
+	c := *cfg
+	c.VirtualCenter = make(map[string]*vcfg.VirtualCenterConfig)
+	for tenantRef, vcConfig := range cfg.VirtualCenter {
+		vcc := *vcConfig
+		c.VirtualCenter[tenantRef] = &vcc
+	}
+	return &c
+}
+
+func TestUpdateConfig(t *testing.T) {
+	config, cleanup := configFromSim(true)
+	defer cleanup()
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+
+	ctx := context.Background()
+	tenantRef := config.Global.VCenterIP
+	if err := connMgr.Connect(ctx, connMgr.Instance(tenantRef)); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+	orig := connMgr.Instance(tenantRef)
+
+	// unchanged config
+	if changes := connMgr.UpdateConfig(copyConfig(config)); len(changes) != 0 {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	if connMgr.Instance(tenantRef) != orig {
+		t.Error("unchanged instance should be kept")
+	}
+
+	// changed datacenters keep the session
+	cfg := copyConfig(config)
+	cfg.VirtualCenter[tenantRef].Datacenters = "DC0"
+	changes := connMgr.UpdateConfig(cfg)
+	if len(changes) != 1 {
+		t.Fatalf("expected a single change, got %v", changes)
+	}
+	vsi := connMgr.Instance(tenantRef)
+	if vsi == orig || vsi.Conn != orig.Conn {
+		t.Error("instance should be replaced, keeping the connection")
+	}
+	if vsi.Cfg.Datacenters != "DC0" {
+		t.Errorf("incorrect datacenters: %s", vsi.Cfg.Datacenters)
+	}
+	select {
+	case <-orig.removed:
+	default:
+		t.Error("replaced instance should be marked removed")
+	}
+
+	// changed password requires a new session and is not logged
+	cfg = copyConfig(cfg)
+	cfg.VirtualCenter[tenantRef].Password = "secret"
+	changes = connMgr.UpdateConfig(cfg)
+	if len(changes) != 1 || changes[0] != "vCenter "+tenantRef+".Password changed" {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	if connMgr.Instance(tenantRef).Conn == vsi.Conn {
+		t.Error("connection should be replaced")
+	}
+
+	// added and removed vCenters
+	cfg = copyConfig(cfg)
+	added := *cfg.VirtualCenter[tenantRef]
+	added.TenantRef = "tenant2"
+	cfg.VirtualCenter[added.TenantRef] = &added
+	delete(cfg.VirtualCenter, tenantRef)
+	changes = connMgr.UpdateConfig(cfg)
+	expected := []string{"vCenter " + tenantRef + " removed", "vCenter tenant2 added"}
+	if len(changes) != len(expected) || changes[0] != expected[0] || changes[1] != expected[1] {
+		t.Errorf("expected changes %v, got %v", expected, changes)
+	}
+	if connMgr.Instance(tenantRef) != nil || connMgr.Instance("tenant2") == nil {
+		t.Errorf("unexpected instances: %v", connMgr.Instances())
+	}
+}
+
+func TestUpdateConfigCAFileContent(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	caFile, err := ioutil.TempFile("", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(caFile.Name())
+	caFile.WriteString("old")
+	caFile.Close()
+
+	tenantRef := config.Global.VCenterIP
+	config.VirtualCenter[tenantRef].CAFile = caFile.Name()
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+	orig := connMgr.Instance(tenantRef)
+
+	if err := ioutil.WriteFile(caFile.Name(), []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changes := connMgr.UpdateConfig(copyConfig(config))
+	expected := "vCenter " + tenantRef + ".CAFile " + caFile.Name() + " content changed"
+	if len(changes) != 1 || changes[0] != expected {
+		t.Errorf("expected change %q, got %v", expected, changes)
+	}
+	if connMgr.Instance(tenantRef).Conn == orig.Conn {
+		t.Error("connection should be replaced")
+	}
+}
+
+func TestUpdateConfigCredentialManagers(t *testing.T) {
+	config, cleanup := configFromSim(false)
+	defer cleanup()
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+
+	tenantRef := "exec"
+	secretRef := vcfg.CredentialProviderRefPrefix + tenantRef
+	cfg := copyConfig(config)
+	vcConfig := *cfg.VirtualCenter[config.Global.VCenterIP]
+	vcConfig.TenantRef = tenantRef
+	vcConfig.SecretRef = secretRef
+	vcConfig.CredentialProvider = vcfg.CredentialProvider{Type: vcfg.CredentialProviderExec, Command: "true"}
+	cfg.VirtualCenter[tenantRef] = &vcConfig
+	connMgr.UpdateConfig(cfg)
+	if connMgr.credentialManagers[secretRef] == nil {
+		t.Fatalf("expected a credential provider for %s", secretRef)
+	}
+
+	// a replaced vCenter registers a new credential provider
+	provider := connMgr.credentialManagers[secretRef]
+	cfg = copyConfig(cfg)
+	cfg.VirtualCenter[tenantRef].Password = "secret"
+	connMgr.UpdateConfig(cfg)
+	if p := connMgr.credentialManagers[secretRef]; p == nil || p == provider {
+		t.Errorf("expected the credential provider of %s to be replaced", secretRef)
+	}
+
+	// a removed vCenter does not leave its credential provider behind
+	cfg = copyConfig(cfg)
+	delete(cfg.VirtualCenter, tenantRef)
+	connMgr.UpdateConfig(cfg)
+	if _, ok := connMgr.credentialManagers[secretRef]; ok {
+		t.Errorf("expected the credential provider of %s to be removed", secretRef)
+	}
+	if connMgr.credentialManagers[vcfg.DefaultCredentialManager] == nil {
+		t.Errorf("the default credential manager must be kept")
+	}
+}
@@ -21,11 +21,11 @@ import (
 	"errors"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/vmware/govmomi/vim25/mo"
 	klog "k8s.io/klog/v2"
 
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 	vclib "k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 )
 
@@ -44,7 +44,11 @@ func (f FindVM) String() string {
 }
 
 // WhichVCandDCByNodeID finds the VC/DC combo that owns a particular VM
-func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID string, searchBy FindVM) (*VMDiscoveryInfo, error) {
+func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID string, searchBy FindVM) (_ *VMDiscoveryInfo, err error) {
+	ctx, span := tracing.Start(ctx, "ConnectionManager.WhichVCandDCByNodeID",
+		tracing.String("node", nodeID), tracing.String("search", searchBy.String()))
+	defer func() { span.End(err) }()
+
 	if nodeID == "" {
 		klog.V(3).Info("WhichVCandDCByNodeID called but nodeID is empty")
 		return nil, errors.New("nodeID is empty")
@@ -77,6 +81,8 @@ func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID st
 
 	vmFound := false
 	globalErr = nil
+	// vCenters skipped as their circuit is open, only written by the producer
+	var skipped []string
 
 	setGlobalErr := func(err error) {
 		globalErrMutex.Lock()
@@ -84,10 +90,19 @@ func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID st
 		globalErrMutex.Unlock()
 	}
 
-	setVMFound := func(found bool) {
+	var vmInfo *VMDiscoveryInfo
+	// cancels the searches still in flight once the first match is recorded
+	ctx, cancelSearches := context.WithCancel(ctx)
+	defer cancelSearches()
+
+	setVMFound := func(info *VMDiscoveryInfo) {
 		mutex.Lock()
-		vmFound = found
+		if !vmFound {
+			vmInfo = info
+			vmFound = true
+		}
 		mutex.Unlock()
+		cancelSearches()
 	}
 
 	getVMFound := func() bool {
@@ -98,20 +113,17 @@ func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID st
 	}
 
 	go func() {
-		for _, vsi := range cm.VsphereInstanceMap {
+		for _, vsi := range cm.Instances() {
 			var datacenterObjs []*vclib.Datacenter
 
 			if getVMFound() {
 				break
 			}
 
-			var err error
-			for i := 0; i < NumConnectionAttempts; i++ {
-				err = cm.Connect(ctx, vsi)
-				if err == nil {
-					break
-				}
-				time.Sleep(time.Duration(RetryAttemptDelaySecs) * time.Second)
+			err := cm.connectForSearch(ctx, vsi)
+			if err == ErrCircuitOpen {
+				skipped = append(skipped, vsi.Cfg.VCenterIP)
+				continue
 			}
 
 			if err != nil {
@@ -160,42 +172,59 @@ func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID st
 		close(queueChannel)
 	}()
 
-	var vmInfo *VMDiscoveryInfo
 	for i := 0; i < PoolSize; i++ {
 		wg.Add(1)
 		go func() {
 			for res := range queueChannel {
+				if getVMFound() {
+					// drain the queue, the VM was found in another datacenter
+					continue
+				}
 				var vm *vclib.VirtualMachine
 				var err error
 
+				searchCtx, searchSpan := tracing.Start(ctx, "ConnectionManager.searchDatacenter",
+					tracing.String("vcenter", res.vc), tracing.String("datacenter", res.datacenter.Name()))
 				switch searchBy {
 				case FindVMByUUID:
-					vm, err = res.datacenter.GetVMByUUID(ctx, myNodeID)
+					vm, err = res.datacenter.GetVMByUUID(searchCtx, myNodeID)
 				case FindVMByIP:
-					vm, err = res.datacenter.GetVMByIP(ctx, myNodeID)
+					vm, err = res.datacenter.GetVMByIP(searchCtx, myNodeID)
 				default:
-					vm, err = res.datacenter.GetVMByDNSName(ctx, myNodeID)
+					vm, err = res.datacenter.GetVMByDNSName(searchCtx, myNodeID)
 				}
 
+				if err != nil && getVMFound() {
+					// cancelled as the VM was found in another datacenter
+					searchSpan.SetAttributes(tracing.Bool("cancelled", true))
+					searchSpan.End(nil)
+					continue
+				}
 				if err != nil {
 					klog.Errorf("Error while looking for vm=%s(%s) in vc=%s and datacenter=%s: %v",
 						myNodeID, searchBy, res.vc, res.datacenter.Name(), err)
 					if err != vclib.ErrNoVMFound {
 						setGlobalErr(err)
+						searchSpan.End(err)
 					} else {
 						klog.V(2).Infof("Did not find node %s in vc=%s and datacenter=%s",
 							myNodeID, res.vc, res.datacenter.Name())
+						searchSpan.SetAttributes(tracing.Bool("found", false))
+						searchSpan.End(nil)
 					}
 					continue
 				}
 
 				var oVM mo.VirtualMachine
-				err = vm.Properties(ctx, vm.Reference(), []string{"config", "summary", "guest"}, &oVM)
+				err = vm.Properties(searchCtx, vm.Reference(), []string{"config", "summary", "guest"}, &oVM)
 				if err != nil {
 					klog.Errorf("Error collecting properties for vm=%+v in vc=%s and datacenter=%s: %v",
 						vm, res.vc, res.datacenter.Name(), err)
+					searchSpan.End(err)
 					continue
 				}
+				searchSpan.SetAttributes(tracing.Bool("found", true))
+				searchSpan.End(nil)
 
 				hostName := oVM.Guest.HostName
 				if searchBy == FindVMByIP {
@@ -209,10 +238,8 @@ func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID st
 					nodeID, vm, res.vc, res.datacenter.Name())
 				klog.V(2).Infof("Hostname: %s, UUID: %s", hostName, UUID)
 
-				vmInfo = &VMDiscoveryInfo{TenantRef: res.tenantRef, DataCenter: res.datacenter, VM: vm, VcServer: res.vc,
-					UUID: UUID, NodeName: hostName}
-				setVMFound(true)
-				break
+				setVMFound(&VMDiscoveryInfo{TenantRef: res.tenantRef, DataCenter: res.datacenter, VM: vm, VcServer: res.vc,
+					UUID: UUID, NodeName: hostName})
 			}
 			wg.Done()
 		}()
@@ -221,6 +248,13 @@ func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID st
 	if vmFound {
 		return vmInfo, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		klog.Warningf("WhichVCandDCByNodeID: %q vm not found in the searched vCenters, skipped: %v", myNodeID, skipped)
+		return nil, partialSearchError(skipped)
+	}
 	if globalErr != nil {
 		return nil, *globalErr
 	}
@@ -230,7 +264,10 @@ func (cm *ConnectionManager) WhichVCandDCByNodeID(ctx context.Context, nodeID st
 }
 
 // WhichVCandDCByFCDId searches for an FCD using the provided ID.
-func (cm *ConnectionManager) WhichVCandDCByFCDId(ctx context.Context, fcdID string) (*FcdDiscoveryInfo, error) {
+func (cm *ConnectionManager) WhichVCandDCByFCDId(ctx context.Context, fcdID string) (_ *FcdDiscoveryInfo, err error) {
+	ctx, span := tracing.Start(ctx, "ConnectionManager.WhichVCandDCByFCDId", tracing.String("fcd", fcdID))
+	defer func() { span.End(err) }()
+
 	if fcdID == "" {
 		klog.V(3).Info("WhichVCandDCByFCDId called but fcdID is empty")
 		return nil, vclib.ErrNoDiskIDFound
@@ -253,6 +290,8 @@ func (cm *ConnectionManager) WhichVCandDCByFCDId(ctx context.Context, fcdID stri
 
 	fcdFound := false
 	globalErr = nil
+	// vCenters skipped as their circuit is open, only written by the producer
+	var skipped []string
 
 	setGlobalErr := func(err error) {
 		globalErrMutex.Lock()
@@ -260,10 +299,19 @@ func (cm *ConnectionManager) WhichVCandDCByFCDId(ctx context.Context, fcdID stri
 		globalErrMutex.Unlock()
 	}
 
-	setFCDFound := func(found bool) {
+	var fcdInfo *FcdDiscoveryInfo
+	// cancels the searches still in flight once the first match is recorded
+	ctx, cancelSearches := context.WithCancel(ctx)
+	defer cancelSearches()
+
+	setFCDFound := func(info *FcdDiscoveryInfo) {
 		mutex.Lock()
-		fcdFound = found
+		if !fcdFound {
+			fcdInfo = info
+			fcdFound = true
+		}
 		mutex.Unlock()
+		cancelSearches()
 	}
 
 	getFCDFound := func() bool {
@@ -274,20 +322,17 @@ func (cm *ConnectionManager) WhichVCandDCByFCDId(ctx context.Context, fcdID stri
 	}
 
 	go func() {
-		for _, vsi := range cm.VsphereInstanceMap {
+		for _, vsi := range cm.Instances() {
 			var datacenterObjs []*vclib.Datacenter
 
 			if getFCDFound() {
 				break
 			}
 
-			var err error
-			for i := 0; i < NumConnectionAttempts; i++ {
-				err = cm.Connect(ctx, vsi)
-				if err == nil {
-					break
-				}
-				time.Sleep(time.Duration(RetryAttemptDelaySecs) * time.Second)
+			err := cm.connectForSearch(ctx, vsi)
+			if err == ErrCircuitOpen {
+				skipped = append(skipped, vsi.Cfg.VCenterIP)
+				continue
 			}
 
 			if err != nil {
@@ -336,31 +381,44 @@ func (cm *ConnectionManager) WhichVCandDCByFCDId(ctx context.Context, fcdID stri
 		close(queueChannel)
 	}()
 
-	var fcdInfo *FcdDiscoveryInfo
 	for i := 0; i < PoolSize; i++ {
 		wg.Add(1)
 		go func() {
 			for res := range queueChannel {
-
-				fcd, err := res.datacenter.DoesFirstClassDiskExist(ctx, fcdID)
+				if getFCDFound() {
+					// drain the queue, the FCD was found in another datacenter
+					continue
+				}
+				searchCtx, searchSpan := tracing.Start(ctx, "ConnectionManager.searchDatacenter",
+					tracing.String("vcenter", res.vc), tracing.String("datacenter", res.datacenter.Name()))
+				fcd, err := res.datacenter.DoesFirstClassDiskExist(searchCtx, fcdID)
+				if err != nil && getFCDFound() {
+					// cancelled as the FCD was found in another datacenter
+					searchSpan.SetAttributes(tracing.Bool("cancelled", true))
+					searchSpan.End(nil)
+					continue
+				}
 				if err != nil {
 					klog.Errorf("Error while looking for FCD=%+v in vc=%s and datacenter=%s: %v",
 						fcd, res.vc, res.datacenter.Name(), err)
 					if err != vclib.ErrNoDiskIDFound {
 						setGlobalErr(err)
+						searchSpan.End(err)
 					} else {
 						klog.V(2).Infof("Did not find FCD %s in vc=%s and datacenter=%s",
 							fcdID, res.vc, res.datacenter.Name())
+						searchSpan.SetAttributes(tracing.Bool("found", false))
+						searchSpan.End(nil)
 					}
 					continue
 				}
+				searchSpan.SetAttributes(tracing.Bool("found", true))
+				searchSpan.End(nil)
 
 				klog.V(2).Infof("Found FCD %s as vm=%+v in vc=%s and datacenter=%s",
 					fcdID, fcd, res.vc, res.datacenter.Name())
 
-				fcdInfo = &FcdDiscoveryInfo{TenantRef: res.tenantRef, DataCenter: res.datacenter, FCDInfo: fcd, VcServer: res.vc}
-				setFCDFound(true)
-				break
+				setFCDFound(&FcdDiscoveryInfo{TenantRef: res.tenantRef, DataCenter: res.datacenter, FCDInfo: fcd, VcServer: res.vc})
 			}
 			wg.Done()
 		}()
@@ -369,6 +427,13 @@ func (cm *ConnectionManager) WhichVCandDCByFCDId(ctx context.Context, fcdID stri
 	if fcdFound {
 		return fcdInfo, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		klog.Warningf("WhichVCandDCByFCDId: %q FCD not found in the searched vCenters, skipped: %v", fcdID, skipped)
+		return nil, partialSearchError(skipped)
+	}
 	if globalErr != nil {
 		return nil, *globalErr
 	}
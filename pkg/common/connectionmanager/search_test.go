@@ -159,3 +159,27 @@ func TestWhichVCandDCByFCDId(t *testing.T) {
 		t.Errorf("FCD Size mismatch %d=%d", volSizeMB, fcdObj.FCDInfo.Config.CapacityInMB)
 	}
 }
+
+func TestWhichVCandDCByNodeIdCancelled(t *testing.T) {
+	config, cleanup := configFromEnvOrSim(true)
+	defer cleanup()
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+
+	vm := simulator.Map.Any("VirtualMachine").(*simulator.VirtualMachine)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	info, err := connMgr.WhichVCandDCByNodeID(ctx, vm.Config.Uuid, FindVMByUUID)
+	if err != context.Canceled {
+		t.Fatalf("WhichVCandDCByNodeID should fail with context.Canceled, info=%v err=%v", info, err)
+	}
+
+	// the cancelled search did not open the circuit of any vCenter
+	info, err = connMgr.WhichVCandDCByNodeID(context.Background(), vm.Config.Uuid, FindVMByUUID)
+	if err != nil || info == nil {
+		t.Fatalf("WhichVCandDCByNodeID err=%v", err)
+	}
+}
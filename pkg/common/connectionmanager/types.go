@@ -18,8 +18,10 @@ package connectionmanager
 
 import (
 	"sync"
+	"time"
 
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 	cm "k8s.io/cloud-provider-vsphere/pkg/common/credentialmanager"
 	k8s "k8s.io/cloud-provider-vsphere/pkg/common/kubernetes"
@@ -28,25 +30,51 @@ import (
 
 // ConnectionManager encapsulates vCenter connections
 type ConnectionManager struct {
+	// Guards the manager maps. It is never held while talking to vCenter.
 	sync.Mutex
 
 	// The k8s client init from the cloud provider service account
 	client clientset.Interface
 
 	// Maps the VC server to VSphereInstance
+	// The map is replaced, never modified, when the configuration is reloaded.
+	// Use Instances() when the configuration may be reloaded concurrently.
 	VsphereInstanceMap map[string]*VSphereInstance
-	// CredentialManager per VC
+	// CredentialManager, or external CredentialProvider, per VC
 	// The global CredentialManager will have an entry in this map with the key of "Global"
-	credentialManagers map[string]*cm.CredentialManager
+	credentialManagers map[string]cm.CredentialProvider
 	// InformerManagers per VC
 	// The global InformerManager will have an entry in this map with the key of "Global"
 	informerManagers map[string]*k8s.InformerManager
+
+	// Interval at which the session monitor checks the vCenter sessions
+	sessionKeepAliveInterval time.Duration
+	// Maximum age of a vCenter session before it is re-authenticated
+	sessionMaxAge time.Duration
+	// Timeout of the vCenter calls that are not bound to a request, zero if unbounded
+	operationTimeout time.Duration
+	// Records connection state changes as events, if a k8s client is available
+	eventRecorder record.EventRecorder
+	// Stop channel of the session monitor, nil if it has not been started
+	monitorStop <-chan struct{}
+	// Set once InitializeSecretLister has been called
+	secretListersInitialized bool
 }
 
 // VSphereInstance represents a vSphere instance where one or more kubernetes nodes are running.
 type VSphereInstance struct {
 	Conn *vclib.VSphereConnection
 	Cfg  *vcfg.VirtualCenterConfig
+
+	healthLock sync.RWMutex
+	health     ConnectionHealth
+
+	breaker *circuitBreaker
+
+	// SHA-256 of the CA file when the instance was created, to detect rotations
+	caDigest string
+	// closed when the instance is removed by a configuration reload
+	removed chan struct{}
 }
 
 // VMDiscoveryInfo contains VM info about a discovered VM
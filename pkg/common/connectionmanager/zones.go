@@ -22,7 +22,6 @@ import (
 	"net/url"
 	"strings"
 	"sync"
-	"time"
 
 	klog "k8s.io/klog/v2"
 
@@ -33,6 +32,8 @@ import (
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 	vclib "k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 )
 
@@ -46,13 +47,24 @@ const (
 	RegionLabel = "Region"
 )
 
-// WhichVCandDCByZone gets the corresponding VC+DC combo that supports the availability zone
+// WhichVCandDCByZone gets the corresponding VC+DC combo that supports the availability zone.
+// zoneLabel and regionLabel are the tag categories of the vCenters that do not
+// override them.
 func (cm *ConnectionManager) WhichVCandDCByZone(ctx context.Context,
+	zoneLabel string, regionLabel string, zoneLooking string, regionLooking string) (*ZoneDiscoveryInfo, error) {
+	ctx, span := tracing.Start(ctx, "ConnectionManager.WhichVCandDCByZone",
+		tracing.String("zone", zoneLooking), tracing.String("region", regionLooking))
+	discoveryInfo, err := cm.whichVCandDCByZone(ctx, zoneLabel, regionLabel, zoneLooking, regionLooking)
+	span.End(err)
+	return discoveryInfo, err
+}
+
+func (cm *ConnectionManager) whichVCandDCByZone(ctx context.Context,
 	zoneLabel string, regionLabel string, zoneLooking string, regionLooking string) (*ZoneDiscoveryInfo, error) {
 	klog.V(4).Infof("WhichVCandDCByZone called with zone: %s and region: %s", zoneLooking, regionLooking)
 
 	// Need at least one VC
-	numOfVCs := len(cm.VsphereInstanceMap)
+	numOfVCs := len(cm.Instances())
 	if numOfVCs == 0 {
 		err := ErrMustHaveAtLeastOneVCDC
 		klog.Errorf("%v", err)
@@ -72,7 +84,8 @@ func (cm *ConnectionManager) getDIFromSingleVC(ctx context.Context,
 	zoneLabel string, regionLabel string, zoneLooking string, regionLooking string) (*ZoneDiscoveryInfo, error) {
 	klog.V(4).Infof("getDIFromSingleVC called with zone: %s and region: %s", zoneLooking, regionLooking)
 
-	if len(cm.VsphereInstanceMap) != 1 {
+	instances := cm.Instances()
+	if len(instances) != 1 {
 		err := ErrUnsupportedConfiguration
 		klog.Errorf("%v", err)
 		return nil, err
@@ -82,17 +95,13 @@ func (cm *ConnectionManager) getDIFromSingleVC(ctx context.Context,
 
 	// Get first vSphere Instance
 	var tmpVsi *VSphereInstance
-	for _, tmpVsi = range cm.VsphereInstanceMap {
+	for _, tmpVsi = range instances {
 		break //Grab the first one because there is only one
 	}
 
-	var err error
-	for i := 0; i < NumConnectionAttempts; i++ {
-		err = cm.Connect(ctx, tmpVsi)
-		if err == nil {
-			break
-		}
-		time.Sleep(time.Duration(RetryAttemptDelaySecs) * time.Second)
+	err := cm.connectForSearch(ctx, tmpVsi)
+	if err == ErrCircuitOpen {
+		return nil, partialSearchError([]string{tmpVsi.Cfg.VCenterIP})
 	}
 
 	numOfDc, err := vclib.GetNumberOfDatacenters(ctx, tmpVsi.Conn)
@@ -128,7 +137,25 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 	zoneLabel string, regionLabel string, zoneLooking string, regionLooking string) (*ZoneDiscoveryInfo, error) {
 	klog.V(4).Infof("getDIFromMultiVCorDC called with zone: %s and region: %s", zoneLooking, regionLooking)
 
-	if len(zoneLabel) == 0 || len(regionLabel) == 0 || len(zoneLooking) == 0 || len(regionLooking) == 0 {
+	if len(zoneLooking) == 0 || len(regionLooking) == 0 {
+		err := ErrMultiVCRequiresZones
+		klog.Errorf("%v", err)
+		return nil, err
+	}
+
+	// the vCenters are searched with their own tag categories, the ones
+	// without categories cannot be searched
+	defaults := vcfg.Labels{Zone: zoneLabel, Region: regionLabel}
+	var instances []*VSphereInstance
+	for _, vsi := range cm.Instances() {
+		labels := vsi.Cfg.ZoneLabels(defaults)
+		if len(labels.Zone) == 0 || len(labels.Region) == 0 {
+			klog.V(4).Infof("getDIFromMultiVCorDC: vc=%s has no zone and region categories", vsi.Cfg.VCenterIP)
+			continue
+		}
+		instances = append(instances, vsi)
+	}
+	if len(instances) == 0 {
 		err := ErrMultiVCRequiresZones
 		klog.Errorf("%v", err)
 		return nil, err
@@ -151,6 +178,8 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 
 	zoneFound := false
 	globalErr = nil
+	// vCenters skipped as their circuit is open, only written by the producer
+	var skipped []string
 
 	setGlobalErr := func(err error) {
 		globalErrMutex.Lock()
@@ -158,10 +187,19 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 		globalErrMutex.Unlock()
 	}
 
-	setZoneFound := func(found bool) {
+	var zoneInfo *ZoneDiscoveryInfo
+	// cancels the searches still in flight once the first match is recorded
+	ctx, cancelSearches := context.WithCancel(ctx)
+	defer cancelSearches()
+
+	setZoneFound := func(info *ZoneDiscoveryInfo) {
 		mutex.Lock()
-		zoneFound = found
+		if !zoneFound {
+			zoneInfo = info
+			zoneFound = true
+		}
 		mutex.Unlock()
+		cancelSearches()
 	}
 
 	getZoneFound := func() bool {
@@ -172,20 +210,17 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 	}
 
 	go func() {
-		for _, vsi := range cm.VsphereInstanceMap {
+		for _, vsi := range instances {
 			var datacenterObjs []*vclib.Datacenter
 
 			if getZoneFound() {
 				break
 			}
 
-			var err error
-			for i := 0; i < NumConnectionAttempts; i++ {
-				err = cm.Connect(ctx, vsi)
-				if err == nil {
-					break
-				}
-				time.Sleep(time.Duration(RetryAttemptDelaySecs) * time.Second)
+			err := cm.connectForSearch(ctx, vsi)
+			if err == ErrCircuitOpen {
+				skipped = append(skipped, vsi.Cfg.VCenterIP)
+				continue
 			}
 
 			if err != nil {
@@ -233,6 +268,9 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 				}
 
 				for _, host := range hostList {
+					if getZoneFound() {
+						break
+					}
 					klog.V(3).Infof("Finding zone in vc=%s and datacenter=%s for host: %s", vsi.Cfg.VCenterIP, datacenterObj.Name(), host.Name())
 					queueChannel <- &zoneSearch{
 						tenantRef:  vsi.Cfg.TenantRef,
@@ -246,16 +284,23 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 		close(queueChannel)
 	}()
 
-	var zoneInfo *ZoneDiscoveryInfo
 	for i := 0; i < PoolSize; i++ {
 		wg.Add(1)
 		go func() {
 			for res := range queueChannel {
+				if getZoneFound() {
+					// drain the queue, the zone was found on another host
+					continue
+				}
 
 				klog.V(3).Infof("Checking zones for host: %s", res.host.Name())
 				result, err := cm.LookupZoneByMoref(ctx, res.tenantRef, res.host.Reference(), zoneLabel, regionLabel)
+				if err != nil && getZoneFound() {
+					// cancelled as the zone was found on another host
+					continue
+				}
 				if err != nil {
-					klog.Errorf("Failed to find zone: %s and region: %s for host %s", zoneLabel, regionLabel, res.host.Name())
+					klog.Errorf("Failed to find zone and region of host %s in vc=%s", res.host.Name(), res.vc)
 					continue
 				}
 
@@ -266,14 +311,11 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 				}
 
 				klog.Infof("Found zone: %s and region: %s for host %s", zoneLooking, regionLooking, res.host.Name())
-				zoneInfo = &ZoneDiscoveryInfo{
+				setZoneFound(&ZoneDiscoveryInfo{
 					TenantRef:  res.tenantRef,
 					VcServer:   res.vc,
 					DataCenter: res.datacenter,
-				}
-
-				setZoneFound(true)
-				break
+				})
 			}
 			wg.Done()
 		}()
@@ -282,17 +324,29 @@ func (cm *ConnectionManager) getDIFromMultiVCorDC(ctx context.Context,
 	if zoneFound {
 		return zoneInfo, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		klog.Warningf("getDIFromMultiVCorDC: zone: %s and region: %s not found in the searched vCenters, skipped: %v",
+			zoneLooking, regionLooking, skipped)
+		return nil, partialSearchError(skipped)
+	}
 	if globalErr != nil {
 		return nil, *globalErr
 	}
 
-	klog.V(4).Infof("getDIFromMultiVCorDC: zone: %s and region: %s not found", zoneLabel, regionLabel)
+	klog.V(4).Infof("getDIFromMultiVCorDC: zone: %s and region: %s not found", zoneLooking, regionLooking)
 	return nil, vclib.ErrNoZoneRegionFound
 }
 
 func withTagsClient(ctx context.Context, connection *vclib.VSphereConnection, f func(c *rest.Client) error) error {
-	c := rest.NewClient(connection.Client)
-	signer, err := connection.Signer(ctx, connection.Client)
+	client := connection.CurrentClient()
+	c, err := connection.NewRESTClient(client)
+	if err != nil {
+		return err
+	}
+	signer, err := connection.Signer(ctx, client)
 	if err != nil {
 		return err
 	}
@@ -315,24 +369,37 @@ func withTagsClient(ctx context.Context, connection *vclib.VSphereConnection, f
 }
 
 // LookupZoneByMoref searches for a zone using the provided managed object reference.
+// zoneLabel and regionLabel are the tag categories used unless the vCenter of
+// tenantRef overrides them.
 func (cm *ConnectionManager) LookupZoneByMoref(ctx context.Context, tenantRef string,
 	moRef types.ManagedObjectReference, zoneLabel string, regionLabel string) (map[string]string, error) {
+	ctx, span := tracing.Start(ctx, "ConnectionManager.LookupZoneByMoref",
+		tracing.String("tenant", tenantRef), tracing.String("moref", moRef.String()))
+	result, err := cm.lookupZoneByMoref(ctx, tenantRef, moRef, zoneLabel, regionLabel)
+	span.End(err)
+	return result, err
+}
 
+func (cm *ConnectionManager) lookupZoneByMoref(ctx context.Context, tenantRef string,
+	moRef types.ManagedObjectReference, zoneLabel string, regionLabel string) (map[string]string, error) {
 	result := make(map[string]string)
 
-	vsi := cm.VsphereInstanceMap[tenantRef]
+	vsi := cm.Instance(tenantRef)
 	if vsi == nil {
 		err := ErrConnectionNotFound
 		klog.Errorf("Unable to find Connection for tenantRef=%s", tenantRef)
 		return nil, err
 	}
+	labels := vsi.Cfg.ZoneLabels(vcfg.Labels{Zone: zoneLabel, Region: regionLabel})
+	zoneLabel, regionLabel = labels.Zone, labels.Region
 
 	err := withTagsClient(ctx, vsi.Conn, func(c *rest.Client) error {
 		client := tags.NewManager(c)
 
-		pc := vsi.Conn.Client.ServiceContent.PropertyCollector
+		vimClient := vsi.Conn.CurrentClient()
+		pc := vimClient.ServiceContent.PropertyCollector
 		// example result: ["Folder", "Datacenter", "Cluster", "Host"]
-		objects, err := mo.Ancestors(ctx, vsi.Conn.Client, pc, moRef)
+		objects, err := mo.Ancestors(ctx, vimClient, pc, moRef)
 		if err != nil {
 			klog.Errorf("Ancestors failed for %s with err %v", moRef, err)
 			return err
@@ -396,3 +463,77 @@ func (cm *ConnectionManager) LookupZoneByMoref(ctx context.Context, tenantRef st
 	}
 	return result, nil
 }
+
+// LookupTagsByMoref returns the tags of the given categories attached to the
+// provided managed object reference or the nearest of its ancestors, by
+// category. Categories without any such tag are left out.
+func (cm *ConnectionManager) LookupTagsByMoref(ctx context.Context, tenantRef string,
+	moRef types.ManagedObjectReference, categories []string) (map[string]string, error) {
+	ctx, span := tracing.Start(ctx, "ConnectionManager.LookupTagsByMoref",
+		tracing.String("tenant", tenantRef), tracing.String("moref", moRef.String()))
+	result, err := cm.lookupTagsByMoref(ctx, tenantRef, moRef, categories)
+	span.End(err)
+	return result, err
+}
+
+func (cm *ConnectionManager) lookupTagsByMoref(ctx context.Context, tenantRef string,
+	moRef types.ManagedObjectReference, categories []string) (map[string]string, error) {
+	result := make(map[string]string)
+	if len(categories) == 0 {
+		return result, nil
+	}
+
+	vsi := cm.Instance(tenantRef)
+	if vsi == nil {
+		err := ErrConnectionNotFound
+		klog.Errorf("Unable to find Connection for tenantRef=%s", tenantRef)
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		wanted[category] = true
+	}
+
+	err := withTagsClient(ctx, vsi.Conn, func(c *rest.Client) error {
+		client := tags.NewManager(c)
+
+		vimClient := vsi.Conn.CurrentClient()
+		pc := vimClient.ServiceContent.PropertyCollector
+		objects, err := mo.Ancestors(ctx, vimClient, pc, moRef)
+		if err != nil {
+			klog.Errorf("Ancestors failed for %s with err %v", moRef, err)
+			return err
+		}
+
+		// search the hierarchy from moRef up, the nearest tag of a category wins
+		for i := range objects {
+			obj := objects[len(objects)-1-i]
+			attached, err := client.GetAttachedTags(ctx, obj)
+			if err != nil {
+				klog.Errorf("Cannot list attached tags. Err: %v", err)
+				continue
+			}
+			for _, tag := range attached {
+				category, err := client.GetCategory(ctx, tag.CategoryID)
+				if err != nil {
+					klog.Errorf("Labels Get category %s error", tag.CategoryID)
+					return err
+				}
+				if _, ok := result[category.Name]; ok || !wanted[category.Name] {
+					continue
+				}
+				klog.V(2).Infof("Found %s tag (%s) attached to %s", category.Name, tag.Name, obj.Self)
+				result[category.Name] = tag.Name
+			}
+			if len(result) == len(wanted) {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		klog.Errorf("Get tags for mo: %s: %s", moRef, err)
+		return nil, err
+	}
+	return result, nil
+}
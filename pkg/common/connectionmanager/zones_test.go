@@ -19,12 +19,14 @@ package connectionmanager
 import (
 	"context"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/vapi/rest"
 	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/mo"
 
 	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 )
@@ -261,3 +263,63 @@ func TestLookupZoneByMoref(t *testing.T) {
 		t.Errorf("Region value mismatch k8s-zone-US-east != %s", zone)
 	}
 }
+
+func TestLookupTagsByMoref(t *testing.T) {
+	config, cleanup := configFromEnvOrSim(false)
+	defer cleanup()
+
+	connMgr := NewConnectionManager(config, nil, nil)
+	defer connMgr.Logout()
+
+	ctx := context.Background()
+	vsi := connMgr.VsphereInstanceMap[config.Global.VCenterIP]
+	if err := connMgr.Connect(ctx, vsi); err != nil {
+		t.Fatalf("Failed to Connect to vSphere: %s", err)
+	}
+
+	restClient := rest.NewClient(vsi.Conn.Client)
+	user := url.UserPassword(vsi.Conn.Username, vsi.Conn.Password)
+	if err := restClient.Login(ctx, user); err != nil {
+		t.Fatalf("Rest login failed. err=%v", err)
+	}
+	m := tags.NewManager(restClient)
+
+	myHost := simulator.Map.Any("HostSystem").(*simulator.HostSystem)
+	dc0, err := vclib.GetDatacenter(ctx, vsi.Conn, "DC0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attach := func(categoryName string, tagName string, ref mo.Reference) {
+		categoryID, err := m.CreateCategory(ctx, &tags.Category{Name: categoryName})
+		if err != nil {
+			// the category was created by a previous call
+			category, err := m.GetCategory(ctx, categoryName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			categoryID = category.ID
+		}
+		tagID, err := m.CreateTag(ctx, &tags.Tag{CategoryID: categoryID, Name: tagName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.AttachTag(ctx, tagID, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the tag of the host overrides the one of its datacenter
+	attach("team", "payments", dc0)
+	attach("team", "platform", myHost)
+	attach("cost-center", "cc-1", dc0)
+	attach("other", "ignored", myHost)
+
+	kv, err := connMgr.LookupTagsByMoref(ctx, config.Global.VCenterIP, myHost.Reference(),
+		[]string{"team", "cost-center", "missing"})
+	if err != nil {
+		t.Fatalf("LookupTagsByMoref failed err=%v", err)
+	}
+	expected := map[string]string{"team": "platform", "cost-center": "cc-1"}
+	if !reflect.DeepEqual(kv, expected) {
+		t.Errorf("expected tags %v, got %v", expected, kv)
+	}
+}
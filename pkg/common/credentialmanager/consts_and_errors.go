@@ -18,6 +18,18 @@ package credentialmanager
 
 import (
 	"errors"
+	"fmt"
+)
+
+const (
+	// CredentialsSecretKey is the key of the secret holding a CredentialsSecret as YAML.
+	CredentialsSecretKey = "credentials.yaml"
+	// CredentialsSecretKeyJSON is the key of the secret holding a CredentialsSecret as JSON.
+	CredentialsSecretKeyJSON = "credentials.json"
+	// CredentialsSecretAPIVersion is the supported apiVersion of a CredentialsSecret.
+	CredentialsSecretAPIVersion = "vsphere.cloudprovider.k8s.io/v1alpha1"
+	// CredentialsSecretKind is the kind of a CredentialsSecret.
+	CredentialsSecretKind = "VCenterCredentials"
 )
 
 // Errors
@@ -28,6 +40,35 @@ var (
 	// ErrCredentialMissing is returned when the credentials do not contain a username and/or password.
 	ErrCredentialMissing = errors.New("Username/Password is missing")
 
-	// ErrUnknownSecretKey is returned when the supplied key does not return a secret.
+	// ErrCertificateMissing is returned when the credentials contain only one of certificate and private key.
+	ErrCertificateMissing = errors.New("Certificate/PrivateKey is missing")
+
+	// ErrTokenMissing is returned when no token is available to authenticate
+	// with the Vault compatible credential provider.
+	ErrTokenMissing = errors.New("Token is missing")
+
+	// ErrUnknownSecretKey is returned when none of the keys of the secret holds credentials.
 	ErrUnknownSecretKey = errors.New("Unknown secret key")
+
+	// ErrUnsupportedSecretVersion is returned when a CredentialsSecret has an
+	// unsupported apiVersion or kind.
+	ErrUnsupportedSecretVersion = errors.New("Unsupported apiVersion or kind of the credentials")
+
+	// ErrServerMissing is returned when an entry of a CredentialsSecret does not name its server.
+	ErrServerMissing = errors.New("Server is missing")
+
+	// ErrDuplicateServer is returned when the credentials of a server are defined more than once.
+	ErrDuplicateServer = errors.New("Credentials of the server are defined more than once")
+
+	// ErrInvalidThumbprint is returned when the thumbprint is not a colon separated hex string.
+	ErrInvalidThumbprint = errors.New("Thumbprint is invalid")
 )
+
+func (e *SecretEntryError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Entry, e.Err)
+}
+
+// Unwrap returns the validation error of the entry.
+func (e *SecretEntryError) Unwrap() error {
+	return e.Err
+}
@@ -17,12 +17,19 @@ limitations under the License.
 package credentialmanager
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"regexp"
+	"sort"
 	"strings"
 
+	yaml "gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	v1 "k8s.io/client-go/listers/core/v1"
 	klog "k8s.io/klog/v2"
 )
@@ -101,6 +108,9 @@ func (credentialManager *CredentialManager) updateCredentialsMapK8s() error {
 }
 
 func (credentialManager *CredentialManager) updateCredentialsMapFile() error {
+	credentialManager.secretsDirectoryLock.Lock()
+	defer credentialManager.secretsDirectoryLock.Unlock()
+
 	//Secretsdirectory was parsed before, no need to do it again
 	if credentialManager.secretsDirectoryParsed {
 		return nil
@@ -118,6 +128,10 @@ func (credentialManager *CredentialManager) updateCredentialsMapFile() error {
 	}
 
 	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "..") {
+			// the versioned data of a Kubernetes volume, the keys link into it
+			continue
+		}
 		if f.IsDir() {
 			klog.Warningf("Skipping parse of directory: %s", f.Name())
 			continue
@@ -187,34 +201,160 @@ func (cache *SecretCache) parseSecret() error {
 	return parseConfig(data, cache.VirtualCenter)
 }
 
-// parseConfig returns vCenter ip/fdqn mapping to its credentials viz. Username and Password.
+// parseConfig returns vCenter ip/fdqn mapping to its credentials viz. Username and Password,
+// or Certificate and PrivateKey. The credentials are read from the flat
+// <server>.username, <server>.password, <server>.cert, <server>.key and
+// <server>.thumbprint keys, and from a CredentialsSecret held by the
+// credentials.yaml or credentials.json key. Other keys are ignored, unless
+// there is none of these. An error is returned for every invalid entry.
 func parseConfig(data map[string][]byte, config map[string]*Credential) error {
 	if len(data) == 0 {
 		return ErrCredentialMissing
 	}
+
+	var errs []error
+	flat := make(map[string]*Credential)
+	structured := make(map[string]*Credential)
 	for credentialKey, credentialValue := range data {
-		if strings.HasSuffix(credentialKey, "password") {
-			vcServer := strings.Split(credentialKey, ".password")[0]
-			if _, ok := config[vcServer]; !ok {
-				config[vcServer] = &Credential{}
-			}
-			config[vcServer].Password = string(credentialValue)
-		} else if strings.HasSuffix(credentialKey, "username") {
-			vcServer := strings.Split(credentialKey, ".username")[0]
-			if _, ok := config[vcServer]; !ok {
-				config[vcServer] = &Credential{}
-			}
-			config[vcServer].User = string(credentialValue)
-		} else {
-			klog.Errorf("Unknown secret key %s", credentialKey)
-			return ErrUnknownSecretKey
+		if credentialKey == CredentialsSecretKey || credentialKey == CredentialsSecretKeyJSON {
+			errs = append(errs, parseCredentialsSecret(credentialKey, credentialValue, structured)...)
+			continue
+		}
+		vcServer, suffix := splitSecretKey(credentialKey)
+		if suffix == "" {
+			klog.Warningf("Ignoring unknown secret key %s", credentialKey)
+			continue
+		}
+		if _, ok := flat[vcServer]; !ok {
+			flat[vcServer] = &Credential{}
+		}
+		switch suffix {
+		case ".username":
+			flat[vcServer].User = string(credentialValue)
+		case ".password":
+			flat[vcServer].Password = string(credentialValue)
+		case ".cert":
+			flat[vcServer].Certificate = string(credentialValue)
+		case ".key":
+			flat[vcServer].PrivateKey = string(credentialValue)
+		case ".thumbprint":
+			flat[vcServer].Thumbprint = string(credentialValue)
+		}
+	}
+	if len(flat) == 0 && len(structured) == 0 && len(errs) == 0 {
+		klog.Error("None of the secret keys holds credentials")
+		return ErrUnknownSecretKey
+	}
+
+	for vcServer, credential := range flat {
+		if _, ok := structured[vcServer]; ok {
+			errs = append(errs, &SecretEntryError{Entry: vcServer, Err: ErrDuplicateServer})
+			continue
+		}
+		if err := validateCredential(vcServer, credential); err != nil {
+			errs = append(errs, &SecretEntryError{Entry: vcServer, Err: err})
+			continue
+		}
+		config[vcServer] = credential
+	}
+	for vcServer, credential := range structured {
+		if credential != nil {
+			config[vcServer] = credential
+		}
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return utilerrors.NewAggregate(errs)
+}
+
+// secretKeySuffixes are the suffixes of the flat secret keys
+var secretKeySuffixes = []string{".username", ".password", ".cert", ".key", ".thumbprint"}
+
+// splitSecretKey splits a flat secret key into the server and the suffix
+// naming the credential field. The suffix is empty if the key is unknown.
+func splitSecretKey(credentialKey string) (string, string) {
+	for _, suffix := range secretKeySuffixes {
+		if strings.HasSuffix(credentialKey, suffix) && len(credentialKey) > len(suffix) {
+			return strings.TrimSuffix(credentialKey, suffix), suffix
+		}
+	}
+	return "", ""
+}
+
+// parseCredentialsSecret adds the entries of the CredentialsSecret held by the
+// given key to config and returns an error for every invalid one. Invalid
+// entries are added as nil, so that their servers are still known to be defined.
+func parseCredentialsSecret(credentialKey string, value []byte, config map[string]*Credential) []error {
+	var secret CredentialsSecret
+	var err error
+	if credentialKey == CredentialsSecretKeyJSON {
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&secret)
+	} else {
+		err = yaml.UnmarshalStrict(value, &secret)
+	}
+	if err != nil {
+		klog.Errorf("Failed to parse secret key %s: %v", credentialKey, err)
+		return []error{&SecretEntryError{Entry: credentialKey, Err: err}}
+	}
+	if secret.APIVersion != CredentialsSecretAPIVersion || secret.Kind != CredentialsSecretKind {
+		klog.Errorf("Secret key %s holds %s %s, expected %s %s", credentialKey, secret.APIVersion, secret.Kind,
+			CredentialsSecretAPIVersion, CredentialsSecretKind)
+		return []error{&SecretEntryError{Entry: credentialKey, Err: ErrUnsupportedSecretVersion}}
+	}
+
+	var errs []error
+	for i, entry := range secret.Servers {
+		name := fmt.Sprintf("%s servers[%d]", credentialKey, i)
+		if entry.Server == "" {
+			errs = append(errs, &SecretEntryError{Entry: name, Err: ErrServerMissing})
+			continue
+		}
+		name = fmt.Sprintf("%s (%s)", name, entry.Server)
+		if _, ok := config[entry.Server]; ok {
+			errs = append(errs, &SecretEntryError{Entry: name, Err: ErrDuplicateServer})
+			continue
 		}
+		credential := &Credential{
+			User:        entry.User,
+			Password:    entry.Password,
+			Certificate: entry.Cert,
+			PrivateKey:  entry.Key,
+			Thumbprint:  entry.Thumbprint,
+		}
+		if err := validateCredential(entry.Server, credential); err != nil {
+			errs = append(errs, &SecretEntryError{Entry: name, Err: err})
+			credential = nil
+		}
+		config[entry.Server] = credential
+	}
+	return errs
+}
+
+// thumbprintPattern matches the SHA-1 or SHA-256 thumbprint of a certificate
+var thumbprintPattern = regexp.MustCompile(`^[0-9A-Fa-f]{2}(:[0-9A-Fa-f]{2}){19}((:[0-9A-Fa-f]{2}){12})?$`)
+
+// validateCredential returns an error unless the credential holds a username
+// and password, or a certificate and private key.
+func validateCredential(vcServer string, credential *Credential) error {
+	if credential.Thumbprint != "" && !thumbprintPattern.MatchString(credential.Thumbprint) {
+		klog.Errorf("Thumbprint %q is invalid for server %s", credential.Thumbprint, vcServer)
+		return ErrInvalidThumbprint
 	}
-	for vcServer, credential := range config {
-		if credential.User == "" || credential.Password == "" {
-			klog.Errorf("Username/Password is missing for server %s", vcServer)
-			return ErrCredentialMissing
+	if credential.Certificate != "" || credential.PrivateKey != "" {
+		if credential.Certificate == "" || credential.PrivateKey == "" {
+			klog.Errorf("Certificate/PrivateKey is missing for server %s", vcServer)
+			return ErrCertificateMissing
 		}
+		return nil
+	}
+	if credential.User == "" || credential.Password == "" {
+		klog.Errorf("Username/Password is missing for server %s", vcServer)
+		return ErrCredentialMissing
 	}
 	return nil
 }
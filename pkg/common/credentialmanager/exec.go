@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	// ExecCredentialAPIVersion is the apiVersion of the ExecCredential written
+	// by a credential plugin.
+	ExecCredentialAPIVersion = "vsphere.cloudprovider.k8s.io/v1alpha1"
+	// ExecCredentialKind is the kind of the ExecCredential written by a
+	// credential plugin.
+	ExecCredentialKind = "VCenterCredential"
+	// ExecServerEnv is the environment variable holding the vCenter server
+	// whose credentials are requested from a credential plugin.
+	ExecServerEnv = "VSPHERE_SERVER"
+)
+
+// ExecCredential is written by a credential plugin to its stdout, e.g.
+//
+//	{
+//	  "apiVersion": "vsphere.cloudprovider.k8s.io/v1alpha1",
+//	  "kind": "VCenterCredential",
+//	  "status": {
+//	    "username": "administrator@vsphere.local",
+//	    "password": "secret",
+//	    "expirationTimestamp": "2021-03-01T10:00:00Z"
+//	  }
+//	}
+type ExecCredential struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Status     ExecCredentialStatus `json:"status"`
+}
+
+// ExecCredentialStatus holds the credentials returned by a credential plugin.
+type ExecCredentialStatus struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Certificate and PrivateKey are the PEM encoded key pair of a solution
+	// user, used instead of Username and Password if set.
+	Certificate string `json:"certificate,omitempty"`
+	PrivateKey  string `json:"privateKey,omitempty"`
+	// The credentials are reused until this time. If not set, the plugin is
+	// run whenever the credentials are needed.
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+}
+
+// ExecProvider obtains the credentials from the output of a credential plugin,
+// in the manner of the kubectl exec credential plugins. The plugin is run with
+// the vCenter server in the VSPHERE_SERVER environment variable and must write
+// an ExecCredential to its stdout.
+type ExecProvider struct {
+	Command string
+	Args    []string
+
+	cacheLock sync.Mutex // guards cache and serializes the runs of the plugin
+	cache     map[string]execCacheEntry
+}
+
+type execCacheEntry struct {
+	credential Credential
+	expiry     time.Time
+}
+
+// NewExecProvider returns a new ExecProvider object.
+func NewExecProvider(command string, args []string) *ExecProvider {
+	return &ExecProvider{
+		Command: command,
+		Args:    args,
+		cache:   make(map[string]execCacheEntry),
+	}
+}
+
+// GetCredential returns the credentials for the given vCenter server, running
+// the credential plugin unless the credentials it returned before have not
+// expired yet.
+func (p *ExecProvider) GetCredential(server string) (*Credential, error) {
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+
+	if entry, ok := p.cache[server]; ok {
+		if time.Now().Before(entry.expiry) {
+			credential := entry.credential
+			return &credential, nil
+		}
+		delete(p.cache, server)
+	}
+
+	status, err := p.run(server)
+	if err != nil {
+		klog.Errorf("Credential plugin %s failed for server %s: %v", p.Command, server, err)
+		return nil, err
+	}
+	credential := Credential{
+		User:        status.Username,
+		Password:    status.Password,
+		Certificate: status.Certificate,
+		PrivateKey:  status.PrivateKey,
+	}
+	if err := validateCredential(server, &credential); err != nil {
+		return nil, err
+	}
+	if status.ExpirationTimestamp != nil {
+		p.cache[server] = execCacheEntry{credential: credential, expiry: *status.ExpirationTimestamp}
+	}
+	return &credential, nil
+}
+
+func (p *ExecProvider) run(server string) (*ExecCredentialStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ProviderTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Env = append(os.Environ(), ExecServerEnv+"="+server)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var execCredential ExecCredential
+	if err := json.Unmarshal(stdout.Bytes(), &execCredential); err != nil {
+		return nil, fmt.Errorf("decoding output: %v", err)
+	}
+	if execCredential.APIVersion != ExecCredentialAPIVersion || execCredential.Kind != ExecCredentialKind {
+		return nil, fmt.Errorf("unsupported output %s %s, expected %s %s", execCredential.APIVersion,
+			execCredential.Kind, ExecCredentialAPIVersion, ExecCredentialKind)
+	}
+	return &execCredential.Status, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// execPlugin is a credential plugin appending a line to the file in its first
+// argument on every run. Its output expires at the time in the second one.
+const execPlugin = `#!/bin/sh
+echo "$VSPHERE_SERVER" >> "$1"
+case "$VSPHERE_SERVER" in
+failing) echo "no credentials for $VSPHERE_SERVER" >&2; exit 1;;
+unversioned) echo '{"status": {"username": "user", "password": "pass"}}'; exit 0;;
+incomplete) echo '{"apiVersion": "vsphere.cloudprovider.k8s.io/v1alpha1", "kind": "VCenterCredential", "status": {"username": "user"}}'; exit 0;;
+esac
+expiry=""
+if [ -n "$2" ]; then expiry=", \"expirationTimestamp\": \"$2\""; fi
+cat <<EOT
+{"apiVersion": "vsphere.cloudprovider.k8s.io/v1alpha1", "kind": "VCenterCredential",
+ "status": {"username": "user@$VSPHERE_SERVER", "password": "pass"$expiry}}
+EOT
+`
+
+func TestExecProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec-provider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	plugin := filepath.Join(dir, "plugin.sh")
+	if err := ioutil.WriteFile(plugin, []byte(execPlugin), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := func(log string) int {
+		content, _ := ioutil.ReadFile(log)
+		return strings.Count(string(content), "\n")
+	}
+
+	tests := []struct {
+		name   string
+		server string
+		expiry string
+		runs   int
+		err    string
+	}{
+		{name: "cached until expiry", server: "vc1", expiry: time.Now().Add(time.Hour).Format(time.RFC3339), runs: 1},
+		{name: "expired", server: "vc1", expiry: time.Now().Add(-time.Hour).Format(time.RFC3339), runs: 2},
+		{name: "no expiry", server: "vc1", runs: 2},
+		{name: "plugin failure", server: "failing", runs: 2, err: "no credentials for failing"},
+		{name: "unsupported output", server: "unversioned", runs: 2, err: "unsupported output"},
+		{name: "incomplete credentials", server: "incomplete", runs: 2, err: ErrCredentialMissing.Error()},
+	}
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := filepath.Join(dir, test.name)
+			provider := NewExecProvider(plugin, []string{log, test.expiry})
+			for j := 0; j < 2; j++ {
+				credential, err := provider.GetCredential(test.server)
+				if test.err != "" {
+					if err == nil || !strings.Contains(err.Error(), test.err) {
+						t.Fatalf("%d: expected error %q, got %v", i, test.err, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if credential.User != "user@"+test.server || credential.Password != "pass" {
+					t.Errorf("unexpected credentials %+v", credential)
+				}
+			}
+			if n := runs(log); n != test.runs {
+				t.Errorf("expected %d runs of the plugin, got %d", test.runs, n)
+			}
+		})
+	}
+}
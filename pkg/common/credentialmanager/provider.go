@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialmanager
+
+import (
+	"time"
+
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
+)
+
+// ProviderTimeout bounds the time an external credential provider may take to
+// return the credentials.
+var ProviderTimeout = 30 * time.Second
+
+// CredentialProvider provides the credentials of vCenter servers.
+type CredentialProvider interface {
+	// GetCredential returns the credentials for the given vCenter server.
+	GetCredential(server string) (*Credential, error)
+}
+
+// CredentialManager provides the credentials from a Kubernetes secret or a
+// secrets directory.
+var _ CredentialProvider = &CredentialManager{}
+
+// NewCredentialProvider returns the external credential provider of the
+// configuration.
+func NewCredentialProvider(cfg *vcfg.CredentialProvider) (CredentialProvider, error) {
+	switch cfg.Type {
+	case vcfg.CredentialProviderExec:
+		return NewExecProvider(cfg.Command, cfg.Args), nil
+	case vcfg.CredentialProviderVault:
+		return NewVaultProvider(cfg.Address, cfg.Path, cfg.TokenFile, cfg.CAFile)
+	default:
+		return nil, vcfg.ErrUnknownCredentialProvider
+	}
+}
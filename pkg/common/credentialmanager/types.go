@@ -36,6 +36,43 @@ type SecretCache struct {
 type Credential struct {
 	User     string `gcfg:"user"`
 	Password string `gcfg:"password"`
+	// Certificate and PrivateKey are the PEM encoded key pair of a solution
+	// user, used instead of User and Password if set.
+	Certificate string `gcfg:"cert"`
+	PrivateKey  string `gcfg:"key"`
+	// Thumbprint of the vCenter certificate, if set by the secret.
+	Thumbprint string `gcfg:"thumbprint"`
+}
+
+// CredentialsSecret is the structured format of the credentials, held as YAML
+// or JSON by the credentials.yaml or credentials.json key of the secret.
+type CredentialsSecret struct {
+	APIVersion string             `yaml:"apiVersion" json:"apiVersion"`
+	Kind       string             `yaml:"kind" json:"kind"`
+	Servers    []ServerCredential `yaml:"servers" json:"servers"`
+}
+
+// ServerCredential holds the credentials of a vCenter server in a
+// CredentialsSecret.
+type ServerCredential struct {
+	// IP or FQDN of the vCenter server
+	Server   string `yaml:"server" json:"server"`
+	User     string `yaml:"user" json:"user"`
+	Password string `yaml:"password" json:"password"`
+	// PEM encoded key pair of a solution user, used instead of User and
+	// Password if set.
+	Cert string `yaml:"cert" json:"cert"`
+	Key  string `yaml:"key" json:"key"`
+	// Thumbprint of the vCenter certificate
+	Thumbprint string `yaml:"thumbprint" json:"thumbprint"`
+}
+
+// SecretEntryError describes an invalid entry of a credentials secret.
+type SecretEntryError struct {
+	// Entry identifies the entry, e.g. its server or its index in the list of
+	// servers of a CredentialsSecret.
+	Entry string
+	Err   error
 }
 
 // CredentialManager is used to manage vCenter credentials stored as
@@ -45,6 +82,7 @@ type CredentialManager struct {
 	SecretNamespace        string
 	SecretLister           clientv1.SecretLister
 	SecretsDirectory       string
-	secretsDirectoryParsed bool // internal placeholder to identify we parsed the SecretsDirectory
+	secretsDirectoryLock   sync.Mutex // guards secretsDirectoryParsed and reading the SecretsDirectory
+	secretsDirectoryParsed bool       // internal placeholder to identify we parsed the SecretsDirectory
 	Cache                  *SecretCache
 }
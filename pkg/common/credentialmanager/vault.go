@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialmanager
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+// VaultTokenEnv is the environment variable holding the token of the Vault
+// compatible credential provider if no token file is configured.
+const VaultTokenEnv = "VAULT_TOKEN"
+
+// VaultProvider reads the credentials from a secret of a Vault compatible HTTP
+// server, stored by a KV secrets engine of version 1 or 2. The secret holds the
+// same keys as the Kubernetes secret, e.g. <server>.username and
+// <server>.password, or just username and password if it is specific to a
+// vCenter.
+type VaultProvider struct {
+	// Address of the server, e.g. https://vault:8200
+	Address string
+	// Path of the secret, e.g. secret/data/vsphere
+	Path string
+	// File holding the token, read for every request so that it may be renewed
+	// by an agent. The token is read from VAULT_TOKEN if not set.
+	TokenFile string
+	Client    *http.Client
+}
+
+// NewVaultProvider returns a new VaultProvider object. The server certificate
+// is verified with the CA certificate in caFile if set.
+func NewVaultProvider(address string, path string, tokenFile string, caFile string) (*VaultProvider, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caFile != "" {
+		caCert, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &VaultProvider{
+		Address:   address,
+		Path:      path,
+		TokenFile: tokenFile,
+		Client:    &http.Client{Transport: transport, Timeout: ProviderTimeout},
+	}, nil
+}
+
+// GetCredential returns the credentials for the given vCenter server.
+func (p *VaultProvider) GetCredential(server string) (*Credential, error) {
+	data, err := p.readSecret()
+	if err != nil {
+		klog.Errorf("Failed to read secret %s from %s: %v", p.Path, p.Address, err)
+		return nil, err
+	}
+
+	secretData := make(map[string][]byte)
+	for key, value := range data {
+		s, ok := value.(string)
+		if !ok {
+			klog.Errorf("Value of secret key %s is not a string", key)
+			return nil, ErrUnknownSecretKey
+		}
+		if !strings.Contains(key, ".") {
+			// a secret specific to the vCenter
+			key = server + "." + key
+		}
+		secretData[key] = []byte(s)
+	}
+
+	credentials := make(map[string]*Credential)
+	if err := parseConfig(secretData, credentials); err != nil {
+		return nil, err
+	}
+	credential, found := credentials[server]
+	if !found {
+		klog.Errorf("credentials not found for server %s", server)
+		return nil, ErrCredentialsNotFound
+	}
+	return credential, nil
+}
+
+// readSecret returns the key value pairs of the secret.
+func (p *VaultProvider) readSecret() (map[string]interface{}, error) {
+	token, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(p.Address, "/") + "/v1/" + strings.TrimPrefix(p.Path, "/")
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, err
+	}
+	// version 2 of the KV secrets engine nests the data next to its metadata
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return nested, nil
+		}
+	}
+	return secret.Data, nil
+}
+
+func (p *VaultProvider) token() (string, error) {
+	token := os.Getenv(VaultTokenEnv)
+	if p.TokenFile != "" {
+		content, err := ioutil.ReadFile(p.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	if token == "" {
+		return "", ErrTokenMissing
+	}
+	return token, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialmanager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// vaultStandIn serves the secrets of a KV version 1 engine mounted at kv and
+// of a version 2 engine mounted at secret.
+func vaultStandIn(token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/vsphere":
+			fmt.Fprint(w, `{"lease_duration": 2764800, "data": {"vc1.username": "user1", "vc1.password": "pass1",
+				"vc2.username": "user2", "vc2.password": "pass2"}}`)
+		case "/v1/secret/data/vc1":
+			fmt.Fprint(w, `{"data": {"data": {"username": "user1", "password": "pass1"},
+				"metadata": {"version": 3}}}`)
+		case "/v1/secret/data/broken":
+			fmt.Fprint(w, `{"data": {"data": {"username": "user1"}, "metadata": {"version": 1}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := vaultStandIn("s.token")
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "vault-provider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("s.token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wrongTokenFile := filepath.Join(dir, "wrong-token")
+	if err := ioutil.WriteFile(wrongTokenFile, []byte("s.other"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		tokenFile string
+		server    string
+		expected  *Credential
+		fails     bool
+	}{
+		{name: "KV version 1", path: "kv/vsphere", tokenFile: tokenFile, server: "vc2",
+			expected: &Credential{User: "user2", Password: "pass2"}},
+		{name: "KV version 2", path: "/secret/data/vc1", tokenFile: tokenFile, server: "vc1",
+			expected: &Credential{User: "user1", Password: "pass1"}},
+		{name: "unknown server", path: "kv/vsphere", tokenFile: tokenFile, server: "vc3", fails: true},
+		{name: "incomplete secret", path: "secret/data/broken", tokenFile: tokenFile, server: "vc1", fails: true},
+		{name: "missing secret", path: "secret/data/vc2", tokenFile: tokenFile, server: "vc2", fails: true},
+		{name: "wrong token", path: "kv/vsphere", tokenFile: wrongTokenFile, server: "vc1", fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := NewVaultProvider(server.URL, test.path, test.tokenFile, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			credential, err := provider.GetCredential(test.server)
+			if test.fails {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", credential)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *credential != *test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, credential)
+			}
+		})
+	}
+}
+
+func TestVaultProviderTokenFromEnv(t *testing.T) {
+	server := vaultStandIn("s.token")
+	defer server.Close()
+
+	provider, err := NewVaultProvider(server.URL, "kv/vsphere", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv(VaultTokenEnv)
+	if _, err := provider.GetCredential("vc1"); err != ErrTokenMissing {
+		t.Errorf("expected %v, got %v", ErrTokenMissing, err)
+	}
+
+	os.Setenv(VaultTokenEnv, "s.token")
+	defer os.Unsetenv(VaultTokenEnv)
+	if _, err := provider.GetCredential("vc1"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialmanager
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	corev1 "k8s.io/api/core/v1"
+	klog "k8s.io/klog/v2"
+)
+
+// SecretsDirectoryDebounce is the quiet period after the last change in the
+// secrets directory before it is read again, so that keys written one by one
+// are picked up together.
+var SecretsDirectoryDebounce = time.Second
+
+// WatchSecretsDirectory reads the SecretsDirectory again whenever its content
+// changes. This includes the atomic swap of the ..data symlink of a mounted
+// Kubernetes secret, as the directory itself is watched. onChange is called
+// with the servers whose credentials changed. It returns once the watch is set
+// up; watching ends when stop is closed.
+func (credentialManager *CredentialManager) WatchSecretsDirectory(stop <-chan struct{}, onChange func(servers []string)) error {
+	dir := credentialManager.SecretsDirectory
+	if dir == "" {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+	klog.V(2).Infof("Watching secrets directory %s for changes", dir)
+
+	if err := credentialManager.updateCredentialsMapFile(); err != nil {
+		klog.Warningf("Failed parsing SecretsDirectory %q: %q", dir, err)
+	}
+	// credentials as of the last successful read, so that changes spread over
+	// several reads, of which some failed, are not missed
+	known := credentialManager.Cache.credentials()
+	go func() {
+		defer watcher.Close()
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-stop:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				klog.V(4).Infof("Secrets directory event: %s", event)
+				debounce = time.After(SecretsDirectoryDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				klog.Warningf("Watching secrets directory %s failed: %v", dir, err)
+			case <-debounce:
+				debounce = nil
+				if err := credentialManager.reloadSecretsDirectory(); err != nil {
+					klog.Warningf("Failed parsing SecretsDirectory %q: %q", dir, err)
+					continue
+				}
+				current := credentialManager.Cache.credentials()
+				servers := changedServers(known, current)
+				known = current
+				if len(servers) > 0 {
+					klog.Infof("Credentials in secrets directory %s changed for servers %v", dir, servers)
+					onChange(servers)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// UpdateFromSecret updates the cached credentials from the given version of
+// the secret of the credential manager, as delivered by a secret informer, and
+// returns the servers whose credentials changed. Other secrets are ignored.
+func (credentialManager *CredentialManager) UpdateFromSecret(secret *corev1.Secret) ([]string, error) {
+	if credentialManager.SecretName == "" || secret.GetName() != credentialManager.SecretName ||
+		secret.GetNamespace() != credentialManager.SecretNamespace {
+		return nil, nil
+	}
+	cacheSecret := credentialManager.Cache.GetSecret()
+	if cacheSecret != nil && cacheSecret.GetResourceVersion() == secret.GetResourceVersion() {
+		return nil, nil
+	}
+
+	known := credentialManager.Cache.credentials()
+	credentialManager.Cache.UpdateSecret(secret)
+	if err := credentialManager.Cache.parseSecret(); err != nil {
+		return nil, err
+	}
+	return changedServers(known, credentialManager.Cache.credentials()), nil
+}
+
+// reloadSecretsDirectory reads the SecretsDirectory again
+func (credentialManager *CredentialManager) reloadSecretsDirectory() error {
+	credentialManager.secretsDirectoryLock.Lock()
+	credentialManager.secretsDirectoryParsed = false
+	credentialManager.secretsDirectoryLock.Unlock()
+	return credentialManager.updateCredentialsMapFile()
+}
+
+// changedServers returns the servers whose credentials were added or changed
+func changedServers(before map[string]Credential, after map[string]Credential) []string {
+	var servers []string
+	for server, credential := range after {
+		if previous, ok := before[server]; !ok || previous != credential {
+			servers = append(servers, server)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// credentials returns a copy of the cached credentials
+func (cache *SecretCache) credentials() map[string]Credential {
+	cache.cacheLock.Lock()
+	defer cache.cacheLock.Unlock()
+	credentials := make(map[string]Credential, len(cache.VirtualCenter))
+	for server, credential := range cache.VirtualCenter {
+		credentials[server] = *credential
+	}
+	return credentials
+}
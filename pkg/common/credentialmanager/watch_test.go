@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialmanager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// writeAtomically updates the directory the way the kubelet updates a mounted
+// secret: the keys link into ..data, which is swapped to a new version.
+func writeAtomically(t *testing.T, dir string, version int, data map[string]string) {
+	versionDir := fmt.Sprintf("..v%d", version)
+	if err := os.Mkdir(filepath.Join(dir, versionDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range data {
+		if err := ioutil.WriteFile(filepath.Join(dir, versionDir, key), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(versionDir, filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	for key := range data {
+		link := filepath.Join(dir, key)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join("..data", key), link); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if version > 1 {
+		os.RemoveAll(filepath.Join(dir, fmt.Sprintf("..v%d", version-1)))
+	}
+}
+
+func TestWatchSecretsDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	debounce := SecretsDirectoryDebounce
+	SecretsDirectoryDebounce = 10 * time.Millisecond
+	defer func() { SecretsDirectoryDebounce = debounce }()
+
+	writeAtomically(t, dir, 1, map[string]string{
+		"10.0.0.1.username": "user",
+		"10.0.0.1.password": "password",
+		"10.0.0.2.username": "user",
+		"10.0.0.2.password": "password",
+	})
+
+	credMgr := NewCredentialManager("", "", dir, nil)
+	stop := make(chan struct{})
+	defer close(stop)
+	changes := make(chan []string, 1)
+	err = credMgr.WatchSecretsDirectory(stop, func(servers []string) {
+		changes <- servers
+	})
+	if err != nil {
+		t.Fatalf("WatchSecretsDirectory err=%v", err)
+	}
+
+	writeAtomically(t, dir, 2, map[string]string{
+		"10.0.0.1.username": "user",
+		"10.0.0.1.password": "rotated",
+		"10.0.0.2.username": "user",
+		"10.0.0.2.password": "password",
+	})
+
+	select {
+	case servers := <-changes:
+		if !reflect.DeepEqual(servers, []string{"10.0.0.1"}) {
+			t.Errorf("expected changed servers [10.0.0.1], got %v", servers)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("rotation of the credentials was not detected")
+	}
+
+	credential, err := credMgr.GetCredential("10.0.0.1")
+	if err != nil {
+		t.Fatalf("GetCredential err=%v", err)
+	}
+	if credential.Password != "rotated" {
+		t.Errorf("expected rotated password, got %s", credential.Password)
+	}
+}
+
+func TestUpdateFromSecret(t *testing.T) {
+	secret := func(name string, version string, user string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", ResourceVersion: version},
+			Data: map[string][]byte{
+				"vc1.username": []byte(user),
+				"vc1.password": []byte("password"),
+				"vc2.username": []byte("user"),
+				"vc2.password": []byte("password"),
+			},
+		}
+	}
+	credentialManager := NewCredentialManager("vsphere-creds", "kube-system", "", nil)
+
+	steps := []struct {
+		secret  *corev1.Secret
+		servers []string
+	}{
+		{secret: secret("vsphere-creds", "1", "user"), servers: []string{"vc1", "vc2"}},
+		{secret: secret("vsphere-creds", "1", "other"), servers: nil},
+		{secret: secret("nsxt-creds", "2", "other"), servers: nil},
+		{secret: secret("vsphere-creds", "3", "user"), servers: nil},
+		{secret: secret("vsphere-creds", "4", "rotated"), servers: []string{"vc1"}},
+	}
+	for i, step := range steps {
+		servers, err := credentialManager.UpdateFromSecret(step.secret)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(servers, step.servers) {
+			t.Errorf("step %d: expected changed servers %v, got %v", i, step.servers, servers)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultServiceName is the service name reported when none is configured.
+	DefaultServiceName = "vsphere-cloud-controller-manager"
+	// DefaultBatchTimeout is the longest time ended spans wait before being exported.
+	DefaultBatchTimeout = 5 * time.Second
+
+	// tracesPath is the path of the OTLP/HTTP traces endpoint, appended to
+	// endpoints without a path.
+	tracesPath = "/v1/traces"
+	// instrumentationScope names the instrumentation in exported spans.
+	instrumentationScope = "k8s.io/cloud-provider-vsphere"
+
+	maxBatchSize  = 512
+	maxQueueSize  = 2048
+	exportTimeout = 10 * time.Second
+
+	// OTLP status codes, which differ from the ones of the OpenTelemetry API
+	statusCodeUnset = 0
+	statusCodeOK    = 1
+	statusCodeError = 2
+)
+
+// Config configures the export of spans.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP collector. "/v1/traces" is
+	// appended when it has no path. Tracing is disabled when empty.
+	Endpoint string
+	// Headers are added to every export request, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// SamplingRatio is the fraction of traces recorded, between 0 and 1.
+	SamplingRatio float64
+	// BatchTimeout is the longest time ended spans wait before being exported.
+	BatchTimeout time.Duration
+}
+
+var (
+	globalLock sync.RWMutex
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer = trace.NewNoopTracerProvider().Tracer(instrumentationScope)
+)
+
+func currentTracer() trace.Tracer {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+	return tracer
+}
+
+// Configure starts exporting spans as configured, replacing and flushing a
+// previous tracer provider. An empty endpoint disables tracing.
+func Configure(cfg Config) error {
+	var tp *sdktrace.TracerProvider
+	if cfg.Endpoint != "" {
+		e, err := newExporter(cfg)
+		if err != nil {
+			return err
+		}
+		if cfg.SamplingRatio < 0 || cfg.SamplingRatio > 1 {
+			return fmt.Errorf("invalid tracing sampling ratio %v: must be between 0 and 1", cfg.SamplingRatio)
+		}
+		serviceName := cfg.ServiceName
+		if serviceName == "" {
+			serviceName = DefaultServiceName
+		}
+		batchTimeout := cfg.BatchTimeout
+		if batchTimeout <= 0 {
+			batchTimeout = DefaultBatchTimeout
+		}
+		tp = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(e,
+				sdktrace.WithBatchTimeout(batchTimeout),
+				sdktrace.WithMaxQueueSize(maxQueueSize),
+				sdktrace.WithMaxExportBatchSize(maxBatchSize),
+				sdktrace.WithExportTimeout(exportTimeout)),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRatio))),
+			sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+		)
+	}
+	setProvider(context.Background(), tp)
+	return nil
+}
+
+// Shutdown disables tracing and exports the spans that have already ended.
+func Shutdown(ctx context.Context) {
+	setProvider(ctx, nil)
+}
+
+// setProvider replaces the tracer provider, shutting down the previous one.
+func setProvider(ctx context.Context, tp *sdktrace.TracerProvider) {
+	globalLock.Lock()
+	previous := provider
+	provider = tp
+	if tp != nil {
+		tracer = tp.Tracer(instrumentationScope)
+	} else {
+		tracer = trace.NewNoopTracerProvider().Tracer(instrumentationScope)
+	}
+	globalLock.Unlock()
+
+	if previous != nil {
+		if err := previous.Shutdown(ctx); err != nil {
+			klog.Warningf("Failed to export the remaining spans: %v", err)
+		}
+	}
+}
+
+// exporter posts batches of spans to an OTLP/HTTP collector in the JSON
+// encoding of the protocol.
+type exporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+var _ sdktrace.SpanExporter = &exporter{}
+
+func newExporter(cfg Config) (*exporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing endpoint %q: %v", cfg.Endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid tracing endpoint %q: scheme must be http or https", cfg.Endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = tracesPath
+	}
+	return &exporter{
+		endpoint: u.String(),
+		headers:  cfg.Headers,
+		client:   &http.Client{Timeout: exportTimeout},
+	}, nil
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(encode(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("collector returned %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	return nil
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (e *exporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// The types below are the JSON encoding of an OTLP ExportTraceServiceRequest.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+// encode returns the export request of spans. All of them come from the same
+// tracer provider, so they share its resource and instrumentation scope.
+func encode(spans []sdktrace.ReadOnlySpan) *otlpRequest {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           s.SpanContext().TraceID().String(),
+			SpanID:            s.SpanContext().SpanID().String(),
+			Name:              s.Name(),
+			Kind:              int(s.SpanKind()),
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime().UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime().UnixNano(), 10),
+			Attributes:        encodeAttributes(s.Attributes()),
+		}
+		if s.Parent().SpanID().IsValid() {
+			span.ParentSpanID = s.Parent().SpanID().String()
+		}
+		switch s.Status().Code {
+		case codes.Error:
+			span.Status = otlpStatus{Code: statusCodeError, Message: s.Status().Description}
+		case codes.Ok:
+			span.Status = otlpStatus{Code: statusCodeOK}
+		default:
+			span.Status = otlpStatus{Code: statusCodeUnset}
+		}
+		encoded = append(encoded, span)
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: encodeAttributes(spans[0].Resource().Attributes()),
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: spans[0].InstrumentationLibrary().Name},
+				Spans: encoded,
+			}},
+		}},
+	}
+}
+
+func encodeAttributes(attributes []attribute.KeyValue) []otlpKeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+	encoded := make([]otlpKeyValue, 0, len(attributes))
+	for _, a := range attributes {
+		kv := otlpKeyValue{Key: string(a.Key)}
+		switch a.Value.Type() {
+		case attribute.BOOL:
+			v := a.Value.AsBool()
+			kv.Value.BoolValue = &v
+		case attribute.INT64:
+			s := strconv.FormatInt(a.Value.AsInt64(), 10)
+			kv.Value.IntValue = &s
+		case attribute.FLOAT64:
+			v := a.Value.AsFloat64()
+			kv.Value.DoubleValue = &v
+		default:
+			s := a.Value.Emit()
+			kv.Value.StringValue = &s
+		}
+		encoded = append(encoded, kv)
+	}
+	return encoded
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing records spans of the operations of the cloud provider, the
+// requests to vCenter and NSX-T and the waits for locks with OpenTelemetry, and
+// exports them to an OpenTelemetry collector with the OTLP/HTTP protocol in its
+// JSON encoding. Tracing is disabled, and Start returns spans which are not
+// recorded, until Configure is called with an endpoint.
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute is a key value pair describing a span.
+type Attribute = attribute.KeyValue
+
+// String returns a string attribute.
+func String(key string, value string) Attribute {
+	return attribute.String(key, value)
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute {
+	return attribute.Int(key, value)
+}
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute {
+	return attribute.Bool(key, value)
+}
+
+// Span is a timed operation within a trace.
+type Span struct {
+	span trace.Span
+}
+
+// Start starts a span with the given name, as a child of the span of ctx if
+// there is one, and returns it together with a context carrying it. It must
+// be ended by calling End.
+func Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	ctx, span := currentTracer().Start(ctx, name, trace.WithAttributes(attributes...))
+	return ctx, &Span{span: span}
+}
+
+// Detach returns a context which is neither canceled with ctx nor bound by its
+// deadline, but carries its span, for work shared beyond the caller of ctx.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	s.span.SetAttributes(attributes...)
+}
+
+// End ends the span, marking it as failed if err is not nil. Only the first
+// call has an effect.
+func (s *Span) End(err error) {
+	if !s.span.IsRecording() {
+		return
+	}
+	if err != nil {
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// TraceID returns the ID of the trace of the span.
+func (s *Span) TraceID() trace.TraceID {
+	return s.span.SpanContext().TraceID()
+}
+
+// Lock acquires lock, recording the time spent waiting for it in a span so
+// that contention shows up in traces.
+func Lock(ctx context.Context, name string, lock sync.Locker, attributes ...Attribute) {
+	_, span := Start(ctx, name, attributes...)
+	lock.Lock()
+	span.End(nil)
+}
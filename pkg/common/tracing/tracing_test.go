@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type collector struct {
+	*httptest.Server
+
+	lock    sync.Mutex
+	header  http.Header
+	path    string
+	service string
+	spans   []otlpSpan
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode export request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.header = r.Header
+		c.path = r.URL.Path
+		for _, rs := range req.ResourceSpans {
+			c.service = *rs.Resource.Attributes[0].Value.StringValue
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *collector) exported() []otlpSpan {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.spans
+}
+
+func TestExport(t *testing.T) {
+	c := newCollector(t)
+	if err := Configure(Config{
+		Endpoint:      c.URL,
+		Headers:       map[string]string{"Authorization": "Bearer token"},
+		ServiceName:   "test",
+		SamplingRatio: 1,
+		BatchTimeout:  time.Hour,
+	}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	ctx, parent := Start(context.Background(), "parent", String("node", "node1"))
+	_, child := Start(ctx, "child", Int("attempt", 2))
+	child.SetAttributes(Bool("found", false))
+	child.End(errors.New("not found"))
+	parent.End(nil)
+	parent.End(errors.New("ignored"))
+
+	Shutdown(context.Background())
+
+	if c.path != tracesPath {
+		t.Errorf("expected export to %s, got %s", tracesPath, c.path)
+	}
+	if c.header.Get("Authorization") != "Bearer token" {
+		t.Errorf("expected configured header, got %v", c.header)
+	}
+	if c.service != "test" {
+		t.Errorf("expected service test, got %s", c.service)
+	}
+	spans := c.exported()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	exportedChild, exportedParent := spans[0], spans[1]
+	if exportedParent.Name != "parent" || exportedChild.Name != "child" {
+		t.Fatalf("unexpected spans %+v", spans)
+	}
+	if exportedChild.TraceID != exportedParent.TraceID || exportedChild.TraceID != parent.TraceID().String() {
+		t.Errorf("expected spans in trace %s, got %s and %s", parent.TraceID(), exportedParent.TraceID, exportedChild.TraceID)
+	}
+	if exportedParent.ParentSpanID != "" {
+		t.Errorf("expected root span, got parent %s", exportedParent.ParentSpanID)
+	}
+	if exportedChild.ParentSpanID != exportedParent.SpanID {
+		t.Errorf("expected child of %s, got %s", exportedParent.SpanID, exportedChild.ParentSpanID)
+	}
+	if exportedParent.Status.Code != statusCodeUnset {
+		t.Errorf("expected unset status, got %+v", exportedParent.Status)
+	}
+	if exportedChild.Status.Code != statusCodeError || exportedChild.Status.Message != "not found" {
+		t.Errorf("expected error status, got %+v", exportedChild.Status)
+	}
+	if len(exportedChild.Attributes) != 2 || *exportedChild.Attributes[0].Value.IntValue != "2" || *exportedChild.Attributes[1].Value.BoolValue {
+		t.Errorf("unexpected attributes %+v", exportedChild.Attributes)
+	}
+	if *exportedParent.Attributes[0].Value.StringValue != "node1" {
+		t.Errorf("unexpected attributes %+v", exportedParent.Attributes)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	c := newCollector(t)
+	if err := Configure(Config{Endpoint: c.URL, SamplingRatio: 0}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	if parent.span.IsRecording() || child.span.IsRecording() {
+		t.Errorf("expected unsampled trace")
+	}
+	child.End(nil)
+	parent.End(nil)
+
+	Shutdown(context.Background())
+	if spans := c.exported(); len(spans) != 0 {
+		t.Errorf("expected no spans, got %d", len(spans))
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	if err := Configure(Config{}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	ctx := context.Background()
+	_, span := Start(ctx, "disabled")
+	if span.span.IsRecording() {
+		t.Errorf("expected no recorded span while disabled")
+	}
+	span.SetAttributes(String("key", "value"))
+	span.End(nil)
+
+	var lock sync.Mutex
+	Lock(ctx, "lock", &lock)
+	lock.Unlock()
+}
+
+func TestInvalidConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Endpoint: "collector:4318"},
+		{Endpoint: "ftp://collector:4318"},
+		{Endpoint: "http://collector:4318", SamplingRatio: 1.5},
+	} {
+		if err := Configure(cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+			Shutdown(context.Background())
+		}
+	}
+}
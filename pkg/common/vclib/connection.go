@@ -17,137 +17,367 @@ limitations under the License.
 package vclib
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/pem"
 	"net"
 	neturl "net/url"
 	"sync"
+	"time"
 
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/sts"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/client-go/util/flowcontrol"
 	klog "k8s.io/klog/v2"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
 )
 
 // VSphereConnection contains information for connecting to vCenter
 type VSphereConnection struct {
-	Client            *vim25.Client
-	Username          string
-	Password          string
+	Client   *vim25.Client
+	Username string
+	Password string
+	// CertFile and KeyFile are the paths of the PEM encoded certificate and
+	// private key of a solution user. If set, the connection logs in with a
+	// SAML token issued by the STS of vCenter instead of username and password.
+	CertFile string
+	KeyFile  string
+	// Certificate and PrivateKey are a PEM encoded key pair of a solution user,
+	// as retrieved from a secret. They take precedence over CertFile and KeyFile.
+	Certificate       string
+	PrivateKey        string
 	Hostname          string
 	Port              string
 	CACert            string
 	Thumbprint        string
 	Insecure          bool
 	RoundTripperCount uint
-	credentialsLock   sync.Mutex
+	// ProxyURL is the URL of the HTTP, HTTPS or SOCKS5 proxy vCenter is
+	// reached through. The proxy environment variables apply if not set.
+	ProxyURL string
+	// ConnectTimeout bounds establishing a connection to vCenter, and
+	// RequestTimeout a single request. Zero means no timeout.
+	ConnectTimeout time.Duration
+	RequestTimeout time.Duration
+	// TLSMinVersion is the minimum TLS version, e.g. tls.VersionTLS12. The
+	// default of Go applies if zero.
+	TLSMinVersion uint16
+	// RateLimitQPS is the maximum rate of requests per second to vCenter, in
+	// bursts of up to RateLimitBurst requests. Zero means no limit.
+	RateLimitQPS   float32
+	RateLimitBurst int
+	// rateLimiter is the token bucket shared by all the clients of the
+	// connection, created once on first use.
+	rateLimiter     flowcontrol.RateLimiter
+	rateLimiterOnce sync.Once
+	// credentialsLock guards the credentials, the thumbprint and the SAML token state
+	credentialsLock sync.Mutex
+	// signer holds the SAML token shared by SOAP and REST logins until it is
+	// due for renewal.
+	signer *sts.Signer
+	// tokenExpiry is the expiry of the SAML token the current session was
+	// logged in with, if any.
+	tokenExpiry time.Time
+	// clientLock guards Client and sessionFault. It is never held while talking
+	// to vCenter, so that a slow vCenter cannot block readers of the current client.
+	clientLock sync.Mutex
+	// sessionFault is set when vCenter rejected a request as not authenticated,
+	// until the session is verified or replaced.
+	sessionFault bool
+	// loginGroup coalesces concurrent logins against this vCenter.
+	loginGroup singleflight.Group
+	// loginLock serializes the coalesced logins, so that a Connect and a
+	// Relogin never replace the client concurrently.
+	loginLock sync.Mutex
 }
 
-var (
-	clientLock sync.Mutex
+const (
+	connectKey = "connect"
+	reloginKey = "relogin"
 )
 
 // Connect makes connection to vCenter and sets VSphereConnection.Client.
 // If connection.Client is already set, it obtains the existing user session.
 // if user session is not valid, connection.Client will be set to the new client.
+// Concurrent calls on the same connection share a single login attempt, while
+// calls on different connections never wait on each other.
 func (connection *VSphereConnection) Connect(ctx context.Context) error {
-	var err error
-	clientLock.Lock()
-	defer clientLock.Unlock()
+	return connection.coalesce(ctx, connectKey, connection.connect)
+}
+
+// coalesce runs loginFunc, unless a login with the same key is already in flight
+// in which case it waits for its result. The login is shared by all the callers,
+// so it is not bound to the context of the one which started it, but runs within
+// LoginTimeout. Callers give up as soon as their own context is done.
+func (connection *VSphereConnection) coalesce(ctx context.Context, key string, loginFunc func(context.Context) error) error {
+	ctx, span := tracing.Start(ctx, "vcenter."+key, tracing.String("vcenter", connection.Hostname))
+	loginCtx := tracing.Detach(ctx)
+	ch := connection.loginGroup.DoChan(key, func() (interface{}, error) {
+		connection.loginLock.Lock()
+		defer connection.loginLock.Unlock()
+		ctx, cancel := context.WithTimeout(loginCtx, LoginTimeout)
+		defer cancel()
+		return nil, loginFunc(ctx)
+	})
+	select {
+	case res := <-ch:
+		span.SetAttributes(tracing.Bool("shared", res.Shared))
+		span.End(res.Err)
+		return res.Err
+	case <-ctx.Done():
+		span.End(ctx.Err())
+		return ctx.Err()
+	}
+}
 
-	if connection.Client == nil {
-		connection.Client, err = connection.NewClient(ctx)
+func (connection *VSphereConnection) connect(ctx context.Context) error {
+	client := connection.CurrentClient()
+	if client != nil {
+		m := session.NewManager(client)
+		userSession, err := m.UserSession(ctx)
 		if err != nil {
-			klog.Errorf("Failed to create govmomi client. err: %+v", err)
+			klog.Errorf("Error while obtaining user session. err: %+v", err)
 			return err
 		}
-		return nil
-	}
-	m := session.NewManager(connection.Client)
-	userSession, err := m.UserSession(ctx)
-	if err != nil {
-		klog.Errorf("Error while obtaining user session. err: %+v", err)
-		return err
-	}
-	if userSession != nil {
-		return nil
+		if userSession != nil {
+			connection.clearSessionFault()
+			return nil
+		}
+		klog.Warning("Creating new client session since the existing session is not valid or not authenticated")
 	}
-	klog.Warning("Creating new client session since the existing session is not valid or not authenticated")
 
-	connection.Client, err = connection.NewClient(ctx)
+	client, err := connection.NewClient(ctx)
 	if err != nil {
 		klog.Errorf("Failed to create govmomi client. err: %+v", err)
 		return err
 	}
+	connection.setClient(client)
 	return nil
 }
 
+// UserSession returns the user session of the existing client, or nil if the
+// session is no longer valid. As it performs a request against vCenter, it
+// also resets the idle timer of the session.
+func (connection *VSphereConnection) UserSession(ctx context.Context) (*types.UserSession, error) {
+	client := connection.CurrentClient()
+	if client == nil {
+		return nil, nil
+	}
+	m := session.NewManager(client)
+	return m.UserSession(ctx)
+}
+
+// Relogin replaces VSphereConnection.Client with a newly authenticated client.
+// The previous session is not logged out, as it may still be in use by
+// in-flight requests; it will expire on vCenter once it is idle.
+func (connection *VSphereConnection) Relogin(ctx context.Context) error {
+	return connection.coalesce(ctx, reloginKey, func(ctx context.Context) error {
+		client, err := connection.NewClient(ctx)
+		if err != nil {
+			klog.Errorf("Failed to create govmomi client. err: %+v", err)
+			return err
+		}
+		connection.setClient(client)
+		return nil
+	})
+}
+
+// Connected reports whether a client has been logged in to vCenter before.
+func (connection *VSphereConnection) Connected() bool {
+	return connection.CurrentClient() != nil
+}
+
+// CurrentClient returns the client of the current session, or nil if the
+// connection has not been established. It is safe to call concurrently with
+// logins, unlike reading Client directly.
+func (connection *VSphereConnection) CurrentClient() *vim25.Client {
+	connection.clientLock.Lock()
+	defer connection.clientLock.Unlock()
+	return connection.Client
+}
+
+func (connection *VSphereConnection) setClient(client *vim25.Client) {
+	connection.clientLock.Lock()
+	defer connection.clientLock.Unlock()
+	connection.Client = client
+	connection.sessionFault = false
+}
+
+// SessionFault reports whether vCenter rejected a request of the current
+// session as not authenticated, in which case the session must be verified
+// before it is relied upon again.
+func (connection *VSphereConnection) SessionFault() bool {
+	connection.clientLock.Lock()
+	defer connection.clientLock.Unlock()
+	return connection.sessionFault
+}
+
+func (connection *VSphereConnection) markSessionFault() {
+	connection.clientLock.Lock()
+	defer connection.clientLock.Unlock()
+	connection.sessionFault = true
+}
+
+func (connection *VSphereConnection) clearSessionFault() {
+	connection.clientLock.Lock()
+	defer connection.clientLock.Unlock()
+	connection.sessionFault = false
+}
+
 // Signer returns an sts.Signer for use with SAML token auth if connection is configured for such.
 // Returns nil if username/password auth is configured for the connection.
+// The token is reused until the last 1/TokenRenewalDivisor of its lifetime, at
+// which point it is renewed, or a new one is issued if renewal fails.
 func (connection *VSphereConnection) Signer(ctx context.Context, client *vim25.Client) (*sts.Signer, error) {
-	// TODO: Add separate fields for certificate and private-key.
-	// For now we can leave the config structs and validation as-is and
-	// decide to use LoginByToken if the username value is PEM encoded.
-	b, _ := pem.Decode([]byte(connection.Username))
-	if b == nil {
-		return nil, nil
-	}
+	connection.credentialsLock.Lock()
+	defer connection.credentialsLock.Unlock()
 
-	cert, err := tls.X509KeyPair([]byte(connection.Username), []byte(connection.Password))
-	if err != nil {
-		klog.Errorf("Failed to load X509 key pair. err: %+v", err)
+	cert, err := connection.keyPair()
+	if err != nil || cert == nil {
 		return nil, err
 	}
 
-	tokens, err := sts.NewClient(ctx, client)
+	now := time.Now()
+	signer := connection.signer
+	if signer != nil && !sameCertificate(signer.Certificate, cert) {
+		// the key pair was rotated, the token is bound to the previous one
+		signer = nil
+	}
+	if signer != nil && !tokenDue(signer, now) {
+		return signer, nil
+	}
+
+	tokens, err := connection.newSTSClient(ctx, client)
 	if err != nil {
 		klog.Errorf("Failed to create STS client. err: %+v", err)
 		return nil, err
 	}
 
 	req := sts.TokenRequest{
-		Certificate: &cert,
+		Certificate: cert,
 		Delegatable: true,
+		Renewable:   true,
+		Lifetime:    TokenLifetime,
+	}
+
+	if signer != nil && signer.Lifetime.Expires.After(now) {
+		req.Token = signer.Token
+		renewed, err := tokens.Renew(ctx, req)
+		if err == nil {
+			klog.V(3).Infof("Renewed SAML token, expires at %s", renewed.Lifetime.Expires)
+			connection.signer = renewed
+			return renewed, nil
+		}
+		klog.Warningf("Failed to renew SAML token, issuing a new one. err: %+v", err)
+		req.Token = ""
 	}
 
-	signer, err := tokens.Issue(ctx, req)
+	signer, err = tokens.Issue(ctx, req)
 	if err != nil {
 		klog.Errorf("Failed to issue SAML token. err: %+v", err)
 		return nil, err
 	}
-
+	klog.V(3).Infof("Issued SAML token, expires at %s", signer.Lifetime.Expires)
+	connection.signer = signer
 	return signer, nil
 }
 
+// keyPair returns the key pair of the solution user to log in with, or nil if
+// the connection is configured for username/password auth.
+// Must be called with credentialsLock held.
+func (connection *VSphereConnection) keyPair() (*tls.Certificate, error) {
+	var cert tls.Certificate
+	var err error
+	switch {
+	case connection.Certificate != "":
+		cert, err = tls.X509KeyPair([]byte(connection.Certificate), []byte(connection.PrivateKey))
+	case connection.CertFile != "":
+		cert, err = tls.LoadX509KeyPair(connection.CertFile, connection.KeyFile)
+	default:
+		// Deprecated: a PEM encoded username is the certificate and the
+		// password its private key. Use CertFile and KeyFile instead.
+		if b, _ := pem.Decode([]byte(connection.Username)); b == nil {
+			return nil, nil
+		}
+		cert, err = tls.X509KeyPair([]byte(connection.Username), []byte(connection.Password))
+	}
+	if err != nil {
+		klog.Errorf("Failed to load X509 key pair. err: %+v", err)
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// tokenDue returns true if the token of the signer is due for renewal
+func tokenDue(signer *sts.Signer, now time.Time) bool {
+	lifetime := signer.Lifetime.Expires.Sub(signer.Lifetime.Created)
+	return signer.Lifetime.Expires.Sub(now) < lifetime/TokenRenewalDivisor
+}
+
+func sameCertificate(a *tls.Certificate, b *tls.Certificate) bool {
+	if a == nil || b == nil || len(a.Certificate) == 0 || len(b.Certificate) == 0 {
+		return false
+	}
+	return bytes.Equal(a.Certificate[0], b.Certificate[0])
+}
+
+// TokenExpiry returns the expiry of the SAML token the current session was
+// logged in with, or the zero time if it was logged in with username and
+// password. Sessions do not outlive their token, so they must be logged in
+// again before it expires.
+func (connection *VSphereConnection) TokenExpiry() time.Time {
+	connection.credentialsLock.Lock()
+	defer connection.credentialsLock.Unlock()
+	return connection.tokenExpiry
+}
+
 // login calls SessionManager.LoginByToken if certificate and private key are configured,
 // otherwise calls SessionManager.Login with user and password.
 func (connection *VSphereConnection) login(ctx context.Context, client *vim25.Client) error {
 	m := session.NewManager(client)
-	connection.credentialsLock.Lock()
-	defer connection.credentialsLock.Unlock()
 
 	signer, err := connection.Signer(ctx, client)
 	if err != nil {
 		return err
 	}
 
+	connection.credentialsLock.Lock()
+	defer connection.credentialsLock.Unlock()
+
 	if signer == nil {
 		klog.V(3).Infof("SessionManager.Login with username %q", connection.Username)
-		return m.Login(ctx, neturl.UserPassword(connection.Username, connection.Password))
+		err = m.Login(ctx, neturl.UserPassword(connection.Username, connection.Password))
+		if err == nil {
+			connection.tokenExpiry = time.Time{}
+		}
+		return err
 	}
 
-	klog.V(3).Infof("SessionManager.LoginByToken with certificate %q", connection.Username)
+	klog.V(3).Info("SessionManager.LoginByToken with solution user certificate")
 
 	header := soap.Header{Security: signer}
 
-	return m.LoginByToken(client.WithHeader(ctx, header))
+	err = m.LoginByToken(client.WithHeader(ctx, header))
+	if err == nil {
+		connection.tokenExpiry = signer.Lifetime.Expires
+	}
+	return err
 }
 
 // Logout calls SessionManager.Logout for the given connection.
+// It is a no-op if the connection has not been established.
 func (connection *VSphereConnection) Logout(ctx context.Context) {
-	m := session.NewManager(connection.Client)
+	client := connection.CurrentClient()
+	if client == nil {
+		return
+	}
+	m := session.NewManager(client)
 	if err := m.Logout(ctx); err != nil {
 		klog.Errorf("Logout failed: %s", err)
 	}
@@ -169,14 +399,23 @@ func (connection *VSphereConnection) NewClient(ctx context.Context) (*vim25.Clie
 		}
 	}
 
+	connection.credentialsLock.Lock()
+	thumbprint := connection.Thumbprint
+	connection.credentialsLock.Unlock()
 	tpHost := connection.Hostname + ":" + connection.Port
-	sc.SetThumbprint(tpHost, connection.Thumbprint)
+	sc.SetThumbprint(tpHost, thumbprint)
+	if err := connection.configureTransport(sc, thumbprint); err != nil {
+		return nil, err
+	}
 
 	client, err := vim25.NewClient(ctx, sc)
 	if err != nil {
 		klog.Errorf("Failed to create new client. err: %+v", err)
 		return nil, err
 	}
+	// Every attempt of the retrying round tripper below waits for the rate
+	// limit, and is recorded in the API metrics once admitted.
+	client.RoundTripper = connection.RateLimit(connection.instrument(client.RoundTripper))
 	err = connection.login(ctx, client)
 	if err != nil {
 		return nil, err
@@ -189,11 +428,33 @@ func (connection *VSphereConnection) NewClient(ctx context.Context) (*vim25.Clie
 	return client, nil
 }
 
-// UpdateCredentials updates username and password.
+// UpdateCredentials updates username and password. It discards a key pair
+// set by UpdateCertificate.
 // Note: Updated username and password will be used when there is no session active
 func (connection *VSphereConnection) UpdateCredentials(username string, password string) {
 	connection.credentialsLock.Lock()
 	defer connection.credentialsLock.Unlock()
 	connection.Username = username
 	connection.Password = password
+	connection.Certificate = ""
+	connection.PrivateKey = ""
+	connection.signer = nil
+}
+
+// UpdateThumbprint updates the thumbprint the vCenter certificate is verified with.
+// Note: Updated thumbprint will be used when there is no session active
+func (connection *VSphereConnection) UpdateThumbprint(thumbprint string) {
+	connection.credentialsLock.Lock()
+	defer connection.credentialsLock.Unlock()
+	connection.Thumbprint = thumbprint
+}
+
+// UpdateCertificate updates the PEM encoded key pair of the solution user.
+// Note: Updated key pair will be used when there is no session active
+func (connection *VSphereConnection) UpdateCertificate(certificate string, privateKey string) {
+	connection.credentialsLock.Lock()
+	defer connection.credentialsLock.Unlock()
+	connection.Certificate = certificate
+	connection.PrivateKey = privateKey
+	connection.signer = nil
 }
@@ -30,6 +30,11 @@ import (
 	"strings"
 	"testing"
 
+	lookup "github.com/vmware/govmomi/lookup/simulator"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/simulator/vpx"
+	sts "github.com/vmware/govmomi/sts/simulator"
+
 	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
 	"k8s.io/cloud-provider-vsphere/pkg/common/vclib/fixtures"
 )
@@ -185,6 +190,59 @@ func TestInvalidCaCert(t *testing.T) {
 	}
 }
 
+func TestLoginBySolutionUserCertificate(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatal(err)
+	}
+	model.Service.TLS = new(tls.Config)
+	s := model.Service.NewServer()
+	defer s.Close()
+	path, handler := sts.New(s.URL, vpx.Setting)
+	model.Service.ServeMux.Handle(path, handler)
+	model.Service.RegisterSDK(lookup.New())
+
+	connection := &vclib.VSphereConnection{
+		Hostname: s.URL.Hostname(),
+		Port:     s.URL.Port(),
+		Insecure: true,
+		CertFile: fixtures.ServerCertPath,
+		KeyFile:  fixtures.ServerKeyPath,
+	}
+
+	ctx := context.Background()
+	if err := connection.Connect(ctx); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+	defer connection.Logout(ctx)
+	if connection.TokenExpiry().IsZero() {
+		t.Error("session should be logged in by token")
+	}
+
+	signer, err := connection.Signer(ctx, connection.Client)
+	if err != nil || signer == nil {
+		t.Fatalf("Signer signer=%v err=%v", signer, err)
+	}
+	if again, _ := connection.Signer(ctx, connection.Client); again != signer {
+		t.Error("token should be reused until it is due for renewal")
+	}
+
+	cert, _ := ioutil.ReadFile(fixtures.ServerCertPath)
+	key, _ := ioutil.ReadFile(fixtures.ServerKeyPath)
+	connection.UpdateCertificate(string(cert), string(key))
+	if again, _ := connection.Signer(ctx, connection.Client); again == signer {
+		t.Error("token should be issued again for an updated certificate")
+	}
+
+	connection.CertFile = ""
+	connection.KeyFile = ""
+	connection.UpdateCredentials("user", "pass")
+	if again, _ := connection.Signer(ctx, connection.Client); again != nil {
+		t.Error("no token should be issued for username/password auth")
+	}
+}
+
 func verifyWrappedX509UnkownAuthorityErr(t *testing.T, err error) {
 	urlErr, ok := err.(*url.Error)
 	if !ok {
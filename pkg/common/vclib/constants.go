@@ -16,6 +16,10 @@ limitations under the License.
 
 package vclib
 
+import (
+	"time"
+)
+
 // FindFCD is the type that represents the types of searches used to
 // discover FCDs.
 type FindFCD int
@@ -28,6 +32,20 @@ const (
 	FindFCDByName // 1
 )
 
+// SAML token constants
+const (
+	// TokenLifetime is the lifetime requested for SAML tokens issued for
+	// solution users.
+	TokenLifetime = time.Hour
+	// TokenRenewalDivisor determines when a SAML token is renewed: once the
+	// remaining lifetime is below 1/TokenRenewalDivisor of its total lifetime.
+	TokenRenewalDivisor = 4
+)
+
+// LoginTimeout bounds a login to vCenter, which is shared by all the callers
+// connecting concurrently and thus not bound to any of their contexts.
+const LoginTimeout = 2 * time.Minute
+
 // Volume Constnts
 const (
 	// ThinDiskType is a good constant, yes it is!
@@ -40,7 +40,7 @@ type Datacenter struct {
 // GetDatacenter returns the DataCenter Object for the given datacenterPath
 // If datacenter is located in a folder, include full path to datacenter else just provide the datacenter name
 func GetDatacenter(ctx context.Context, connection *VSphereConnection, datacenterPath string) (*Datacenter, error) {
-	finder := find.NewFinder(connection.Client, false)
+	finder := find.NewFinder(connection.CurrentClient(), false)
 	datacenter, err := finder.Datacenter(ctx, datacenterPath)
 	if err != nil {
 		klog.Errorf("Failed to find the datacenter: %s. err: %+v", datacenterPath, err)
@@ -53,7 +53,7 @@ func GetDatacenter(ctx context.Context, connection *VSphereConnection, datacente
 // GetAllDatacenter returns all the DataCenter Objects
 func GetAllDatacenter(ctx context.Context, connection *VSphereConnection) ([]*Datacenter, error) {
 	var dc []*Datacenter
-	finder := find.NewFinder(connection.Client, false)
+	finder := find.NewFinder(connection.CurrentClient(), false)
 	datacenters, err := finder.DatacenterList(ctx, "*")
 	if err != nil {
 		klog.Errorf("Failed to find the datacenter. err: %+v", err)
@@ -68,7 +68,7 @@ func GetAllDatacenter(ctx context.Context, connection *VSphereConnection) ([]*Da
 
 // GetNumberOfDatacenters returns the number of DataCenters in this vCenter
 func GetNumberOfDatacenters(ctx context.Context, connection *VSphereConnection) (int, error) {
-	finder := find.NewFinder(connection.Client, false)
+	finder := find.NewFinder(connection.CurrentClient(), false)
 	datacenters, err := finder.DatacenterList(ctx, "*")
 	if err != nil {
 		klog.Errorf("Failed to find the datacenter. err: %+v", err)
@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vclib
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
+)
+
+// APISOAP and APIREST are the values of the api label of the vCenter API
+// metrics.
+const (
+	APISOAP = "soap"
+	APIREST = "rest"
+)
+
+// faultNotAuthenticated is the fault type of requests of an invalid session.
+const faultNotAuthenticated = "NotAuthenticated"
+
+var (
+	apiRequestDurationMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "vcenter_api_request_duration_seconds",
+			Help:           "Latency of vCenter API requests by API and method",
+			Buckets:        metrics.ExponentialBuckets(0.005, 2, 14),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter", "api", "method"},
+	)
+
+	apiRequestErrorsMetric = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "vcenter_api_request_errors_total",
+			Help:           "Number of failed vCenter API requests by API, method and fault type",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter", "api", "method", "fault"},
+	)
+
+	apiRequestsInFlightMetric = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "vcenter_api_requests_in_flight",
+			Help:           "Number of vCenter API requests in flight by API and method",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter", "api", "method"},
+	)
+
+	registerAPIMetricsOnce sync.Once
+)
+
+// registerAPIMetrics registers the vCenter API metrics with the global
+// registry used by the cloud controller manager.
+func registerAPIMetrics() {
+	registerAPIMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(apiRequestDurationMetric)
+		legacyregistry.MustRegister(apiRequestErrorsMetric)
+		legacyregistry.MustRegister(apiRequestsInFlightMetric)
+	})
+}
+
+// observeAPIRequest records a vCenter API request which started at start in
+// the API metrics, as failed if fault is not empty.
+func observeAPIRequest(vcenter string, api string, method string, start time.Time, fault string) {
+	apiRequestDurationMetric.WithLabelValues(vcenter, api, method).Observe(time.Since(start).Seconds())
+	if fault != "" {
+		apiRequestErrorsMetric.WithLabelValues(vcenter, api, method, fault).Inc()
+	}
+}
+
+// soapMethod returns the name of the SOAP method of a request body, e.g.
+// RetrieveProperties for a *methods.RetrievePropertiesBody.
+func soapMethod(req soap.HasFault) string {
+	t := reflect.TypeOf(req)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Body")
+}
+
+// soapFaultType classifies the error of a SOAP request: the type of the vim
+// fault, e.g. NotAuthenticated, SoapFault for other SOAP faults, Timeout or
+// Canceled for requests which did not complete in time and Transport for any
+// other error.
+func soapFaultType(err error) string {
+	if soap.IsSoapFault(err) {
+		if fault := soap.ToSoapFault(err).VimFault(); fault != nil {
+			return reflect.Indirect(reflect.ValueOf(fault)).Type().Name()
+		}
+		return "SoapFault"
+	}
+	return transportFaultType(err)
+}
+
+// transportFaultType classifies an error which is not reported by vCenter.
+func transportFaultType(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Timeout"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "Timeout"
+	}
+	return "Transport"
+}
+
+// instrumentedRoundTripper is a SOAP round tripper which records every
+// request in the vCenter API metrics and in a span. Requests rejected as not
+// authenticated mark the session of the connection as faulted.
+type instrumentedRoundTripper struct {
+	roundTripper soap.RoundTripper
+	vcenter      string
+	connection   *VSphereConnection
+}
+
+// RoundTrip implements soap.RoundTripper.
+func (rt *instrumentedRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	method := soapMethod(req)
+	inFlight := apiRequestsInFlightMetric.WithLabelValues(rt.vcenter, APISOAP, method)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	ctx, span := tracing.Start(ctx, APISOAP+" "+method,
+		tracing.String("vcenter", rt.vcenter), tracing.String("method", method))
+	start := time.Now()
+	err := rt.roundTripper.RoundTrip(ctx, req, res)
+	fault := ""
+	if err != nil {
+		fault = soapFaultType(err)
+		span.SetAttributes(tracing.String("fault", fault))
+		if fault == faultNotAuthenticated && rt.connection != nil {
+			rt.connection.markSessionFault()
+		}
+	}
+	span.End(err)
+	observeAPIRequest(rt.vcenter, APISOAP, method, start, fault)
+	return err
+}
+
+// restMethod returns the HTTP method and the path of a vAPI REST request,
+// with the identifiers of objects elided, e.g.
+// GET /rest/com/vmware/cis/tagging/tag/id:{id}.
+func restMethod(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "id:") {
+			segments[i] = "id:{id}"
+		}
+	}
+	method := req.Method + " " + strings.Join(segments, "/")
+	if action := req.URL.Query().Get("~action"); action != "" {
+		method += "?~action=" + action
+	}
+	return method
+}
+
+// instrumentedTransport is a HTTP round tripper, used by the REST client,
+// which records every request in the vCenter API metrics and in a span.
+type instrumentedTransport struct {
+	transport http.RoundTripper
+	vcenter   string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := restMethod(req)
+	inFlight := apiRequestsInFlightMetric.WithLabelValues(t.vcenter, APIREST, method)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	_, span := tracing.Start(req.Context(), APIREST+" "+method,
+		tracing.String("vcenter", t.vcenter), tracing.String("method", method))
+	start := time.Now()
+	res, err := t.transport.RoundTrip(req)
+	fault := ""
+	if err != nil {
+		fault = transportFaultType(err)
+		span.End(err)
+	} else {
+		span.SetAttributes(tracing.Int("status", res.StatusCode))
+		if res.StatusCode >= http.StatusBadRequest {
+			fault = strings.ReplaceAll(http.StatusText(res.StatusCode), " ", "")
+			span.End(errors.New(res.Status))
+		} else {
+			span.End(nil)
+		}
+	}
+	observeAPIRequest(t.vcenter, APIREST, method, start, fault)
+	return res, err
+}
+
+// instrument wraps a SOAP round tripper of the connection, so that every
+// request is recorded in the vCenter API metrics.
+func (connection *VSphereConnection) instrument(roundTripper soap.RoundTripper) soap.RoundTripper {
+	registerAPIMetrics()
+	return &instrumentedRoundTripper{roundTripper: roundTripper, vcenter: connection.Hostname, connection: connection}
+}
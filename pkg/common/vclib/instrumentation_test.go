@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vclib
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/simulator/vpx"
+	vapi "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/component-base/metrics/testutil"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
+)
+
+func TestAPIMetrics(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatal(err)
+	}
+	model.Service.TLS = new(tls.Config)
+	s := model.Service.NewServer()
+	defer s.Close()
+	path, handler := vapi.New(s.URL, vpx.Setting)
+	model.Service.ServeMux.Handle(path, handler)
+
+	connection := &VSphereConnection{
+		Hostname: s.URL.Hostname(),
+		Port:     s.URL.Port(),
+		Insecure: true,
+		Username: "user",
+		Password: "pass",
+	}
+	ctx := context.Background()
+	if err := connection.Connect(ctx); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+	defer connection.Logout(ctx)
+	vcenter := connection.Hostname
+
+	if _, err := methods.GetCurrentTime(ctx, connection.Client); err != nil {
+		t.Fatalf("GetCurrentTime err=%v", err)
+	}
+	latency, _ := testutil.GetHistogramMetricValue(apiRequestDurationMetric.WithLabelValues(vcenter, APISOAP, "CurrentTime"))
+	if latency <= 0 {
+		t.Error("latency of the CurrentTime request should be recorded")
+	}
+
+	req := types.Destroy_Task{This: types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-missing"}}
+	if _, err := methods.Destroy_Task(ctx, connection.Client, &req); err == nil {
+		t.Fatal("Destroy_Task should fail for a missing VM")
+	}
+	faults, _ := testutil.GetCounterMetricValue(apiRequestErrorsMetric.WithLabelValues(vcenter, APISOAP, "Destroy_Task", "ManagedObjectNotFound"))
+	if faults != 1 {
+		t.Errorf("fault of the Destroy_Task request should be counted by type, got %v", faults)
+	}
+
+	c, err := connection.NewRESTClient(connection.Client)
+	if err != nil {
+		t.Fatalf("NewRESTClient err=%v", err)
+	}
+	if err := c.Login(ctx, url.UserPassword("user", "pass")); err != nil {
+		t.Fatalf("Login err=%v", err)
+	}
+	resource := c.Resource("/com/vmware/cis/tagging/tag/id:urn:vmomi:InventoryServiceTag:missing:GLOBAL")
+	if err := c.Do(ctx, resource.Request(http.MethodGet), nil); err == nil {
+		t.Fatal("getting a missing tag should fail")
+	}
+	method := "GET /rest/com/vmware/cis/tagging/tag/id:{id}"
+	faults, _ = testutil.GetCounterMetricValue(apiRequestErrorsMetric.WithLabelValues(vcenter, APIREST, method, "NotFound"))
+	if faults != 1 {
+		t.Errorf("failed REST request should be counted by status, got %v", faults)
+	}
+
+	for _, labels := range [][]string{{vcenter, APISOAP, "CurrentTime"}, {vcenter, APIREST, method}} {
+		inFlight, _ := testutil.GetGaugeMetricValue(apiRequestsInFlightMetric.WithLabelValues(labels...))
+		if inFlight != 0 {
+			t.Errorf("no %v requests should be in flight, got %v", labels, inFlight)
+		}
+	}
+}
+
+func TestAPISpans(t *testing.T) {
+	type span struct {
+		TraceID      string `json:"traceId"`
+		ParentSpanID string `json:"parentSpanId"`
+		Name         string `json:"name"`
+	}
+	var lock sync.Mutex
+	var spans []span
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []span `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode export request: %v", err)
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}))
+	defer collector.Close()
+
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatal(err)
+	}
+	model.Service.TLS = new(tls.Config)
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	connection := &VSphereConnection{
+		Hostname: s.URL.Hostname(),
+		Port:     s.URL.Port(),
+		Insecure: true,
+		Username: "user",
+		Password: "pass",
+	}
+	if err := connection.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+	defer connection.Logout(context.Background())
+
+	if err := tracing.Configure(tracing.Config{Endpoint: collector.URL, SamplingRatio: 1}); err != nil {
+		t.Fatalf("Configure err=%v", err)
+	}
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	if _, err := methods.GetCurrentTime(ctx, connection.Client); err != nil {
+		t.Fatalf("GetCurrentTime err=%v", err)
+	}
+	parent.End(nil)
+	tracing.Shutdown(context.Background())
+
+	lock.Lock()
+	defer lock.Unlock()
+	for _, span := range spans {
+		if span.Name != APISOAP+" CurrentTime" {
+			continue
+		}
+		if span.TraceID != parent.TraceID().String() || span.ParentSpanID == "" {
+			t.Errorf("CurrentTime span should be a child of the span of the request context, got %+v", span)
+		}
+		return
+	}
+	t.Errorf("CurrentTime request should be recorded in a span, got %+v", spans)
+}
+
+func TestRESTMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		want   string
+	}{
+		{http.MethodPost, "https://vc/rest/com/vmware/cis/session", "POST /rest/com/vmware/cis/session"},
+		{http.MethodGet, "https://vc/rest/com/vmware/cis/tagging/category/id:urn:vmomi:InventoryServiceCategory:1:GLOBAL",
+			"GET /rest/com/vmware/cis/tagging/category/id:{id}"},
+		{http.MethodPost, "https://vc/rest/com/vmware/cis/tagging/tag-association/id:urn:vmomi:InventoryServiceTag:1:GLOBAL?~action=list-attached-objects",
+			"POST /rest/com/vmware/cis/tagging/tag-association/id:{id}?~action=list-attached-objects"},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, test.url, nil)
+		if got := restMethod(req); got != test.want {
+			t.Errorf("restMethod(%s %s)=%q, want %q", test.method, test.url, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vclib
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/soap"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/tracing"
+)
+
+var (
+	rateLimitedRequestsMetric = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "vcenter_rate_limited_requests_total",
+			Help:           "Number of requests to a vCenter that waited for the client-side rate limit",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter"},
+	)
+
+	rateLimitWaitMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "vcenter_rate_limit_wait_duration_seconds",
+			Help:           "Time requests to a vCenter waited for the client-side rate limit",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"vcenter"},
+	)
+
+	registerRateLimitMetricsOnce sync.Once
+)
+
+// registerRateLimitMetrics registers the rate limit metrics with the global
+// registry used by the cloud controller manager.
+func registerRateLimitMetrics() {
+	registerRateLimitMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(rateLimitedRequestsMetric)
+		legacyregistry.MustRegister(rateLimitWaitMetric)
+	})
+}
+
+// limiter returns the token bucket shared by all the clients of the
+// connection, or nil if requests to vCenter are not rate limited.
+func (connection *VSphereConnection) limiter() flowcontrol.RateLimiter {
+	connection.rateLimiterOnce.Do(func() {
+		if connection.RateLimitQPS <= 0 {
+			return
+		}
+		burst := connection.RateLimitBurst
+		if burst < 1 {
+			burst = 1
+		}
+		connection.rateLimiter = flowcontrol.NewTokenBucketRateLimiter(connection.RateLimitQPS, burst)
+		registerRateLimitMetrics()
+	})
+	return connection.rateLimiter
+}
+
+// waitForRateLimit blocks until the rate limit of the connection admits
+// another request, or ctx is done.
+func (connection *VSphereConnection) waitForRateLimit(ctx context.Context) error {
+	limiter := connection.limiter()
+	if limiter == nil || limiter.TryAccept() {
+		return nil
+	}
+
+	rateLimitedRequestsMetric.WithLabelValues(connection.Hostname).Inc()
+	_, span := tracing.Start(ctx, "vcenter.RateLimitWait", tracing.String("vcenter", connection.Hostname))
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	rateLimitWaitMetric.WithLabelValues(connection.Hostname).Observe(time.Since(start).Seconds())
+	span.End(err)
+	return err
+}
+
+// rateLimitedRoundTripper is a SOAP round tripper which waits for the rate
+// limit of the connection before every request.
+type rateLimitedRoundTripper struct {
+	roundTripper soap.RoundTripper
+	connection   *VSphereConnection
+}
+
+// RoundTrip implements soap.RoundTripper.
+func (rt *rateLimitedRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if err := rt.connection.waitForRateLimit(ctx); err != nil {
+		return err
+	}
+	return rt.roundTripper.RoundTrip(ctx, req, res)
+}
+
+// rateLimitedTransport is a HTTP round tripper, used by the REST client, which
+// waits for the rate limit of the connection before every request.
+type rateLimitedTransport struct {
+	transport  http.RoundTripper
+	connection *VSphereConnection
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.connection.waitForRateLimit(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// RateLimit wraps a SOAP round tripper of the connection, so that it waits for
+// the rate limit before every request. It is returned unchanged if requests
+// are not rate limited.
+func (connection *VSphereConnection) RateLimit(roundTripper soap.RoundTripper) soap.RoundTripper {
+	if connection.limiter() == nil {
+		return roundTripper
+	}
+	return &rateLimitedRoundTripper{roundTripper: roundTripper, connection: connection}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vclib_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/simulator/vpx"
+	vapi "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vim25/methods"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+)
+
+func TestRateLimit(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatal(err)
+	}
+	model.Service.TLS = new(tls.Config)
+	s := model.Service.NewServer()
+	defer s.Close()
+	path, handler := vapi.New(s.URL, vpx.Setting)
+	model.Service.ServeMux.Handle(path, handler)
+
+	connection := &vclib.VSphereConnection{
+		Hostname:       s.URL.Hostname(),
+		Port:           s.URL.Port(),
+		Insecure:       true,
+		Username:       "user",
+		Password:       "pass",
+		RateLimitQPS:   20,
+		RateLimitBurst: 1,
+	}
+
+	ctx := context.Background()
+	if err := connection.Connect(ctx); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+	defer connection.Logout(ctx)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := methods.GetCurrentTime(ctx, connection.Client); err != nil {
+			t.Fatalf("GetCurrentTime err=%v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("10 SOAP requests at 20 QPS should take at least 400ms, took %v", elapsed)
+	}
+
+	c, err := connection.NewRESTClient(connection.Client)
+	if err != nil {
+		t.Fatalf("NewRESTClient err=%v", err)
+	}
+	if err := c.Login(ctx, url.UserPassword("user", "pass")); err != nil {
+		t.Fatalf("Login err=%v", err)
+	}
+	start = time.Now()
+	for i := 0; i < 10; i++ {
+		if _, err := c.Session(ctx); err != nil {
+			t.Fatalf("Session err=%v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("10 REST requests at 20 QPS should take at least 400ms, took %v", elapsed)
+	}
+
+	throttled := 0.0
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather err=%v", err)
+	}
+	for _, family := range families {
+		if family.GetName() == "cloudprovider_vsphere_vcenter_rate_limited_requests_total" {
+			for _, m := range family.GetMetric() {
+				throttled += m.GetCounter().GetValue()
+			}
+		}
+	}
+	if throttled < 18 {
+		t.Errorf("throttled requests should be counted, got %v", throttled)
+	}
+
+	// a request that cannot be admitted before its deadline fails right away
+	tctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	methods.GetCurrentTime(ctx, connection.Client)
+	if _, err := methods.GetCurrentTime(tctx, connection.Client); err == nil {
+		t.Error("request should fail when the rate limit cannot admit it before the deadline")
+	}
+}
+
+func TestWithoutRateLimit(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatal(err)
+	}
+	model.Service.TLS = new(tls.Config)
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	connection := &vclib.VSphereConnection{
+		Hostname: s.URL.Hostname(),
+		Port:     s.URL.Port(),
+		Insecure: true,
+		Username: "user",
+		Password: "pass",
+	}
+
+	ctx := context.Background()
+	if err := connection.Connect(ctx); err != nil {
+		t.Fatalf("Connect err=%v", err)
+	}
+	defer connection.Logout(ctx)
+
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		if _, err := methods.GetCurrentTime(ctx, connection.Client); err != nil {
+			t.Fatalf("GetCurrentTime err=%v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("requests should not be rate limited by default, took %v", elapsed)
+	}
+}
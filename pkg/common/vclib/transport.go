@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vclib
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/sts"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	klog "k8s.io/klog/v2"
+)
+
+// customTransport reports whether transport settings are configured for the
+// connection. Otherwise, the transport of govmomi is used unchanged.
+func (connection *VSphereConnection) customTransport() bool {
+	return connection.ProxyURL != "" || connection.ConnectTimeout != 0 ||
+		connection.RequestTimeout != 0 || connection.TLSMinVersion != 0
+}
+
+// configureTransport applies the proxy, timeouts and TLS settings of the
+// connection to the given SOAP client, which is the client of the connection
+// or a service client derived from it. thumbprint is the trusted thumbprint of
+// the vCenter certificate.
+func (connection *VSphereConnection) configureTransport(sc *soap.Client, thumbprint string) error {
+	if !connection.customTransport() {
+		return nil
+	}
+	transport, ok := sc.Transport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	if connection.ProxyURL != "" {
+		proxyURL, err := neturl.Parse(connection.ProxyURL)
+		if err != nil {
+			return err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	dialer := &net.Dialer{
+		Timeout:   connection.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport.DialContext = dialer.DialContext
+	sc.Timeout = connection.RequestTimeout
+
+	// The configuration may be shared with the client the service client was
+	// derived from.
+	config := transport.TLSClientConfig.Clone()
+	if connection.TLSMinVersion != 0 {
+		config.MinVersion = connection.TLSMinVersion
+	}
+	if !config.InsecureSkipVerify {
+		// govmomi verifies thumbprints when dialing, which is bypassed by
+		// proxies and the dialer above; verify during the handshake instead.
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = verifyConnection(config.RootCAs, thumbprint)
+	}
+	transport.TLSClientConfig = config
+	transport.DialTLS = nil
+	return nil
+}
+
+// verifyConnection returns a tls.Config.VerifyConnection func which verifies
+// the certificate of the server with the given root CAs, or the system's CA
+// certificates if nil. If the certificate is not trusted, it is accepted if it
+// matches the given thumbprint.
+func verifyConnection(roots *x509.CertPool, thumbprint string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("no server certificate")
+		}
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			DNSName:       state.ServerName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(opts)
+		if err == nil {
+			return nil
+		}
+
+		var unknownAuthority x509.UnknownAuthorityError
+		var hostname x509.HostnameError
+		if thumbprint == "" || (!errors.As(err, &unknownAuthority) && !errors.As(err, &hostname)) {
+			return err
+		}
+		if peer := soap.ThumbprintSHA1(state.PeerCertificates[0]); !strings.EqualFold(peer, thumbprint) {
+			return fmt.Errorf("host %q thumbprint does not match %q", state.ServerName, thumbprint)
+		}
+		return nil
+	}
+}
+
+// NewRESTClient returns a vAPI REST client for the given client of the
+// connection, using the transport settings and the rate limit of the
+// connection. Its requests are recorded in the vCenter API metrics.
+func (connection *VSphereConnection) NewRESTClient(client *vim25.Client) (*rest.Client, error) {
+	c := rest.NewClient(client)
+	connection.credentialsLock.Lock()
+	thumbprint := connection.Thumbprint
+	connection.credentialsLock.Unlock()
+	if err := connection.configureTransport(c.Client, thumbprint); err != nil {
+		return nil, err
+	}
+	registerAPIMetrics()
+	c.Client.Client.Transport = &instrumentedTransport{transport: c.Client.Client.Transport, vcenter: connection.Hostname}
+	if connection.limiter() != nil {
+		c.Client.Client.Transport = &rateLimitedTransport{transport: c.Client.Client.Transport, connection: connection}
+	}
+	return c, nil
+}
+
+// newSTSClient returns a client of the STS of vCenter, using the transport
+// settings of the connection. With custom transport settings, the STS is
+// expected at its default path on vCenter, as the lookup service used to find
+// an external Platform Services Controller cannot be reached through them.
+// Must be called with credentialsLock held.
+func (connection *VSphereConnection) newSTSClient(ctx context.Context, client *vim25.Client) (*sts.Client, error) {
+	if !connection.customTransport() {
+		return sts.NewClient(ctx, client)
+	}
+	tokens := &sts.Client{Client: client.Client.NewServiceClient(sts.Path, sts.Namespace)}
+	if err := connection.configureTransport(tokens.Client, connection.Thumbprint); err != nil {
+		return nil, err
+	}
+	klog.V(4).Infof("Using STS at %s", tokens.URL())
+	return tokens, nil
+}
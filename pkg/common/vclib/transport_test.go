@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vclib_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib"
+	"k8s.io/cloud-provider-vsphere/pkg/common/vclib/fixtures"
+)
+
+// createTestProxy starts a HTTP proxy which tunnels CONNECT requests and
+// returns it together with the number of tunnels it established.
+func createTestProxy(t *testing.T) (*httptest.Server, *int32) {
+	var tunnels int32
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("proxy response writer cannot be hijacked")
+			upstream.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		client, _, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("Could not hijack proxy connection: %v", err)
+			upstream.Close()
+			return
+		}
+		atomic.AddInt32(&tunnels, 1)
+
+		go func() {
+			defer upstream.Close()
+			defer client.Close()
+			io.Copy(upstream, client)
+		}()
+		go io.Copy(client, upstream)
+	}))
+
+	return proxy, &tunnels
+}
+
+func TestWithProxyAndValidThumbprint(t *testing.T) {
+	handler, verifyConnectionWasMade := getRequestVerifier(t)
+
+	server, thumbprint := createTestServer(t, fixtures.CaCertPath, fixtures.ServerCertPath, fixtures.ServerKeyPath, handler)
+	server.StartTLS()
+	defer server.Close()
+	u := mustParseUrl(t, server.URL)
+
+	proxy, tunnels := createTestProxy(t)
+	defer proxy.Close()
+
+	connection := &vclib.VSphereConnection{
+		Hostname:   u.Hostname(),
+		Port:       u.Port(),
+		Thumbprint: thumbprint,
+		ProxyURL:   proxy.URL,
+	}
+
+	// Ignoring error here, because we only care about the TLS connection
+	connection.NewClient(context.Background())
+
+	verifyConnectionWasMade()
+	if atomic.LoadInt32(tunnels) == 0 {
+		t.Fatal("Expected the connection to be tunneled through the proxy")
+	}
+}
+
+func TestWithProxyAndWrongThumbprint(t *testing.T) {
+	handler, _ := getRequestVerifier(t)
+
+	server, _ := createTestServer(t, fixtures.CaCertPath, fixtures.ServerCertPath, fixtures.ServerKeyPath, handler)
+	server.StartTLS()
+	defer server.Close()
+	u := mustParseUrl(t, server.URL)
+
+	proxy, _ := createTestProxy(t)
+	defer proxy.Close()
+
+	connection := &vclib.VSphereConnection{
+		Hostname:   u.Hostname(),
+		Port:       u.Port(),
+		Thumbprint: "obviously wrong",
+		ProxyURL:   proxy.URL,
+	}
+
+	_, err := connection.NewClient(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "thumbprint does not match") {
+		t.Fatalf("Expected wrong thumbprint error, got '%v'", err)
+	}
+}
+
+func TestWithInvalidProxyURL(t *testing.T) {
+	connection := &vclib.VSphereConnection{
+		Hostname: "127.0.0.1",
+		Port:     "443",
+		ProxyURL: "http://[::1",
+	}
+
+	if _, err := connection.NewClient(context.Background()); err == nil {
+		t.Fatal("Expected an error for an invalid proxy URL")
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}
+
+	server, thumbprint := createTestServer(t, fixtures.CaCertPath, fixtures.ServerCertPath, fixtures.ServerKeyPath, handler)
+	server.StartTLS()
+	defer server.Close()
+	defer close(release)
+	u := mustParseUrl(t, server.URL)
+
+	connection := &vclib.VSphereConnection{
+		Hostname:       u.Hostname(),
+		Port:           u.Port(),
+		Thumbprint:     thumbprint,
+		RequestTimeout: 200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err := connection.NewClient(context.Background())
+	if err == nil {
+		t.Fatal("Expected the request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Expected the request to time out after %v, took %v", connection.RequestTimeout, elapsed)
+	}
+}
+
+func TestWithTLSMinVersion(t *testing.T) {
+	handler, _ := getRequestVerifier(t)
+
+	server, thumbprint := createTestServer(t, fixtures.CaCertPath, fixtures.ServerCertPath, fixtures.ServerKeyPath, handler)
+	server.TLS.MaxVersion = tls.VersionTLS12
+	server.StartTLS()
+	defer server.Close()
+	u := mustParseUrl(t, server.URL)
+
+	connection := &vclib.VSphereConnection{
+		Hostname:      u.Hostname(),
+		Port:          u.Port(),
+		Thumbprint:    thumbprint,
+		TLSMinVersion: tls.VersionTLS13,
+	}
+
+	_, err := connection.NewClient(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "protocol version") {
+		t.Fatalf("Expected a protocol version error, got '%v'", err)
+	}
+}
@@ -46,6 +46,20 @@ func (cfg *Config) FromEnv() error {
 		}
 		cfg.InsecureFlag = InsecureFlag
 	}
+	if v := os.Getenv("NSXT_REMOTE_AUTH"); v != "" {
+		remoteAuth, err := strconv.ParseBool(v)
+		if err != nil {
+			klog.Errorf("Failed to parse NSXT_REMOTE_AUTH: %s", err)
+			return fmt.Errorf("Failed to parse NSXT_REMOTE_AUTH: %s", err)
+		}
+		cfg.RemoteAuth = remoteAuth
+	}
+	if v := os.Getenv("NSXT_VMC_ACCESS_TOKEN"); v != "" {
+		cfg.VMCAccessToken = v
+	}
+	if v := os.Getenv("NSXT_VMC_AUTH_HOST"); v != "" {
+		cfg.VMCAuthHost = v
+	}
 	if v := os.Getenv("NSXT_CLIENT_AUTH_CERT_FILE"); v != "" {
 		cfg.ClientAuthCertFile = v
 	}
@@ -90,11 +104,6 @@ func ReadNsxtConfig(configData []byte) (*Config, error) {
 		klog.Info("ReadNsxtConfig YAML succeeded")
 	}
 
-	// Env Vars should override config file entries if present
-	if err := cfg.FromEnv(); err != nil {
-		return nil, err
-	}
-
 	klog.Info("NSXT Config initialized")
 	return cfg, nil
 }
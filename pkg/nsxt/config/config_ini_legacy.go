@@ -84,6 +84,13 @@ func (cfg *NsxtINI) validateConfig() error {
 
 // CompleteAndValidate sets default values, overrides by env and validates the resulting config
 func (nci *NsxtConfigINI) CompleteAndValidate() error {
+	// Env Vars should override config file entries if present
+	cfg := Config(nci.NSXT)
+	if err := cfg.FromEnv(); err != nil {
+		return err
+	}
+	nci.NSXT = NsxtINI(cfg)
+
 	return nci.NSXT.validateConfig()
 }
 
@@ -35,6 +35,9 @@ func TestFromEnv(t *testing.T) {
 	os.Setenv("NSXT_CA_FILE", "ca-cert")
 	os.Setenv("NSXT_SECRET_NAME", "secret-name")
 	os.Setenv("NSXT_SECRET_NAMESPACE", "secret-ns")
+	os.Setenv("NSXT_REMOTE_AUTH", "true")
+	os.Setenv("NSXT_VMC_ACCESS_TOKEN", "vmc-token")
+	os.Setenv("NSXT_VMC_AUTH_HOST", "vmc-host")
 
 	err := cfg.FromEnv()
 	if err != nil {
@@ -49,10 +52,49 @@ func TestFromEnv(t *testing.T) {
 	assert.Equal(t, "ca-cert", cfg.CAFile)
 	assert.Equal(t, "secret-name", cfg.SecretName)
 	assert.Equal(t, "secret-ns", cfg.SecretNamespace)
+	assert.Equal(t, true, cfg.RemoteAuth)
+	assert.Equal(t, "vmc-token", cfg.VMCAccessToken)
+	assert.Equal(t, "vmc-host", cfg.VMCAuthHost)
 
 	clearNsxtEnv()
 }
 
+func TestReadNsxtConfigEnv(t *testing.T) {
+	defer clearNsxtEnv()
+	contents := `
+nsxt:
+  user: admin
+  password: secret
+`
+	// the host is required
+	_, err := ReadNsxtConfig([]byte(contents))
+	assert.NotNil(t, err)
+
+	// and completed by the environment before validation
+	os.Setenv("NSXT_MANAGER_HOST", "nsxt-server")
+	os.Setenv("NSXT_PASSWORD", "env-secret")
+	cfg, err := ReadNsxtConfig([]byte(contents))
+	assert.Nil(t, err)
+	assert.Equal(t, "nsxt-server", cfg.Host)
+	assert.Equal(t, "admin", cfg.User)
+	// the environment takes precedence over the file
+	assert.Equal(t, "env-secret", cfg.Password)
+
+	iniContents := `
+[NSXT]
+user = admin
+password = secret
+`
+	cfg, err = ReadNsxtConfig([]byte(iniContents))
+	assert.Nil(t, err)
+	assert.Equal(t, "nsxt-server", cfg.Host)
+	assert.Equal(t, "env-secret", cfg.Password)
+
+	os.Setenv("NSXT_ALLOW_UNVERIFIED_SSL", "maybe")
+	_, err = ReadNsxtConfig([]byte(contents))
+	assert.NotNil(t, err)
+}
+
 func clearNsxtEnv() {
 	env := os.Environ()
 	for _, pair := range env {
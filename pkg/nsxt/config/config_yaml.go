@@ -20,7 +20,7 @@ import (
 	"errors"
 	"fmt"
 
-	"gopkg.in/yaml.v2"
+	vcfg "k8s.io/cloud-provider-vsphere/pkg/common/config"
 )
 
 /*
@@ -85,6 +85,13 @@ func (cfg *NsxtYAML) validateConfig() error {
 
 // CompleteAndValidate sets default values, overrides by env and validates the resulting config
 func (ncy *NsxtConfigYAML) CompleteAndValidate() error {
+	// Env Vars should override config file entries if present
+	cfg := Config(ncy.NSXT)
+	if err := cfg.FromEnv(); err != nil {
+		return err
+	}
+	ncy.NSXT = NsxtYAML(cfg)
+
 	return ncy.NSXT.validateConfig()
 }
 
@@ -96,7 +103,7 @@ func ReadRawConfigYAML(configData []byte) (*NsxtConfigYAML, error) {
 
 	cfg := NsxtConfigYAML{}
 
-	if err := yaml.Unmarshal(configData, &cfg); err != nil {
+	if err := vcfg.UnmarshalYAML(configData, &cfg); err != nil {
 		return nil, err
 	}
 
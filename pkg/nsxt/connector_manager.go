@@ -111,7 +111,7 @@ func NewConnectorManager(nsxtConfig *config.Config) (*ConnectorManager, error) {
 	if nsxtConfig.RemoteAuth {
 		connector.AddRequestProcessor(newRemoteBasicAuthHeaderProcessor())
 	}
-	cm.connector = connector
+	cm.connector = newInstrumentedConnector(connector, nsxtConfig.Host)
 
 	return cm, nil
 }
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nsxt
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/core"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/protocol/client"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	apiRequestDurationMetric = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "nsxt_api_request_duration_seconds",
+			Help:           "Latency of NSX-T API requests by method",
+			Buckets:        metrics.ExponentialBuckets(0.005, 2, 14),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"endpoint", "method"},
+	)
+
+	apiRequestErrorsMetric = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "nsxt_api_request_errors_total",
+			Help:           "Number of failed NSX-T API requests by method and error type",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"endpoint", "method", "fault"},
+	)
+
+	apiRequestsInFlightMetric = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "cloudprovider_vsphere",
+			Name:           "nsxt_api_requests_in_flight",
+			Help:           "Number of NSX-T API requests in flight by method",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"endpoint", "method"},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// registerMetrics registers the NSX-T API metrics with the global registry
+// used by the cloud controller manager.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(apiRequestDurationMetric)
+		legacyregistry.MustRegister(apiRequestErrorsMetric)
+		legacyregistry.MustRegister(apiRequestsInFlightMetric)
+	})
+}
+
+// instrumentedConnector is a connector whose API provider records every
+// request in the NSX-T API metrics.
+type instrumentedConnector struct {
+	client.Connector
+	endpoint string
+}
+
+// newInstrumentedConnector wraps the connector of the NSX-T manager at endpoint.
+func newInstrumentedConnector(connector client.Connector, endpoint string) client.Connector {
+	registerMetrics()
+	return &instrumentedConnector{Connector: connector, endpoint: endpoint}
+}
+
+// GetApiProvider implements client.Connector.
+func (c *instrumentedConnector) GetApiProvider() core.APIProvider {
+	return &instrumentedAPIProvider{provider: c.Connector.GetApiProvider(), endpoint: c.endpoint}
+}
+
+// instrumentedAPIProvider records the invocations of an API provider in the
+// NSX-T API metrics.
+type instrumentedAPIProvider struct {
+	provider core.APIProvider
+	endpoint string
+}
+
+// Invoke implements core.APIProvider.
+func (p *instrumentedAPIProvider) Invoke(serviceID string, operationID string, inputValue data.DataValue, ctx *core.ExecutionContext) core.MethodResult {
+	method := apiMethod(serviceID, operationID)
+	inFlight := apiRequestsInFlightMetric.WithLabelValues(p.endpoint, method)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	start := time.Now()
+	result := p.provider.Invoke(serviceID, operationID, inputValue, ctx)
+	apiRequestDurationMetric.WithLabelValues(p.endpoint, method).Observe(time.Since(start).Seconds())
+	if !result.IsSuccess() {
+		apiRequestErrorsMetric.WithLabelValues(p.endpoint, method, faultType(result.Error())).Inc()
+	}
+	return result
+}
+
+// apiMethod returns the method label of an operation of a vAPI service, e.g.
+// nsx_policy.infra.lb_services.get.
+func apiMethod(serviceID string, operationID string) string {
+	return strings.TrimPrefix(serviceID, "com.vmware.") + "." + operationID
+}
+
+// faultType returns the type of a vAPI error, e.g. not_found for
+// com.vmware.vapi.std.errors.not_found.
+func faultType(err *data.ErrorValue) string {
+	name := err.Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nsxt
+
+import (
+	"testing"
+
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/core"
+	"github.com/vmware/vsphere-automation-sdk-go/runtime/data"
+	"k8s.io/cloud-provider-vsphere/pkg/nsxt/config"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+type fakeAPIProvider struct {
+	err *data.ErrorValue
+}
+
+func (p *fakeAPIProvider) Invoke(serviceID string, operationID string, inputValue data.DataValue, ctx *core.ExecutionContext) core.MethodResult {
+	return core.NewMethodResult(data.NewStringValue("ok"), p.err)
+}
+
+func TestInstrumentedAPIProvider(t *testing.T) {
+	registerMetrics()
+	const endpoint = "nsxt.example.com"
+	method := "nsx_policy.infra.lb_services.get"
+
+	provider := &instrumentedAPIProvider{provider: &fakeAPIProvider{}, endpoint: endpoint}
+	if result := provider.Invoke("com.vmware.nsx_policy.infra.lb_services", "get", nil, nil); !result.IsSuccess() {
+		t.Fatal("result of the provider should be returned")
+	}
+	latency, _ := testutil.GetHistogramMetricValue(apiRequestDurationMetric.WithLabelValues(endpoint, method))
+	if latency <= 0 {
+		t.Error("latency of the request should be recorded")
+	}
+
+	provider.provider = &fakeAPIProvider{err: data.NewErrorValue("com.vmware.vapi.std.errors.not_found", nil)}
+	if result := provider.Invoke("com.vmware.nsx_policy.infra.lb_services", "get", nil, nil); result.IsSuccess() {
+		t.Fatal("error of the provider should be returned")
+	}
+	faults, _ := testutil.GetCounterMetricValue(apiRequestErrorsMetric.WithLabelValues(endpoint, method, "not_found"))
+	if faults != 1 {
+		t.Errorf("error of the request should be counted by type, got %v", faults)
+	}
+	inFlight, _ := testutil.GetGaugeMetricValue(apiRequestsInFlightMetric.WithLabelValues(endpoint, method))
+	if inFlight != 0 {
+		t.Errorf("no requests should be in flight, got %v", inFlight)
+	}
+}
+
+func TestConnectorIsInstrumented(t *testing.T) {
+	cm, err := NewConnectorManager(&config.Config{Host: "nsxt.example.com", User: "admin", Password: "secret"})
+	if err != nil {
+		t.Fatalf("NewConnectorManager err=%v", err)
+	}
+	if _, ok := cm.GetConnector().GetApiProvider().(*instrumentedAPIProvider); !ok {
+		t.Error("API provider of the connector should be instrumented")
+	}
+	if cm.GetConnector().SecurityContext() == nil {
+		t.Error("security context should be set on the wrapped connector")
+	}
+}